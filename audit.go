@@ -0,0 +1,122 @@
+package gen
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gorm.io/gen/field"
+)
+
+const auditSettingKey = "gen:audit_columns"
+
+// AuditColumns audit columns filled automatically by query code
+type AuditColumns struct {
+	CreatedBy string // column filled with operator carried by context on create, see gen.WithOperator
+	UpdatedBy string // column filled with operator carried by context on create and update
+	CreatedAt string // time column tagged with autoCreateTime
+	UpdatedAt string // time column tagged with autoUpdateTime
+}
+
+// modelOpts tag time columns for gorm to fill them
+func (c AuditColumns) modelOpts() (opts []ModelOpt) {
+	if c.CreatedAt != "" {
+		opts = append(opts, FieldGORMTag(c.CreatedAt, func(tag field.GormTag) field.GormTag {
+			return tag.Set("autoCreateTime", "")
+		}))
+	}
+	if c.UpdatedAt != "" {
+		opts = append(opts, FieldGORMTag(c.UpdatedAt, func(tag field.GormTag) field.GormTag {
+			return tag.Set("autoUpdateTime", "")
+		}))
+	}
+	return opts
+}
+
+type operatorCtxKey struct{}
+
+// WithOperator return context carrying operator, which fills audit columns CreatedBy/UpdatedBy
+func WithOperator(ctx context.Context, operator interface{}) context.Context {
+	return context.WithValue(ctx, operatorCtxKey{}, operator)
+}
+
+// OperatorFromContext return operator carried by context
+func OperatorFromContext(ctx context.Context) (operator interface{}, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	operator = ctx.Value(operatorCtxKey{})
+	return operator, operator != nil
+}
+
+// UseAudit fill columns with operator carried by context, createdBy on create, updatedBy on create and update,
+// empty column is ignored. Statements of d fail with the returned error if callbacks can't be registered
+func (d *DO) UseAudit(createdBy, updatedBy string) error {
+	if err := registerAuditCallbacks(d.db); err != nil {
+		d.db = d.withError(err).db
+		return err
+	}
+	d.auditColumns = [2]string{createdBy, updatedBy}
+	d.db = d.settingDB(d.db)
+	return nil
+}
+
+var auditCallbacks sync.Map // callbacks of db -> struct{}
+
+func registerAuditCallbacks(db *gorm.DB) error {
+	if _, loaded := auditCallbacks.LoadOrStore(db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register("gen:audit_create", fillCreateAudit); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("gen:audit_update", fillUpdateAudit)
+}
+
+// audit return audit columns and operator of statement, ok is false when there's nothing to fill
+func audit(db *gorm.DB) (columns [2]string, operator interface{}, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return columns, nil, false
+	}
+	v, audited := db.Get(auditSettingKey)
+	if !audited {
+		return columns, nil, false
+	}
+	operator, ok = OperatorFromContext(db.Statement.Context)
+	return v.([2]string), operator, ok
+}
+
+func fillCreateAudit(db *gorm.DB) {
+	columns, operator, ok := audit(db)
+	if !ok {
+		return
+	}
+	for _, column := range columns {
+		if column != "" && db.Statement.Schema.LookUpField(column) != nil {
+			db.Statement.SetColumn(column, operator, true)
+		}
+	}
+}
+
+func fillUpdateAudit(db *gorm.DB) {
+	columns, operator, ok := audit(db)
+	if !ok || columns[1] == "" {
+		return
+	}
+	f := db.Statement.Schema.LookUpField(columns[1])
+	if f == nil {
+		return
+	}
+
+	// assignments are built from SET clause instead of dest when it exists
+	if c, exist := db.Statement.Clauses["SET"]; exist {
+		if set, isSet := c.Expression.(clause.Set); isSet {
+			c.Expression = append(set, clause.Assignment{Column: clause.Column{Name: f.DBName}, Value: operator})
+			db.Statement.Clauses["SET"] = c
+		}
+		return
+	}
+	db.Statement.SetColumn(f.DBName, operator, true)
+}
@@ -0,0 +1,108 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	"gorm.io/gen/internal/parser"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// baseModelFileName name of file generated for base model, see Config.BaseModelColumns
+const baseModelFileName = "base_model"
+
+// extractBaseModel mark fields of BaseModelColumns as embedded in models having all of them, fields of the first
+// model(by table name) are declared in base model, models whose fields have different types or tags keep them
+func (g *Generator) extractBaseModel() {
+	g.baseModel = nil
+	if len(g.BaseModelColumns) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(g.models))
+	for name, meta := range g.models {
+		if meta != nil && meta.Generated && g.modelPackage(meta.TableName) == "" { // embedded only in model package
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		meta := g.models[name]
+		fields, ok := g.baseModelFields(meta)
+		if !ok {
+			continue
+		}
+		if g.baseModel == nil {
+			g.baseModel = &generate.QueryStructMeta{
+				Generated:       true,
+				FileName:        baseModelFileName,
+				ModelStructName: g.BaseModelName,
+				StructInfo:      parser.Param{Package: meta.StructInfo.Package},
+				Fields:          fields,
+				ImportPkgPaths:  meta.ImportPkgPaths,
+			}
+		} else if !sameFields(g.baseModel.Fields, fields) {
+			continue
+		}
+		for _, f := range fields {
+			f.Embedded = true
+		}
+		meta.BaseModel = g.BaseModelName
+	}
+}
+
+// baseModelFields fields of meta mapped to BaseModelColumns in order, ok is false if any column is missing
+// or mapped to named type generated for the model, e.g. enum
+func (g *Generator) baseModelFields(meta *generate.QueryStructMeta) (fields []*model.Field, ok bool) {
+	if meta.ModelStructName == g.BaseModelName {
+		return nil, false
+	}
+	for _, column := range g.BaseModelColumns {
+		var found *model.Field
+		for _, f := range meta.Fields {
+			if f.ColumnName == column && !f.IsRelation() {
+				found = f
+				break
+			}
+		}
+		if found == nil || found.Enum != nil || found.BinaryUUID != nil || found.Geometry != nil {
+			return nil, false
+		}
+		fields = append(fields, found)
+	}
+	return fields, true
+}
+
+// sameFields whether fields have the same names, types and tags
+func sameFields(a, b []*model.Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Type != b[i].Type || a[i].Tags() != b[i].Tags() {
+			return false
+		}
+	}
+	return true
+}
+
+// generateBaseModelFile generate base model struct into model pkg path
+func (g *Generator) generateBaseModelFile(modelOutPath string) error {
+	if g.baseModel == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := g.modelTemplates.render(baseModelTemplate, tmpl.BaseModel, &buf, g.baseModel); err != nil {
+		return err
+	}
+	modelFile := modelOutPath + g.baseModel.FileName + ".gen.go"
+	if err := g.output(modelFile, buf.Bytes()); err != nil {
+		return err
+	}
+	g.info(fmt.Sprintf("generate base model file(%v -> {%s.%s}): %s", g.BaseModelColumns, g.baseModel.StructInfo.Package, g.baseModel.ModelStructName, modelFile))
+	return nil
+}
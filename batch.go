@@ -0,0 +1,194 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
+)
+
+// DefaultBatchSize batch size of CreateInBatches(batchSize <= 0), UpdateColumnsInBatches and DeleteByPrimaryKeys,
+// unless it's set by UseBatchSize(Config.BatchSize of generated code)
+const DefaultBatchSize = 100
+
+// placeholderLimits max number of bind parameters of a statement by dialect name
+var placeholderLimits = map[string]int{"sqlserver": 2100, "postgres": 65535, "mysql": 65535, "sqlite": 32766}
+
+// UseBatchSize set default batch size of batch methods
+func (d *DO) UseBatchSize(size int) { d.batchSize = size }
+
+// capBatchSize return batch size of statements binding params placeholders per row and fixed placeholders besides rows,
+// default batch size is used if size <= 0, and it's capped so that placeholders of a batch don't exceed limit of dialect,
+// e.g. 2100 of sqlserver
+func (d *DO) capBatchSize(size, params, fixed int) int {
+	if size <= 0 {
+		size = d.batchSize
+	}
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if limit, ok := placeholderLimits[d.db.Dialector.Name()]; ok && params > 0 && size*params+fixed > limit {
+		size = (limit - fixed) / params
+		if size <= 0 {
+			size = 1
+		}
+	}
+	return size
+}
+
+// fixedParams return number of placeholders of statement built by build besides params placeholders of its row,
+// e.g. conditions, tenant id and deleted_at of soft delete added by callbacks, counted by dry running it with one row
+func (d *DO) fixedParams(params int, build func(db *gorm.DB) *gorm.DB) int {
+	stmt := build(d.db.Session(&gorm.Session{DryRun: true, SkipHooks: true})).Statement
+	if fixed := len(stmt.Vars) - params; fixed > 0 {
+		return fixed
+	}
+	return 0
+}
+
+// modelSchema return parsed schema of model
+func (d *DO) modelSchema() (*schema.Schema, error) {
+	if d.modelType == nil {
+		return nil, fmt.Errorf("model is not specified")
+	}
+	stmt := &gorm.Statement{DB: d.db}
+	if err := stmt.Parse(d.newResultPointer()); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// createParams return number of placeholders of each created record, 0 if model is unknown
+func (d *DO) createParams() (params int) {
+	s, err := d.modelSchema()
+	if err != nil {
+		return 0
+	}
+	for _, f := range s.Fields {
+		if f.DBName != "" && f.Creatable {
+			params++
+		}
+	}
+	return params
+}
+
+// inBatches call fc with [start, end) of each batch of n items, batches are run in a transaction if there are more than one
+func (d *DO) inBatches(n, batchSize int, fc func(tx *DO, start, end int) error) error {
+	run := func(tx *gorm.DB) error {
+		for start := 0; start < n; start += batchSize {
+			end := start + batchSize
+			if end > n {
+				end = n
+			}
+			if err := fc(d.getInstance(tx), start, end); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if n <= batchSize || d.db.SkipDefaultTransaction {
+		return run(d.db.Session(&gorm.Session{})) // batches start from statement of d as tx of Transaction does
+	}
+	return d.db.Transaction(run)
+}
+
+// UpdateColumnsInBatches update columns of records in values(slice of model) matched by primary key batch by batch,
+// each batch is updated by one statement: UPDATE ... SET column = CASE pk WHEN ? THEN ? ... ELSE column END WHERE pk IN (?),
+// hooks and auto update time are skipped as UpdateColumns
+func (d *DO) UpdateColumnsInBatches(values interface{}, batchSize int, columns ...field.Expr) (info ResultInfo, err error) {
+	pk, err := d.primaryField()
+	if err != nil {
+		return info, err
+	}
+	s := pk.Schema
+	if len(columns) == 0 {
+		return info, fmt.Errorf("no column of model %s to update", s.Name)
+	}
+	fields := make([]*schema.Field, len(columns))
+	for i, column := range columns {
+		f := s.LookUpField(string(column.ColumnName()))
+		if f == nil || f.DBName == "" || f.PrimaryKey {
+			return info, fmt.Errorf("column %s of model %s cannot be updated", column.ColumnName(), s.Name)
+		}
+		fields[i] = f
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return info, fmt.Errorf("values should be slice of model %s", s.Name)
+	}
+	ctx := d.db.Statement.Context
+	update := func(db *gorm.DB, batch reflect.Value) *gorm.DB {
+		pks := make([]interface{}, batch.Len())
+		for i := range pks {
+			pks[i], _ = pk.ValueOf(ctx, reflect.Indirect(batch.Index(i)))
+		}
+
+		updates := make(map[string]interface{}, len(fields))
+		cases := "CASE ? " + strings.Repeat("WHEN ? THEN ? ", len(pks)) + "ELSE ? END"
+		for _, f := range fields {
+			vars := make([]interface{}, 0, 2*len(pks)+2)
+			vars = append(vars, clause.Column{Name: pk.DBName})
+			for i := range pks {
+				value, _ := f.ValueOf(ctx, reflect.Indirect(batch.Index(i)))
+				vars = append(vars, pks[i], value)
+			}
+			updates[f.DBName] = gorm.Expr(cases, append(vars, clause.Column{Name: f.DBName})...)
+		}
+		return db.Model(d.newResultPointer()).
+			Where(clause.IN{Column: clause.Column{Name: pk.DBName}, Values: pks}).UpdateColumns(updates)
+	}
+
+	params, fixed := 2*len(fields)+1, 0
+	if rv.Len() > 0 {
+		fixed = d.fixedParams(params, func(db *gorm.DB) *gorm.DB { return update(db, rv.Slice(0, 1)) })
+	}
+	err = d.inBatches(rv.Len(), d.capBatchSize(batchSize, params, fixed), func(tx *DO, start, end int) error {
+		batch := rv.Slice(start, end)
+		result, err := tx.exec(batch.Interface(), func() *gorm.DB { return update(tx.db, batch) })
+		info.RowsAffected += result.RowsAffected
+		return err
+	})
+	info.Error = err
+	return info, err
+}
+
+// DeleteByPrimaryKeys delete records by primary keys(slice of primary key values) batch by batch,
+// primary keys of each batch are matched by IN
+func (d *DO) DeleteByPrimaryKeys(pks interface{}, batchSize int) (info ResultInfo, err error) {
+	pk, err := d.primaryField()
+	if err != nil {
+		return info, err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(pks))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return info, fmt.Errorf("primary keys should be slice")
+	}
+	del := func(db *gorm.DB, values []interface{}) *gorm.DB {
+		in := clause.IN{Column: clause.Column{Name: pk.DBName}, Values: values}
+		return db.Clauses(clause.Where{Exprs: []clause.Expression{in}}).
+			Model(d.newResultPointer()).Delete(reflect.New(d.modelType).Interface())
+	}
+
+	fixed := 0
+	if rv.Len() > 0 {
+		fixed = d.fixedParams(1, func(db *gorm.DB) *gorm.DB { return del(db, []interface{}{rv.Index(0).Interface()}) })
+	}
+	err = d.inBatches(rv.Len(), d.capBatchSize(batchSize, 1, fixed), func(tx *DO, start, end int) error {
+		values := make([]interface{}, end-start)
+		for i := range values {
+			values[i] = rv.Index(start + i).Interface()
+		}
+		result, err := tx.exec(nil, func() *gorm.DB { return del(tx.db, values) })
+		info.RowsAffected += result.RowsAffected
+		return err
+	})
+	info.Error = err
+	return info, err
+}
@@ -0,0 +1,268 @@
+package gen
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Cache store records looked up by primary key, implementations are provided in package gorm.io/gen/cache
+type Cache interface {
+	// Get return cached value, ok is false when key is absent or expired
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set store value with ttl, ttl <= 0 means never expire
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete remove keys
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// WithCache enable caching records looked up by primary key, records are cached by columns and columns with serializer
+// are cached serialized(e.g. encrypted PII), cached records are deleted when they are created, updated or deleted
+// through the same query object
+func WithCache(cache Cache, ttl time.Duration) DOOption {
+	return &cacheOption{cache: cache, ttl: ttl}
+}
+
+type cacheOption struct {
+	cache Cache
+	ttl   time.Duration
+}
+
+// Apply update config to new config
+func (o *cacheOption) Apply(config *DOConfig) error {
+	config.cache = o.cache
+	config.cacheTTL = o.ttl
+	return nil
+}
+
+// AfterInitialize initialize plugins after db connected
+func (o *cacheOption) AfterInitialize(*DO) error { return nil }
+
+// TakeByPrimaryKey query record by primary key, look up cache first when cache is enabled by WithCache.
+// Conditions of current query are ignored when record is returned from cache
+func (d *DO) TakeByPrimaryKey(pk interface{}) (result interface{}, err error) {
+	pkField, err := d.primaryField()
+	if err != nil {
+		return nil, err
+	}
+	tx := d.getInstance(d.db.Clauses(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Name: pkField.DBName}, Value: pk}}}))
+	if !d.cacheEnabled() {
+		return tx.Take()
+	}
+	if _, ok := TenantFromContext(d.db.Statement.Context); d.tenantColumn != "" && !ok {
+		return nil, ErrMissingTenant
+	}
+
+	ctx, key := d.db.Statement.Context, d.cacheKey(pk)
+	if data, ok, err := d.cache.Get(ctx, key); err != nil {
+		d.db.Logger.Warn(ctx, "get cache %s fail: %s", key, err)
+	} else if ok {
+		result = d.newResultPointer()
+		if err = d.decodeRecord(data, result); err == nil {
+			return result, nil
+		}
+		d.db.Logger.Warn(ctx, "decode cache %s fail: %s", key, err)
+	}
+
+	if result, err = tx.Take(); err != nil {
+		return nil, err
+	}
+	if data, err := d.encodeRecord(result); err != nil {
+		d.db.Logger.Warn(ctx, "encode cache %s fail: %s", key, err)
+	} else if err = d.cache.Set(ctx, key, data, d.cacheTTL); err != nil {
+		d.db.Logger.Warn(ctx, "set cache %s fail: %s", key, err)
+	}
+	return result, nil
+}
+
+// encodeRecord encode columns of record as json object keyed by column name, so that values are cached as they are
+// regardless of json tags and masking of json output(e.g. json:"-" fields and PII). Columns with serializer are
+// cached as their serialized value, e.g. PII is cached encrypted
+func (d *DO) encodeRecord(record interface{}) ([]byte, error) {
+	s, err := d.modelSchema()
+	if err != nil {
+		return nil, err
+	}
+	ctx, rv := d.db.Statement.Context, reflect.Indirect(reflect.ValueOf(record))
+	columns := make(map[string]interface{}, len(s.Fields))
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+		value, zero := f.ValueOf(ctx, rv)
+		if f.Serializer != nil {
+			if value, err = value.(driver.Valuer).Value(); err != nil {
+				return nil, fmt.Errorf("serialize column %s fail: %w", f.DBName, err)
+			}
+			switch v := value.(type) {
+			case nil:
+				continue
+			case string:
+				value = []byte(v)
+			}
+		} else if zero && f.FieldType.Kind() != reflect.Ptr {
+			continue
+		}
+		columns[f.DBName] = value
+	}
+	return json.Marshal(columns)
+}
+
+// decodeRecord decode columns encoded by encodeRecord into record
+func (d *DO) decodeRecord(data []byte, record interface{}) error {
+	s, err := d.modelSchema()
+	if err != nil {
+		return err
+	}
+	var columns map[string]json.RawMessage
+	if err = json.Unmarshal(data, &columns); err != nil {
+		return err
+	}
+	ctx, rv := d.db.Statement.Context, reflect.Indirect(reflect.ValueOf(record))
+	for _, f := range s.Fields {
+		raw, ok := columns[f.DBName]
+		if f.DBName == "" || !ok {
+			continue
+		}
+		if f.Serializer != nil {
+			var value []byte
+			if err = json.Unmarshal(raw, &value); err == nil {
+				err = f.Serializer.Scan(ctx, f, rv, value)
+			}
+			if err != nil {
+				return fmt.Errorf("deserialize column %s fail: %w", f.DBName, err)
+			}
+			continue
+		}
+		value := reflect.New(f.FieldType)
+		if err = json.Unmarshal(raw, value.Interface()); err != nil {
+			return fmt.Errorf("decode column %s fail: %w", f.DBName, err)
+		}
+		f.ReflectValueOf(ctx, rv).Set(value.Elem())
+	}
+	return nil
+}
+
+// exec execute write operation, delete cached records written by it when cache is enabled.
+// Records are taken from values, or queried with current conditions before writing when values is nil
+func (d *DO) exec(values interface{}, fc func() *gorm.DB) (info ResultInfo, err error) {
+	var keys []string
+	if d.cacheEnabled() && values == nil {
+		if keys, err = d.queryCacheKeys(); err != nil {
+			return ResultInfo{Error: err}, err
+		}
+	}
+
+	result := fc()
+	info = ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}
+	if result.Error != nil || !d.cacheEnabled() {
+		return info, result.Error
+	}
+
+	if values != nil {
+		keys = d.cacheKeys(values)
+	}
+	if len(keys) == 0 {
+		return info, nil
+	}
+	if err = d.cache.Delete(d.db.Statement.Context, keys...); err != nil {
+		info.Error = err
+	}
+	return info, err
+}
+
+func (d *DO) cacheEnabled() bool {
+	return d.DOConfig != nil && d.cache != nil && d.modelType != nil
+}
+
+func (d *DO) primaryField() (*schema.Field, error) {
+	s, err := d.modelSchema()
+	if err != nil {
+		return nil, err
+	}
+	// gorm prioritizes id or auto increment column of composite primary key, which doesn't identify a record alone
+	if len(s.PrimaryFields) != 1 {
+		return nil, fmt.Errorf("model %s has no or composite primary key", s.Name)
+	}
+	return s.PrimaryFields[0], nil
+}
+
+// cacheKey return cache key of record, records of tenant scoped table are cached by tenant carried by context,
+// so that a record cached for one tenant is never returned to another
+func (d *DO) cacheKey(pk interface{}) string {
+	if d.tenantColumn != "" {
+		tenantID, _ := TenantFromContext(d.db.Statement.Context)
+		return fmt.Sprintf("%s:%v:%v", d.TableName(), tenantID, pk)
+	}
+	return fmt.Sprintf("%s:%v", d.TableName(), pk)
+}
+
+// cacheKeys return cache keys of records in values, records with zero primary key are skipped
+func (d *DO) cacheKeys(values interface{}) (keys []string) {
+	pkField, err := d.primaryField()
+	if err != nil {
+		return nil
+	}
+
+	ctx := d.db.Statement.Context
+	appendKey := func(v reflect.Value) {
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.Struct || v.Type() != d.modelType {
+			return
+		}
+		if pk, zero := pkField.ValueOf(ctx, v); !zero {
+			if pv := reflect.ValueOf(pk); pv.Kind() == reflect.Ptr {
+				pk = pv.Elem().Interface()
+			}
+			keys = append(keys, d.cacheKey(pk))
+		}
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(values))
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			appendKey(value.Index(i))
+		}
+	default:
+		appendKey(value)
+	}
+	return keys
+}
+
+// queryCacheKeys query cache keys of records matching current conditions, records of model without single primary
+// key are never cached, so there's nothing to query
+func (d *DO) queryCacheKeys() ([]string, error) {
+	if _, ok := d.db.Statement.Clauses["WHERE"]; !ok && !d.db.AllowGlobalUpdate {
+		return nil, nil
+	}
+	s, err := d.modelSchema()
+	if err != nil || len(s.PrimaryFields) != 1 {
+		return nil, err
+	}
+	pkField := s.PrimaryFields[0]
+
+	pks := reflect.New(reflect.SliceOf(pkField.FieldType))
+	if err = d.db.Session(&gorm.Session{}).Model(d.newResultPointer()).Pluck(pkField.DBName, pks.Interface()).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, pks.Elem().Len())
+	for i := 0; i < pks.Elem().Len(); i++ {
+		pk := pks.Elem().Index(i)
+		if pk.Kind() == reflect.Ptr {
+			if pk.IsNil() {
+				continue
+			}
+			pk = pk.Elem()
+		}
+		keys = append(keys, d.cacheKey(pk.Interface()))
+	}
+	return keys, nil
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gen"
+)
+
+var (
+	_ gen.Cache = new(Memory)
+	_ gen.Cache = new(Redis)
+)
+
+func testCache(t *testing.T, c gen.Cache) {
+	ctx := context.Background()
+	if err := c.Set(ctx, "user:1", []byte(`{"id":1}`), 0); err != nil {
+		t.Fatalf("set fail: %s", err)
+	}
+	if err := c.Set(ctx, "user:2", []byte(`{"id":2}`), 50*time.Millisecond); err != nil {
+		t.Fatalf("set fail: %s", err)
+	}
+	if value, ok, err := c.Get(ctx, "user:1"); err != nil || !ok || string(value) != `{"id":1}` {
+		t.Errorf("get user:1 got: %q %v %v", value, ok, err)
+	}
+	if _, ok, err := c.Get(ctx, "user:2"); err != nil || !ok {
+		t.Errorf("get user:2 got: %v %v", ok, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, ok, err := c.Get(ctx, "user:2"); err != nil || ok {
+		t.Errorf("get expired user:2 got: %v %v", ok, err)
+	}
+	if err := c.Delete(ctx, "user:1", "user:3"); err != nil {
+		t.Fatalf("delete fail: %s", err)
+	}
+	if _, ok, err := c.Get(ctx, "user:1"); err != nil || ok {
+		t.Errorf("get deleted user:1 got: %v %v", ok, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testCache(t, NewMemory())
+}
+
+func TestRedis(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen fail: %s", err)
+	}
+	defer ln.Close() // nolint
+	go serveFakeRedis(ln)
+
+	r := NewRedis(RedisConfig{Addr: ln.Addr().String(), Prefix: "test:"})
+	defer r.Close() // nolint
+	testCache(t, r)
+}
+
+// serveFakeRedis serve GET/SET/DEL commands backed by Memory
+func serveFakeRedis(ln net.Listener) {
+	store := NewMemory()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close() // nolint
+			r := bufio.NewReader(conn)
+			for {
+				args, err := readCommand(r)
+				if err != nil {
+					return
+				}
+				ctx := context.Background()
+				switch strings.ToUpper(args[0]) {
+				case "GET":
+					if value, ok, _ := store.Get(ctx, args[1]); ok {
+						fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+					} else {
+						fmt.Fprint(conn, "$-1\r\n")
+					}
+				case "SET":
+					var ttl time.Duration
+					if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+						ms, _ := strconv.Atoi(args[4])
+						ttl = time.Duration(ms) * time.Millisecond
+					}
+					_ = store.Set(ctx, args[1], []byte(args[2]), ttl)
+					fmt.Fprint(conn, "+OK\r\n")
+				case "DEL":
+					_ = store.Delete(ctx, args[1:]...)
+					fmt.Fprintf(conn, ":%d\r\n", len(args)-1)
+				default:
+					fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+				}
+			}
+		}(conn)
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line)[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line)[1:])
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err = io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
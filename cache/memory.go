@@ -0,0 +1,67 @@
+// Package cache provides reference implementations of gen.Cache
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory in-memory cache, expired items are removed when they are read or overwritten
+type Memory struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+}
+
+type memoryItem struct {
+	value    []byte
+	expireAt time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool { return !i.expireAt.IsZero() && now.After(i.expireAt) }
+
+// NewMemory create in-memory cache
+func NewMemory() *Memory {
+	return &Memory{items: make(map[string]memoryItem)}
+}
+
+// Get return cached value
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	item, ok := m.items[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	if item.expired(time.Now()) {
+		m.mu.Lock()
+		if item, ok = m.items[key]; ok && item.expired(time.Now()) {
+			delete(m.items, key)
+		}
+		m.mu.Unlock()
+		return nil, false, nil
+	}
+	return item.value, true, nil
+}
+
+// Set store value with ttl, ttl <= 0 means never expire
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
+	m.mu.Lock()
+	m.items[key] = item
+	m.mu.Unlock()
+	return nil
+}
+
+// Delete remove keys
+func (m *Memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	m.mu.Unlock()
+	return nil
+}
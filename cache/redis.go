@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisConfig redis connection configuration
+type RedisConfig struct {
+	Addr     string        // host:port, default: 127.0.0.1:6379
+	Password string        // password for AUTH, empty means no auth
+	DB       int           // database selected after connected
+	Prefix   string        // prefix of all keys
+	PoolSize int           // max idle connections, default: 8
+	Timeout  time.Duration // dial and read/write timeout, default: 3s
+}
+
+// Redis redis cache speaking RESP protocol, without dependency on any redis client
+type Redis struct {
+	config RedisConfig
+	idle   chan *redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// NewRedis create redis cache, connections are dialed on demand
+func NewRedis(config RedisConfig) *Redis {
+	if config.Addr == "" {
+		config.Addr = "127.0.0.1:6379"
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 8
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+	return &Redis{config: config, idle: make(chan *redisConn, config.PoolSize)}
+}
+
+// Get return cached value
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", r.config.Prefix+key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	return value, true, nil
+}
+
+// Set store value with ttl, ttl <= 0 means never expire
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) (err error) {
+	if ttl > 0 {
+		_, err = r.do(ctx, "SET", r.config.Prefix+key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	} else {
+		_, err = r.do(ctx, "SET", r.config.Prefix+key, string(value))
+	}
+	return err
+}
+
+// Delete remove keys
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]string, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, r.config.Prefix+key)
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// Close close idle connections
+func (r *Redis) Close() error {
+	for {
+		select {
+		case conn := <-r.idle:
+			_ = conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *Redis) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(ctx, r.config.Timeout, args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) { // connection is broken
+		_ = conn.Close()
+		return nil, err
+	}
+	select {
+	case r.idle <- conn:
+	default:
+		_ = conn.Close()
+	}
+	return reply, err
+}
+
+func (r *Redis) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-r.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: r.config.Timeout}
+	c, err := dialer.DialContext(ctx, "tcp", r.config.Addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{Conn: c, r: bufio.NewReader(c)}
+	if r.config.Password != "" {
+		if _, err = conn.do(ctx, r.config.Timeout, "AUTH", r.config.Password); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	if r.config.DB != 0 {
+		if _, err = conn.do(ctx, r.config.Timeout, "SELECT", strconv.Itoa(r.config.DB)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func (c *redisConn) do(ctx context.Context, timeout time.Duration, args ...string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := c.Write(buf); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply read one RESP reply: simple string as string, integer as int64, bulk string as []byte, array as []interface{}
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid redis reply %q", line)
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err = io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("invalid redis reply %q", line)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/utils/tests"
@@ -24,16 +25,100 @@ const (
 
 	// WithQueryInterface generate code with exported interface object
 	WithQueryInterface
+
+	// WithExistsMethod generate Exists/ExistsBy<PK> and CountBy<IndexedColumn> methods,
+	// CountBy methods need index info which requires FieldWithIndexTag when syncing table from db
+	WithExistsMethod
+
+	// WithEachMethod generate Each/EachInBatches methods which stream query results instead of loading all records
+	WithEachMethod
+
+	// WithLockingMethod generate ForUpdate/ForShare methods which apply row level locking
+	WithLockingMethod
+
+	// WithCacheMethod generate GetBy<PK> methods served by cache, cache is enabled by gen.WithCache when calling Use
+	WithCacheMethod
+
+	// WithResolverMethod generate ReadOnly/Primary/UseResolver methods, resolvers are registered by gen.WithDBResolver when calling Use
+	WithResolverMethod
+
+	// WithPreloadMethod generate With<Relation> methods preloading relation fields, and With<Relation> functions of
+	// nested preloads, e.g. u.WithContext(ctx).WithOrders(query.WithItems())
+	WithPreloadMethod
+
+	// WithFilterMethod generate <Model>Filter struct of optional filters(equality, range of time and number columns,
+	// In of enum columns) and ApplyFilter method building conditions of non-nil filters
+	WithFilterMethod
+
+	// WithSortMethod generate <Model>Field enum of columns, Parse<Model>Field, and OrderBy and SelectFields methods
+	// accepting the enum, see ParseOrders and ParseFields
+	WithSortMethod
+
+	// WithBatchMethod generate UpdateColumnsInBatches and DeleteBy<PK>s methods, which run in batches of
+	// Config.BatchSize capped by placeholder limit of dialect
+	WithBatchMethod
+
+	// WithReturningMethod generate UpdateReturning/DeleteReturning methods which return affected records by RETURNING,
+	// only for postgres and sqlite(3.35+)
+	WithReturningMethod
+
+	// WithAffectedMethod generate UpdateAffected, UpdatesAffected, DeleteAffected, etc. returning number of affected
+	// rows instead of gen.ResultInfo, see Config.StrictRowsAffected
+	WithAffectedMethod
+
+	// WithHintMethod generate <Model>Index constants of indexes declared by index tags of fields(FieldWithIndexTag for
+	// tables), UseIndex, ForceIndex, IgnoreIndex(mysql) accepting them and OptimizerHint, so hints of non-existent
+	// indexes fail at compile time
+	WithHintMethod
+
+	// WithGenericDAO generate CRUD methods once as generic core in query file(OutFile) embedded by query structs of
+	// tables instead of in each query file, which cuts size and compile time of generated code, requires Go 1.18+
+	WithGenericDAO
+
+	// WithFakeDAO generate <Model>Fake, an in-memory fake of DAO of each table keyed by primary key in <table>.fake.gen.go,
+	// supporting Create, Save, finders by primary key and indexes and where-equality filtering, for unit tests
+	// needing neither mocks nor database. Tables without single primary key of builtin type are skipped
+	WithFakeDAO
+
+	// WithImportExportMethod generate ExportCSV/ImportCSV and ExportJSONLines/ImportJSONLines methods transferring
+	// records in column order of model, for data migration and support tooling
+	WithImportExportMethod
+)
+
+// decimalTypes supported DecimalType -> go type of decimal column
+var decimalTypes = map[string]string{"shopspring": "decimal.Decimal", "string": "string", "float64": "float64"}
+
+// dateTypes, timeTypes, dateTimeTypes supported DateType, TimeType, DateTimeType -> go type of column
+var (
+	dateTypes     = map[string]string{"time": "time.Time", "datatypes": "datatypes.Date", "string": "string"}
+	timeTypes     = map[string]string{"time": "time.Time", "datatypes": "datatypes.Time", "string": "string"}
+	dateTimeTypes = map[string]string{"time": "time.Time", "string": "string"}
 )
 
+// returningDialects dialects supporting RETURNING of update and delete, see WithReturningMethod
+var returningDialects = map[string]bool{"postgres": true, "sqlite": true}
+
+// nullableStyles supported NullableStyle
+var nullableStyles = map[string]bool{"pointer": true, "sqlnull": true, "genericNull": true}
+
+// gormHooks supported WithHooks
+var gormHooks = map[string]bool{
+	"BeforeSave": true, "BeforeCreate": true, "AfterCreate": true, "AfterSave": true, "BeforeUpdate": true,
+	"AfterUpdate": true, "BeforeDelete": true, "AfterDelete": true, "AfterFind": true,
+}
+
 // Config generator's basic configuration
 type Config struct {
 	db *gorm.DB // db connection
 
 	OutPath      string // query code path
-	OutFile      string // query code file name, default: gen.go
+	OutFile      string // file of Use and Query shared by tables, default: gen.go, query code of table is in <table>.gen.go
 	ModelPkgPath string // generated model code's package name
 	WithUnitTest bool   // generate unit test for query code
+	// database of generated unit tests: "sqlite"(default) SQLite file, "testcontainers" database of dialect(mysql,
+	// postgres, sqlserver) started by testcontainers-go, falling back to SQLite when it fails, e.g. docker is
+	// unavailable. Schema is applied by AutoMigrate of models, so no test database needs to be provisioned
+	UnitTestDB string
 
 	// generate model global configuration
 	FieldNullable     bool // generate pointer when field is nullable
@@ -41,9 +126,210 @@ type Config struct {
 	FieldSignable     bool // detect integer field's unsigned type, adjust generated data type
 	FieldWithIndexTag bool // generate with gorm index tag
 	FieldWithTypeTag  bool // generate with gorm column type tag
+	FieldWithEnumType bool // generate named type with constants and Valid method for enum column, slice type for set column
+	// generate named type with constants and Valid method for integer column whose values are declared in comment,
+	// e.g. comment "status: 1-active 2-disabled" of users.status generates UserStatusActive = 1, UserStatusDisabled = 2
+	FieldWithCommentEnum bool
+	// render column comment as doc comment above field shown in IDE tooltips instead of trailing comment, comment
+	// starting with "DEPRECATED:" is always rendered as "Deprecated:" paragraph marking field deprecated
+	FieldWithDocComment bool
+	// tag field of deprecated column with <-:update, so it's still read and updated but not written by create
+	FieldOmitDeprecated bool
+	// go type of postgres array column by element data type, e.g. "integer" -> "pq.Int32Array", merged into default
+	// mapping to lib/pq array types, element type mapped to "" is generated by DataTypeMap as other columns
+	ArrayTypeMap map[string]string
+	// generate json/jsonb column as datatypes.JSON, use FieldJSONType to bind column to struct type with json serializer
+	FieldWithJSONType bool
+	// generate postgres uuid column as uuid.UUID of github.com/google/uuid, columns of UUIDColumnTypes(opt-in for mysql,
+	// e.g. char(36), binary(16)) too. binary(16) column generates named type of uuid.UUID which is stored as 16 bytes
+	FieldWithUUIDType bool
+	UUIDColumnTypes   []string
+	UUIDHook          bool // generate BeforeCreate hook of model populating zero uuid primary key with uuid.New()
+	// go type of decimal/numeric column: shopspring(decimal.Decimal of github.com/shopspring/decimal), string or float64,
+	// column type tag is kept. default: float64 for decimal and int32 for numeric
+	DecimalType string
+	// type of nullable field generated when FieldNullable is true: pointer(default), sqlnull(sql.NullString, sql.NullInt64, etc.,
+	// types without sql.NullXXX are still pointer) or genericNull(sql.Null[T], requires go1.22)
+	NullableStyle string
+
+	// go type of date, time and datetime/timestamp columns, "time" means time.Time(default):
+	// DateType: time, datatypes(datatypes.Date) or string; TimeType: time, datatypes(datatypes.Time) or string;
+	// DateTimeType: time or string. String columns keep column type tag
+	DateType     string
+	TimeType     string
+	DateTimeType string
+	TimeUTC      bool // generate BeforeSave and AfterFind hooks of model converting time.Time fields to UTC
+
+	// generate mysql spatial(point, polygon, geometry, etc.) and postgis geometry/geography column as named type
+	// of github.com/paulmach/orb geometry with Scan/Value of (e)wkb, e.g. ShopLocation{Point: orb.Point{lng, lat}, SRID: 4326},
+	// use FieldGeometryType to specify orb type of column
+	FieldWithGeometryType bool
+
+	// go type of integer column by dialect name(mysql, postgres, sqlite, sqlserver, clickhouse) and column type
+	// (e.g. tinyint(1)) or data type(e.g. tinyint, mediumint), overriding default mapping, unsigned column is still
+	// prefixed with u by FieldSignable, e.g. {"mysql": {"tinyint": "int8", "tinyint(1)": "int8"}} generates int8
+	// and uint8 for tinyint columns instead of int32 and bool for tinyint(1)
+	IntegerTypeMap map[string]map[string]string
+
+	// generate with gorm check tag of check constraints(mysql, postgres, sqlserver) and constraint tag(e.g.
+	// constraint:OnUpdate:CASCADE,OnDelete:SET NULL) of belongs to relation fields whose foreign key column
+	// has referential actions, so that AutoMigrate of generated models keeps constraints of existing schema
+	FieldWithConstraintTag bool
+
+	// generate belongs to relation field on model of single column foreign key(e.g. Post.Author *User of author_id)
+	// and has many relation field on referenced model(e.g. User.Posts []Post) for foreign keys between generated
+	// tables, so that relation query fields(e.g. Preload(q.User.Posts)) and association methods are generated
+	FieldWithRelation bool
+	// relation fields of models by table name, each is <relationship>:<field name>:<related table>[:<gorm tag>],
+	// relationship is has_one, has_many, belongs_to or many_to_many, e.g. {"users": {"has_many:Orders:orders",
+	// "many_to_many:Roles:roles:many2many:user_roles"}}, related table should be generated too
+	Relations map[string][]string
+
+	// generate constants of column names(Column<Model><Field>) and index names(Index<Model><Name>) of model next to
+	// TableName<Model>, so raw SQL fragments, hints and logs reference them instead of string literals
+	FieldWithNameConst bool
+
+	// lookup tables whose rows are generated as constants of ids named by model and code when generating, table
+	// name -> "<id column>:<code column>"(default "id:code"), e.g. {"statuses": ""} generates StatusShipped = 3 of
+	// row (3, shipped), so code references constants instead of magic ids. Rows can't be loaded from schema JSON
+	LookupTables map[string]string
+
+	// handling of tables without primary key, whose generated Save, Update and Delete of records can't tell rows
+	// apart: "skip" skips them with a warning, "readOnly" tags columns of their models with -> so records are never
+	// written, "uniqueIndex" tags columns of unique index of LogicalKeys, or the first unique index of NOT NULL
+	// columns by name, as primaryKey, tables without such index fall back to readOnly. Default: generate as they are
+	NoPrimaryKey string
+	// unique index used as logical primary key of table without primary key, table name -> index name, e.g.
+	// {"user_roles": "idx_user_roles_user_role"}, NoPrimaryKey of listed tables is "uniqueIndex"
+	LogicalKeys map[string]string
 
 	Mode GenerateMode // generate mode
 
+	// named SQL fragments included by {{include "name"}} in SQL annotations of ApplyInterface methods, fragments
+	// are expanded before parsing, so they can use @param, @@table and templates, and include other fragments,
+	// e.g. {"activeUsers": "deleted_at IS NULL {{if status > 0}}AND status=@status{{end}}"}
+	SQLFragments map[string]string
+	// dialect of SQL variants to generate, SQL annotations of ApplyInterface methods can declare variants of dialects
+	// by "-- dialect: <name>[, <name>...]" lines, e.g. "postgres", the unmarked SQL before the first such line is
+	// generated for method without variant of it. Default: all variants are generated and selected by name of
+	// connected dialector at runtime, dialects without variant use the unmarked SQL, or the first variant if it's empty
+	SQLDialect string
+	// default statement timeout and retry policy of transient errors(deadlock, serialization failure) of DIY methods
+	// generated by ApplyInterface, annotated by "@timeout 3s" and "@retry 3 100ms"(max attempts and backoff) lines
+	// of method comment for each method. Row/Rows and sql.Result methods are not covered, and methods called in
+	// transaction are not retried
+	StatementPolicy StatementPolicy
+	// generated Use traces statements of query code by tracer of OpenTelemetry global provider(tracing.Global), with
+	// table, operation and rows affected of statement, tracer passed by gen.WithTracing when calling Use replaces it
+	Tracing bool
+
+	// default batch size of generated CreateInBatches(batchSize <= 0), UpdateColumnsInBatches and DeleteBy<PK>s,
+	// default: gen.DefaultBatchSize. Batch size is capped by placeholder limit of dialect at runtime, e.g. 2100 of sqlserver
+	BatchSize int
+
+	// generated <Method>Affected methods of WithAffectedMethod and DIY methods returning (gen.RowsAffected, error)
+	// return gen.ErrNoRowsAffected when no row is affected, e.g. for idempotency checks
+	StrictRowsAffected bool
+	// unique lookup methods ExistsBy<PK> and FindByPK of soft-deleted models(gorm.DeletedAt) also find soft-deleted
+	// records. Default: soft-deleted records are excluded from them as other queries, FindBy<PK>Unscoped finds them
+	// for single call. GetBy<PK> served by cache always excludes them
+	UnscopedLookup bool
+
+	// column used for optimistic locking, generated Save/Updates/UpdateColumns of model check and increase it,
+	// updates by columns or expressions(Update, UpdateSimple, UpdateColumn, etc.) increase it
+	VersionColumn string
+	TenantColumn  string // column used for tenant scope, statements are filtered by tenant carried by context, see gen.WithTenant
+
+	// sharded tables, table name -> suffix pattern, generate Suffix and
+	// ForMonth(time layout, e.g. "_200601") or ForShard(fmt format, e.g. "_%02d") methods
+	ShardedTables map[string]string
+
+	AuditColumns AuditColumns // audit columns filled automatically
+
+	// extra methods generated on every model: String(format column fields for logging), IsZero, PrimaryKey(accessor of
+	// single column primary key), Clone(deep copy) and Validate(check NOT NULL, varchar length, tinyint/unsigned range
+	// and enum values, return gen.ValidationErrors), method is skipped if model has method or field of the same name.
+	// Method is rendered with table metadata by template model_string.tmpl, model_is_zero.tmpl, model_primary_key.tmpl,
+	// model_clone.tmpl or model_validate.tmpl in ModelTemplateDir if it's provided
+	ExtraModelMethods []string
+	// Validate reports zero value of non-pointer string, time and slice field of NOT NULL column without default value,
+	// which is usually not set by callers
+	ValidateNotNullZero bool
+
+	// sensitive string columns(column or table.column, e.g. users.email) generated as gen.PII, which is encrypted by
+	// cipher set by gen.SetPIICipher on write and decrypted on read, and masked in String and JSON output.
+	// Columns should be wide enough for base64 of ciphertext, and cannot be queried by plain value unless cipher is deterministic
+	PIIColumns []string
+	// columns(column or table.column, e.g. users.password) not generated in models, same as FieldIgnore of the table
+	ExcludeColumns []string
+
+	// types whose methods are copied onto models by table name, "*" for all tables, type is in form of
+	// <import path or dir>.<Type> as WithMethod, e.g. {"users": {"./common.CommonMethod"}}
+	TableMethods map[string][]string
+	// options of models by table name, "*" for all tables, e.g. {"users": {gen.FieldType("id", "uint64")}}
+	TableModelOpts map[string][]ModelOpt
+
+	// gorm hooks(e.g. BeforeCreate, BeforeUpdate) whose stubs are generated in <model file>_hooks.go of model package
+	// if the file doesn't exist, the file is never regenerated or pruned, so business logic added to it is kept.
+	// Hooks generated by UUIDHook and TimeUTC or declared by model methods are skipped
+	WithHooks []string
+
+	// columns extracted into shared struct BaseModelName(default BaseModel) generated in base_model.gen.go of model
+	// package, e.g. id, created_at, updated_at, deleted_at. Models having all of them with the same types and tags
+	// embed it instead of declaring them, methods of the shared struct can be declared in other files of model package
+	BaseModelColumns []string
+	BaseModelName    string
+
+	// layout of model files: "table"(default) generates a file for model of each table, "single" generates models
+	// of a package into models.gen.go
+	ModelLayout string
+	// sub-packages of model package grouping models by table name pattern(* as wildcard), the longest matched pattern
+	// wins, e.g. {"user_*": "user"} generates models of user_profile and user_address into model/user. Relations and
+	// BaseModel are not generated across packages, package name should differ from query structs(e.g. user of users)
+	ModelPackages map[string]string
+
+	// lock file storing fingerprint(schema, fields and methods) of each table, model and query files of tables
+	// whose fingerprint is unchanged since last generation are skipped, unless ForceGenerate is true. Files of all
+	// tables are regenerated once config, TableModelOpts or version of gen changes
+	LockFile      string
+	ForceGenerate bool
+
+	// remove generated model and query files which are not generated this time, e.g. files of dropped tables,
+	// should be used when generating all tables
+	Prune bool
+
+	// manifest file listing generated files with source table and sha256 of content, e.g. gen.manifest.json,
+	// tools can tell generated files from hand-written ones by it, see ReadManifest
+	ManifestFile string
+
+	// verify generated code after writing, "build" type-checks packages of generated files in-process(go/packages)
+	// and fails generation with errors attributed to table and templates of offending files, e.g. of bad type
+	// mappings(WithDataTypeMap, FieldType) or custom templates
+	Verify string
+
+	Concurrency int // number of tables introspected and rendered concurrently, default: number of CPUs
+
+	// directory of templates(*.tmpl) overriding built-in model templates, model.tmpl for model struct
+	// and model_method.tmpl for each method of model, executed with the same data as built-in ones
+	ModelTemplateDir string
+	// directory of templates(*.tmpl) overriding built-in query templates: query_struct.tmpl, query_iface.tmpl,
+	// crud_method.tmpl, diy_method.tmpl, and query_extra.tmpl appended to query file of each table
+	QueryTemplateDir string
+
+	// text or path of file injected at the top of generated go files, e.g. copyright or license,
+	// lines not starting with // are commented
+	FileHeader string
+	BuildTags  string // build constraint of generated go files, e.g. "!integration"
+
+	// formatter of generated go files: goimports(default), gofmt or gofumpt,
+	// imports are always fixed by goimports since generated code relies on it
+	Formatter string
+
+	// emit provenance header in generated go files: generator version, config fingerprint,
+	// source table, schema fingerprint and generation time unless OmitTimestamp is true
+	Provenance    bool
+	OmitTimestamp bool
+
 	queryPkgName   string // generated query code's package name
 	modelPkgPath   string // model pkg path in target project
 	dbNameOpts     []model.SchemaNameOpt
@@ -58,6 +344,16 @@ type Config struct {
 	fieldJSONTagNS func(columnName string) (tagContent string)
 
 	modelOpts []ModelOpt
+
+	templateFuncs template.FuncMap // functions used in custom templates
+
+	exporters []exporter
+	dto       *dtoExport
+
+	schema *model.Schema // metadata used instead of db, see Generator.UseSchemaJSON
+
+	reportProgress func(Progress) // see WithProgress
+	plugins        []Plugin       // see WithPlugins
 }
 
 // WithOpts set global  model options
@@ -103,6 +399,16 @@ func (cfg *Config) WithJSONTagNameStrategy(ns func(columnName string) (tagConten
 	cfg.fieldJSONTagNS = ns
 }
 
+// WithTemplateFuncs register functions used in custom templates of ModelTemplateDir and QueryTemplateDir
+func (cfg *Config) WithTemplateFuncs(funcs template.FuncMap) {
+	if cfg.templateFuncs == nil {
+		cfg.templateFuncs = make(template.FuncMap, len(funcs))
+	}
+	for name, fn := range funcs {
+		cfg.templateFuncs[name] = fn
+	}
+}
+
 // WithImportPkgPath specify import package path
 func (cfg *Config) WithImportPkgPath(paths ...string) {
 	for i, path := range paths {
@@ -135,6 +441,76 @@ func (cfg *Config) Revise() (err error) {
 	}
 	cfg.queryPkgName = filepath.Base(cfg.OutPath)
 
+	if cfg.Formatter != "" && !formatters[cfg.Formatter] {
+		return fmt.Errorf("unknown formatter %q (support goimports || gofmt || gofumpt)", cfg.Formatter)
+	}
+
+	if _, ok := decimalTypes[cfg.DecimalType]; cfg.DecimalType != "" && !ok {
+		return fmt.Errorf("unknown decimal type %q (support shopspring || string || float64)", cfg.DecimalType)
+	}
+
+	if cfg.NullableStyle != "" && !nullableStyles[cfg.NullableStyle] {
+		return fmt.Errorf("unknown nullable style %q (support pointer || sqlnull || genericNull)", cfg.NullableStyle)
+	}
+
+	if cfg.NoPrimaryKey != "" && cfg.NoPrimaryKey != noPrimaryKeySkip && cfg.NoPrimaryKey != noPrimaryKeyReadOnly &&
+		cfg.NoPrimaryKey != noPrimaryKeyUniqueIndex {
+		return fmt.Errorf("unknown no primary key strategy %q (support skip || readOnly || uniqueIndex)", cfg.NoPrimaryKey)
+	}
+
+	if _, ok := dateTypes[cfg.DateType]; cfg.DateType != "" && !ok {
+		return fmt.Errorf("unknown date type %q (support time || datatypes || string)", cfg.DateType)
+	}
+	if _, ok := timeTypes[cfg.TimeType]; cfg.TimeType != "" && !ok {
+		return fmt.Errorf("unknown time type %q (support time || datatypes || string)", cfg.TimeType)
+	}
+	if _, ok := dateTimeTypes[cfg.DateTimeType]; cfg.DateTimeType != "" && !ok {
+		return fmt.Errorf("unknown datetime type %q (support time || string)", cfg.DateTimeType)
+	}
+
+	for _, method := range cfg.ExtraModelMethods {
+		if _, ok := extraModelMethods[method]; !ok {
+			return fmt.Errorf("unknown model method %q (support String || IsZero || PrimaryKey || Clone || Validate)", method)
+		}
+	}
+
+	for _, relations := range cfg.Relations {
+		for _, relation := range relations {
+			if _, err := parseRelation(relation); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, hook := range cfg.WithHooks {
+		if !gormHooks[hook] {
+			return fmt.Errorf("unknown gorm hook %q (support BeforeSave || BeforeCreate || AfterCreate || AfterSave || "+
+				"BeforeUpdate || AfterUpdate || BeforeDelete || AfterDelete || AfterFind)", hook)
+		}
+	}
+
+	if cfg.ModelLayout != "" && cfg.ModelLayout != modelLayoutTable && cfg.ModelLayout != modelLayoutSingle {
+		return fmt.Errorf("unknown model layout %q (support table || single)", cfg.ModelLayout)
+	}
+	if err = cfg.checkModelPackages(); err != nil {
+		return err
+	}
+
+	if cfg.UnitTestDB != "" && cfg.UnitTestDB != unitTestDBSQLite && cfg.UnitTestDB != unitTestDBContainers {
+		return fmt.Errorf("unknown unit test db %q (support sqlite || testcontainers)", cfg.UnitTestDB)
+	}
+	if cfg.Verify != "" && cfg.Verify != verifyBuild {
+		return fmt.Errorf("unknown verify mode %q (support build)", cfg.Verify)
+	}
+
+	if cfg.BatchSize < 0 {
+		return fmt.Errorf("invalid batch size %d", cfg.BatchSize)
+	}
+
+	if len(cfg.BaseModelColumns) > 0 && cfg.BaseModelName == "" {
+		cfg.BaseModelName = "BaseModel"
+	}
+
 	if cfg.db == nil {
 		cfg.db, _ = gorm.Open(tests.DummyDialector{})
 	}
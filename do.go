@@ -11,6 +11,7 @@ import (
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
+	"gorm.io/hints"
 
 	"gorm.io/gen/field"
 	"gorm.io/gen/helper"
@@ -33,6 +34,11 @@ type DO struct {
 	modelType reflect.Type
 	tableName string
 
+	tenantColumn  string
+	auditColumns  [2]string
+	versionColumn string
+	batchSize     int // default batch size of batch methods, see UseBatchSize
+
 	backfillData interface{}
 }
 
@@ -41,6 +47,32 @@ func (d DO) getInstance(db *gorm.DB) *DO {
 	return &d
 }
 
+// settingDB mark db with settings read by callbacks, which are registered by tracer, tenant scope, audit and version
+func (d *DO) settingDB(db *gorm.DB) *gorm.DB {
+	if d.DOConfig != nil && d.session != nil {
+		db = db.Session(d.session)
+	}
+	if d.DOConfig != nil && d.tracer != nil {
+		db = db.Set(tracerSettingKey, d.tracer)
+	}
+	if d.DOConfig != nil && d.timeout > 0 {
+		db = db.Set(timeoutSettingKey, d.timeout)
+	}
+	if d.DOConfig != nil && d.explainThreshold > 0 {
+		db = db.Set(explainSettingKey, d.explainThreshold)
+	}
+	if d.tenantColumn != "" {
+		db = db.Set(tenantSettingKey, d.tenantColumn)
+	}
+	if d.auditColumns != [2]string{} {
+		db = db.Set(auditSettingKey, d.auditColumns)
+	}
+	if d.versionColumn != "" {
+		db = db.Set(versionSettingKey, d.versionColumn)
+	}
+	return db.Session(&gorm.Session{})
+}
+
 type doOptions func(*gorm.DB) *gorm.DB
 
 var (
@@ -61,11 +93,18 @@ func (d *DO) UseDB(db *gorm.DB, opts ...DOOption) {
 		}
 	}
 	d.DOConfig = config
+	for _, opt := range opts {
+		if opt != nil {
+			if initErr := opt.AfterInitialize(d); initErr != nil {
+				panic(initErr)
+			}
+		}
+	}
 }
 
 // ReplaceDB replace db connection
 func (d *DO) ReplaceDB(db *gorm.DB) {
-	d.db = db.Session(&gorm.Session{})
+	d.db = d.settingDB(db.Session(&gorm.Session{}))
 }
 
 // ReplaceConnPool replace db connection pool
@@ -546,6 +585,94 @@ func (d *DO) Preload(field field.RelationField) Dao {
 	return d.getInstance(d.db.Preload(field.Path(), args...))
 }
 
+// Preload relation preloaded with its nested relations, built by With<Relation> functions generated in query package
+// (WithPreloadMethod), e.g. u.WithContext(ctx).WithOrders(query.WithItems(query.WithProduct()))
+type Preload struct {
+	Name   string // relation field name
+	Nested []Preload
+}
+
+// paths preload paths of p under parent, e.g. Orders, Orders.Items, Orders.Items.Product
+func (p Preload) paths(parent string) []string {
+	path := p.Name
+	if parent != "" {
+		path = parent + "." + p.Name
+	}
+	paths := []string{path}
+	for _, nested := range p.Nested {
+		paths = append(paths, nested.paths(path)...)
+	}
+	return paths
+}
+
+// PreloadNested preload relations with their nested relations
+func (d *DO) PreloadNested(preloads ...Preload) Dao {
+	db := d.db
+	for _, p := range preloads {
+		for _, path := range p.paths("") {
+			db = db.Preload(path)
+		}
+	}
+	return d.getInstance(db)
+}
+
+// ForUpdate lock selected rows with SELECT ... FOR UPDATE, or table hints WITH (UPDLOCK, ROWLOCK) of sqlserver
+func (d *DO) ForUpdate(opts ...LockOption) Dao {
+	return d.lock("UPDATE", opts)
+}
+
+// ForShare lock selected rows with SELECT ... FOR SHARE, or table hints WITH (HOLDLOCK, ROWLOCK) of sqlserver
+func (d *DO) ForShare(opts ...LockOption) Dao {
+	return d.lock("SHARE", opts)
+}
+
+// lock apply row level locking of strength, LockNoWait and LockSkipLocked conflict with each other
+func (d *DO) lock(strength string, opts []LockOption) Dao {
+	var option LockOption
+	for _, opt := range opts {
+		if option != "" && opt != option {
+			return d.withError(fmt.Errorf("%w: %s and %s", ErrConflictingLockOptions, option, opt))
+		}
+		option = opt
+	}
+
+	switch d.db.Dialector.Name() {
+	case "sqlite": // sqlite locks the whole database in transaction
+		return d
+	case "sqlserver": // table hints apply to the table of FROM, tables joined are not locked
+		h := tableHints{lockHints[strength], "ROWLOCK"}
+		if option != "" {
+			h = append(h, lockHints[string(option)])
+		}
+		return d.getInstance(d.db.Clauses(h))
+	case "clickhouse":
+		return d.withError(ErrLockingNotSupported)
+	}
+	return d.Clauses(clause.Locking{Strength: strength, Options: string(option)})
+}
+
+// lockHints table hints of sqlserver by locking strength and option
+var lockHints = map[string]string{"UPDATE": "UPDLOCK", "SHARE": "HOLDLOCK", string(LockNoWait): "NOWAIT", string(LockSkipLocked): "READPAST"}
+
+// tableHints table hints of sqlserver written after table of FROM clause, e.g. WITH (UPDLOCK, ROWLOCK)
+type tableHints []string
+
+// ModifyStatement add hints after FROM clause
+func (h tableHints) ModifyStatement(stmt *gorm.Statement) {
+	from := stmt.Clauses["FROM"]
+	if from.AfterExpression == nil {
+		from.AfterExpression = h
+	} else {
+		from.AfterExpression = hints.Exprs{from.AfterExpression, h}
+	}
+	stmt.Clauses["FROM"] = from
+}
+
+// Build build hints
+func (h tableHints) Build(builder clause.Builder) {
+	builder.WriteString("WITH (" + strings.Join(h, ", ") + ")")
+}
+
 // UpdateFrom specify update sub query
 func (d *DO) UpdateFrom(q SubQuery) Dao {
 	var tableName strings.Builder
@@ -588,17 +715,22 @@ func getFromClause(db *gorm.DB) *clause.From {
 
 // Create ...
 func (d *DO) Create(value interface{}) error {
-	return d.db.Create(value).Error
+	_, err := d.exec(value, func() *gorm.DB { return d.db.Create(value) })
+	return err
 }
 
-// CreateInBatches ...
+// CreateInBatches create records batch by batch, default batch size is used if batchSize <= 0,
+// and batch size is capped by placeholder limit of dialect
 func (d *DO) CreateInBatches(value interface{}, batchSize int) error {
-	return d.db.CreateInBatches(value, batchSize).Error
+	batchSize = d.capBatchSize(batchSize, d.createParams(), 0)
+	_, err := d.exec(value, func() *gorm.DB { return d.db.CreateInBatches(value, batchSize) })
+	return err
 }
 
 // Save ...
 func (d *DO) Save(value interface{}) error {
-	return d.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(value).Error
+	_, err := d.exec(value, func() *gorm.DB { return d.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(value) })
+	return err
 }
 
 // First ...
@@ -675,16 +807,16 @@ func (d *DO) Update(column field.Expr, value interface{}) (info ResultInfo, err
 	tx := d.db.Model(d.newResultPointer())
 	columnStr := column.BuildColumn(d.db.Statement, field.WithoutQuote).String()
 
-	var result *gorm.DB
-	switch value := value.(type) {
-	case field.AssignExpr:
-		result = tx.Update(columnStr, value.AssignExpr())
-	case SubQuery:
-		result = tx.Update(columnStr, value.underlyingDB())
-	default:
-		result = tx.Update(columnStr, value)
-	}
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	return d.exec(nil, func() *gorm.DB {
+		switch value := value.(type) {
+		case field.AssignExpr:
+			return tx.Update(columnStr, value.AssignExpr())
+		case SubQuery:
+			return tx.Update(columnStr, value.underlyingDB())
+		default:
+			return tx.Update(columnStr, value)
+		}
+	})
 }
 
 // UpdateSimple ...
@@ -693,8 +825,21 @@ func (d *DO) UpdateSimple(columns ...field.AssignExpr) (info ResultInfo, err err
 		return
 	}
 
-	result := d.db.Model(d.newResultPointer()).Clauses(d.assignSet(columns)).Omit("*").Updates(map[string]interface{}{})
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	return d.exec(nil, func() *gorm.DB {
+		return d.db.Model(d.newResultPointer()).Clauses(d.assignSet(columns)).Omit("*").Updates(map[string]interface{}{})
+	})
+}
+
+// UpdateReturning update columns as UpdateSimple and scan updated records into dest(pointer to slice of model)
+// by RETURNING, which is supported by postgres and sqlite 3.35+
+func (d *DO) UpdateReturning(dest interface{}, columns ...field.AssignExpr) (info ResultInfo, err error) {
+	if len(columns) == 0 {
+		return
+	}
+
+	return d.exec(nil, func() *gorm.DB {
+		return d.db.Model(dest).Clauses(clause.Returning{}, d.assignSet(columns)).Omit("*").Updates(map[string]interface{}{})
+	})
 }
 
 // Updates ...
@@ -723,8 +868,11 @@ func (d *DO) Updates(value interface{}) (info ResultInfo, err error) {
 		ptr.Elem().Set(reflect.ValueOf(value))
 		value = ptr.Interface()
 	}
-	result := tx.Updates(value)
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	var written interface{}
+	if d.cacheEnabled() && valTyp == d.modelType && len(d.cacheKeys(value)) > 0 { // updated by primary key
+		written = value
+	}
+	return d.exec(written, func() *gorm.DB { return tx.Updates(value) })
 }
 
 // UpdateColumn ...
@@ -732,16 +880,16 @@ func (d *DO) UpdateColumn(column field.Expr, value interface{}) (info ResultInfo
 	tx := d.db.Model(d.newResultPointer())
 	columnStr := column.BuildColumn(d.db.Statement, field.WithoutQuote).String()
 
-	var result *gorm.DB
-	switch value := value.(type) {
-	case field.Expr:
-		result = tx.UpdateColumn(columnStr, value.RawExpr())
-	case SubQuery:
-		result = d.db.UpdateColumn(columnStr, value.underlyingDB())
-	default:
-		result = d.db.UpdateColumn(columnStr, value)
-	}
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	return d.exec(nil, func() *gorm.DB {
+		switch value := value.(type) {
+		case field.Expr:
+			return tx.UpdateColumn(columnStr, value.RawExpr())
+		case SubQuery:
+			return d.db.UpdateColumn(columnStr, value.underlyingDB())
+		default:
+			return d.db.UpdateColumn(columnStr, value)
+		}
+	})
 }
 
 // UpdateColumnSimple ...
@@ -750,14 +898,14 @@ func (d *DO) UpdateColumnSimple(columns ...field.AssignExpr) (info ResultInfo, e
 		return
 	}
 
-	result := d.db.Model(d.newResultPointer()).Clauses(d.assignSet(columns)).Omit("*").UpdateColumns(map[string]interface{}{})
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	return d.exec(nil, func() *gorm.DB {
+		return d.db.Model(d.newResultPointer()).Clauses(d.assignSet(columns)).Omit("*").UpdateColumns(map[string]interface{}{})
+	})
 }
 
 // UpdateColumns ...
 func (d *DO) UpdateColumns(value interface{}) (info ResultInfo, err error) {
-	result := d.db.Model(d.newResultPointer()).UpdateColumns(value)
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	return d.exec(nil, func() *gorm.DB { return d.db.Model(d.newResultPointer()).UpdateColumns(value) })
 }
 
 // assignSet fetch all set
@@ -781,18 +929,23 @@ func (d *DO) assignSet(exprs []field.AssignExpr) (set clause.Set) {
 
 // Delete ...
 func (d *DO) Delete(models ...interface{}) (info ResultInfo, err error) {
-	var result *gorm.DB
 	if len(models) == 0 || reflect.ValueOf(models[0]).Len() == 0 {
-		result = d.db.Model(d.newResultPointer()).Delete(reflect.New(d.modelType).Interface())
-	} else {
-		targets := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(d.modelType)), 0, len(models))
-		value := reflect.ValueOf(models[0])
-		for i := 0; i < value.Len(); i++ {
-			targets = reflect.Append(targets, value.Index(i))
-		}
-		result = d.db.Delete(targets.Interface())
+		return d.exec(nil, func() *gorm.DB { return d.db.Model(d.newResultPointer()).Delete(reflect.New(d.modelType).Interface()) })
 	}
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	targets := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(d.modelType)), 0, len(models))
+	value := reflect.ValueOf(models[0])
+	for i := 0; i < value.Len(); i++ {
+		targets = reflect.Append(targets, value.Index(i))
+	}
+	return d.exec(targets.Interface(), func() *gorm.DB { return d.db.Delete(targets.Interface()) })
+}
+
+// DeleteReturning delete records and scan deleted records into dest(pointer to slice of model)
+// by RETURNING, which is supported by postgres and sqlite 3.35+
+func (d *DO) DeleteReturning(dest interface{}) (info ResultInfo, err error) {
+	return d.exec(nil, func() *gorm.DB {
+		return d.db.Model(d.newResultPointer()).Clauses(clause.Returning{}).Delete(dest)
+	})
 }
 
 // Count ...
@@ -800,6 +953,13 @@ func (d *DO) Count() (count int64, err error) {
 	return count, d.db.Session(&gorm.Session{}).Model(d.newResultPointer()).Count(&count).Error
 }
 
+// Exists check whether any record matches, executed as SELECT 1 ... LIMIT 1
+func (d *DO) Exists() (exists bool, err error) {
+	var flags []int
+	err = d.db.Session(&gorm.Session{}).Model(d.newResultPointer()).Select("1").Limit(1).Scan(&flags).Error
+	return len(flags) > 0, err
+}
+
 // Row ...
 func (d *DO) Row() *sql.Row {
 	return d.db.Model(d.newResultPointer()).Row()
@@ -825,6 +985,31 @@ func (d *DO) ScanRows(rows *sql.Rows, dest interface{}) error {
 	return d.db.Model(d.newResultPointer()).ScanRows(rows, dest)
 }
 
+// Each iterate query result row by row without loading all records into memory,
+// fc is called with a new model pointer for every row
+func (d *DO) Each(fc func(result interface{}) error) error {
+	if d.modelType == nil {
+		return fmt.Errorf("cannot iterate rows without model")
+	}
+
+	rows, err := d.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close() // nolint
+
+	for rows.Next() {
+		result := reflect.New(d.modelType).Interface()
+		if err = d.ScanRows(rows, result); err != nil {
+			return err
+		}
+		if err = fc(result); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // WithResult ...
 func (d DO) WithResult(fc func(tx Dao)) ResultInfo {
 	d.db = d.db.Set("", "")
@@ -1,5 +1,11 @@
 package gen
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // DOOption gorm option interface
 type DOOption interface {
 	Apply(*DOConfig) error
@@ -7,6 +13,13 @@ type DOOption interface {
 }
 
 type DOConfig struct {
+	cache    Cache
+	cacheTTL time.Duration
+	tracer   Tracer
+	session  *gorm.Session
+	timeout  time.Duration
+
+	explainThreshold time.Duration
 }
 
 // Apply update config to new config
@@ -21,3 +34,13 @@ func (c *DOConfig) Apply(config *DOConfig) error {
 func (c *DOConfig) AfterInitialize(db *DO) error {
 	return nil
 }
+
+// LockOption row level locking option
+type LockOption string
+
+const (
+	// LockNoWait report error immediately if rows are locked by others
+	LockNoWait LockOption = "NOWAIT"
+	// LockSkipLocked skip rows locked by others
+	LockSkipLocked LockOption = "SKIP LOCKED"
+)
@@ -1,14 +1,30 @@
 package gen
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+	"github.com/mattn/go-sqlite3"
+	mssql "github.com/microsoft/go-mssqldb"
 	"gorm.io/datatypes"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils/tests"
 	"gorm.io/hints"
+	"gorm.io/plugin/dbresolver"
 
 	"gorm.io/gen/field"
 )
@@ -183,6 +199,14 @@ func TestDO_methods(t *testing.T) {
 			ExpectedVars: []interface{}{uint(0)},
 			Result:       "SELECT * WHERE `id` > ? /* hint */",
 		},
+		{
+			Expr:   u.ForUpdate().Select(),
+			Result: "SELECT * FOR UPDATE",
+		},
+		{
+			Expr:   u.ForShare(LockNoWait).Select(),
+			Result: "SELECT * FOR SHARE NOWAIT",
+		},
 		{
 			Expr:   u.Clauses(hints.UseIndex("user_name")).Select(),
 			Opts:   []stmtOpt{withFROM},
@@ -201,6 +225,12 @@ func TestDO_methods(t *testing.T) {
 			Opts:   []stmtOpt{withFROM},
 			Result: "SELECT * FROM `users_info` FORCE INDEX FOR JOIN (`user_name`,`user_id`) IGNORE INDEX FOR GROUP BY (`user_name`)",
 		},
+		{
+			Expr:         u.AsOf(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).Select(),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Result:       "SELECT * FROM `users_info` FOR SYSTEM_TIME AS OF ?",
+		},
 		// ======================== where conditions ========================
 		{
 			Expr:         u.Where(u.Where(u.ID.Neq(0)), u.Where(u.Age.Gt(18))),
@@ -393,3 +423,940 @@ func TestDO_methods(t *testing.T) {
 		checkBuildExpr(t, testcase.Expr, testcase.Opts, testcase.Result, testcase.ExpectedVars)
 	}
 }
+
+// openSQLite open sqlite db in temp dir with tables of models migrated
+func openSQLite(t *testing.T, models ...interface{}) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "gen.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate fail: %s", err)
+	}
+	return db
+}
+
+// newTestDO create DO of model on db, opts are passed to UseDB
+func newTestDO(db *gorm.DB, model interface{}, opts ...DOOption) *DO {
+	do := new(DO)
+	do.UseDB(db, opts...)
+	do.UseModel(model)
+	return do
+}
+
+// recordSQL record sql of statements executed by db
+func recordSQL(t *testing.T, db *gorm.DB) *[]string {
+	var sqls []string
+	record := func(tx *gorm.DB) { sqls = append(sqls, tx.Statement.SQL.String()) }
+	if err := db.Callback().Query().After("*").Register("test:record_query", record); err != nil {
+		t.Fatalf("register callback fail: %s", err)
+	}
+	if err := db.Callback().Row().After("*").Register("test:record_row", record); err != nil {
+		t.Fatalf("register callback fail: %s", err)
+	}
+	return &sqls
+}
+
+func TestDO_Exists(t *testing.T) {
+	db := openSQLite(t, &User{})
+	sqls := recordSQL(t, db)
+	do := newTestDO(db, &User{})
+	name := field.NewString("", "name")
+
+	if exists, err := do.Exists(); err != nil || exists {
+		t.Errorf("empty table should not have records, got: %v %v", exists, err)
+	}
+	if err := do.Create(&User{Name: "modi"}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	for cond, expect := range map[string]bool{"modi": true, "gorm": false} {
+		if exists, err := do.Where(name.Eq(cond)).(*DO).Exists(); err != nil || exists != expect {
+			t.Errorf("exists of name %s should be %v, got: %v %v", cond, expect, exists, err)
+		}
+	}
+	if len(*sqls) != 3 {
+		t.Errorf("each exists should run one query, got: %v", *sqls)
+	}
+	for _, sql := range *sqls {
+		if !strings.HasPrefix(sql, "SELECT 1 FROM `users_info`") || !strings.HasSuffix(sql, "LIMIT 1") {
+			t.Errorf("exists should select 1 with limit 1, got: %s", sql)
+		}
+	}
+}
+
+func TestDO_Each(t *testing.T) {
+	db := openSQLite(t, &User{})
+	do := newTestDO(db, &User{})
+	if err := do.Create([]*User{{Name: "a"}, {Name: "b"}, {Name: "c"}}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+
+	var names []string
+	err := do.Order(field.NewUint("", "id")).(*DO).Each(func(result interface{}) error {
+		names = append(names, result.(*User).Name)
+		return nil
+	})
+	if err != nil || strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("each should iterate records in order, got: %v %v", names, err)
+	}
+
+	stop, calls := errors.New("stop"), 0
+	err = do.Each(func(interface{}) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("error of fc should stop iteration, got: %v after %d calls", err, calls)
+	}
+
+	var table DO
+	table.UseDB(db)
+	table.UseTable("users_info")
+	if err = table.Each(func(interface{}) error { return nil }); err == nil {
+		t.Errorf("each without model should fail")
+	}
+}
+
+type sqlserverDialector struct{ tests.DummyDialector }
+
+func (sqlserverDialector) Name() string { return "sqlserver" }
+
+func TestDO_lock(t *testing.T) {
+	if err := u.ForUpdate(LockNoWait, LockSkipLocked).underlyingDB().Error; !errors.Is(err, ErrConflictingLockOptions) {
+		t.Errorf("NOWAIT and SKIP LOCKED should conflict, got: %v", err)
+	}
+	checkBuildExpr(t, u.ForUpdate(LockSkipLocked, LockSkipLocked).Select(), nil, "SELECT * FOR UPDATE SKIP LOCKED", nil)
+
+	sqlserverDB, _ := gorm.Open(sqlserverDialector{}, &gorm.Config{DryRun: true})
+	mssql := newTestDO(sqlserverDB, User{})
+	for _, testcase := range []struct {
+		Expr   SubQuery
+		Result string
+	}{
+		{Expr: mssql.ForUpdate(), Result: "SELECT * FROM `users_info` WITH (UPDLOCK, ROWLOCK)"},
+		{Expr: mssql.ForShare(LockNoWait), Result: "SELECT * FROM `users_info` WITH (HOLDLOCK, ROWLOCK, NOWAIT)"},
+		{Expr: mssql.ForUpdate(LockSkipLocked).Clauses(hints.UseIndex("idx_name")), Result: "SELECT * FROM `users_info` WITH (UPDLOCK, ROWLOCK, READPAST) USE INDEX (`idx_name`)"},
+	} {
+		checkBuildExpr(t, testcase.Expr.(Dao).Select(), []stmtOpt{withFROM}, testcase.Result, nil)
+	}
+}
+
+type versionedUser struct {
+	ID      uint
+	Name    string
+	Age     int
+	Version int
+}
+
+func TestDO_UseVersion(t *testing.T) {
+	db := openSQLite(t, &versionedUser{})
+	do := newTestDO(db, &versionedUser{})
+	if err := do.UseVersion("version"); err != nil {
+		t.Fatalf("use version fail: %s", err)
+	}
+	if err := do.Create(&versionedUser{Name: "modi", Version: 1}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	name, age, version := field.NewString("", "name"), field.NewInt("", "age"), field.NewInt("", "version")
+	one := do.Where(field.NewUint("", "id").Eq(1)).(*DO)
+
+	for _, testcase := range []struct {
+		Name    string
+		Update  func() (ResultInfo, error)
+		Version int
+	}{
+		{"Update", func() (ResultInfo, error) { return one.Update(name, "gorm") }, 2},
+		{"UpdateSimple", func() (ResultInfo, error) { return one.UpdateSimple(age.Add(1)) }, 3},
+		{"UpdateColumn", func() (ResultInfo, error) { return one.UpdateColumn(age, 18) }, 4},
+		{"UpdateColumnSimple", func() (ResultInfo, error) { return one.UpdateColumnSimple(age.Add(1)) }, 5},
+		{"UpdateColumns(map)", func() (ResultInfo, error) { return one.UpdateColumns(map[string]interface{}{"age": 20}) }, 6},
+		{"Updates(map)", func() (ResultInfo, error) { return one.Updates(map[string]interface{}{"name": "modi"}) }, 7},
+		{"explicit version", func() (ResultInfo, error) { return one.UpdateSimple(version.Value(10)) }, 10},
+		{"Updates(struct)", func() (ResultInfo, error) { return one.Updates(&versionedUser{Age: 30}) }, 10},
+	} {
+		if _, err := testcase.Update(); err != nil {
+			t.Fatalf("%s fail: %s", testcase.Name, err)
+		}
+		var result versionedUser
+		if err := db.Take(&result).Error; err != nil {
+			t.Fatalf("take fail: %s", err)
+		}
+		if result.Version != testcase.Version {
+			t.Errorf("version should be %d after %s, got: %d", testcase.Version, testcase.Name, result.Version)
+		}
+	}
+
+	var sql string
+	dryRunDB, _ := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	_ = dryRunDB.Callback().Update().After("*").Register("test:record_update", func(tx *gorm.DB) { sql = tx.Statement.SQL.String() })
+	dryRun := newTestDO(dryRunDB, &versionedUser{})
+	_ = dryRun.UseVersion("version")
+	sub := newTestDO(dryRunDB, &versionedUser{}).Select(field.NewUint("", "id")).As("t")
+	if _, err := dryRun.UpdateFrom(sub).Where(field.NewUint("", "id").EqCol(field.NewUint("t", "id"))).UpdateSimple(age.Add(1)); err != nil {
+		t.Fatalf("update from fail: %s", err)
+	}
+	if !strings.Contains(sql, "`version`=`version` + 1") {
+		t.Errorf("update from should increase version, got: %s", sql)
+	}
+
+	plain := newTestDO(db, &versionedUser{})
+	if _, err := plain.Where(field.NewUint("", "id").Eq(1)).Update(age, 40); err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+	var result versionedUser
+	if err := db.Take(&result).Error; err != nil || result.Version != 10 {
+		t.Errorf("version should not be increased without UseVersion, got: %d %v", result.Version, err)
+	}
+}
+
+type recordTracer struct{ spans *[]string }
+
+func (t recordTracer) Start(ctx context.Context, table, operation string) (context.Context, Span) {
+	return ctx, recordSpan{spans: t.spans, name: table + "." + operation}
+}
+
+type recordSpan struct {
+	spans *[]string
+	name  string
+}
+
+func (s recordSpan) End(rowsAffected int64, err error) {
+	*s.spans = append(*s.spans, fmt.Sprintf("%s:%d:%v", s.name, rowsAffected, err))
+}
+
+func TestDO_WithTracing(t *testing.T) {
+	db := openSQLite(t, &User{})
+	var spans []string
+	var observed []string
+	metrics := MetricsTracer(func(table, operation string, duration time.Duration, err error) {
+		observed = append(observed, table+"."+operation)
+	})
+	do := newTestDO(db, &User{}, WithTracing(Tracers(recordTracer{spans: &spans}, metrics)))
+	name := field.NewString("", "name")
+
+	if err := do.Create([]*User{{Name: "modi"}, {Name: "gorm"}}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	if _, err := do.Where(name.Eq("modi")).Update(name, "gen"); err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+	if _, err := do.Where(name.Eq("gen")).Find(); err != nil {
+		t.Fatalf("find fail: %s", err)
+	}
+	if _, err := do.Where(name.Eq("none")).First(); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("first should not find record, got: %v", err)
+	}
+	if _, err := do.Where(name.Eq("gorm")).Delete(); err != nil {
+		t.Fatalf("delete fail: %s", err)
+	}
+	expect := []string{
+		"users_info.create:2:<nil>",
+		"users_info.update:1:<nil>",
+		"users_info.query:1:<nil>",
+		"users_info.query:0:record not found",
+		"users_info.delete:1:<nil>",
+	}
+	if !reflect.DeepEqual(spans, expect) {
+		t.Errorf("spans should be %v, got: %v", expect, spans)
+	}
+	if expect := []string{"users_info.create", "users_info.update", "users_info.query", "users_info.query", "users_info.delete"}; !reflect.DeepEqual(observed, expect) {
+		t.Errorf("metrics should observe %v, got: %v", expect, observed)
+	}
+
+	spans = nil
+	if err := newTestDO(db, &User{}).Create(&User{Name: "modi"}); err != nil || len(spans) != 0 {
+		t.Errorf("statements of DO without tracer should not be traced, got: %v %v", spans, err)
+	}
+}
+
+func TestDO_UseResolver(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"source", "replica", "archive"} {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, name+".db")), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("open sqlite fail: %s", err)
+		}
+		if err = db.AutoMigrate(&User{}); err != nil {
+			t.Fatalf("migrate fail: %s", err)
+		}
+		if err = db.Create(&User{Name: name}).Error; err != nil {
+			t.Fatalf("create fail: %s", err)
+		}
+	}
+	open := func() *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "source.db")), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("open sqlite fail: %s", err)
+		}
+		return db
+	}
+	do := newTestDO(open(), &User{}, WithDBResolver(dbresolver.Config{Replicas: []gorm.Dialector{sqlite.Open(filepath.Join(dir, "replica.db"))}}))
+
+	// plugin registered by others is kept by WithDBResolver
+	db := open()
+	err := db.Use(dbresolver.Register(dbresolver.Config{}).
+		Register(dbresolver.Config{Replicas: []gorm.Dialector{sqlite.Open(filepath.Join(dir, "archive.db"))}}, "archive"))
+	if err != nil {
+		t.Fatalf("register archive resolver fail: %s", err)
+	}
+	archived := newTestDO(db, &User{}, WithDBResolver(dbresolver.Config{Replicas: []gorm.Dialector{sqlite.Open(filepath.Join(dir, "replica.db"))}}))
+
+	for _, testcase := range []struct {
+		Name   string
+		Query  Dao
+		Expect string
+	}{
+		{"default", do, "replica"},
+		{"read", do.Clauses(dbresolver.Read), "replica"},
+		{"write", do.Clauses(dbresolver.Write), "source"},
+		{"registered by others", archived, "source"},
+		{"named resolver", archived.UseResolver("archive"), "archive"},
+	} {
+		user := new(User)
+		if err := testcase.Query.(*DO).db.Take(user).Error; err != nil {
+			t.Fatalf("%s take fail: %s", testcase.Name, err)
+		}
+		if user.Name != testcase.Expect {
+			t.Errorf("%s query should read from %s, got: %s", testcase.Name, testcase.Expect, user.Name)
+		}
+	}
+}
+
+func TestDO_Sticky(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"source", "replica"} {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, name+".db")), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("open sqlite fail: %s", err)
+		}
+		if err = db.AutoMigrate(&User{}); err != nil {
+			t.Fatalf("migrate fail: %s", err)
+		}
+		if err = db.Create(&User{Name: name}).Error; err != nil {
+			t.Fatalf("create fail: %s", err)
+		}
+	}
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "source.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	// dbresolver registered by others, callbacks are registered by Sticky
+	if err = db.Use(dbresolver.Register(dbresolver.Config{Replicas: []gorm.Dialector{sqlite.Open(filepath.Join(dir, "replica.db"))}})); err != nil {
+		t.Fatalf("register resolver fail: %s", err)
+	}
+	do := newTestDO(db, &User{})
+	take := func(query Dao) string {
+		result, err := query.(*DO).Take()
+		if err != nil {
+			t.Fatalf("take fail: %s", err)
+		}
+		return result.(*User).Name
+	}
+
+	sticky := do.Sticky(context.Background())
+	if name := take(sticky); name != "replica" {
+		t.Errorf("reads before write should not be pinned, got: %s", name)
+	}
+	if err = sticky.(*DO).Create(&User{Name: "created"}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	if name := take(sticky); name != "source" {
+		t.Errorf("reads after write should be pinned to source, got: %s", name)
+	}
+	if name := take(do); name != "replica" {
+		t.Errorf("reads without sticky context should not be pinned, got: %s", name)
+	}
+
+	for _, testcase := range []struct {
+		SQL    string
+		Expect string
+	}{
+		{"WITH names AS (SELECT name FROM users_info) SELECT * FROM names", "replica"},
+		{"/* update */ SELECT 'update' UNION SELECT name FROM users_info", "replica"},
+		{"WITH ids AS (SELECT id FROM users_info LIMIT 1) UPDATE users_info SET age = 1 WHERE id IN (SELECT id FROM ids)", "source"},
+	} {
+		sticky := do.Sticky(context.Background())
+		if err = sticky.(*DO).UnderlyingDB().Exec(testcase.SQL).Error; err != nil {
+			t.Fatalf("exec %s fail: %s", testcase.SQL, err)
+		}
+		if name := take(sticky); name != testcase.Expect {
+			t.Errorf("reads after %s should be from %s, got: %s", testcase.SQL, testcase.Expect, name)
+		}
+	}
+}
+
+func TestIsReadStatement(t *testing.T) {
+	for sql, expect := range map[string]bool{
+		"SELECT * FROM users":                  true,
+		"  select 1":                           true,
+		"SHOW TABLES":                          true,
+		"EXPLAIN SELECT 1":                     true,
+		"(SELECT 1) UNION (SELECT 2)":          true,
+		"-- delete\nSELECT 1":                  true,
+		"/* insert */ SELECT 'update'":         true,
+		"WITH x AS (SELECT 1) SELECT * FROM x": true,
+		"WITH RECURSIVE x(n) AS (SELECT 1 UNION ALL SELECT n+1 FROM x WHERE n < 3) SELECT n FROM x": true,
+		"WITH `update` AS (SELECT 1) SELECT * FROM `update`":                                        true,
+		"INSERT INTO users (name) SELECT name FROM x":                                               false,
+		"UPDATE users SET name = 'select'":                                                          false,
+		"WITH x AS (SELECT id FROM users) DELETE FROM users WHERE id IN (SELECT id FROM x)":         false,
+		"selected": false,
+		"":         false,
+	} {
+		if got := isReadStatement(sql); got != expect {
+			t.Errorf("isReadStatement(%q) should be %v, got: %v", sql, expect, got)
+		}
+	}
+}
+
+// mapCache Cache of map for tests
+type mapCache map[string][]byte
+
+func (c mapCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := c[key]
+	return value, ok, nil
+}
+
+func (c mapCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c[key] = value
+	return nil
+}
+
+func (c mapCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c, key)
+	}
+	return nil
+}
+
+type tenantUser struct {
+	ID       uint
+	TenantID int
+	Name     string
+}
+
+func TestDO_UseTenant(t *testing.T) {
+	db := openSQLite(t, &tenantUser{})
+	cache := mapCache{}
+	do := newTestDO(db, &tenantUser{}, WithCache(cache, 0))
+	if err := do.UseTenant("tenant_id"); err != nil {
+		t.Fatalf("use tenant fail: %s", err)
+	}
+	ctx1, ctx2 := WithTenant(context.Background(), 1), WithTenant(context.Background(), 2)
+	name := field.NewString("", "name")
+
+	if err := do.WithContext(ctx1).Create(&tenantUser{ID: 1, Name: "modi"}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	if err := do.WithContext(ctx2).Create(&tenantUser{ID: 2, Name: "modi"}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	if err := do.Create(&tenantUser{ID: 3}); !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("create without tenant should fail, got: %v", err)
+	}
+	if count, err := do.WithContext(ctx1).Where(name.Eq("modi")).Count(); err != nil || count != 1 {
+		t.Errorf("query should be scoped by tenant, got: %d %v", count, err)
+	}
+	if info, err := do.WithContext(ctx2).Where(name.Eq("modi")).Update(name, "gen"); err != nil || info.RowsAffected != 1 {
+		t.Errorf("update should be scoped by tenant, got: %d %v", info.RowsAffected, err)
+	}
+	if _, err := do.Where(name.Eq("modi")).Find(); !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("query without tenant should fail, got: %v", err)
+	}
+
+	// raw SQL can't be scoped, it should filter by tenant column itself
+	var names []string
+	tx := do.WithContext(ctx1).(*DO).UnderlyingDB()
+	if err := tx.Raw("SELECT name FROM tenant_users").Scan(&names).Error; !errors.Is(err, ErrUnscopedRawSQL) {
+		t.Errorf("raw sql without tenant column should fail, got: %v", err)
+	}
+	if err := tx.Exec("DELETE FROM tenant_users").Error; !errors.Is(err, ErrUnscopedRawSQL) {
+		t.Errorf("exec without tenant column should fail, got: %v", err)
+	}
+	if err := tx.Raw("SELECT name FROM tenant_users WHERE tenant_id = ?", 1).Scan(&names).Error; err != nil || len(names) != 1 {
+		t.Errorf("raw sql filtering by tenant column should pass, got: %v %v", names, err)
+	}
+	if err := do.UnderlyingDB().Raw("SELECT name FROM tenant_users WHERE tenant_id = ?", 1).Scan(&names).Error; !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("raw sql without tenant should fail, got: %v", err)
+	}
+
+	// records are cached by tenant
+	if _, err := do.WithContext(ctx1).(*DO).TakeByPrimaryKey(1); err != nil {
+		t.Fatalf("take by primary key fail: %s", err)
+	}
+	if _, ok := cache["tenant_users:1:1"]; !ok || len(cache) != 1 {
+		t.Errorf("record should be cached by tenant, got: %v", cache)
+	}
+	if _, err := do.WithContext(ctx2).(*DO).TakeByPrimaryKey(1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("record cached for another tenant should not be found, got: %v", err)
+	}
+	if _, err := do.TakeByPrimaryKey(1); !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("take by primary key without tenant should fail, got: %v", err)
+	}
+}
+
+type auditedUser struct {
+	ID        uint
+	Name      string
+	CreatedBy string
+	UpdatedBy string
+}
+
+type piiUser struct {
+	ID       uint
+	Email    PII               `gorm:"serializer:pii"`
+	Phone    *PII              `gorm:"serializer:pii"`
+	Settings map[string]string `gorm:"serializer:json"`
+	Secret   string            `json:"-"`
+	Age      *int
+}
+
+func TestDO_WithCachePII(t *testing.T) {
+	c, err := NewAESCipher([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("new cipher fail: %s", err)
+	}
+	SetPIICipher(c)
+	defer SetPIICipher(nil)
+
+	db := openSQLite(t, &piiUser{})
+	cache := mapCache{}
+	do := newTestDO(db, &piiUser{}, WithCache(cache, 0))
+	phone, age := PII("13800138000"), 0
+	if err = do.Create(&piiUser{ID: 1, Email: "alice@example.com", Phone: &phone, Settings: map[string]string{"theme": "dark"}, Secret: "s", Age: &age}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+
+	for _, from := range []string{"db", "cache"} {
+		result, err := do.TakeByPrimaryKey(1)
+		if err != nil {
+			t.Fatalf("take by primary key from %s fail: %s", from, err)
+		}
+		user := result.(*piiUser)
+		if user.Email.Plain() != "alice@example.com" || user.Phone == nil || user.Phone.Plain() != "13800138000" {
+			t.Errorf("pii of record from %s should be plain, got: %q %v", from, user.Email.Plain(), user.Phone)
+		}
+		if user.Settings["theme"] != "dark" || user.Secret != "s" || user.Age == nil || *user.Age != 0 {
+			t.Errorf("record from %s should keep all columns, got: %+v", from, user)
+		}
+	}
+	data, ok := cache["pii_users:1"]
+	if !ok || strings.Contains(string(data), "alice") || strings.Contains(string(data), "a***") {
+		t.Errorf("pii should be cached encrypted, got: %s", data)
+	}
+
+	// record is cached by columns, it's served from cache
+	if err = db.Model(&piiUser{}).Where("id = ?", 1).UpdateColumn("secret", "changed").Error; err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+	if result, err := do.TakeByPrimaryKey(1); err != nil || result.(*piiUser).Secret != "s" {
+		t.Errorf("record should be served from cache, got: %+v %v", result, err)
+	}
+}
+
+type membership struct {
+	TenantID int    `gorm:"primaryKey"`
+	UserCode string `gorm:"primaryKey"`
+	Role     string
+}
+
+func TestDO_WithCacheCompositeKey(t *testing.T) {
+	db := openSQLite(t, &membership{})
+	cache := mapCache{}
+	do := newTestDO(db, &membership{}, WithCache(cache, 0))
+	tenantID, role := field.NewInt("", "tenant_id"), field.NewString("", "role")
+	if err := do.Create(&membership{TenantID: 1, UserCode: "a", Role: "dev"}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+
+	if _, err := do.Where(tenantID.Eq(1)).Update(role, "admin"); err != nil {
+		t.Errorf("update of model with composite primary key should not fail by cache, got: %s", err)
+	}
+	if _, err := do.Where(tenantID.Eq(1)).UpdateSimple(role.Value("owner")); err != nil {
+		t.Errorf("update simple of model with composite primary key should not fail by cache, got: %s", err)
+	}
+	if _, err := do.Where(tenantID.Eq(1)).Delete(); err != nil {
+		t.Errorf("delete of model with composite primary key should not fail by cache, got: %s", err)
+	}
+	if count, err := do.Count(); err != nil || count != 0 {
+		t.Errorf("record should be deleted, got: %d %v", count, err)
+	}
+	if _, err := do.TakeByPrimaryKey(1); err == nil {
+		t.Errorf("take by primary key of model with composite primary key should fail")
+	}
+	if len(cache) != 0 {
+		t.Errorf("records without single primary key should not be cached, got: %v", cache)
+	}
+}
+
+func TestDO_transferPII(t *testing.T) {
+	c, err := NewAESCipher([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("new cipher fail: %s", err)
+	}
+	SetPIICipher(c)
+	defer SetPIICipher(nil)
+
+	src := newTestDO(openSQLite(t, &piiUser{}), &piiUser{})
+	phone, age := PII("13800138000"), 0
+	if err = src.Create(&piiUser{ID: 1, Email: "alice@example.com", Phone: &phone, Settings: map[string]string{"theme": "dark"}, Secret: "s", Age: &age}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+
+	for name, transfer := range map[string]struct {
+		export func(do *DO, w io.Writer) error
+		load   func(do *DO, r io.Reader) (int64, error)
+	}{
+		"csv": {
+			func(do *DO, w io.Writer) error { return do.ExportCSV(w) },
+			func(do *DO, r io.Reader) (int64, error) { return do.ImportCSV(r, 10) },
+		},
+		"json lines": {
+			func(do *DO, w io.Writer) error { return do.ExportJSONLines(w) },
+			func(do *DO, r io.Reader) (int64, error) { return do.ImportJSONLines(r, 10) },
+		},
+	} {
+		var buf bytes.Buffer
+		if err = transfer.export(src, &buf); err != nil {
+			t.Fatalf("export %s fail: %s", name, err)
+		}
+		if data := buf.String(); strings.Contains(data, "alice") || strings.Contains(data, "a***") || strings.Contains(data, "138") {
+			t.Errorf("pii should be exported encrypted as %s, got:\n%s", name, data)
+		}
+
+		dst := newTestDO(openSQLite(t, &piiUser{}), &piiUser{})
+		if count, err := transfer.load(dst, &buf); err != nil || count != 1 {
+			t.Fatalf("import %s fail: %d %v", name, count, err)
+		}
+		result, err := dst.TakeByPrimaryKey(1)
+		if err != nil {
+			t.Fatalf("take imported record of %s fail: %s", name, err)
+		}
+		user := result.(*piiUser)
+		if user.Email.Plain() != "alice@example.com" || user.Phone == nil || user.Phone.Plain() != "13800138000" {
+			t.Errorf("pii imported from %s should be decrypted as it was, got: %q %v", name, user.Email.Plain(), user.Phone)
+		}
+		if user.Settings["theme"] != "dark" || user.Secret != "s" || user.Age == nil || *user.Age != 0 {
+			t.Errorf("record imported from %s should keep all columns, got: %+v", name, user)
+		}
+	}
+}
+
+func TestDO_UseAudit(t *testing.T) {
+	db := openSQLite(t, &auditedUser{})
+	do := newTestDO(db, &auditedUser{})
+	if err := do.UseAudit("created_by", "updated_by"); err != nil {
+		t.Fatalf("use audit fail: %s", err)
+	}
+	name, id := field.NewString("", "name"), field.NewUint("", "id")
+	take := func() (user auditedUser) {
+		if err := db.Take(&user).Error; err != nil {
+			t.Fatalf("take fail: %s", err)
+		}
+		return user
+	}
+
+	if err := do.WithContext(WithOperator(context.Background(), "modi")).Create(&auditedUser{ID: 1}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	if user := take(); user.CreatedBy != "modi" || user.UpdatedBy != "modi" {
+		t.Errorf("create should fill created_by and updated_by, got: %+v", user)
+	}
+	for i, update := range []func(ctx context.Context) (ResultInfo, error){
+		func(ctx context.Context) (ResultInfo, error) {
+			return do.WithContext(ctx).Where(id.Eq(1)).Update(name, "gen")
+		},
+		func(ctx context.Context) (ResultInfo, error) {
+			return do.WithContext(ctx).Where(id.Eq(1)).UpdateSimple(name.Value("gen"))
+		},
+		func(ctx context.Context) (ResultInfo, error) {
+			return do.WithContext(ctx).Updates(&auditedUser{ID: 1, Name: "gen"})
+		},
+	} {
+		operator := fmt.Sprintf("operator%d", i)
+		if _, err := update(WithOperator(context.Background(), operator)); err != nil {
+			t.Fatalf("update fail: %s", err)
+		}
+		if user := take(); user.CreatedBy != "modi" || user.UpdatedBy != operator {
+			t.Errorf("update should fill updated_by with %s only, got: %+v", operator, user)
+		}
+	}
+	if _, err := do.Where(id.Eq(1)).Update(name, "gorm"); err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+	if user := take(); user.UpdatedBy != "operator2" {
+		t.Errorf("update without operator should leave updated_by alone, got: %+v", user)
+	}
+}
+
+func TestDO_sessionOptions(t *testing.T) {
+	db := openSQLite(t, &User{})
+	var buf bytes.Buffer
+	do := newTestDO(db, &User{}, WithPrepareStmt(), WithSkipDefaultTransaction(),
+		WithLogger(logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Info})))
+
+	if err := do.Create(&User{Name: "modi"}); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	if _, err := do.Where(field.NewString("", "name").Eq("modi")).(*DO).First(); err != nil {
+		t.Fatalf("first fail: %s", err)
+	}
+
+	tx := do.UnderlyingDB()
+	if !tx.SkipDefaultTransaction {
+		t.Errorf("statements should skip default transaction")
+	}
+	if stmtDB, ok := tx.Statement.ConnPool.(*gorm.PreparedStmtDB); !ok || len(stmtDB.PreparedSQL) != 2 {
+		t.Errorf("statements should be prepared and cached, got: %#v", tx.Statement.ConnPool)
+	}
+	if !strings.Contains(buf.String(), "INSERT INTO `users_info`") || !strings.Contains(buf.String(), "SELECT * FROM `users_info`") {
+		t.Errorf("statements should be logged by logger of option, got:\n%s", buf.String())
+	}
+
+	if _, ok := db.Statement.ConnPool.(*gorm.PreparedStmtDB); ok || db.SkipDefaultTransaction {
+		t.Errorf("options should not change db passed to UseDB")
+	}
+}
+
+func TestDO_WithDefaultTimeout(t *testing.T) {
+	db := openSQLite(t, &User{})
+	var deadline time.Time
+	var hasDeadline bool
+	var stmtCtx context.Context
+	wait := false // wait for statement context done before querying
+	if err := db.Callback().Query().Before("gorm:query").Register("test:deadline", func(tx *gorm.DB) {
+		deadline, hasDeadline = tx.Statement.Context.Deadline()
+		if stmtCtx = tx.Statement.Context; wait {
+			select {
+			case <-stmtCtx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}); err != nil {
+		t.Fatalf("register callback fail: %s", err)
+	}
+	do := newTestDO(db, &User{}, WithDefaultTimeout(time.Minute))
+
+	start := time.Now()
+	if _, err := do.Find(); err != nil {
+		t.Fatalf("find fail: %s", err)
+	}
+	if !hasDeadline || deadline.Before(start.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("statement should have deadline of default timeout, got: %v %v", deadline, hasDeadline)
+	}
+	if !errors.Is(stmtCtx.Err(), context.Canceled) {
+		t.Errorf("context of timeout should be cancelled after statement, got: %v", stmtCtx.Err())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	expect, _ := ctx.Deadline()
+	if _, err := do.WithContext(ctx).Find(); err != nil {
+		t.Fatalf("find fail: %s", err)
+	}
+	if !deadline.Equal(expect) {
+		t.Errorf("deadline of context should be kept, expect %v, got: %v", expect, deadline)
+	}
+
+	if _, err := newTestDO(db, &User{}).Find(); err != nil {
+		t.Fatalf("find fail: %s", err)
+	}
+	if hasDeadline {
+		t.Errorf("statements of DO without option should not have deadline, got: %v", deadline)
+	}
+
+	wait = true
+	if _, err := newTestDO(db, &User{}, WithDefaultTimeout(10*time.Millisecond)).Find(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("statement should be cancelled after default timeout, got: %v", err)
+	}
+}
+
+func TestDO_WithExplainSlowQuery(t *testing.T) {
+	db := openSQLite(t, &User{})
+	type cancelKey struct{}
+	if err := db.Callback().Query().After("gorm:query").Register("test:cancel", func(tx *gorm.DB) {
+		if cancel, ok := tx.Statement.Context.Value(cancelKey{}).(context.CancelFunc); ok {
+			cancel()
+		}
+	}); err != nil {
+		t.Fatalf("register callback fail: %s", err)
+	}
+	var buf bytes.Buffer
+	find := func(do *DO, ctx context.Context) string {
+		buf.Reset()
+		if _, err := do.WithContext(ctx).(*DO).Find(); err != nil {
+			t.Fatalf("find fail: %s", err)
+		}
+		return buf.String()
+	}
+	newDO := func(opts ...DOOption) *DO {
+		opts = append(opts, WithLogger(logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Warn})))
+		return newTestDO(db, &User{}, opts...)
+	}
+
+	// callbacks of default timeout are registered first, whose context is cancelled before explain
+	timed := newDO(WithDefaultTimeout(time.Minute), WithExplainSlowQuery(time.Nanosecond))
+	do := newDO(WithExplainSlowQuery(time.Nanosecond))
+	if output := find(do, context.Background()); !strings.Contains(output, "slow query of") ||
+		!strings.Contains(output, "SELECT * FROM `users_info`") || !strings.Contains(output, "SCAN users_info") {
+		t.Errorf("plan of slow query should be logged, got:\n%s", output)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if output := find(do, context.WithValue(ctx, cancelKey{}, cancel)); !strings.Contains(output, "explain fail: context canceled") {
+		t.Errorf("explain should be cancelled with context of statement, got:\n%s", output)
+	}
+
+	if output := find(timed, context.Background()); !strings.Contains(output, "SCAN users_info") {
+		t.Errorf("plan of slow query with default timeout should be logged, got:\n%s", output)
+	}
+
+	for _, threshold := range []time.Duration{time.Hour, 0, -time.Second} {
+		if output := find(newDO(WithExplainSlowQuery(threshold)), context.Background()); output != "" {
+			t.Errorf("query should not be explained with threshold %s, got:\n%s", threshold, output)
+		}
+	}
+}
+
+type namedDialector struct {
+	tests.DummyDialector
+	name string
+}
+
+func (d namedDialector) Name() string { return d.name }
+
+type softDeletedUser struct {
+	ID        uint
+	TenantID  int
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+func TestDO_batchPlaceholderLimit(t *testing.T) {
+	for dialect, limit := range placeholderLimits {
+		db, err := gorm.Open(namedDialector{name: dialect}, &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+		if err != nil {
+			t.Fatalf("open %s fail: %s", dialect, err)
+		}
+		var params []int
+		record := func(tx *gorm.DB) { params = append(params, len(tx.Statement.Vars)) }
+		if err = db.Callback().Update().After("*").Register("test:record_update", record); err != nil {
+			t.Fatalf("register callback fail: %s", err)
+		}
+		if err = db.Callback().Delete().After("*").Register("test:record_delete", record); err != nil {
+			t.Fatalf("register callback fail: %s", err)
+		}
+
+		do := newTestDO(db, &softDeletedUser{})
+		if err = do.UseTenant("tenant_id"); err != nil {
+			t.Fatalf("use tenant fail: %s", err)
+		}
+		// tenant id and condition are bound besides rows, so is deleted_at of soft delete
+		scoped := do.WithContext(WithTenant(context.Background(), 1)).Where(field.NewString("", "name").Neq("admin")).(*DO)
+
+		ids := make([]uint, limit)
+		users := make([]*softDeletedUser, limit/3)
+		for i := range ids {
+			ids[i] = uint(i + 1)
+		}
+		for i := range users {
+			users[i] = &softDeletedUser{ID: uint(i + 1), Name: "modi"}
+		}
+		for method, run := range map[string]func() (ResultInfo, error){
+			"DeleteByPrimaryKeys": func() (ResultInfo, error) { return scoped.DeleteByPrimaryKeys(ids, limit) },
+			"UpdateColumnsInBatches": func() (ResultInfo, error) {
+				return scoped.UpdateColumnsInBatches(users, limit, field.NewString("", "name"))
+			},
+		} {
+			params = params[:0]
+			if _, err = run(); err != nil {
+				t.Fatalf("%s of %s fail: %s", method, dialect, err)
+			}
+			// one row statement counting fixed placeholders and 2 batches
+			if len(params) != 3 {
+				t.Errorf("%s of %s should take 3 statements, got %v", method, dialect, params)
+			}
+			for _, n := range params {
+				if n > limit {
+					t.Errorf("%s of %s should bind at most %d placeholders, got %v", method, dialect, limit, params)
+				}
+			}
+		}
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	for _, testcase := range []struct {
+		err       error
+		transient bool
+	}{
+		{&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}, true},
+		{fmt.Errorf("update users: %w", &mysql.MySQLError{Number: 1205}), true},
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'deadlock' for key 'name'"}, false},
+		{&pgconn.PgError{Code: "40P01"}, true},
+		{&pgconn.PgError{Code: "40001"}, true},
+		{&pgconn.PgError{Code: "23505", Message: "could not serialize: duplicate key"}, false},
+		{mssql.Error{Number: 1205}, true},
+		{mssql.Error{Number: 2627, Message: "deadlock"}, false},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{errors.New("Error 1213: Deadlock found when trying to get lock"), false}, // not a driver error
+		{context.DeadlineExceeded, false},
+		{nil, false},
+	} {
+		if transient := IsTransientError(testcase.err); transient != testcase.transient {
+			t.Errorf("IsTransientError(%#v) should be %v, got %v", testcase.err, testcase.transient, transient)
+		}
+	}
+}
+
+func TestRunWithPolicy(t *testing.T) {
+	db := openSQLite(t, &User{})
+	deadlock := &mysql.MySQLError{Number: 1213}
+	policy := StatementPolicy{Timeout: time.Minute, MaxAttempts: 3, Backoff: time.Millisecond}
+	failing := func(attempts *int, failures int) func(tx *gorm.DB) error {
+		return func(tx *gorm.DB) error {
+			*attempts++
+			if _, ok := tx.Statement.Context.Deadline(); !ok {
+				return errors.New("attempt should have deadline")
+			}
+			if *attempts <= failures {
+				return deadlock
+			}
+			return tx.Exec("SELECT 1").Error
+		}
+	}
+
+	var attempts int
+	if err := RunWithPolicy(db, policy, failing(&attempts, 2)); err != nil || attempts != 3 {
+		t.Errorf("transient error should be retried, got: %d %v", attempts, err)
+	}
+	attempts = 0
+	if err := RunWithPolicy(db, policy, failing(&attempts, 5)); err != deadlock || attempts != 3 {
+		t.Errorf("error of the last attempt should be returned, got: %d %v", attempts, err)
+	}
+	attempts = 0
+	notFound := func(*gorm.DB) error { attempts++; return gorm.ErrRecordNotFound }
+	if err := RunWithPolicy(db, policy, notFound); err != gorm.ErrRecordNotFound || attempts != 1 {
+		t.Errorf("error not transient should not be retried, got: %d %v", attempts, err)
+	}
+
+	// transaction is aborted by failed statement, it's not retried
+	attempts = 0
+	err := db.Transaction(func(tx *gorm.DB) error { return RunWithPolicy(tx, policy, failing(&attempts, 1)) })
+	if err != deadlock || attempts != 1 {
+		t.Errorf("statement in transaction should not be retried, got: %d %v", attempts, err)
+	}
+
+	// backoff is interrupted by done context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	attempts = 0
+	start := time.Now()
+	err = RunWithPolicy(db.WithContext(ctx), StatementPolicy{MaxAttempts: 3, Backoff: time.Minute}, failing(&attempts, 5))
+	if err != deadlock || attempts != 1 || time.Since(start) > time.Second {
+		t.Errorf("retry should stop when context is done, got: %d %v", attempts, err)
+	}
+}
@@ -0,0 +1,500 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/internal/model"
+)
+
+// kinds of DriftChange
+const (
+	DriftAdded   = "added"
+	DriftDropped = "dropped"
+	DriftChanged = "changed"
+)
+
+// Drift changes of schema of tables in db since baseline, see Generator.Drift
+type Drift struct {
+	Baseline string        // schema JSON, lock file or model directory compared with
+	Changes  []DriftChange // sorted by table, tables first, then columns and indexes
+}
+
+// DriftChange change of table, column or index since baseline
+type DriftChange struct {
+	Table  string // table name, generated file name for lock file
+	Object string // table, column or index
+	Name   string // name of column or index, empty for table
+	Kind   string // DriftAdded, DriftDropped or DriftChanged
+	From   string // definition in baseline, empty if it's added
+	To     string // definition in db, empty if it's dropped
+}
+
+// Empty whether nothing changed since baseline
+func (d *Drift) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// String human-readable report of changes grouped by table, e.g.
+//
+//	users
+//	  + column age: INTEGER NOT NULL DEFAULT 0
+//	  ~ column name: TEXT -> VARCHAR(64) NOT NULL
+//	  - index idx_users_nick: (nick)
+func (d *Drift) String() string {
+	if d.Empty() {
+		return fmt.Sprintf("no drift since %s\n", d.Baseline)
+	}
+
+	marks := map[string]string{DriftAdded: "+", DriftDropped: "-", DriftChanged: "~"}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d changes since %s\n", len(d.Changes), d.Baseline)
+	table := ""
+	for _, c := range d.Changes {
+		if c.Object == "table" {
+			table = ""
+			fmt.Fprintf(&buf, "%s table %s", marks[c.Kind], c.Table)
+			if c.Kind == DriftChanged {
+				fmt.Fprintf(&buf, ": %s -> %s", c.From, c.To)
+			}
+			buf.WriteString("\n")
+			continue
+		}
+		if c.Table != table {
+			table = c.Table
+			fmt.Fprintf(&buf, "%s\n", table)
+		}
+		fmt.Fprintf(&buf, "  %s %s %s: ", marks[c.Kind], c.Object, c.Name)
+		switch c.Kind {
+		case DriftAdded:
+			buf.WriteString(c.To)
+		case DriftDropped:
+			buf.WriteString(c.From)
+		default:
+			fmt.Fprintf(&buf, "%s -> %s", c.From, c.To)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// Drift compare schema of generated tables in db with baseline, which is schema JSON exported by
+// WithSchemaJSONExport or snapshot of WithMigrationExport, lock file of LockFile, or directory of models
+// generated before. Schema JSON and models are compared by columns and indexes, types and indexes of models are
+// compared only if they're generated with FieldWithTypeTag and FieldWithIndexTag. Lock file is compared by
+// fingerprints of tables, so config should be the same as generating. Baseline tables which are not generated
+// are reported dropped only if they don't exist in db. Models in model package are compared if baseline is empty
+func (g *Generator) Drift(baseline string) (*Drift, error) {
+	g.relateModels()
+	g.extractBaseModel()
+
+	if baseline == "" {
+		outPath, err := g.getModelOutputPath()
+		if err != nil {
+			return nil, err
+		}
+		baseline = filepath.Clean(outPath)
+	}
+	info, err := os.Stat(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("read drift baseline fail: %w", err)
+	}
+	tables, err := g.getTables()
+	if err != nil {
+		return nil, fmt.Errorf("get tables fail: %w", err)
+	}
+	existing := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		existing[table] = true
+	}
+
+	drift := &Drift{Baseline: baseline}
+	live := migrationSchema(g.dialect(), g.exportModels())
+	if info.IsDir() {
+		base, err := modelSchema(baseline)
+		if err != nil {
+			return nil, err
+		}
+		drift.Changes = g.driftSchema(base, live, existing, true)
+		return drift, nil
+	}
+
+	content, err := ioutil.ReadFile(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("read drift baseline fail: %w", err)
+	}
+	var probe struct {
+		Tables json.RawMessage `json:"tables"`
+	}
+	if err = json.Unmarshal(content, &probe); err != nil {
+		return nil, fmt.Errorf("parse drift baseline %s fail: %w", baseline, err)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(probe.Tables), []byte("{")) {
+		var lock lockFile
+		if err = json.Unmarshal(content, &lock); err != nil {
+			return nil, fmt.Errorf("parse lock file %s fail: %w", baseline, err)
+		}
+		if drift.Changes, err = g.driftLock(&lock, existing); err != nil {
+			return nil, err
+		}
+		return drift, nil
+	}
+
+	var base model.Schema
+	if err = json.Unmarshal(content, &base); err != nil {
+		return nil, fmt.Errorf("parse schema json %s fail: %w", baseline, err)
+	}
+	drift.Changes = g.driftSchema(&base, live, existing, false)
+	return drift, nil
+}
+
+// driftLock compare fingerprints of generated tables with lock file
+func (g *Generator) driftLock(lock *lockFile, existing map[string]bool) (changes []DriftChange, err error) {
+	current := make(map[string]string, len(g.models))
+	for _, meta := range g.models {
+		if meta.TableSchema() == nil {
+			continue
+		}
+		if current[meta.FileName], err = g.fingerprint(meta); err != nil {
+			return nil, err
+		}
+	}
+	existingFiles := make(map[string]bool, len(existing))
+	for table := range existing {
+		existingFiles[g.tableFileName(table)] = true
+	}
+
+	for name, sum := range current {
+		switch last, ok := lock.Tables[name]; {
+		case !ok:
+			changes = append(changes, DriftChange{Table: name, Object: "table", Kind: DriftAdded, To: shortSum(sum)})
+		case last != sum:
+			changes = append(changes, DriftChange{Table: name, Object: "table", Kind: DriftChanged, From: shortSum(last), To: shortSum(sum)})
+		}
+	}
+	for name, sum := range lock.Tables {
+		if _, ok := current[name]; !ok && !existingFiles[name] {
+			changes = append(changes, DriftChange{Table: name, Object: "table", Kind: DriftDropped, From: shortSum(sum)})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Table < changes[j].Table })
+	return changes, nil
+}
+
+// tableFileName name of generated file of table
+func (g *Generator) tableFileName(table string) string {
+	if g.fileNameNS != nil {
+		return g.fileNameNS(table)
+	}
+	return schema.NamingStrategy{SingularTable: true}.TableName(table)
+}
+
+func shortSum(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
+
+// driftSchema compare schema of generated tables with baseline, attributes unknown by partial baseline(models)
+// are taken from db
+func (g *Generator) driftSchema(base, live *model.Schema, existing map[string]bool, partial bool) []DriftChange {
+	d := migrationDialect(g.dialect())
+	var tables, objects []DriftChange
+	for _, t := range live.Tables {
+		old := base.Table(t.Name)
+		if old == nil {
+			tables = append(tables, DriftChange{Table: t.Name, Object: "table", Kind: DriftAdded})
+			continue
+		}
+		old = g.driftBaseline(old, t, partial)
+
+		oldColumns := make(map[string]*model.ColumnSchema, len(old.Columns))
+		for _, c := range old.Columns {
+			oldColumns[c.Name] = c
+		}
+		newColumns := make(map[string]bool, len(t.Columns))
+		for _, c := range t.Columns {
+			newColumns[c.Name] = true
+			switch oc := oldColumns[c.Name]; {
+			case oc == nil:
+				objects = append(objects, DriftChange{Table: t.Name, Object: "column", Name: c.Name, Kind: DriftAdded, To: d.describeColumn(c)})
+			case d.describeColumn(oc) != d.describeColumn(c):
+				objects = append(objects, DriftChange{Table: t.Name, Object: "column", Name: c.Name, Kind: DriftChanged,
+					From: d.describeColumn(oc), To: d.describeColumn(c)})
+			}
+		}
+		for _, c := range old.Columns {
+			if !newColumns[c.Name] {
+				objects = append(objects, DriftChange{Table: t.Name, Object: "column", Name: c.Name, Kind: DriftDropped, From: d.describeColumn(c)})
+			}
+		}
+
+		oldIndexes := make(map[string]*model.IndexSchema, len(old.Indexes))
+		for _, idx := range old.Indexes {
+			oldIndexes[idx.Name] = idx
+		}
+		newIndexes := make(map[string]bool, len(t.Indexes))
+		for _, idx := range t.Indexes {
+			newIndexes[idx.Name] = true
+			switch oi := oldIndexes[idx.Name]; {
+			case idx.PrimaryKey:
+			case oi == nil:
+				objects = append(objects, DriftChange{Table: t.Name, Object: "index", Name: idx.Name, Kind: DriftAdded, To: d.describeIndex(idx)})
+			case d.describeIndex(oi) != d.describeIndex(idx):
+				objects = append(objects, DriftChange{Table: t.Name, Object: "index", Name: idx.Name, Kind: DriftChanged,
+					From: d.describeIndex(oi), To: d.describeIndex(idx)})
+			}
+		}
+		for _, idx := range old.Indexes {
+			if !newIndexes[idx.Name] && !idx.PrimaryKey {
+				objects = append(objects, DriftChange{Table: t.Name, Object: "index", Name: idx.Name, Kind: DriftDropped, From: d.describeIndex(idx)})
+			}
+		}
+	}
+	for _, t := range base.Tables {
+		if live.Table(t.Name) == nil && !existing[t.Name] {
+			tables = append(tables, DriftChange{Table: t.Name, Object: "table", Kind: DriftDropped})
+		}
+	}
+
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Table < tables[j].Table })
+	return append(tables, objects...)
+}
+
+// driftBaseline baseline of table without excluded columns and their indexes, attributes unknown by partial
+// baseline are taken from live
+func (g *Generator) driftBaseline(old, live *model.TableSchema, partial bool) *model.TableSchema {
+	excluded := make(map[string]bool)
+	for _, column := range tableColumns(g.ExcludeColumns, old.Name) {
+		excluded[column] = true
+	}
+	liveColumns := make(map[string]*model.ColumnSchema, len(live.Columns))
+	for _, c := range live.Columns {
+		liveColumns[c.Name] = c
+	}
+
+	t := &model.TableSchema{Name: old.Name}
+	for _, c := range old.Columns {
+		if excluded[c.Name] {
+			continue
+		}
+		if lc := liveColumns[c.Name]; partial && lc != nil {
+			merged := *lc
+			merged.PrimaryKey = c.PrimaryKey
+			if c.AutoIncrement != nil {
+				merged.AutoIncrement = c.AutoIncrement
+			}
+			if c.ColumnType != "" {
+				merged.ColumnType = c.ColumnType
+			}
+			if c.PrimaryKey == nil || !*c.PrimaryKey { // not null isn't tagged for primary key
+				merged.Nullable = c.Nullable
+			}
+			c = &merged
+		}
+		t.Columns = append(t.Columns, c)
+	}
+	if partial && len(old.Indexes) == 0 { // models generated without index tags
+		t.Indexes = live.Indexes
+		return t
+	}
+indexes:
+	for _, idx := range old.Indexes {
+		for _, column := range idx.Columns {
+			if excluded[column] {
+				continue indexes
+			}
+		}
+		t.Indexes = append(t.Indexes, idx)
+	}
+	return t
+}
+
+// describeColumn definition of column without name, e.g. VARCHAR(64) NOT NULL DEFAULT 0
+func (d migrationDialect) describeColumn(c *model.ColumnSchema) string {
+	def := strings.TrimPrefix(d.columnDef(&model.ColumnSchema{
+		Name:          c.Name,
+		DatabaseType:  strings.ToUpper(c.DatabaseType),
+		ColumnType:    strings.ToUpper(c.ColumnType),
+		AutoIncrement: c.AutoIncrement,
+		Nullable:      c.Nullable,
+		Default:       c.Default,
+	}), d.quote(c.Name)+" ")
+	if c.PrimaryKey != nil && *c.PrimaryKey {
+		def += " PRIMARY KEY"
+	}
+	return def
+}
+
+// describeIndex definition of index without name, e.g. UNIQUE (name, age)
+func (d migrationDialect) describeIndex(idx *model.IndexSchema) string {
+	def := "(" + strings.Join(idx.Columns, ", ") + ")"
+	if idx.Unique {
+		def = "UNIQUE " + def
+	}
+	return def
+}
+
+// modelSchema schema of tables declared by models in dir and its sub directories, read from TableName<Model>
+// constants and column, type, primaryKey, autoIncrement, not null, index and uniqueIndex of gorm tags
+func modelSchema(dir string) (*model.Schema, error) {
+	s := &model.Schema{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		tables, err := packageModels(path)
+		s.Tables = append(s.Tables, tables...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read models in %s fail: %w", dir, err)
+	}
+	sort.Slice(s.Tables, func(i, j int) bool { return s.Tables[i].Name < s.Tables[j].Name })
+	return s, nil
+}
+
+// packageModels tables of models declared in go files of dir
+func packageModels(dir string) ([]*model.TableSchema, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*model.TableSchema
+	for _, pkg := range pkgs {
+		structs := make(map[string]*ast.StructType)
+		tableNames := make(map[string]string) // model -> table
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if st, ok := spec.Type.(*ast.StructType); ok {
+							structs[spec.Name.Name] = st
+						}
+					case *ast.ValueSpec:
+						for i, name := range spec.Names {
+							if !strings.HasPrefix(name.Name, "TableName") || i >= len(spec.Values) {
+								continue
+							}
+							if lit, ok := spec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+								tableNames[strings.TrimPrefix(name.Name, "TableName")], _ = strconv.Unquote(lit.Value)
+							}
+						}
+					}
+				}
+			}
+		}
+
+		for name, table := range tableNames {
+			if st := structs[name]; st != nil && table != "" {
+				t := &model.TableSchema{Name: table}
+				addModelColumns(t, st, structs, make(map[*ast.StructType]bool))
+				tables = append(tables, t)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// addModelColumns add columns and indexes of gorm tags of fields of st into t, fields of embedded structs
+// declared in the same package are included
+func addModelColumns(t *model.TableSchema, st *ast.StructType, structs map[string]*ast.StructType, seen map[*ast.StructType]bool) {
+	if seen[st] {
+		return
+	}
+	seen[st] = true
+
+	for _, f := range st.Fields.List {
+		var tag string
+		if f.Tag != nil {
+			value, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(value).Get("gorm")
+		}
+		if ident, ok := f.Type.(*ast.Ident); ok && len(f.Names) == 0 && structs[ident.Name] != nil && tag == "" {
+			addModelColumns(t, structs[ident.Name], structs, seen)
+			continue
+		}
+
+		c := &model.ColumnSchema{}
+		notNull, primaryKey := false, false
+		var indexes []string
+		for _, part := range strings.Split(tag, ";") {
+			key, value, _ := strings.Cut(part, ":")
+			switch key = strings.ToLower(strings.TrimSpace(key)); key {
+			case "column":
+				c.Name = value
+			case "type":
+				c.ColumnType = value
+			case "primarykey":
+				primaryKey = true
+			case "autoincrement":
+				autoIncrement := value == "" || value == "true"
+				c.AutoIncrement = &autoIncrement
+			case "not null":
+				notNull = true
+			case "index", "uniqueindex":
+				indexes = append(indexes, key+":"+value)
+			}
+		}
+		if c.Name == "" {
+			continue
+		}
+		nullable := !notNull
+		c.PrimaryKey, c.Nullable = &primaryKey, &nullable
+		t.Columns = append(t.Columns, c)
+		for _, idx := range indexes {
+			key, value, _ := strings.Cut(idx, ":")
+			addModelIndex(t, c.Name, value, key == "uniqueindex")
+		}
+	}
+}
+
+// addModelIndex add column into index of tag value name,priority:n
+func addModelIndex(t *model.TableSchema, column, value string, unique bool) {
+	parts := strings.Split(value, ",")
+	priority := 0
+	for _, part := range parts[1:] {
+		if key, p, _ := strings.Cut(strings.TrimSpace(part), ":"); key == "priority" {
+			priority, _ = strconv.Atoi(p)
+		}
+	}
+	var idx *model.IndexSchema
+	for _, i := range t.Indexes {
+		if i.Name == parts[0] {
+			idx = i
+		}
+	}
+	if idx == nil {
+		idx = &model.IndexSchema{Name: parts[0], Unique: unique}
+		t.Indexes = append(t.Indexes, idx)
+	}
+	if priority <= 0 {
+		priority = len(idx.Columns) + 1
+	}
+	for len(idx.Columns) < priority {
+		idx.Columns = append(idx.Columns, "")
+	}
+	idx.Columns[priority-1] = column
+}
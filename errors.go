@@ -5,4 +5,29 @@ import "errors"
 var (
 	// ErrEmptyCondition empty condition
 	ErrEmptyCondition = errors.New("empty condition")
+
+	// ErrLockingNotSupported row level locking is not supported by dialector
+	ErrLockingNotSupported = errors.New("row level locking is not supported")
+
+	// ErrConflictingLockOptions row level locking options conflict with each other, e.g. NOWAIT and SKIP LOCKED
+	ErrConflictingLockOptions = errors.New("conflicting lock options")
+
+	// ErrStaleObject record has been modified or deleted since it was read, returned by optimistic locking
+	ErrStaleObject = errors.New("stale object")
+
+	// ErrMissingTenant context carries no tenant id while table is scoped by tenant
+	ErrMissingTenant = errors.New("missing tenant in context")
+
+	// ErrUnscopedRawSQL raw SQL(Raw/Exec) of table scoped by tenant doesn't reference tenant column, raw SQL can't be
+	// scoped automatically and should filter by tenant column itself
+	ErrUnscopedRawSQL = errors.New("raw sql of tenant scoped table doesn't filter by tenant column")
+
+	// ErrMissingPIICipher pii column is read or written before cipher is set by SetPIICipher
+	ErrMissingPIICipher = errors.New("missing pii cipher")
+
+	// ErrNoRowsAffected update or delete matched no row, returned by generated methods when Config.StrictRowsAffected is true
+	ErrNoRowsAffected = errors.New("no rows affected")
+
+	// ErrUnknownField name is not a column of table, returned by generated Parse<Model>Field
+	ErrUnknownField = errors.New("unknown field")
 )
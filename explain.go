@@ -0,0 +1,148 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	explainSettingKey  = "gen:explain"
+	explainInstanceKey = "gen:explain_start"
+)
+
+// explainPrefixes dialect -> statement prefix showing query plan, slow queries of other dialects are logged without plan
+var explainPrefixes = map[string]string{"mysql": "EXPLAIN ", "postgres": "EXPLAIN ", "sqlite": "EXPLAIN QUERY PLAN "}
+
+// WithExplainSlowQuery log query plan of queries of query code slower than threshold by logger of db, with name of
+// generated method running the query(or caller of methods promoted from gen.DO), e.g. query.userDo.FindByName.
+// Plan is shown by EXPLAIN of mysql/postgres and EXPLAIN QUERY PLAN of sqlite. Slow queries run twice, so it's meant
+// for development. Only queries loading records(Find/First/Take/Count/Scan...) are covered, Row/Rows are not because
+// their rows are still open. Threshold <= 0 disables it, so it can be turned off by configuration
+func WithExplainSlowQuery(threshold time.Duration) DOOption {
+	return &explainOption{threshold: threshold}
+}
+
+type explainOption struct{ threshold time.Duration }
+
+// Apply update config to new config
+func (o *explainOption) Apply(config *DOConfig) error {
+	config.explainThreshold = o.threshold
+	return nil
+}
+
+// AfterInitialize initialize plugins after db connected
+func (o *explainOption) AfterInitialize(do *DO) error {
+	if o.threshold <= 0 {
+		return nil
+	}
+	if err := registerExplainCallbacks(do.db); err != nil {
+		return err
+	}
+	do.db = do.settingDB(do.db)
+	return nil
+}
+
+var explainCallbacks sync.Map // callbacks of db -> struct{}
+
+// registerExplainCallbacks register callbacks timing queries once for each db,
+// only queries of db marked by settingDB are explained
+func registerExplainCallbacks(db *gorm.DB) error {
+	if _, loaded := explainCallbacks.LoadOrStore(db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+	if err := db.Callback().Query().Before("*").Register("gen:explain_before_query", startExplain); err != nil {
+		return err
+	}
+	return db.Callback().Query().After("*").Register("gen:explain_after_query", endExplain)
+}
+
+func startExplain(db *gorm.DB) {
+	if _, ok := db.Get(explainSettingKey); ok {
+		db.InstanceSet(explainInstanceKey, time.Now())
+	}
+}
+
+func endExplain(db *gorm.DB) {
+	start, ok := db.InstanceGet(explainInstanceKey)
+	if !ok || db.Error != nil || db.DryRun {
+		return
+	}
+	threshold, _ := db.Get(explainSettingKey)
+	elapsed := time.Since(start.(time.Time))
+	if elapsed < threshold.(time.Duration) {
+		return
+	}
+
+	ctx, sql := db.Statement.Context, db.Statement.SQL.String()
+	plan, err := explainQuery(ctx, db, sql)
+	if err != nil {
+		plan = fmt.Sprintf("explain fail: %s", err)
+	}
+	db.Logger.Warn(ctx, "slow query of %s took %s: %s\n%s",
+		callerMethod(), elapsed, db.Dialector.Explain(sql, db.Statement.Vars...), plan)
+}
+
+// explainQuery return query plan of sql as rows of tab separated columns, it's cancelled with context of statement
+func explainQuery(ctx context.Context, db *gorm.DB, sql string) (string, error) {
+	prefix, ok := explainPrefixes[db.Dialector.Name()]
+	if !ok {
+		return "", fmt.Errorf("explain of %s is not supported", db.Dialector.Name())
+	}
+	rows, err := db.Statement.ConnPool.QueryContext(ctx, prefix+sql, db.Statement.Vars...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close() // nolint
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	lines := []string{strings.Join(columns, "\t")}
+	values, dest := make([]interface{}, len(columns)), make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		cells := make([]string, len(values))
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			cells[i] = fmt.Sprint(v)
+		}
+		lines = append(lines, strings.Join(cells, "\t"))
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+// callerMethod return name of the first function calling into gorm and gen, e.g. query.userDo.FindByName
+func callerMethod() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		pkg, slash := frame.Function, strings.LastIndex(frame.Function, "/")
+		if dot := strings.IndexByte(pkg[slash+1:], '.'); dot >= 0 {
+			pkg = pkg[:slash+1+dot]
+		}
+		switch {
+		case strings.HasPrefix(pkg, "gorm.io/gorm"), strings.HasPrefix(pkg, "gorm.io/plugin/"),
+			pkg == "gorm.io/gen", strings.HasPrefix(pkg, "gorm.io/gen/internal/"):
+		default:
+			return frame.Function[slash+1:]
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package gen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// exporter export generated models into other formats, called after code generated
+type exporter func(g *Generator, models []*generate.QueryStructMeta) error
+
+// export run all exporters
+func (g *Generator) export() error {
+	if len(g.exporters) == 0 {
+		return nil
+	}
+	models := g.exportModels()
+	for _, export := range g.exporters {
+		if err := export(g, models); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportModels return models to export sorted by name, including models generated from db and applied structs
+func (g *Generator) exportModels() []*generate.QueryStructMeta {
+	metas := make(map[string]*generate.QueryStructMeta, len(g.models)+len(g.Data))
+	for name, meta := range g.models {
+		metas[name] = meta
+	}
+	for name, info := range g.Data {
+		if _, ok := metas[name]; !ok {
+			metas[name] = info.QueryStructMeta
+		}
+	}
+
+	models := make([]*generate.QueryStructMeta, 0, len(metas))
+	for _, meta := range metas {
+		models = append(models, meta)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ModelStructName < models[j].ModelStructName })
+	return models
+}
+
+// writeFile save non-go file to path, creating its directory
+func (g *Generator) writeFile(fileName string, content []byte) (err error) {
+	if err = os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+		return fmt.Errorf("make dir of %s fail: %w", fileName, err)
+	}
+	if content, err = g.beforeRender(fileName, content); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(fileName, content, 0640); err != nil {
+		return err
+	}
+	g.written(fileName)
+	g.info("generate export file: " + fileName)
+	return g.afterWrite(fileName)
+}
+
+// arrayGoTypes array types of database driver -> slice type of their values
+var arrayGoTypes = map[string]string{
+	"pq.BoolArray":    "[]bool",
+	"pq.ByteaArray":   "[][]byte",
+	"pq.Float32Array": "[]float32",
+	"pq.Float64Array": "[]float64",
+	"pq.Int32Array":   "[]int32",
+	"pq.Int64Array":   "[]int64",
+	"pq.StringArray":  "[]string",
+}
+
+// fieldGoType return go type of field without pointer, named type generated for field is replaced by its underlying type
+// and array type of database driver is replaced by slice type
+func fieldGoType(f *model.Field) string {
+	if f.Enum != nil && f.Enum.Set {
+		return "[]" + f.Enum.Type
+	} else if f.Enum != nil {
+		return f.Enum.Type
+	} else if f.BinaryUUID != nil {
+		return model.UUIDType
+	}
+	typ := strings.TrimPrefix(f.Type, "*")
+	if _, valueType, ok := nullValue(typ); ok {
+		typ = valueType
+	}
+	if slice, ok := arrayGoTypes[typ]; ok {
+		return slice
+	}
+	return typ
+}
+
+// sqlNullTypes sql.NullXXX types -> name and type of their value field
+var sqlNullTypes = map[string][2]string{
+	"sql.NullString":  {"String", "string"},
+	"sql.NullInt64":   {"Int64", "int64"},
+	"sql.NullInt32":   {"Int32", "int32"},
+	"sql.NullInt16":   {"Int16", "int16"},
+	"sql.NullByte":    {"Byte", "uint8"},
+	"sql.NullFloat64": {"Float64", "float64"},
+	"sql.NullBool":    {"Bool", "bool"},
+	"sql.NullTime":    {"Time", "time.Time"},
+}
+
+// nullValue return name and type of value field of sql.NullXXX or sql.Null[T], ok is false if typ is not null type
+func nullValue(typ string) (name, valueType string, ok bool) {
+	if v, ok := sqlNullTypes[typ]; ok {
+		return v[0], v[1], true
+	}
+	if strings.HasPrefix(typ, "sql.Null[") && strings.HasSuffix(typ, "]") {
+		return "V", strings.TrimSuffix(strings.TrimPrefix(typ, "sql.Null["), "]"), true
+	}
+	return "", "", false
+}
+
+// fieldNullable whether field is nullable, as pointer or sql.NullXXX/sql.Null[T]
+func fieldNullable(f *model.Field) bool {
+	_, _, null := nullValue(f.Type)
+	return null || strings.HasPrefix(f.Type, "*")
+}
+
+// sliceElem return element type of slice type, ok is false if typ is not slice or is []byte
+func sliceElem(typ string) (elem string, ok bool) {
+	if typ == "[]byte" || typ == "[]uint8" || !strings.HasPrefix(typ, "[]") {
+		return typ, false
+	}
+	return strings.TrimPrefix(typ, "[]"), true
+}
@@ -0,0 +1,73 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"gorm.io/gen/internal/generate"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// diTemplates dependency injection framework -> provider template
+var diTemplates = map[string]string{
+	"wire": tmpl.DIWire,
+	"fx":   tmpl.DIFx,
+}
+
+type diDAO struct {
+	Name  string // field name in Query
+	Table string
+	Iface string // DAO interface, e.g. IUserDo
+}
+
+type diProviders struct {
+	Package string
+	DAOs    []diDAO
+}
+
+// WithDIExport generate di.gen.go in query package providing *Query of *gorm.DB and DAO interface(I<Model>Do)
+// of each table, framework is wire(ProviderSet) or fx(Module), requires WithQueryInterface mode
+func (cfg *Config) WithDIExport(framework string) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		diTmpl, ok := diTemplates[framework]
+		if !ok {
+			return fmt.Errorf("unknow DI framework %q (support wire || fx for now)", framework)
+		}
+		if len(g.Data) == 0 {
+			return nil
+		}
+		if !g.judgeMode(WithQueryInterface) {
+			return fmt.Errorf("DI providers of %s require WithQueryInterface mode", framework)
+		}
+
+		providers := diProviders{Package: g.queryPkgName}
+		for _, meta := range models {
+			data, ok := g.Data[meta.ModelStructName]
+			if !ok {
+				continue
+			}
+			providers.DAOs = append(providers.DAOs, diDAO{
+				Name:  data.ModelStructName,
+				Table: data.TableName,
+				Iface: data.ReturnObject(),
+			})
+		}
+
+		header, err := g.provenance(nil)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		buf.WriteString(header)
+		if err := render(diTmpl, &buf, providers); err != nil {
+			return err
+		}
+		fileName := filepath.Join(g.OutPath, "di.gen.go")
+		if err := g.output(fileName, buf.Bytes()); err != nil {
+			return err
+		}
+		g.info("generate DI file: " + fileName)
+		return nil
+	})
+}
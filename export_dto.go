@@ -0,0 +1,131 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// dtoExport DTO package configured by WithDTOExport
+type dtoExport struct {
+	outPath  string
+	excludes []string // excluded columns, e.g. password or users.password
+}
+
+// excluded whether column of table is excluded from DTO
+func (d *dtoExport) excluded(tableName, columnName string) bool {
+	if d == nil {
+		return false
+	}
+	for _, exclude := range d.excludes {
+		if exclude == columnName || exclude == tableName+"."+columnName {
+			return true
+		}
+	}
+	return false
+}
+
+type dtoField struct {
+	Name     string
+	Type     string
+	Tag      string
+	Embedded bool // declared in base model embedded by model
+}
+
+type dtoStruct struct {
+	Package     string
+	ImportPaths []string
+	Name        string
+	Model       string // model type, e.g. model.User
+	TableName   string
+	Fields      []dtoField
+	Embedded    bool // model embeds base model, whose fields cannot be set in composite literal
+}
+
+// WithDTOExport generate a package with API-facing struct of each generated model into outPath,
+// together with <Model>ToDTO/<Model>FromDTO converters. Columns in excludes are left out of DTO,
+// a column is matched by its name (e.g. password_hash) or qualified by table name (e.g. users.password_hash).
+// Handlers generated by WithHandlerExport bind and respond with DTO once it's enabled
+func (cfg *Config) WithDTOExport(outPath string, excludes ...string) {
+	cfg.dto = &dtoExport{outPath: outPath, excludes: excludes}
+	// handlers refer to DTO package, so export it before others
+	cfg.exporters = append([]exporter{func(g *Generator, models []*generate.QueryStructMeta) error {
+		outPath, err := filepath.Abs(outPath)
+		if err != nil {
+			return fmt.Errorf("dto outpath is invalid: %w", err)
+		}
+		if err = os.MkdirAll(outPath, os.ModePerm); err != nil {
+			return fmt.Errorf("make dir dto outpath(%s) fail: %s", outPath, err)
+		}
+
+		for _, meta := range models {
+			if !meta.Generated {
+				continue
+			}
+			modelPkgPath := meta.StructInfo.PkgPath
+			if modelPkgPath == "" {
+				modelPkgPath = g.modelPkgPath
+			}
+
+			header, err := g.provenance(meta)
+			if err != nil {
+				return err
+			}
+			var buf bytes.Buffer
+			buf.WriteString(header)
+			d := newDTOStruct(meta, g.dto)
+			d.Package = filepath.Base(outPath)
+			d.ImportPaths = append([]string{`"` + modelPkgPath + `"`}, meta.ImportPkgPaths...)
+			if err := render(tmpl.DTO, &buf, d); err != nil {
+				return err
+			}
+
+			fileName := filepath.Join(outPath, meta.FileName+".gen.go")
+			if err := g.output(fileName, buf.Bytes()); err != nil {
+				return err
+			}
+			g.info("generate dto file: " + fileName)
+		}
+		return nil
+	}}, cfg.exporters...)
+}
+
+func newDTOStruct(meta *generate.QueryStructMeta, dto *dtoExport) *dtoStruct {
+	d := &dtoStruct{
+		Name:      meta.ModelStructName,
+		Model:     meta.StructInfo.Package + "." + meta.StructInfo.Type,
+		TableName: meta.TableName,
+	}
+	for _, f := range meta.Fields {
+		if f.IsRelation() || dto.excluded(meta.TableName, f.ColumnName) {
+			continue
+		}
+		typ := f.Type
+		if f.Enum != nil { // named type is declared in model package
+			typ = strings.Replace(typ, f.Enum.Name, meta.StructInfo.Package+"."+f.Enum.Name, 1)
+		} else if f.BinaryUUID != nil {
+			typ = strings.Replace(typ, f.BinaryUUID.Name, meta.StructInfo.Package+"."+f.BinaryUUID.Name, 1)
+		} else if f.Geometry != nil {
+			typ = strings.Replace(typ, f.Geometry.Name, meta.StructInfo.Package+"."+f.Geometry.Name, 1)
+		}
+		d.Fields = append(d.Fields, dtoField{Name: f.Name, Type: typ, Tag: dtoTag(f), Embedded: f.Embedded})
+		d.Embedded = d.Embedded || f.Embedded
+	}
+	return d
+}
+
+// dtoTag return json tag of DTO field, which is the same as model field
+func dtoTag(f *model.Field) string {
+	tag, ok := f.Tag[field.TagKeyJson]
+	if !ok || strings.TrimSpace(tag) == "" {
+		tag = f.ColumnName
+	}
+	return field.Tag{field.TagKeyJson: tag}.Build()
+}
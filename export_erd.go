@@ -0,0 +1,200 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// erdTemplates format -> ER diagram template
+var erdTemplates = map[string]string{
+	"mermaid":  tmpl.ERDMermaid,
+	"graphviz": tmpl.ERDGraphviz,
+}
+
+// erdCardinalities relationship -> mermaid cardinality between owner and related entity
+var erdCardinalities = map[field.RelationshipType]string{
+	field.HasOne:    "||--o|",
+	field.HasMany:   "||--o{",
+	field.BelongsTo: "}o--||",
+	field.Many2Many: "}o--o{",
+}
+
+var erdIdentifierRegexp = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+type erdColumn struct {
+	Name    string
+	Type    string
+	Keys    string // PK, FK, UK
+	Comment string
+}
+
+type erdEntity struct {
+	Name    string
+	Columns []erdColumn
+}
+
+type erdRelation struct {
+	Left        string
+	Right       string
+	Cardinality string
+	Label       string
+}
+
+type erdDiagram struct {
+	Entities  []erdEntity
+	Relations []erdRelation
+}
+
+// WithERDExport export an entity-relationship diagram of generated models into fileName,
+// format is mermaid or graphviz. Relations come from relation fields of models and foreign keys
+// detected by column name, e.g. company_id references table companies with primary key
+func (cfg *Config) WithERDExport(fileName, format string) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		erdTmpl, ok := erdTemplates[format]
+		if !ok {
+			return fmt.Errorf("unknow erd format %q (support mermaid || graphviz for now)", format)
+		}
+		d := newERDiagram(models)
+		if format == "graphviz" {
+			d.escapeHTML()
+		}
+
+		var buf bytes.Buffer
+		if err := render(erdTmpl, &buf, d); err != nil {
+			return err
+		}
+		return g.writeFile(fileName, buf.Bytes())
+	})
+}
+
+func newERDiagram(models []*generate.QueryStructMeta) *erdDiagram {
+	d := new(erdDiagram)
+	entities := make(map[string]string, len(models)) // model struct name -> entity
+	tables := make(map[string]*generate.QueryStructMeta, len(models))
+	for _, meta := range models {
+		entities[meta.ModelStructName] = erdEntityName(meta)
+		tables[meta.TableName] = meta
+	}
+	// entities linked by relation fields, foreign keys between them are not drawn again
+	linked := make(map[[2]string]bool)
+	for _, meta := range models {
+		for _, r := range meta.Relations() {
+			if target, ok := entities[r.Type()[strings.LastIndex(r.Type(), ".")+1:]]; ok {
+				linked[[2]string{entities[meta.ModelStructName], target}] = true
+				linked[[2]string{target, entities[meta.ModelStructName]}] = true
+			}
+		}
+	}
+
+	for _, meta := range models {
+		entity := erdEntity{Name: entities[meta.ModelStructName]}
+		for _, f := range meta.Fields {
+			if f.IsRelation() {
+				typ := f.Relation.Type()
+				target, ok := entities[typ[strings.LastIndex(typ, ".")+1:]]
+				if !ok {
+					continue
+				}
+				d.Relations = append(d.Relations, erdRelation{
+					Left:        entity.Name,
+					Right:       target,
+					Cardinality: erdCardinalities[f.Relation.Relationship()],
+					Label:       f.Relation.Name(),
+				})
+				continue
+			}
+
+			col := erdColumn{
+				Name:    f.ColumnName,
+				Type:    erdIdentifierRegexp.ReplaceAllString(strings.TrimPrefix(f.Type, "*"), "_"),
+				Comment: strings.ReplaceAll(f.ColumnComment, `"`, `'`),
+			}
+			var keys, indexes []string
+			if f.IsPrimaryKey() {
+				keys = append(keys, "PK")
+			}
+			if ref := erdReference(f, tables); ref != nil {
+				keys = append(keys, "FK")
+				target := entities[ref.ModelStructName]
+				if !linked[[2]string{entity.Name, target}] {
+					d.Relations = append(d.Relations, erdRelation{
+						Left:        target,
+						Right:       entity.Name,
+						Cardinality: erdCardinalities[field.HasMany],
+						Label:       f.ColumnName,
+					})
+				}
+			}
+			for _, v := range f.GORMTag[field.TagKeyGormUniqueIndex] {
+				keys = append(keys, "UK")
+				indexes = append(indexes, strings.Split(v, ",")[0])
+			}
+			for _, v := range f.GORMTag[field.TagKeyGormIndex] {
+				indexes = append(indexes, strings.Split(v, ",")[0])
+			}
+			col.Keys = strings.Join(uniqueStrings(keys), ",")
+			if len(indexes) > 0 {
+				col.Comment = strings.TrimSpace(col.Comment + " idx: " + strings.Join(indexes, ","))
+			}
+			entity.Columns = append(entity.Columns, col)
+		}
+		d.Entities = append(d.Entities, entity)
+	}
+	return d
+}
+
+// erdReference return the model referenced by foreign key column named <singular table name>_id
+func erdReference(f *model.Field, tables map[string]*generate.QueryStructMeta) *generate.QueryStructMeta {
+	prefix := strings.TrimSuffix(f.ColumnName, "_id")
+	if prefix == f.ColumnName || prefix == "" {
+		return nil
+	}
+	for _, name := range []string{(schema.NamingStrategy{}).TableName(prefix), prefix} {
+		if meta, ok := tables[name]; ok && meta.PrimaryKey() != nil {
+			return meta
+		}
+	}
+	return nil
+}
+
+func erdEntityName(meta *generate.QueryStructMeta) string {
+	if meta.TableName != "" {
+		return erdIdentifierRegexp.ReplaceAllString(meta.TableName, "_")
+	}
+	return meta.ModelStructName
+}
+
+// escapeHTML escape texts used in graphviz HTML-like labels
+func (d *erdDiagram) escapeHTML() {
+	for i := range d.Entities {
+		for j := range d.Entities[i].Columns {
+			col := &d.Entities[i].Columns[j]
+			col.Name, col.Comment = html.EscapeString(col.Name), html.EscapeString(col.Comment)
+		}
+	}
+	for i := range d.Relations {
+		d.Relations[i].Label = strings.ReplaceAll(d.Relations[i].Label, `"`, `'`)
+	}
+}
+
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	result := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
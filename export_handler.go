@@ -0,0 +1,204 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// handlerTemplates framework -> handler template
+var handlerTemplates = map[string]string{
+	"gin":  tmpl.HandlerGin,
+	"echo": tmpl.HandlerEcho,
+}
+
+type handlerFilter struct {
+	Name   string // field name in query struct
+	Column string
+	Type   string // type of query parameter
+	Method string // method of generated field comparing with value
+}
+
+type handlerPK struct {
+	Name   string
+	Column string
+	Type   string
+	Parse  string // statements parsing path parameter `param` into primary key
+}
+
+type handler struct {
+	Package      string
+	ImportPaths  []string
+	Model        string // model type, e.g. model.User
+	Name         string // model struct name
+	Route        string
+	Filters      []handlerFilter
+	PK           *handlerPK // single primary key used by Get/Update/Delete handlers
+	QueryPackage string
+	DTOPackage   string // bind and respond with DTO of package when it's not empty
+}
+
+// Body type of request and response body
+func (h *handler) Body() string {
+	if h.DTOPackage == "" {
+		return h.Model
+	}
+	return h.DTOPackage + "." + h.Name
+}
+
+// FromBody expression converting request body v into model
+func (h *handler) FromBody(v string) string {
+	if h.DTOPackage == "" {
+		return v
+	}
+	return fmt.Sprintf("%s.%sFromDTO(%s)", h.DTOPackage, h.Name, v)
+}
+
+// ToBody expression converting model v into response body
+func (h *handler) ToBody(v string) string {
+	if h.DTOPackage == "" {
+		return v
+	}
+	return fmt.Sprintf("%s.%sToDTO(%s)", h.DTOPackage, h.Name, v)
+}
+
+// ToBodyList expression converting model list v into response body list
+func (h *handler) ToBodyList(v string) string {
+	if h.DTOPackage == "" {
+		return v
+	}
+	return fmt.Sprintf("%s.%sListToDTO(%s)", h.DTOPackage, h.Name, v)
+}
+
+// WithHandlerExport generate HTTP CRUD handlers of each generated query struct into outPath,
+// framework is gin or echo. Handlers list records with pagination and filters of comparable columns,
+// Get/Update/Delete handlers are generated only for table with single primary key
+func (cfg *Config) WithHandlerExport(outPath, framework string) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		handlerTmpl, ok := handlerTemplates[framework]
+		if !ok {
+			return fmt.Errorf("unknow handler framework %q (support gin || echo for now)", framework)
+		}
+		if len(g.Data) == 0 {
+			return nil
+		}
+		outPath, err := filepath.Abs(outPath)
+		if err != nil {
+			return fmt.Errorf("handler outpath is invalid: %w", err)
+		}
+		if err = os.MkdirAll(outPath, os.ModePerm); err != nil {
+			return fmt.Errorf("make dir handler outpath(%s) fail: %s", outPath, err)
+		}
+		queryPkgPath, err := loadPkgPath(g.OutPath)
+		if err != nil {
+			return fmt.Errorf("parse query pkg path fail: %w", err)
+		}
+		var dtoPkgPath string
+		if g.dto != nil {
+			if dtoPkgPath, err = loadPkgPath(g.dto.outPath); err != nil {
+				return fmt.Errorf("parse dto pkg path fail: %w", err)
+			}
+		}
+
+		for _, meta := range models {
+			data, ok := g.Data[meta.ModelStructName]
+			if !ok {
+				continue
+			}
+			modelPkgPath := data.StructInfo.PkgPath
+			if modelPkgPath == "" {
+				modelPkgPath = g.modelPkgPath
+			}
+
+			header, err := g.provenance(data.QueryStructMeta)
+			if err != nil {
+				return err
+			}
+			var buf bytes.Buffer
+			buf.WriteString(header)
+			h := newHandler(data.QueryStructMeta, g.dto)
+			h.Package = filepath.Base(outPath)
+			h.QueryPackage = g.queryPkgName
+			h.ImportPaths = []string{`"` + modelPkgPath + `"`, `"` + queryPkgPath + `"`}
+			if g.dto != nil && data.Generated {
+				h.DTOPackage = filepath.Base(dtoPkgPath)
+				h.ImportPaths = append(h.ImportPaths, `"`+dtoPkgPath+`"`)
+			}
+			if err := render(handlerTmpl, &buf, h); err != nil {
+				return err
+			}
+
+			fileName := filepath.Join(outPath, data.FileName+".gen.go")
+			if err := g.output(fileName, buf.Bytes()); err != nil {
+				return err
+			}
+			g.info("generate handler file: " + fileName)
+		}
+		return nil
+	})
+}
+
+func newHandler(meta *generate.QueryStructMeta, dto *dtoExport) *handler {
+	h := &handler{
+		Model: meta.StructInfo.Package + "." + meta.StructInfo.Type,
+		Name:  meta.ModelStructName,
+		Route: "/" + meta.TableName,
+	}
+	if meta.TableName == "" {
+		h.Route = "/" + strings.ToLower(meta.ModelStructName)
+	}
+
+	for _, f := range meta.Fields {
+		if f.IsRelation() || f.ColumnName == "" || dto.excluded(meta.TableName, f.ColumnName) {
+			continue
+		}
+		if typ, method := handlerFilterType(f); typ != "" {
+			h.Filters = append(h.Filters, handlerFilter{Name: f.Name, Column: f.ColumnName, Type: typ, Method: method})
+		}
+	}
+	if pk := meta.PrimaryKey(); pk != nil {
+		if parse := handlerPKParse(pk); parse != "" {
+			h.PK = &handlerPK{Name: pk.Name, Column: pk.ColumnName, Type: pk.GenValueType(), Parse: parse}
+		}
+	}
+	return h
+}
+
+// handlerFilterType return type of query parameter filtering field and method comparing with it,
+// empty if field cannot be filtered by query parameter
+func handlerFilterType(f *model.Field) (typ, method string) {
+	if f.GenType() == "Bool" {
+		return "bool", "Is"
+	}
+	switch typ = f.GenValueType(); typ {
+	case "", "[]byte", "time.Time":
+		return "", ""
+	}
+	return typ, "Eq"
+}
+
+// handlerPKParse return statements parsing path parameter `param` into primary key,
+// empty if primary key type is not supported
+func handlerPKParse(pk *model.Field) string {
+	typ := pk.GenValueType()
+	bitSize := strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int")
+	if bitSize == "" {
+		bitSize = "64"
+	}
+	switch typ {
+	case "string":
+		return "return param, nil"
+	case "int", "int8", "int16", "int32", "int64":
+		return fmt.Sprintf("v, err := strconv.ParseInt(param, 10, %s)\n\treturn %s(v), err", bitSize, typ)
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return fmt.Sprintf("v, err := strconv.ParseUint(param, 10, %s)\n\treturn %s(v), err", bitSize, typ)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,404 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// formats of migration files, see WithMigrationExport
+const (
+	migrationGolangMigrate = "golang-migrate"
+	migrationGoose         = "goose"
+)
+
+// migrationVersion version prefix of migration files
+const migrationVersion = "20060102150405"
+
+type autoMigrate struct {
+	Package     string
+	ImportPaths []string
+	Models      []string
+}
+
+// WithAutoMigrateExport generate automigrate.gen.go in model package, which registers models generated from tables
+// by Models() and migrates them by AutoMigrate(db), e.g. model.AutoMigrate(db)
+func (cfg *Config) WithAutoMigrateExport() {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		modelOutPath, err := g.getModelOutputPath()
+		if err != nil {
+			return err
+		}
+		var tables []*generate.QueryStructMeta
+		subPackages := false
+		for _, meta := range models {
+			if meta.Generated && meta.TableName != "" {
+				tables = append(tables, meta)
+				subPackages = subPackages || g.modelPackage(meta.TableName) != ""
+			}
+		}
+		if len(tables) == 0 {
+			return nil
+		}
+		if subPackages && g.modelPkgPath == "" {
+			g.fillModelPkgPath(modelOutPath)
+			g.fillSubPackagePaths()
+		}
+
+		data := autoMigrate{Package: filepath.Base(modelOutPath)}
+		imports := make(map[string]bool)
+		for _, meta := range tables {
+			if g.modelPackage(meta.TableName) == "" {
+				data.Models = append(data.Models, meta.StructInfo.Type)
+				continue
+			}
+			if meta.StructInfo.PkgPath == "" {
+				return fmt.Errorf("import path of model package of %s is unknown, generate models before exporting", meta.TableName)
+			}
+			imports[`"`+meta.StructInfo.PkgPath+`"`] = true
+			data.Models = append(data.Models, meta.StructInfo.Package+"."+meta.StructInfo.Type)
+		}
+		for path := range imports {
+			data.ImportPaths = append(data.ImportPaths, path)
+		}
+		sort.Strings(data.ImportPaths)
+
+		header, err := g.provenance(nil)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		buf.WriteString(header)
+		if err = render(tmpl.AutoMigrate, &buf, data); err != nil {
+			return err
+		}
+		if err = os.MkdirAll(modelOutPath, os.ModePerm); err != nil {
+			return err
+		}
+		fileName := modelOutPath + "automigrate.gen.go"
+		if err = g.output(fileName, buf.Bytes()); err != nil {
+			return err
+		}
+		g.info("generate AutoMigrate file: " + fileName)
+		return nil
+	})
+}
+
+// WithMigrationExport write versioned SQL migration of difference between models generated from tables and
+// snapshot(metadata file of WithSchemaJSONExport) into dir, format is golang-migrate(<version>_<name>.up.sql and
+// .down.sql) or goose(<version>_<name>.sql), version is UTC time. Snapshot is rewritten by schema of the models once
+// migration is written, so next migration only contains later changes, missing snapshot means empty database.
+// Tables, columns and indexes(requires FieldWithIndexTag) are migrated, foreign keys and comments are not
+func (cfg *Config) WithMigrationExport(dir, format, snapshot, name string) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		if format != migrationGolangMigrate && format != migrationGoose {
+			return fmt.Errorf("unknown migration format %q (support golang-migrate || goose)", format)
+		}
+		if name == "" {
+			name = "gen"
+		}
+
+		from := &model.Schema{}
+		if content, err := ioutil.ReadFile(snapshot); err == nil {
+			if err = json.Unmarshal(content, from); err != nil {
+				return fmt.Errorf("parse schema snapshot %s fail: %w", snapshot, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		to := migrationSchema(g.dialect(), models)
+
+		up, down := diffSchema(g.dialect(), from, to)
+		if len(up) == 0 {
+			g.info("schema is same as snapshot " + snapshot + ", no migration is written")
+			return nil
+		}
+
+		prefix := filepath.Join(dir, time.Now().UTC().Format(migrationVersion)+"_"+name)
+		files := map[string]string{prefix + ".sql": "-- +goose Up\n" + strings.Join(up, "\n") + "\n\n-- +goose Down\n" + strings.Join(down, "\n") + "\n"}
+		if format == migrationGolangMigrate {
+			files = map[string]string{prefix + ".up.sql": strings.Join(up, "\n") + "\n", prefix + ".down.sql": strings.Join(down, "\n") + "\n"}
+		}
+		for fileName, content := range files {
+			if err := g.writeFile(fileName, []byte(content)); err != nil {
+				return err
+			}
+		}
+
+		content, err := marshalSchema(to)
+		if err != nil {
+			return err
+		}
+		return g.writeFile(snapshot, content)
+	})
+}
+
+// migrationSchema schema of models generated from tables, excluded columns and their indexes are absent
+func migrationSchema(dialect string, models []*generate.QueryStructMeta) *model.Schema {
+	s := &model.Schema{Dialect: dialect}
+	for _, meta := range models {
+		table := meta.TableSchema()
+		if table == nil {
+			continue
+		}
+		columns := make(map[string]bool, len(meta.Fields))
+		for _, f := range meta.Fields {
+			columns[f.ColumnName] = f.ColumnName != ""
+		}
+		t := &model.TableSchema{Name: table.Name}
+		for _, c := range table.Columns {
+			if columns[c.Name] {
+				t.Columns = append(t.Columns, c)
+			}
+		}
+	indexes:
+		for _, idx := range table.Indexes {
+			for _, column := range idx.Columns {
+				if !columns[column] {
+					continue indexes
+				}
+			}
+			t.Indexes = append(t.Indexes, idx)
+		}
+		s.Tables = append(s.Tables, t)
+	}
+	sort.Slice(s.Tables, func(i, j int) bool { return s.Tables[i].Name < s.Tables[j].Name })
+	return s
+}
+
+// diffSchema statements migrating from to to(up), and statements reverting them in reverse order(down)
+func diffSchema(dialect string, from, to *model.Schema) (up, down []string) {
+	d := migrationDialect(dialect)
+	var reverts [][]string
+	add := func(forward, backward []string) {
+		up = append(up, forward...)
+		reverts = append(reverts, backward)
+	}
+
+	for _, t := range to.Tables {
+		old := from.Table(t.Name)
+		if old == nil {
+			add(d.createTable(t), []string{d.dropTable(t.Name)})
+			continue
+		}
+
+		oldColumns := make(map[string]*model.ColumnSchema, len(old.Columns))
+		for _, c := range old.Columns {
+			oldColumns[c.Name] = c
+		}
+		newColumns := make(map[string]bool, len(t.Columns))
+		for _, c := range t.Columns {
+			newColumns[c.Name] = true
+			switch oc := oldColumns[c.Name]; {
+			case oc == nil:
+				add([]string{d.addColumn(t.Name, c)}, []string{d.dropColumn(t.Name, c.Name)})
+			case d.columnDef(oc) != d.columnDef(c):
+				add(d.alterColumn(t.Name, c), d.alterColumn(t.Name, oc))
+			}
+		}
+		for _, c := range old.Columns {
+			if !newColumns[c.Name] {
+				add([]string{d.dropColumn(t.Name, c.Name)}, []string{d.addColumn(t.Name, c)})
+			}
+		}
+
+		oldIndexes := make(map[string]*model.IndexSchema, len(old.Indexes))
+		for _, idx := range old.Indexes {
+			oldIndexes[idx.Name] = idx
+		}
+		newIndexes := make(map[string]bool, len(t.Indexes))
+		for _, idx := range t.Indexes {
+			newIndexes[idx.Name] = true
+			oi := oldIndexes[idx.Name]
+			switch {
+			case idx.PrimaryKey:
+			case oi == nil:
+				add([]string{d.createIndex(t.Name, idx)}, []string{d.dropIndex(t.Name, idx.Name)})
+			case oi.Unique != idx.Unique || strings.Join(oi.Columns, ",") != strings.Join(idx.Columns, ","):
+				add([]string{d.dropIndex(t.Name, idx.Name), d.createIndex(t.Name, idx)},
+					[]string{d.dropIndex(t.Name, idx.Name), d.createIndex(t.Name, oi)})
+			}
+		}
+		for _, idx := range old.Indexes {
+			if !newIndexes[idx.Name] && !idx.PrimaryKey {
+				add([]string{d.dropIndex(t.Name, idx.Name)}, []string{d.createIndex(t.Name, idx)})
+			}
+		}
+	}
+	for _, t := range from.Tables {
+		if to.Table(t.Name) == nil {
+			add([]string{d.dropTable(t.Name)}, d.createTable(t))
+		}
+	}
+
+	for i := len(reverts) - 1; i >= 0; i-- {
+		down = append(down, reverts[i]...)
+	}
+	return up, down
+}
+
+// migrationDialect renders DDL statements of dialect
+type migrationDialect string
+
+func (d migrationDialect) quote(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		switch d {
+		case "mysql":
+			parts[i] = "`" + part + "`"
+		case "sqlserver":
+			parts[i] = "[" + strings.Trim(part, "[]") + "]"
+		default:
+			parts[i] = `"` + part + `"`
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func (d migrationDialect) quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = d.quote(c)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// migrationNumber matches numeric default value
+var migrationNumber = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// columnType declared type of column, type with size reported by db is preferred
+func (d migrationDialect) columnType(c *model.ColumnSchema) string {
+	if c.ColumnType != "" {
+		return c.ColumnType
+	}
+	return c.DatabaseType
+}
+
+// columnDef definition of column in CREATE TABLE and ADD COLUMN, primary key is declared by table
+func (d migrationDialect) columnDef(c *model.ColumnSchema) string {
+	def := d.quote(c.Name) + " " + d.columnType(c)
+	autoIncrement := c.AutoIncrement != nil && *c.AutoIncrement
+	if autoIncrement {
+		switch d {
+		case "mysql":
+			def += " AUTO_INCREMENT"
+		case "postgres":
+			def += " GENERATED BY DEFAULT AS IDENTITY"
+		case "sqlserver":
+			def += " IDENTITY(1,1)"
+		}
+	}
+	if c.Nullable != nil && !*c.Nullable {
+		def += " NOT NULL"
+	}
+	if value := d.defaultValue(c); value != "" && !autoIncrement {
+		def += " DEFAULT " + value
+	}
+	return def
+}
+
+// defaultValue default of column as SQL expression, string default reported by mysql without quotes is quoted
+func (d migrationDialect) defaultValue(c *model.ColumnSchema) string {
+	if c.Default == nil || *c.Default == "" {
+		return ""
+	}
+	value := *c.Default
+	if d == "mysql" && !migrationNumber.MatchString(value) && !strings.HasPrefix(value, "'") &&
+		!strings.Contains(value, "(") && strings.ToUpper(value) != value {
+		value = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return value
+}
+
+func (d migrationDialect) createTable(t *model.TableSchema) []string {
+	defs := make([]string, 0, len(t.Columns)+1)
+	var pk []string
+	for _, c := range t.Columns {
+		defs = append(defs, "  "+d.columnDef(c))
+		if c.PrimaryKey != nil && *c.PrimaryKey {
+			pk = append(pk, c.Name)
+		}
+	}
+	if len(pk) > 0 {
+		defs = append(defs, "  PRIMARY KEY ("+d.quoteColumns(pk)+")")
+	}
+	stmts := []string{"CREATE TABLE " + d.quote(t.Name) + " (\n" + strings.Join(defs, ",\n") + "\n);"}
+	for _, idx := range t.Indexes {
+		if !idx.PrimaryKey {
+			stmts = append(stmts, d.createIndex(t.Name, idx))
+		}
+	}
+	return stmts
+}
+
+func (d migrationDialect) dropTable(table string) string {
+	return "DROP TABLE " + d.quote(table) + ";"
+}
+
+func (d migrationDialect) addColumn(table string, c *model.ColumnSchema) string {
+	if d == "sqlserver" {
+		return "ALTER TABLE " + d.quote(table) + " ADD " + d.columnDef(c) + ";"
+	}
+	return "ALTER TABLE " + d.quote(table) + " ADD COLUMN " + d.columnDef(c) + ";"
+}
+
+func (d migrationDialect) dropColumn(table, column string) string {
+	return "ALTER TABLE " + d.quote(table) + " DROP COLUMN " + d.quote(column) + ";"
+}
+
+// alterColumn statements changing column into c, sqlite can't alter columns, which is left as comment
+func (d migrationDialect) alterColumn(table string, c *model.ColumnSchema) []string {
+	prefix := "ALTER TABLE " + d.quote(table) + " "
+	switch d {
+	case "postgres":
+		column := prefix + "ALTER COLUMN " + d.quote(c.Name) + " "
+		stmts := []string{column + "TYPE " + d.columnType(c) + ";"}
+		if c.Nullable != nil && !*c.Nullable {
+			stmts = append(stmts, column+"SET NOT NULL;")
+		} else {
+			stmts = append(stmts, column+"DROP NOT NULL;")
+		}
+		if value := d.defaultValue(c); value != "" && (c.AutoIncrement == nil || !*c.AutoIncrement) {
+			stmts = append(stmts, column+"SET DEFAULT "+value+";")
+		} else {
+			stmts = append(stmts, column+"DROP DEFAULT;")
+		}
+		return stmts
+	case "sqlserver":
+		null := " NULL"
+		if c.Nullable != nil && !*c.Nullable {
+			null = " NOT NULL"
+		}
+		return []string{prefix + "ALTER COLUMN " + d.quote(c.Name) + " " + d.columnType(c) + null + ";"}
+	case "sqlite":
+		return []string{"-- sqlite can't alter column, rebuild table " + table + " to change column into: " + d.columnDef(c)}
+	}
+	return []string{prefix + "MODIFY COLUMN " + d.columnDef(c) + ";"}
+}
+
+func (d migrationDialect) createIndex(table string, idx *model.IndexSchema) string {
+	unique := ""
+	if idx.Unique {
+		unique = "UNIQUE "
+	}
+	return "CREATE " + unique + "INDEX " + d.quote(idx.Name) + " ON " + d.quote(table) + " (" + d.quoteColumns(idx.Columns) + ");"
+}
+
+func (d migrationDialect) dropIndex(table, index string) string {
+	switch d {
+	case "mysql", "sqlserver":
+		return "DROP INDEX " + d.quote(index) + " ON " + d.quote(table) + ";"
+	}
+	return "DROP INDEX " + d.quote(index) + ";"
+}
@@ -0,0 +1,200 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// openAPISchema schema object of OpenAPI 3.0
+type openAPISchema struct {
+	Type        string                    `json:"type,omitempty" yaml:"type,omitempty"`
+	Format      string                    `json:"format,omitempty" yaml:"format,omitempty"`
+	Nullable    bool                      `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Description string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	Enum        []interface{}             `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Items       *openAPISchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties  map[string]*openAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
+
+type openAPIComponents struct {
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas" yaml:"schemas"`
+	} `json:"components" yaml:"components"`
+}
+
+// openAPITypes go type -> OpenAPI type and format
+var openAPITypes = map[string][2]string{
+	"int":             {"integer", "int64"},
+	"int8":            {"integer", "int32"},
+	"int16":           {"integer", "int32"},
+	"int32":           {"integer", "int32"},
+	"int64":           {"integer", "int64"},
+	"uint":            {"integer", "int64"},
+	"uint8":           {"integer", "int32"},
+	"uint16":          {"integer", "int32"},
+	"uint32":          {"integer", "int64"},
+	"uint64":          {"integer", "int64"},
+	"float32":         {"number", "float"},
+	"float64":         {"number", "double"},
+	"bool":            {"boolean", ""},
+	"string":          {"string", ""},
+	"[]byte":          {"string", "byte"},
+	"time.Time":       {"string", "date-time"},
+	"gorm.DeletedAt":  {"string", "date-time"},
+	"datatypes.Date":  {"string", "date"},
+	"json.RawMessage": {"", ""}, // any type
+	"datatypes.JSON":  {"", ""},
+	"uuid.UUID":       {"string", "uuid"},
+	"decimal.Decimal": {"string", "decimal"},
+	"datatypes.Time":  {"string", ""},
+}
+
+var (
+	// enum values listed in column comment, e.g. "order status, enum: pending,paid" or "enum(1:active|2:disabled)"
+	commentEnumRegexp = regexp.MustCompile(`(?i)\benum\s*(?:[:：]\s*([^;；]+)|\(([^)]*)\))`)
+	// enum values of column type, e.g. enum('pending','paid')
+	typeEnumRegexp = regexp.MustCompile(`(?i)^enum\s*\((.*)\)$`)
+)
+
+// WithOpenAPIExport export OpenAPI 3 components.schemas of generated models into fileName,
+// written in JSON when fileName ends with .json, otherwise in YAML.
+// Enum values are read from column type enum(...) or column comment like "status, enum: pending,paid"
+func (cfg *Config) WithOpenAPIExport(fileName string) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) (err error) {
+		var doc openAPIComponents
+		doc.Components.Schemas = make(map[string]*openAPISchema, len(models))
+		for _, meta := range models {
+			doc.Components.Schemas[meta.ModelStructName] = newOpenAPISchema(meta)
+		}
+
+		var buf bytes.Buffer
+		if strings.EqualFold(filepath.Ext(fileName), ".json") {
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(doc)
+		} else {
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+			err = encoder.Encode(doc)
+		}
+		if err != nil {
+			return err
+		}
+		return g.writeFile(fileName, buf.Bytes())
+	})
+}
+
+func newOpenAPISchema(meta *generate.QueryStructMeta) *openAPISchema {
+	schema := &openAPISchema{
+		Type:        "object",
+		Description: meta.ModelStructName + " mapped from table <" + meta.TableName + ">",
+		Properties:  make(map[string]*openAPISchema, len(meta.Fields)),
+	}
+	for _, f := range meta.Fields {
+		if f.IsRelation() {
+			continue
+		}
+		name := jsonName(f)
+		if name == "-" {
+			continue
+		}
+
+		goType, isSlice := sliceElem(fieldGoType(f))
+		types, ok := openAPITypes[goType]
+		if !ok {
+			types = openAPITypes["string"]
+		}
+		prop := &openAPISchema{Type: types[0], Format: types[1], Enum: enumValues(f, types[0])}
+		if isSlice {
+			prop = &openAPISchema{Type: "array", Items: prop}
+		}
+		if valueName, _, ok := nullValue(f.Type); ok { // sql.NullXXX is encoded as object
+			prop = &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{
+				valueName: prop,
+				"Valid":   {Type: "boolean"},
+			}}
+		}
+		prop.Nullable = strings.HasPrefix(f.Type, "*") || goType == "gorm.DeletedAt"
+		prop.Description = f.ColumnComment
+		schema.Properties[name] = prop
+	}
+	return schema
+}
+
+// jsonName return name of field in json
+func jsonName(f *model.Field) string {
+	if tag, ok := f.Tag[field.TagKeyJson]; ok {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	if f.ColumnName != "" {
+		return f.ColumnName
+	}
+	return f.Name
+}
+
+// enumValues return enum values of field declared by column type or comment, values are converted to typ
+func enumValues(f *model.Field, typ string) []interface{} {
+	var values []string
+	if f.Enum != nil {
+		for _, v := range f.Enum.Values {
+			values = append(values, v.Value)
+		}
+	} else if types := f.GORMTag[field.TagKeyGormType]; len(types) > 0 {
+		if matches := typeEnumRegexp.FindStringSubmatch(strings.TrimSpace(types[0])); matches != nil {
+			for _, v := range strings.Split(matches[1], ",") {
+				values = append(values, strings.Trim(strings.TrimSpace(v), `'"`))
+			}
+		}
+	}
+	if matches := commentEnumRegexp.FindStringSubmatch(f.ColumnComment); values == nil && matches != nil {
+		list := matches[1] + matches[2]
+		for _, v := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '|' || r == '，' }) {
+			// value may be followed by its meaning, e.g. 1:active, 1=active
+			if i := strings.IndexAny(v, ":=："); i >= 0 {
+				v = v[:i]
+			}
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	enum := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		switch typ {
+		case "integer":
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil
+			}
+			enum = append(enum, i)
+		case "number":
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil
+			}
+			enum = append(enum, n)
+		case "string":
+			enum = append(enum, v)
+		default:
+			return nil
+		}
+	}
+	if len(enum) == 0 {
+		return nil
+	}
+	return enum
+}
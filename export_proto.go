@@ -0,0 +1,150 @@
+package gen
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+const (
+	protoTimestamp = "google.protobuf.Timestamp"
+
+	protoImportTimestamp = "google/protobuf/timestamp.proto"
+	protoImportWrappers  = "google/protobuf/wrappers.proto"
+	protoImportEmpty     = "google/protobuf/empty.proto"
+)
+
+// protoTypes go type -> proto scalar type and wrapper type used when field is nullable
+var protoTypes = map[string][2]string{
+	"int":             {"int64", "google.protobuf.Int64Value"},
+	"int8":            {"int32", "google.protobuf.Int32Value"},
+	"int16":           {"int32", "google.protobuf.Int32Value"},
+	"int32":           {"int32", "google.protobuf.Int32Value"},
+	"int64":           {"int64", "google.protobuf.Int64Value"},
+	"uint":            {"uint64", "google.protobuf.UInt64Value"},
+	"uint8":           {"uint32", "google.protobuf.UInt32Value"},
+	"uint16":          {"uint32", "google.protobuf.UInt32Value"},
+	"uint32":          {"uint32", "google.protobuf.UInt32Value"},
+	"uint64":          {"uint64", "google.protobuf.UInt64Value"},
+	"float32":         {"float", "google.protobuf.FloatValue"},
+	"float64":         {"double", "google.protobuf.DoubleValue"},
+	"bool":            {"bool", "google.protobuf.BoolValue"},
+	"string":          {"string", "google.protobuf.StringValue"},
+	"[]byte":          {"bytes", "google.protobuf.BytesValue"},
+	"json.RawMessage": {"bytes", "google.protobuf.BytesValue"},
+	"datatypes.JSON":  {"bytes", "google.protobuf.BytesValue"},
+	"uuid.UUID":       {"string", "google.protobuf.StringValue"},
+	"decimal.Decimal": {"string", "google.protobuf.StringValue"},
+	"datatypes.Time":  {"string", "google.protobuf.StringValue"},
+	"time.Time":       {protoTimestamp, protoTimestamp},
+	"gorm.DeletedAt":  {protoTimestamp, protoTimestamp},
+	"datatypes.Date":  {protoTimestamp, protoTimestamp},
+}
+
+type protoField struct {
+	Type    string
+	Name    string
+	Number  int
+	Comment string
+}
+
+type protoMessage struct {
+	Package   string
+	Imports   []string
+	Message   string
+	TableName string
+	Fields    []protoField
+	Service   bool
+	PK        *protoField // single primary key used by Get/Update/Delete service methods
+}
+
+// WithProtoExport export a .proto file with message of each generated model into outPath,
+// CRUD service definitions are included when withService is true
+func (cfg *Config) WithProtoExport(outPath string, withService bool) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		for _, meta := range models {
+			var buf bytes.Buffer
+			if err := render(tmpl.Proto, &buf, newProtoMessage(meta, withService)); err != nil {
+				return err
+			}
+			if err := g.writeFile(filepath.Join(outPath, meta.FileName+".proto"), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func newProtoMessage(meta *generate.QueryStructMeta, withService bool) *protoMessage {
+	msg := &protoMessage{
+		Package:   meta.StructInfo.Package,
+		Message:   meta.ModelStructName,
+		TableName: meta.TableName,
+		Service:   withService,
+	}
+	if msg.Package == "" {
+		msg.Package = "model"
+	}
+
+	imports := make(map[string]struct{})
+	var pks []protoField
+	for _, f := range meta.Fields {
+		if f.IsRelation() {
+			continue
+		}
+		scalar, typ, imp := protoType(f)
+		if imp != "" {
+			imports[imp] = struct{}{}
+		}
+		pf := protoField{
+			Type:    typ,
+			Name:    f.ColumnName,
+			Number:  len(msg.Fields) + 1,
+			Comment: strings.ReplaceAll(f.ColumnComment, "\n", "\n  // "),
+		}
+		msg.Fields = append(msg.Fields, pf)
+		if f.IsPrimaryKey() {
+			pf.Type = scalar
+			pks = append(pks, pf)
+		}
+	}
+	if withService && len(pks) == 1 {
+		msg.PK = &pks[0]
+		imports[protoImportEmpty] = struct{}{}
+	}
+
+	for imp := range imports {
+		msg.Imports = append(msg.Imports, imp)
+	}
+	sort.Strings(msg.Imports)
+	return msg
+}
+
+// protoType return scalar type and field type of model field in proto, with import it requires.
+// Nullable(pointer or sql.NullXXX) field is mapped to wrapper type, slice is mapped to repeated field, unknown type is mapped to string
+func protoType(f *model.Field) (scalar, typ, imp string) {
+	goType, isSlice := sliceElem(fieldGoType(f))
+	types, ok := protoTypes[goType]
+	if !ok {
+		types = protoTypes["string"]
+	}
+	scalar, typ = types[0], types[0]
+	if isSlice {
+		typ = "repeated " + scalar
+	} else if fieldNullable(f) {
+		typ = types[1]
+	}
+
+	switch {
+	case scalar == protoTimestamp:
+		imp = protoImportTimestamp
+	case strings.HasPrefix(typ, "google.protobuf."):
+		imp = protoImportWrappers
+	}
+	return scalar, typ, imp
+}
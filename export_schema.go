@@ -0,0 +1,87 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// WithSchemaJSONExport export introspected metadata of tables(columns, types, nullability, indexes,
+// foreign keys detected by column name and comments) into fileName as JSON, the file can be used to
+// generate without db by Generator.UseSchemaJSON. Indexes require FieldWithIndexTag, check constraints and
+// foreign keys require FieldWithConstraintTag
+func (cfg *Config) WithSchemaJSONExport(fileName string) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		s := &model.Schema{Dialect: g.dialect()}
+		tables := make(map[string]*generate.QueryStructMeta, len(models))
+		for _, meta := range models {
+			if meta.TableSchema() != nil {
+				tables[meta.TableName] = meta
+			}
+		}
+		for _, meta := range models {
+			table := meta.TableSchema()
+			if table == nil {
+				continue
+			}
+			table.ForeignKeys = nil
+			for _, f := range meta.Fields {
+				if ref := erdReference(f, tables); ref != nil {
+					table.ForeignKeys = append(table.ForeignKeys, &model.ForeignKeySchema{
+						Column:    f.ColumnName,
+						RefTable:  ref.TableName,
+						RefColumn: ref.PrimaryKey().ColumnName,
+					})
+				}
+			}
+			s.Tables = append(s.Tables, table)
+		}
+
+		content, err := marshalSchema(s)
+		if err != nil {
+			return err
+		}
+		return g.writeFile(fileName, content)
+	})
+}
+
+// marshalSchema encode metadata as indented JSON
+func marshalSchema(s *model.Schema) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UseSchemaJSON generate models from metadata exported by WithSchemaJSONExport instead of db
+func (g *Generator) UseSchemaJSON(fileName string) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		panic(fmt.Errorf("read schema json fail: %w", err))
+	}
+	var s model.Schema
+	if err = json.Unmarshal(content, &s); err != nil {
+		panic(fmt.Errorf("parse schema json %s fail: %w", fileName, err))
+	}
+	g.schema = &s
+}
+
+// getTables return all tables sorted by name in db, or in schema when it's used
+func (g *Generator) getTables() (tables []string, err error) {
+	if g.schema != nil {
+		tables = g.schema.TableNames()
+	} else if tables, err = g.db.Migrator().GetTables(); err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
@@ -0,0 +1,133 @@
+package gen
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// tsTypes go type -> typescript type of its json value
+var tsTypes = map[string]string{
+	"int":             "number",
+	"int8":            "number",
+	"int16":           "number",
+	"int32":           "number",
+	"int64":           "number",
+	"uint":            "number",
+	"uint8":           "number",
+	"uint16":          "number",
+	"uint32":          "number",
+	"uint64":          "number",
+	"float32":         "number",
+	"float64":         "number",
+	"bool":            "boolean",
+	"string":          "string",
+	"[]byte":          "string", // base64 encoded
+	"time.Time":       "string",
+	"gorm.DeletedAt":  "string | null",
+	"datatypes.Date":  "string",
+	"json.RawMessage": "unknown",
+	"datatypes.JSON":  "unknown",
+	"uuid.UUID":       "string",
+	"decimal.Decimal": "string",
+	"datatypes.Time":  "string",
+}
+
+var tsIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+type tsField struct {
+	Name     string
+	Type     string
+	Optional bool
+	Comment  string
+}
+
+type tsInterface struct {
+	Name      string
+	TableName string
+	Fields    []tsField
+}
+
+// WithTypeScriptExport export a .d.ts file with interface of each generated model into outPath,
+// property names follow json tags of model fields, nullable(pointer) fields are typed as `T | null`
+// and fields with omitempty are optional
+func (cfg *Config) WithTypeScriptExport(outPath string) {
+	cfg.exporters = append(cfg.exporters, func(g *Generator, models []*generate.QueryStructMeta) error {
+		for _, meta := range models {
+			var buf bytes.Buffer
+			if err := render(tmpl.TypeScript, &buf, newTSInterface(meta)); err != nil {
+				return err
+			}
+			if err := g.writeFile(filepath.Join(outPath, meta.FileName+".d.ts"), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func newTSInterface(meta *generate.QueryStructMeta) *tsInterface {
+	iface := &tsInterface{Name: meta.ModelStructName, TableName: meta.TableName}
+	for _, f := range meta.Fields {
+		if f.IsRelation() {
+			continue
+		}
+		name := jsonName(f)
+		if name == "-" {
+			continue
+		}
+		if !tsIdentifierRegexp.MatchString(name) {
+			name = strconv.Quote(name)
+		}
+		iface.Fields = append(iface.Fields, tsField{
+			Name:     name,
+			Type:     tsType(f),
+			Optional: jsonOmitEmpty(f),
+			Comment:  strings.ReplaceAll(f.ColumnComment, "*/", "* /"),
+		})
+	}
+	return iface
+}
+
+// tsType return typescript type of field, unknown go type is typed as unknown
+func tsType(f *model.Field) string {
+	goType, isSlice := sliceElem(fieldGoType(f))
+	typ, ok := tsTypes[goType]
+	if !ok {
+		typ = "unknown"
+	}
+	if f.Enum != nil && typ == "string" { // union of string literals
+		values := make([]string, len(f.Enum.Values))
+		for i, v := range f.Enum.Values {
+			values[i] = strconv.Quote(v.Value)
+		}
+		typ = strings.Join(values, " | ")
+	}
+	if isSlice && typ != "unknown" {
+		if strings.Contains(typ, " | ") {
+			typ = "(" + typ + ")"
+		}
+		typ += "[]"
+	}
+	if tag := f.Tag[field.TagKeyJson]; strings.Contains(tag, ",string") && typ != "unknown" {
+		typ = "string"
+	}
+	if name, _, ok := nullValue(f.Type); ok && typ != "unknown" { // sql.NullXXX is encoded as object
+		return "{ " + name + ": " + typ + "; Valid: boolean }"
+	}
+	if strings.HasPrefix(f.Type, "*") && !strings.HasSuffix(typ, " | null") && typ != "unknown" {
+		typ += " | null"
+	}
+	return typ
+}
+
+func jsonOmitEmpty(f *model.Field) bool {
+	return strings.Contains(f.Tag[field.TagKeyJson], ",omitempty")
+}
@@ -0,0 +1,62 @@
+package gen
+
+import (
+	"reflect"
+	"time"
+)
+
+// FakeEqual whether value of field equals want in where-equality filters of generated fake DAO(WithFakeDAO).
+// Pointers are dereferenced, nil pointer equals nil, numbers are compared by value regardless of their types,
+// e.g. int32(18) equals 18, and times are compared by time.Time.Equal
+func FakeEqual(value, want interface{}) bool {
+	v, w := fakeIndirect(reflect.ValueOf(value)), fakeIndirect(reflect.ValueOf(want))
+	if !v.IsValid() || !w.IsValid() {
+		return v.IsValid() == w.IsValid()
+	}
+
+	if vt, ok := v.Interface().(time.Time); ok {
+		wt, ok := w.Interface().(time.Time)
+		return ok && vt.Equal(wt)
+	}
+	switch {
+	case fakeIsInt(v.Kind()) && fakeIsInt(w.Kind()):
+		return v.Int() == w.Int()
+	case fakeIsUint(v.Kind()) && fakeIsUint(w.Kind()):
+		return v.Uint() == w.Uint()
+	case fakeIsInt(v.Kind()) && fakeIsUint(w.Kind()):
+		return v.Int() >= 0 && uint64(v.Int()) == w.Uint()
+	case fakeIsUint(v.Kind()) && fakeIsInt(w.Kind()):
+		return w.Int() >= 0 && v.Uint() == uint64(w.Int())
+	case fakeIsNumber(v.Kind()) && fakeIsNumber(w.Kind()):
+		return fakeFloat(v) == fakeFloat(w)
+	case v.Kind() == reflect.String && w.Kind() == reflect.String:
+		return v.String() == w.String()
+	}
+	return reflect.DeepEqual(v.Interface(), w.Interface())
+}
+
+// fakeIndirect dereference pointers, invalid value is returned for nil
+func fakeIndirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	return v
+}
+
+func fakeIsInt(k reflect.Kind) bool { return k >= reflect.Int && k <= reflect.Int64 }
+
+func fakeIsUint(k reflect.Kind) bool { return k >= reflect.Uint && k <= reflect.Uintptr }
+
+func fakeIsNumber(k reflect.Kind) bool {
+	return fakeIsInt(k) || fakeIsUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func fakeFloat(v reflect.Value) float64 {
+	switch {
+	case fakeIsInt(v.Kind()):
+		return float64(v.Int())
+	case fakeIsUint(v.Kind()):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
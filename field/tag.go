@@ -19,6 +19,14 @@ const (
 	TagKeyGormIndex         = "index"
 	TagKeyGormDefault       = "default"
 	TagKeyGormComment       = "comment"
+	TagKeyGormSerializer    = "serializer"
+	TagKeyGormReadOnly      = "->"
+	TagKeyGormCheck         = "check"
+	TagKeyGormForeignKey    = "foreignKey"
+	TagKeyGormConstraint    = "constraint"
+	TagKeyGormReferences    = "references"
+
+	TagKeyGormWritePermission = "<-" // e.g. <-:update allows update but not create
 )
 
 var (
@@ -34,7 +42,11 @@ var (
 		TagKeyGormUniqueIndex:   5,
 		TagKeyGormIndex:         4,
 		TagKeyGormDefault:       3,
+		TagKeyGormSerializer:    2,
+		TagKeyGormReadOnly:      1,
 		TagKeyGormComment:       0,
+
+		TagKeyGormWritePermission: 1,
 	}
 )
 
@@ -100,6 +100,27 @@ var (
 			return m
 		}
 	}
+	// FieldJSONType specify go type of json column in generated struct, e.g. *types.Profile,
+	// value is (de)serialized by gorm json serializer, import its package by Config.WithImportPkgPath
+	FieldJSONType = func(columnName string, goType string) model.ModifyFieldOpt {
+		return func(m *model.Field) *model.Field {
+			if m.ColumnName == columnName {
+				m.Type = goType
+				m.GORMTag.Set(field.TagKeyGormSerializer, "json")
+			}
+			return m
+		}
+	}
+	// FieldGeometryType specify orb type of spatial column, e.g. orb.Point, orb.Polygon or orb.Geometry which
+	// accepts any geometry, the column is generated as named geometry type even if FieldWithGeometryType is false
+	FieldGeometryType = func(columnName string, geometryType string) model.ModifyFieldOpt {
+		return func(m *model.Field) *model.Field {
+			if m.ColumnName == columnName {
+				m.Type = strings.TrimSuffix(m.Type, strings.TrimLeft(m.Type, "*")) + geometryType
+			}
+			return m
+		}
+	}
 	// FieldGenType specify field gen type in generated dao
 	FieldGenType = func(columnName string, newType string) model.ModifyFieldOpt {
 		return func(m *model.Field) *model.Field {
@@ -256,7 +277,8 @@ var (
 		}
 	}
 
-	// WithMethod add custom method for table model
+	// WithMethod add custom method for table model, method is a struct(all its methods), a method value, or type name
+	// in form of <import path or dir>.<Type>, e.g. "github.com/acme/app/common.CommonMethod", "./common.CommonMethod"
 	WithMethod = func(methods ...interface{}) model.AddMethodOpt {
 		return func() []interface{} { return methods }
 	}
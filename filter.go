@@ -0,0 +1,16 @@
+package gen
+
+// Range optional bounds of range field of <Model>Filter generated by WithFilterMethod, nil bound is ignored
+type Range[T any] struct {
+	Min *T // inclusive lower bound
+	Max *T // inclusive upper bound
+}
+
+// Between range of values from min to max inclusively
+func Between[T any](min, max T) *Range[T] { return &Range[T]{Min: &min, Max: &max} }
+
+// AtLeast range of values not less than min
+func AtLeast[T any](min T) *Range[T] { return &Range[T]{Min: &min} }
+
+// AtMost range of values not greater than max
+func AtMost[T any](max T) *Range[T] { return &Range[T]{Max: &max} }
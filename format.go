@@ -0,0 +1,45 @@
+package gen
+
+import (
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+	gofumpt "mvdan.cc/gofumpt/format"
+)
+
+// formatters supported formatters of generated go files, applied after imports fixed by goimports
+var formatters = map[string]bool{"goimports": true, "gofmt": true, "gofumpt": true}
+
+// format generated go code with Formatter
+func (g *Generator) format(src []byte) ([]byte, error) {
+	switch g.Formatter {
+	case "gofmt":
+		return format.Source(src)
+	case "gofumpt":
+		return gofumpt.Source(src, g.gofumptOpts)
+	default:
+		return src, nil
+	}
+}
+
+// gofumptOptions return options of module containing dir, which decide import grouping and language features
+func gofumptOptions(dir string) (opts gofumpt.Options) {
+	for dir, last := filepath.Clean(dir), ""; dir != last; dir, last = filepath.Dir(dir), dir {
+		content, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			continue
+		}
+		if f, err := modfile.ParseLax("go.mod", content, nil); err == nil {
+			if f.Module != nil {
+				opts.ModulePath = f.Module.Mod.Path
+			}
+			if f.Go != nil {
+				opts.LangVersion = f.Go.Version
+			}
+		}
+		break
+	}
+	return opts
+}
@@ -11,14 +11,18 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/imports"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	gofumpt "mvdan.cc/gofumpt/format"
 
 	"gorm.io/gen/helper"
 	"gorm.io/gen/internal/generate"
@@ -65,8 +69,49 @@ func NewGenerator(cfg Config) *Generator {
 type genInfo struct {
 	*generate.QueryStructMeta
 	Interfaces []*generate.InterfaceMethod
+
+	mode GenerateMode
 }
 
+// HasExistsMethod whether to generate exists and count by index methods
+func (i *genInfo) HasExistsMethod() bool { return i.mode&WithExistsMethod != 0 }
+
+// HasEachMethod whether to generate stream query methods
+func (i *genInfo) HasEachMethod() bool { return i.mode&WithEachMethod != 0 }
+
+// HasLockingMethod whether to generate row level locking methods
+func (i *genInfo) HasLockingMethod() bool { return i.mode&WithLockingMethod != 0 }
+
+// HasCacheMethod whether to generate cached primary key lookup methods
+func (i *genInfo) HasCacheMethod() bool { return i.mode&WithCacheMethod != 0 }
+
+// HasResolverMethod whether to generate read/write splitting methods
+func (i *genInfo) HasResolverMethod() bool { return i.mode&WithResolverMethod != 0 }
+
+// HasPreloadMethod whether to generate named preload methods of relations
+func (i *genInfo) HasPreloadMethod() bool { return i.mode&WithPreloadMethod != 0 }
+
+// HasFilterMethod whether to generate filter struct and ApplyFilter method
+func (i *genInfo) HasFilterMethod() bool { return i.mode&WithFilterMethod != 0 }
+
+// HasSortMethod whether to generate field enum, OrderBy and SelectFields methods
+func (i *genInfo) HasSortMethod() bool { return i.mode&WithSortMethod != 0 }
+
+// HasBatchMethod whether to generate batch update and delete methods
+func (i *genInfo) HasBatchMethod() bool { return i.mode&WithBatchMethod != 0 }
+
+// HasReturningMethod whether to generate update and delete methods returning affected records
+func (i *genInfo) HasReturningMethod() bool { return i.mode&WithReturningMethod != 0 }
+
+// HasAffectedMethod whether to generate update and delete methods returning number of affected rows
+func (i *genInfo) HasAffectedMethod() bool { return i.mode&WithAffectedMethod != 0 }
+
+// HasHintMethod whether to generate index constants and hint methods
+func (i *genInfo) HasHintMethod() bool { return i.mode&WithHintMethod != 0 }
+
+// HasImportExportMethod whether to generate CSV and JSON Lines import/export methods
+func (i *genInfo) HasImportExportMethod() bool { return i.mode&WithImportExportMethod != 0 }
+
 func (i *genInfo) appendMethods(methods []*generate.InterfaceMethod) {
 	for _, newMethod := range methods {
 		if i.methodInGenInfo(newMethod) {
@@ -91,6 +136,20 @@ type Generator struct {
 
 	Data   map[string]*genInfo                  //gen query data
 	models map[string]*generate.QueryStructMeta //gen model data
+	mu     sync.Mutex                           // guard models and summary when handling tables concurrently
+
+	pluginMu sync.Mutex // serialize hooks of plugins
+
+	summary Summary // files written and skipped
+
+	lock, lastLock *lockFile // fingerprints of this and last generation
+
+	baseModel *generate.QueryStructMeta // shared struct embedded by models, see Config.BaseModelColumns
+
+	modelTemplates, queryTemplates *customTemplates // user provided templates
+	header                         []byte           // header of generated go files, see Config.FileHeader
+	gofumptOpts                    gofumpt.Options  // options of gofumpt formatter
+	configSum                      string           // fingerprint of config, see configFingerprint
 }
 
 // UseDB set db connection
@@ -107,38 +166,89 @@ func (g *Generator) UseDB(db *gorm.DB) {
 
 // GenerateModel catch table info from db, return a BaseStruct
 func (g *Generator) GenerateModel(tableName string, opts ...ModelOpt) *generate.QueryStructMeta {
-	return g.GenerateModelAs(tableName, g.db.Config.NamingStrategy.SchemaName(tableName), opts...)
+	return g.GenerateModelAs(tableName, g.modelName(tableName), opts...)
 }
 
 // GenerateModelAs catch table info from db, return a BaseStruct
 func (g *Generator) GenerateModelAs(tableName string, modelName string, opts ...ModelOpt) *generate.QueryStructMeta {
-	meta, err := generate.GetQueryStructMeta(g.db, g.genModelConfig(tableName, modelName, opts))
+	meta, err := g.generateModelAs(tableName, modelName, opts)
 	if err != nil {
 		g.db.Logger.Error(context.Background(), "generate struct from table fail: %s", err)
 		panic("generate struct fail")
 	}
+	return meta
+}
+
+func (g *Generator) generateModelAs(tableName string, modelName string, opts []ModelOpt) (*generate.QueryStructMeta, error) {
+	start := time.Now()
+	meta, err := generate.GetQueryStructMeta(g.db, g.genModelConfig(tableName, modelName, opts))
+	if err != nil {
+		return nil, err
+	}
 	if meta == nil {
 		g.info(fmt.Sprintf("ignore table <%s>", tableName))
-		return nil
+		return nil, nil
+	}
+	if ok, err := g.noPrimaryKey(meta); err != nil || !ok {
+		return nil, err
 	}
+	if err = g.loadTemporal(meta); err != nil {
+		return nil, err
+	}
+	if err = g.loadLookup(meta); err != nil {
+		return nil, err
+	}
+	if err = g.afterModel(meta); err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
 	g.models[meta.ModelStructName] = meta
+	g.mu.Unlock()
 
-	g.info(fmt.Sprintf("got %d columns from table <%s>", len(meta.Fields), meta.TableName))
-	return meta
+	g.info(fmt.Sprintf("got %d columns from table <%s> in %s", len(meta.Fields), meta.TableName, time.Since(start).Round(time.Millisecond)))
+	return meta, nil
 }
 
 // GenerateAllTable generate all tables in db
 func (g *Generator) GenerateAllTable(opts ...ModelOpt) (tableModels []interface{}) {
-	tableList, err := g.db.Migrator().GetTables()
+	tableList, err := g.getTables()
 	if err != nil {
 		panic(fmt.Errorf("get all tables fail: %w", err))
 	}
 
 	g.info(fmt.Sprintf("find %d table from db: %s", len(tableList), tableList))
 
+	return g.GenerateModels(tableList, opts...)
+}
+
+// GenerateModels catch info of tables from db concurrently(see Config.Concurrency),
+// return BaseStructs in the order of tableList. Partitions of native partitioned tables are skipped
+func (g *Generator) GenerateModels(tableList []string, opts ...ModelOpt) (tableModels []interface{}) {
+	tableList, err := g.beforeIntrospect(g.skipPartitions(tableList))
+	if err != nil {
+		panic(err)
+	}
+
 	tableModels = make([]interface{}, len(tableList))
+	errs := make([]error, len(tableList))
+	progress := g.beginProgress("introspect", len(tableList))
+	pool := pools.NewPool(g.concurrency())
 	for i, tableName := range tableList {
-		tableModels[i] = g.GenerateModel(tableName, opts...)
+		pool.Wait()
+		go func(i int, tableName string) {
+			defer pool.Done()
+			meta, err := g.generateModelAs(tableName, g.modelName(tableName), opts)
+			tableModels[i], errs[i] = meta, err
+			progress.step(tableName)
+		}(i, tableName)
+	}
+	pool.WaitAll()
+
+	for i, err := range errs {
+		if err != nil {
+			g.db.Logger.Error(context.Background(), "generate struct from table <%s> fail: %s", tableList[i], err)
+			panic("generate struct fail")
+		}
 	}
 	return tableModels
 }
@@ -155,19 +265,59 @@ func (g *Generator) GenerateModelFrom(obj helper.Object) *generate.QueryStructMe
 	return s
 }
 
+// dialect name of database, or dialect recorded in schema when it's used
+func (g *Generator) dialect() string {
+	if g.schema != nil {
+		return g.schema.Dialect
+	}
+	return g.db.Dialector.Name()
+}
+
+// modelName default model name of table, schema of schema-qualified table is kept as prefix except default schema
+// dbo of sql server, e.g. sales.orders -> SalesOrder, dbo.users -> User
+func (g *Generator) modelName(tableName string) string {
+	if schemaName, name, ok := strings.Cut(tableName, "."); ok {
+		schemaName, name = strings.Trim(schemaName, "[]"), strings.Trim(name, "[]")
+		if g.dialect() == "sqlserver" && strings.EqualFold(schemaName, "dbo") {
+			tableName = name
+		} else {
+			tableName = schemaName + "_" + name
+		}
+	}
+	return g.db.Config.NamingStrategy.SchemaName(tableName)
+}
+
+// schemaName name of schema of tables specified by WithDbNameOpts, "" for current schema
+func (g *Generator) schemaName() string {
+	return (&model.Config{NameStrategy: model.NameStrategy{SchemaNameOpts: g.dbNameOpts}}).GetSchemaName(g.db)
+}
+
 func (g *Generator) genModelConfig(tableName string, modelName string, modelOpts []ModelOpt) *model.Config {
 	if modelOpts == nil {
 		modelOpts = g.modelOpts
 	} else {
 		modelOpts = append(modelOpts, g.modelOpts...)
 	}
+	modelOpts = append(modelOpts, g.AuditColumns.modelOpts()...)
+	modelOpts = append(append(modelOpts, g.TableModelOpts["*"]...), g.TableModelOpts[tableName]...)
+	if methods := append(append([]string(nil), g.TableMethods["*"]...), g.TableMethods[tableName]...); len(methods) > 0 {
+		types := make([]interface{}, len(methods))
+		for i, m := range methods {
+			types[i] = m
+		}
+		modelOpts = append(modelOpts, WithMethod(types...))
+	}
+	if columns := tableColumns(g.ExcludeColumns, tableName); len(columns) > 0 {
+		modelOpts = append(modelOpts, FieldIgnore(columns...))
+	}
 	return &model.Config{
-		ModelPkg:       g.Config.ModelPkgPath,
+		ModelPkg:       g.modelPkgName(tableName),
 		TablePrefix:    g.getTablePrefix(),
 		TableName:      tableName,
 		ModelName:      modelName,
 		ImportPkgPaths: g.importPkgPaths,
 		ModelOpts:      modelOpts,
+		Schema:         g.schema,
 		NameStrategy: model.NameStrategy{
 			SchemaNameOpts: g.dbNameOpts,
 			TableNameNS:    g.tableNameNS,
@@ -182,12 +332,50 @@ func (g *Generator) genModelConfig(tableName string, modelName string, modelOpts
 			FieldCoverable:    g.FieldCoverable,
 			FieldWithIndexTag: g.FieldWithIndexTag,
 			FieldWithTypeTag:  g.FieldWithTypeTag,
+			FieldWithEnumType: g.FieldWithEnumType,
+
+			FieldWithCommentEnum: g.FieldWithCommentEnum,
+			ArrayTypeMap:         g.ArrayTypeMap,
+			FieldWithJSONType:    g.FieldWithJSONType,
+			FieldWithUUIDType:    g.FieldWithUUIDType,
+			UUIDColumnTypes:      g.UUIDColumnTypes,
+			DecimalType:          decimalTypes[g.DecimalType],
+			NullableStyle:        g.NullableStyle,
+			DateType:             dateTypes[g.DateType],
+			TimeType:             timeTypes[g.TimeType],
+			DateTimeType:         dateTimeTypes[g.DateTimeType],
+
+			FieldWithGeometryType: g.FieldWithGeometryType,
+
+			IntegerTypeMap: g.IntegerTypeMap[g.dialect()],
+
+			FieldWithConstraintTag: g.FieldWithConstraintTag,
+			FieldWithRelation:      g.FieldWithRelation,
+
+			PIIColumns: tableColumns(g.PIIColumns, tableName),
+
+			ValidateNotNullZero: g.ValidateNotNullZero,
+
+			FieldWithDocComment: g.FieldWithDocComment,
+			FieldOmitDeprecated: g.FieldOmitDeprecated,
 
 			FieldJSONTagNS: g.fieldJSONTagNS,
 		},
 	}
 }
 
+// tableColumns columns of table in columns of form column or table.column
+func tableColumns(columns []string, tableName string) (tableColumns []string) {
+	for _, c := range columns {
+		if table, column, ok := strings.Cut(c, "."); !ok {
+			tableColumns = append(tableColumns, c)
+		} else if table == tableName {
+			tableColumns = append(tableColumns, column)
+		}
+	}
+	return tableColumns
+}
+
 func (g *Generator) getTablePrefix() string {
 	if ns, ok := g.db.NamingStrategy.(schema.NamingStrategy); ok {
 		return ns.TablePrefix
@@ -249,19 +437,48 @@ func (g *Generator) apply(fc interface{}, structs []*generate.QueryStructMeta) {
 			panic("gen struct fail")
 		}
 
-		functions, err := generate.BuildDIYMethod(readInterface, interfaceStructMeta, genInfo.Interfaces)
+		functions, err := generate.BuildDIYMethod(readInterface, interfaceStructMeta, genInfo.Interfaces, g.SQLFragments, g.SQLDialect)
 		if err != nil {
 			g.db.Logger.Error(context.Background(), "check interface fail: %v", err)
 			panic("check interface fail")
 		}
+		for _, function := range functions {
+			function.StrictRowsAffected = g.StrictRowsAffected
+			function.DefaultPolicy(g.StatementPolicy.Timeout, g.StatementPolicy.MaxAttempts, g.StatementPolicy.Backoff)
+		}
 		genInfo.appendMethods(functions)
 	}
 }
 
 // Execute generate code to output path
 func (g *Generator) Execute() {
+	start := time.Now()
 	g.info("Start generating code.")
 
+	if err := g.loadTemplates(); err != nil {
+		g.db.Logger.Error(context.Background(), "load templates fail: %s", err)
+		panic("load templates fail")
+	}
+
+	g.header = fileHeader(g.FileHeader, g.BuildTags)
+	configSum, err := g.configFingerprint()
+	if err != nil {
+		g.db.Logger.Error(context.Background(), "fingerprint config fail: %s", err)
+		panic("fingerprint config fail")
+	}
+	g.configSum = configSum
+	if g.Formatter == "gofumpt" {
+		g.gofumptOpts = gofumptOptions(g.OutPath)
+	}
+
+	g.relateModels()
+	g.extractBaseModel()
+
+	if err := g.loadLock(); err != nil {
+		g.db.Logger.Error(context.Background(), "load lock file fail: %s", err)
+		panic("load lock file fail")
+	}
+
 	if err := g.generateModelFile(); err != nil {
 		g.db.Logger.Error(context.Background(), "generate model struct fail: %s", err)
 		panic("generate model struct fail")
@@ -272,7 +489,47 @@ func (g *Generator) Execute() {
 		panic("generate query code fail")
 	}
 
-	g.info("Generate code done.")
+	if err := g.prune(); err != nil {
+		g.db.Logger.Error(context.Background(), "prune stale files fail: %s", err)
+		panic("prune stale files fail")
+	}
+
+	if err := g.export(); err != nil {
+		g.db.Logger.Error(context.Background(), "export models fail: %s", err)
+		panic("export models fail")
+	}
+
+	if err := g.verify(); err != nil {
+		g.db.Logger.Error(context.Background(), "verify generated code fail: %s", err)
+		panic("verify generated code fail")
+	}
+
+	if err := g.saveLock(); err != nil {
+		g.db.Logger.Error(context.Background(), "save lock file fail: %s", err)
+		panic("save lock file fail")
+	}
+
+	if err := g.saveManifest(); err != nil {
+		g.db.Logger.Error(context.Background(), "save manifest file fail: %s", err)
+		panic("save manifest file fail")
+	}
+
+	g.info(fmt.Sprintf("Generate code done in %s.", time.Since(start).Round(time.Millisecond)))
+}
+
+// Export run exporters(e.g. WithSchemaJSONExport, WithOpenAPIExport, WithERDExport) of generated models without
+// generating code, exporters of generated code(WithDTOExport, WithHandlerExport, WithDIExport) require Execute
+func (g *Generator) Export() {
+	g.info("Start exporting models.")
+
+	g.relateModels()
+
+	if err := g.export(); err != nil {
+		g.db.Logger.Error(context.Background(), "export models fail: %s", err)
+		panic("export models fail")
+	}
+
+	g.info("Export models done.")
 }
 
 // info logger
@@ -283,6 +540,14 @@ func (g *Generator) info(logInfos ...string) {
 	}
 }
 
+// concurrency number of tables handled concurrently
+func (g *Generator) concurrency() int {
+	if g.Concurrency <= 0 {
+		return concurrent
+	}
+	return g.Concurrency
+}
+
 // generateQueryFile generate query code and save to file
 func (g *Generator) generateQueryFile() (err error) {
 	if len(g.Data) == 0 {
@@ -294,17 +559,25 @@ func (g *Generator) generateQueryFile() (err error) {
 	}
 
 	errChan := make(chan error)
-	pool := pools.NewPool(concurrent)
+	progress := g.beginProgress("query", len(g.Data))
+	pool := pools.NewPool(g.concurrency())
 	// generate query code for all struct
 	for _, info := range g.Data {
 		pool.Wait()
 		go func(info *genInfo) {
 			defer pool.Done()
+			defer progress.step(info.TableName)
 			err := g.generateSingleQueryFile(info)
 			if err != nil {
 				errChan <- err
 			}
 
+			if err == nil && g.judgeMode(WithFakeDAO) {
+				if err = g.generateFakeFile(info); err != nil {
+					errChan <- err
+				}
+			}
+
 			if g.WithUnitTest {
 				err = g.generateQueryUnitTestFile(info)
 				if err != nil { // do not panic
@@ -320,7 +593,12 @@ func (g *Generator) generateQueryFile() (err error) {
 	}
 
 	// generate query file
+	header, err := g.provenance(nil)
+	if err != nil {
+		return err
+	}
 	var buf bytes.Buffer
+	buf.WriteString(header)
 	err = render(tmpl.Header, &buf, map[string]interface{}{
 		"Package":        g.queryPkgName,
 		"ImportPkgPaths": importList.Add(g.importPkgPaths...).Paths(),
@@ -339,6 +617,18 @@ func (g *Generator) generateQueryFile() (err error) {
 	if err != nil {
 		return err
 	}
+	if g.judgeMode(WithGenericDAO) {
+		err = render(tmpl.GenericDAO, &buf, g)
+		if err != nil {
+			return err
+		}
+	}
+	if g.judgeMode(WithPreloadMethod) {
+		err = render(tmpl.PreloadFunc, &buf, g.preloadNames())
+		if err != nil {
+			return err
+		}
+	}
 
 	err = g.output(g.OutFile, buf.Bytes())
 	if err != nil {
@@ -349,10 +639,16 @@ func (g *Generator) generateQueryFile() (err error) {
 	// generate query unit test file
 	if g.WithUnitTest {
 		buf.Reset()
+		buf.WriteString(header)
 
+		container := g.unitTestContainer()
+		importPkgPaths := unitTestImportList.Add(g.importPkgPaths...)
+		if container != nil {
+			importPkgPaths = importPkgPaths.Add(container.imports()...)
+		}
 		err = render(tmpl.Header, &buf, map[string]interface{}{
 			"Package":        g.queryPkgName,
-			"ImportPkgPaths": unitTestImportList.Add(g.importPkgPaths...).Paths(),
+			"ImportPkgPaths": importPkgPaths.Paths(),
 		})
 		if err != nil {
 			g.db.Logger.Error(context.Background(), "generate query unit test fail: %s", err)
@@ -362,6 +658,14 @@ func (g *Generator) generateQueryFile() (err error) {
 		if err != nil {
 			return err
 		}
+		if container != nil {
+			err = render(tmpl.UnitTestContainerDB, &buf, container)
+		} else {
+			err = render(tmpl.UnitTestSQLiteDB, &buf, nil)
+		}
+		if err != nil {
+			return err
+		}
 		err = render(tmpl.QueryMethodTest, &buf, g)
 		if err != nil {
 			g.db.Logger.Error(context.Background(), "generate query unit test fail: %s", err)
@@ -381,7 +685,19 @@ func (g *Generator) generateQueryFile() (err error) {
 
 // generateSingleQueryFile generate query code and save to file
 func (g *Generator) generateSingleQueryFile(data *genInfo) (err error) {
+	fileName := fmt.Sprintf("%s%s%s.gen.go", g.OutPath, string(os.PathSeparator), data.FileName)
+	if g.unchanged(data.QueryStructMeta, fileName) {
+		g.skipped(fileName)
+		g.info(fmt.Sprintf("skip unchanged query file: %s", fileName))
+		return nil
+	}
+
+	header, err := g.provenance(data.QueryStructMeta)
+	if err != nil {
+		return err
+	}
 	var buf bytes.Buffer
+	buf.WriteString(header)
 
 	structPkgPath := data.StructInfo.PkgPath
 	if structPkgPath == "" {
@@ -395,43 +711,236 @@ func (g *Generator) generateSingleQueryFile(data *genInfo) (err error) {
 		return err
 	}
 
-	data.QueryStructMeta = data.QueryStructMeta.IfaceMode(g.judgeMode(WithQueryInterface))
+	data.QueryStructMeta = data.QueryStructMeta.IfaceMode(g.judgeMode(WithQueryInterface)).
+		GenericMode(g.judgeMode(WithGenericDAO)).VersionMode(g.VersionColumn).TenantMode(g.TenantColumn).
+		ShardMode(g.ShardedTables[data.TableName]).AuditMode(g.AuditColumns.CreatedBy, g.AuditColumns.UpdatedBy).
+		BatchMode(g.BatchSize).RowsAffectedMode(g.StrictRowsAffected).LookupMode(g.UnscopedLookup)
+	data.mode = g.Mode
+	if !returningDialects[g.dialect()] {
+		data.mode &^= WithReturningMethod
+	}
 
 	structTmpl := tmpl.TableQueryStructWithContext
 	if g.judgeMode(WithoutContext) {
 		structTmpl = tmpl.TableQueryStruct
 	}
-	err = render(structTmpl, &buf, data.QueryStructMeta)
+	err = g.queryTemplates.render(queryStructTemplate, structTmpl, &buf, data.QueryStructMeta)
 	if err != nil {
 		return err
 	}
 
 	if g.judgeMode(WithQueryInterface) {
-		err = render(tmpl.TableQueryIface, &buf, data)
+		err = g.queryTemplates.render(queryIfaceTemplate, tmpl.TableQueryIface, &buf, data)
 		if err != nil {
 			return err
 		}
 	}
 
 	for _, method := range data.Interfaces {
-		err = render(tmpl.DIYMethod, &buf, method)
+		err = g.queryTemplates.render(diyMethodTemplate, tmpl.DIYMethod, &buf, method)
 		if err != nil {
 			return err
 		}
 	}
 
-	err = render(tmpl.CRUDMethod, &buf, data.QueryStructMeta)
+	crudTmpl := tmpl.CRUDMethod
+	if g.judgeMode(WithGenericDAO) {
+		crudTmpl = tmpl.GenericCRUDMethod
+	}
+	err = g.queryTemplates.render(crudMethodTemplate, crudTmpl, &buf, data.QueryStructMeta)
 	if err != nil {
 		return err
 	}
 
-	defer g.info(fmt.Sprintf("generate query file: %s%s%s.gen.go", g.OutPath, string(os.PathSeparator), data.FileName))
-	return g.output(fmt.Sprintf("%s%s%s.gen.go", g.OutPath, string(os.PathSeparator), data.FileName), buf.Bytes())
+	if data.HasExistsMethod() {
+		err = render(tmpl.ExistsMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasEachMethod() {
+		err = render(tmpl.EachMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasLockingMethod() {
+		err = render(tmpl.LockingMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasCacheMethod() {
+		err = render(tmpl.CacheMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasResolverMethod() {
+		err = render(tmpl.ResolverMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasPreloadMethod() {
+		err = render(tmpl.PreloadMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasFilterMethod() {
+		err = render(tmpl.FilterMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasSortMethod() {
+		err = render(tmpl.SortMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasBatchMethod() {
+		err = render(tmpl.BatchMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasReturningMethod() {
+		err = render(tmpl.ReturningMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasAffectedMethod() {
+		err = render(tmpl.AffectedMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasHintMethod() {
+		err = render(tmpl.HintMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.HasImportExportMethod() {
+		err = render(tmpl.ImportExportMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.ShardPattern() != "" {
+		err = render(tmpl.ShardMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.VersionField() != nil {
+		err = render(tmpl.VersionMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.Temporal {
+		err = render(tmpl.TemporalMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.CompositeKey() != nil {
+		err = render(tmpl.CompositeKeyMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.SoftDeleteField() != nil {
+		err = render(tmpl.SoftDeleteMethod, &buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = g.queryTemplates.render(queryExtraTemplate, "", &buf, data.QueryStructMeta)
+	if err != nil {
+		return err
+	}
+
+	defer g.info(fmt.Sprintf("generate query file: %s", fileName))
+	return g.output(fileName, buf.Bytes())
+}
+
+// generateFakeFile generate in-memory fake DAO of table and save to file
+func (g *Generator) generateFakeFile(data *genInfo) (err error) {
+	if data.FakeKey() == nil {
+		g.info(fmt.Sprintf("skip fake DAO of table %s without single primary key of builtin type", data.TableName))
+		return nil
+	}
+	fileName := fmt.Sprintf("%s%s%s.fake.gen.go", g.OutPath, string(os.PathSeparator), data.FileName)
+	if g.unchanged(data.QueryStructMeta, fileName) {
+		g.skipped(fileName)
+		return nil
+	}
+
+	header, err := g.provenance(data.QueryStructMeta)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(header)
+
+	structPkgPath := data.StructInfo.PkgPath
+	if structPkgPath == "" {
+		structPkgPath = g.modelPkgPath
+	}
+	err = render(tmpl.Header, &buf, map[string]interface{}{
+		"Package":        g.queryPkgName,
+		"ImportPkgPaths": fakeImportList.Add(structPkgPath).Add(data.ImportPkgPaths...).Paths(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = render(tmpl.FakeDAO, &buf, data.QueryStructMeta)
+	if err != nil {
+		return err
+	}
+
+	defer g.info(fmt.Sprintf("generate fake DAO file: %s", fileName))
+	return g.output(fileName, buf.Bytes())
 }
 
 // generateQueryUnitTestFile generate unit test file for query
 func (g *Generator) generateQueryUnitTestFile(data *genInfo) (err error) {
+	fileName := fmt.Sprintf("%s%s%s.gen_test.go", g.OutPath, string(os.PathSeparator), data.FileName)
+	if g.unchanged(data.QueryStructMeta, fileName) {
+		g.skipped(fileName)
+		return nil
+	}
+
+	header, err := g.provenance(data.QueryStructMeta)
+	if err != nil {
+		return err
+	}
 	var buf bytes.Buffer
+	buf.WriteString(header)
 
 	structPkgPath := data.StructInfo.PkgPath
 	if structPkgPath == "" {
@@ -457,8 +966,8 @@ func (g *Generator) generateQueryUnitTestFile(data *genInfo) (err error) {
 		}
 	}
 
-	defer g.info(fmt.Sprintf("generate unit test file: %s%s%s.gen_test.go", g.OutPath, string(os.PathSeparator), data.FileName))
-	return g.output(fmt.Sprintf("%s%s%s.gen_test.go", g.OutPath, string(os.PathSeparator), data.FileName), buf.Bytes())
+	defer g.info(fmt.Sprintf("generate unit test file: %s", fileName))
+	return g.output(fileName, buf.Bytes())
 }
 
 // generateModelFile generate model structures and save to file
@@ -476,32 +985,142 @@ func (g *Generator) generateModelFile() error {
 		return fmt.Errorf("create model pkg path(%s) fail: %s", modelOutPath, err)
 	}
 
+	if err = g.checkPackageConflicts(); err != nil {
+		return err
+	}
+	if err = g.generateBaseModelFile(modelOutPath); err != nil {
+		return err
+	}
+
+	total := 0
+	for _, data := range g.models {
+		if data != nil && data.Generated {
+			total++
+		}
+	}
+
+	var mu sync.Mutex
+	merged := make(map[string]map[string][]byte) // models.gen.go -> model name -> content in single layout
 	errChan := make(chan error)
-	pool := pools.NewPool(concurrent)
+	progress := g.beginProgress("model", total)
+	pool := pools.NewPool(g.concurrency())
 	for _, data := range g.models {
 		if data == nil || !data.Generated {
 			continue
 		}
+		if modelFile := g.modelFile(modelOutPath, data); g.ModelLayout != modelLayoutSingle && g.unchanged(data, modelFile) {
+			g.skipped(modelFile)
+			g.info(fmt.Sprintf("skip unchanged model file: %s", modelFile))
+			progress.step(data.TableName)
+			continue
+		}
 		pool.Wait()
 		go func(data *generate.QueryStructMeta) {
 			defer pool.Done()
+			defer progress.step(data.TableName)
 
+			header, err := g.provenance(data)
+			if err != nil {
+				errChan <- err
+				return
+			}
 			var buf bytes.Buffer
-			err := render(tmpl.Model, &buf, data)
+			buf.WriteString(header)
+			err = g.modelTemplates.render(modelTemplate, tmpl.Model, &buf, data)
 			if err != nil {
 				errChan <- err
 				return
 			}
 
+			if g.FieldWithNameConst {
+				err = g.modelTemplates.render(modelNameConstTemplate, tmpl.ModelNameConst, &buf, data)
+				if err != nil {
+					errChan <- err
+					return
+				}
+			}
+
 			for _, method := range data.ModelMethods {
-				err = render(tmpl.ModelMethod, &buf, method)
+				err = g.modelTemplates.render(modelMethodTemplate, tmpl.ModelMethod, &buf, method)
+				if err != nil {
+					errChan <- err
+					return
+				}
+			}
+
+			for _, enum := range data.Enums() {
+				err = g.modelTemplates.render(modelEnumTemplate, tmpl.ModelEnum, &buf, enum)
+				if err != nil {
+					errChan <- err
+					return
+				}
+			}
+
+			if data.Lookup != nil {
+				err = g.modelTemplates.render(modelLookupTemplate, tmpl.ModelLookup, &buf, data.Lookup)
+				if err != nil {
+					errChan <- err
+					return
+				}
+			}
+
+			for _, typ := range data.BinaryUUIDs() {
+				err = g.modelTemplates.render(modelUUIDTemplate, tmpl.ModelBinaryUUID, &buf, typ)
+				if err != nil {
+					errChan <- err
+					return
+				}
+			}
+
+			for _, typ := range data.Geometries() {
+				err = g.modelTemplates.render(modelGeometryTemplate, tmpl.ModelGeometry, &buf, typ)
+				if err != nil {
+					errChan <- err
+					return
+				}
+			}
+
+			if g.UUIDHook && len(data.UUIDKeys()) > 0 && !data.HasModelMethod("BeforeCreate") {
+				err = g.modelTemplates.render(modelHookTemplate, tmpl.ModelUUIDHook, &buf, data)
+				if err != nil {
+					errChan <- err
+					return
+				}
+			}
+
+			if g.TimeUTC && len(data.UTCFields()) > 0 && !data.HasModelMethod("BeforeSave") && !data.HasModelMethod("AfterFind") {
+				err = g.modelTemplates.render(modelUTCHookTemplate, tmpl.ModelUTCHook, &buf, data)
 				if err != nil {
 					errChan <- err
 					return
 				}
 			}
 
-			modelFile := modelOutPath + data.FileName + ".gen.go"
+			for _, method := range g.ExtraModelMethods {
+				if data.HasModelMethod(method) || data.HasFieldNamed(method) || (method == "PrimaryKey" && data.PrimaryKey() == nil) {
+					continue
+				}
+				t := extraModelMethods[method]
+				if err = g.modelTemplates.render(t.name, t.builtin, &buf, data); err != nil {
+					errChan <- err
+					return
+				}
+			}
+
+			modelFile := g.modelFile(modelOutPath, data)
+			if g.ModelLayout == modelLayoutSingle {
+				mu.Lock()
+				if merged[modelFile] == nil {
+					merged[modelFile] = make(map[string][]byte)
+				}
+				merged[modelFile][data.ModelStructName] = buf.Bytes()
+				mu.Unlock()
+				return
+			}
+			if err = os.MkdirAll(filepath.Dir(modelFile), os.ModePerm); err != nil {
+				errChan <- err
+				return
+			}
 			err = g.output(modelFile, buf.Bytes())
 			if err != nil {
 				errChan <- err
@@ -515,9 +1134,13 @@ func (g *Generator) generateModelFile() error {
 	case err = <-errChan:
 		return err
 	case <-pool.AsyncWaitAll():
-		g.fillModelPkgPath(modelOutPath)
 	}
-	return nil
+	if err = g.outputMergedModels(merged); err != nil {
+		return err
+	}
+	g.fillModelPkgPath(modelOutPath)
+	g.fillSubPackagePaths()
+	return g.generateHookStubs(modelOutPath)
 }
 
 func (g *Generator) getModelOutputPath() (outPath string, err error) {
@@ -533,23 +1156,37 @@ func (g *Generator) getModelOutputPath() (outPath string, err error) {
 }
 
 func (g *Generator) fillModelPkgPath(filePath string) {
+	pkgPath, err := loadPkgPath(filePath)
+	if err != nil {
+		g.db.Logger.Warn(context.Background(), "parse model pkg path fail: %s", err)
+		return
+	}
+	g.Config.modelPkgPath = pkgPath
+}
+
+// loadPkgPath return import path of package in dir
+func loadPkgPath(dir string) (string, error) {
 	pkgs, err := packages.Load(&packages.Config{
 		Mode: packages.NeedName,
-		Dir:  filePath,
+		Dir:  dir,
 	})
 	if err != nil {
-		g.db.Logger.Warn(context.Background(), "parse model pkg path fail: %s", err)
-		return
+		return "", err
 	}
 	if len(pkgs) == 0 {
-		g.db.Logger.Warn(context.Background(), "parse model pkg path fail: got 0 packages")
-		return
+		return "", fmt.Errorf("got 0 packages")
 	}
-	g.Config.modelPkgPath = pkgs[0].PkgPath
+	return pkgs[0].PkgPath, nil
 }
 
 // output format and output
-func (g *Generator) output(fileName string, content []byte) error {
+func (g *Generator) output(fileName string, content []byte) (err error) {
+	if content, err = g.beforeRender(fileName, content); err != nil {
+		return err
+	}
+	if len(g.header) > 0 {
+		content = append(append([]byte{}, g.header...), bytes.TrimLeft(content, "\n")...)
+	}
 	result, err := imports.Process(fileName, content, nil)
 	if err != nil {
 		lines := strings.Split(string(content), "\n")
@@ -567,7 +1204,14 @@ func (g *Generator) output(fileName string, content []byte) error {
 		}
 		return fmt.Errorf("cannot format file: %w", err)
 	}
-	return ioutil.WriteFile(fileName, result, 0640)
+	if result, err = g.format(result); err != nil {
+		return fmt.Errorf("format file %s with %s fail: %w", fileName, g.Formatter, err)
+	}
+	if err = ioutil.WriteFile(fileName, result, 0640); err != nil {
+		return err
+	}
+	g.written(fileName)
+	return g.afterWrite(fileName)
 }
 
 func (g *Generator) pushQueryStructMeta(meta *generate.QueryStructMeta) (*genInfo, error) {
@@ -595,6 +1239,9 @@ func getImportPkgPaths(data *genInfo) []string {
 	for _, path := range data.ImportPkgPaths {
 		importPathMap[path] = struct{}{}
 	}
+	if data.HasJSONField() { // JSON query helpers
+		importPathMap[`"gorm.io/datatypes"`] = struct{}{}
+	}
 	// imports.Process (called in Generator.output) will guess missing imports, and will be
 	// much faster if import path is already specified. So add all imports from DIY interface package.
 	for _, method := range data.Interfaces {
@@ -606,5 +1253,6 @@ func getImportPkgPaths(data *genInfo) []string {
 	for importPath := range importPathMap {
 		importPkgPaths = append(importPkgPaths, importPath)
 	}
+	sort.Strings(importPkgPaths)
 	return importPkgPaths
 }
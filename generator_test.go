@@ -2,16 +2,31 @@ package gen
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"text/template"
 	"time"
 
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils/tests"
+	gofumpt "mvdan.cc/gofumpt/format"
 
 	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
 )
 
 func TestConfig(t *testing.T) {
@@ -310,3 +325,2427 @@ var teacher = func() Teacher {
 	t.UseModel(TeacherRaw{})
 	return t
 }()
+
+func TestWithoutPartitions(t *testing.T) {
+	parents := map[string]string{"events_p2024_01": "events", "events_p2024_02": "events", "events_p2024_02_a": "events_p2024_02"}
+	kept, skipped := withoutPartitions([]string{"events", "events_p2024_01", "events_p2024_02", "events_p2024_02_a", "users"}, parents)
+	if len(kept) != 2 || kept[0] != "events" || kept[1] != "users" {
+		t.Errorf("partitions should be skipped, got %v", kept)
+	}
+	if skipped["events"] != 3 || len(skipped) != 1 {
+		t.Errorf("partitions should be counted for root partitioned table, got %v", skipped)
+	}
+}
+
+func TestEachMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	m.generate(Config{Mode: WithEachMethod | WithQueryInterface})
+	for _, expect := range []string{"Each(ctx context.Context, fc func(*model.User) error) error", "EachInBatches(batchSize int, fc func(results []*model.User, batch int) error) error"} {
+		if content := m.read("query/users.gen.go"); !strings.Contains(content, expect) {
+			t.Errorf("query should contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	m.generate(Config{Mode: WithEachMethod})
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: "a"}, &model.User{Name: "b"}, &model.User{Name: "c"}) == nil, "create fail")
+	var names []string
+	err := u.Each(ctx, func(user *model.User) error {
+		names = append(names, user.Name)
+		return nil
+	})
+	check(err == nil && len(names) == 3, "Each should iterate all records, got %v %v", names, err)
+	var sizes []int
+	err = u.EachInBatches(2, func(users []*model.User, batch int) error {
+		sizes = append(sizes, len(users))
+		return nil
+	})
+	check(err == nil && fmt.Sprint(sizes) == "[2 1]", "EachInBatches should iterate batches of size, got %v %v", sizes, err)`, "example.com/app/dao/model")
+}
+
+func TestLockingMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	m.generate(Config{Mode: WithLockingMethod | WithQueryInterface})
+	for _, expect := range []string{"ForUpdate(opts ...gen.LockOption) IUserDo", "ForShare(opts ...gen.LockOption) IUserDo"} {
+		if content := m.read("query/users.gen.go"); !strings.Contains(content, expect) {
+			t.Errorf("query should contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	m.generate(Config{Mode: WithLockingMethod})
+	m.run(`
+	err := q.Transaction(func(tx *query.Query) error {
+		_, err := tx.User.WithContext(ctx).ForUpdate(gen.LockSkipLocked).Where(tx.User.ID.Eq(1)).Find()
+		return err
+	})
+	check(err == nil, "locking of sqlite should be no-op, got %v", err)
+	_, err = q.User.WithContext(ctx).ForShare(gen.LockNoWait, gen.LockSkipLocked).Find()
+	check(errors.Is(err, gen.ErrConflictingLockOptions), "conflicting options should fail, got %v", err)`, "errors", "gorm.io/gen")
+}
+
+func TestVersionMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER, version INTEGER NOT NULL DEFAULT 0)")
+	m.generate(Config{VersionColumn: "version"})
+	m.run(`
+	u := q.User
+	stale := func() *model.User {
+		user, err := u.WithContext(ctx).Where(u.ID.Eq(1)).Take()
+		check(err == nil, "take fail: %v", err)
+		return user
+	}
+	user := &model.User{ID: 1, Name: "modi"}
+	check(u.WithContext(ctx).Save(user) == nil && user.Version == 1, "save should create record with version 1, got %d", user.Version)
+
+	for name, update := range map[string]func() (gen.ResultInfo, error){
+		"Update":        func() (gen.ResultInfo, error) { return u.WithContext(ctx).Where(u.ID.Eq(1)).Update(u.Name, "gorm") },
+		"UpdateSimple":  func() (gen.ResultInfo, error) { return u.WithContext(ctx).Where(u.ID.Eq(1)).UpdateSimple(u.Age.Add(1)) },
+		"UpdateColumn":  func() (gen.ResultInfo, error) { return u.WithContext(ctx).Where(u.ID.Eq(1)).UpdateColumn(u.Age, 18) },
+		"UpdateColumns": func() (gen.ResultInfo, error) { return u.WithContext(ctx).Where(u.ID.Eq(1)).UpdateColumns(map[string]interface{}{"age": 20}) },
+	} {
+		user := stale()
+		_, err := update()
+		check(err == nil, "%s fail: %v", name, err)
+		user.Name = "stale"
+		_, err = u.WithContext(ctx).Updates(user)
+		check(errors.Is(err, gen.ErrStaleObject), "updates of record read before %s should be stale, got %v", name, err)
+		check(errors.Is(u.WithContext(ctx).Save(user), gen.ErrStaleObject), "save of record read before %s should be stale", name)
+		_, err = u.WithContext(ctx).UpdateColumns(user)
+		check(errors.Is(err, gen.ErrStaleObject), "update columns of record read before %s should be stale, got %v", name, err)
+	}
+
+	user = stale()
+	user.Name = "fresh"
+	_, err := u.WithContext(ctx).UpdateColumns(user)
+	check(err == nil && stale().Version == user.Version, "update columns of fresh record should increase version, got %v", err)
+	check(u.WithContext(ctx).Save(user) == nil && stale().Version == user.Version, "save of fresh record should increase version")`,
+		"errors", "gorm.io/gen", "example.com/app/dao/model")
+}
+
+func TestTracing(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	m.generate(Config{Tracing: true})
+	if content := m.read("query/gen.go"); !strings.Contains(content, "gen.WithTracing(tracing.Global())") {
+		t.Errorf("Use should trace by global tracer, got:\n%s", content)
+	}
+	m.run(`
+	check(q.User.WithContext(ctx).Create(&model.User{Name: "modi"}) == nil, "create by global tracer fail")
+
+	var operations []string
+	metrics := gen.MetricsTracer(func(table, operation string, _ time.Duration, _ error) {
+		operations = append(operations, table+"."+operation)
+	})
+	traced := query.Use(db, gen.WithTracing(metrics))
+	_, err := traced.User.WithContext(ctx).Where(traced.User.Name.Eq("modi")).Find()
+	check(err == nil && fmt.Sprint(operations) == "[users.query]", "tracer passed to Use should replace global tracer, got %v %v", operations, err)`,
+		"time", "gorm.io/gen", "example.com/app/dao/model")
+}
+
+func TestResolverMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)", "INSERT INTO users VALUES (1, 'source')")
+	m.generate(Config{Mode: WithResolverMethod})
+	m.run(`
+	replica, err := gorm.Open(sqlite.Open(os.Args[1] + ".replica"))
+	check(err == nil, "open replica fail: %v", err)
+	check(replica.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)").Error == nil, "create replica fail")
+	check(replica.Exec("INSERT INTO users VALUES (1, 'replica')").Error == nil, "insert replica fail")
+
+	rq := query.Use(db, gen.WithDBResolver(dbresolver.Config{Replicas: []gorm.Dialector{sqlite.Open(os.Args[1] + ".replica")}}))
+	u := rq.User
+	for name, take := range map[string]func() (*model.User, error){
+		"replica": u.WithContext(ctx).ReadOnly().Take,
+		"source":  u.WithContext(ctx).Primary().Take,
+	} {
+		user, err := take()
+		check(err == nil && user.Name == name, "query should read from %s, got %v %v", name, user, err)
+	}
+
+	sticky := u.WithContext(ctx).Sticky(ctx)
+	check(sticky.Create(&model.User{ID: 2, Name: "created"}) == nil, "create fail")
+	user, err := sticky.Where(u.ID.Eq(2)).Take()
+	check(err == nil && user.Name == "created", "reads after write should be pinned to source, got %v %v", user, err)
+	_, err = u.WithContext(ctx).Where(u.ID.Eq(2)).Take()
+	check(err != nil, "reads without sticky context should be from replica")`, "gorm.io/gen", "gorm.io/plugin/dbresolver", "example.com/app/dao/model")
+}
+
+func TestTenantColumn(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, tenant_id INTEGER NOT NULL, name TEXT)")
+	m.generate(Config{TenantColumn: "tenant_id", WithUnitTest: true})
+	m.test()
+	m.run(`
+	u := q.User
+	ctx1, ctx2 := gen.WithTenant(ctx, 1), gen.WithTenant(ctx, 2)
+	user := &model.User{ID: 1, Name: "modi"}
+	check(u.WithContext(ctx1).Create(user) == nil && user.TenantID == 1, "create should fill tenant, got %d", user.TenantID)
+	_, err := u.WithContext(ctx2).Where(u.ID.Eq(1)).Take()
+	check(errors.Is(err, gorm.ErrRecordNotFound), "record of another tenant should not be found, got %v", err)
+	_, err = u.WithContext(ctx).Find()
+	check(errors.Is(err, gen.ErrMissingTenant), "query without tenant should fail, got %v", err)`,
+		"errors", "gorm.io/gen", "example.com/app/dao/model")
+}
+
+func TestShardedTables(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE orders (id INTEGER PRIMARY KEY, amount INTEGER)", "CREATE TABLE logs (id INTEGER PRIMARY KEY, msg TEXT)")
+	m.generate(Config{ShardedTables: map[string]string{"orders": "_200601", "logs": "_%02d"}})
+	m.run(`
+	check(db.Exec("CREATE TABLE orders_202401 (id INTEGER PRIMARY KEY, amount INTEGER)").Error == nil, "create orders_202401 fail")
+	check(db.Exec("CREATE TABLE logs_07 (id INTEGER PRIMARY KEY, msg TEXT)").Error == nil, "create logs_07 fail")
+
+	jan := q.Order.ForMonth(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	check(jan.WithContext(ctx).Create(&model.Order{ID: 1, Amount: 100}) == nil, "create in sharded table fail")
+	order, err := jan.WithContext(ctx).Where(jan.ID.Eq(1)).Take()
+	check(err == nil && order.Amount == 100, "record should be read from orders_202401, got %v %v", order, err)
+	count, err := q.Order.WithContext(ctx).Count()
+	check(err == nil && count == 0, "base table should be left alone, got %d %v", count, err)
+
+	check(q.Log.ForShard(7).WithContext(ctx).Create(&model.Log{Msg: "modi"}) == nil, "create in shard fail")
+	count, err = q.Log.Suffix("_07").WithContext(ctx).Count()
+	check(err == nil && count == 1, "record should be created in logs_07, got %d %v", count, err)`,
+		"time", "example.com/app/dao/model")
+}
+
+func TestAuditColumns(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, author TEXT, editor TEXT, inserted_at DATETIME, modified_at DATETIME)")
+	m.generate(Config{AuditColumns: AuditColumns{CreatedBy: "author", UpdatedBy: "editor", CreatedAt: "inserted_at", UpdatedAt: "modified_at"}})
+	for _, expect := range []string{`gorm:"column:inserted_at;autoCreateTime"`, `gorm:"column:modified_at;autoUpdateTime"`} {
+		if content := m.read("model/posts.gen.go"); !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	p := q.Post
+	check(p.WithContext(gen.WithOperator(ctx, "modi")).Create(&model.Post{ID: 1, Title: "gen"}) == nil, "create fail")
+	post, err := p.WithContext(ctx).Take()
+	check(err == nil && post.Author == "modi" && post.Editor == "modi" && !post.InsertedAt.IsZero() && !post.ModifiedAt.IsZero(),
+		"create should fill audit columns, got %+v %v", post, err)
+	_, err = p.WithContext(gen.WithOperator(ctx, "gorm")).Where(p.ID.Eq(1)).Update(p.Title, "gorm")
+	check(err == nil, "update fail: %v", err)
+	updated, err := p.WithContext(ctx).Take()
+	check(err == nil && updated.Author == "modi" && updated.Editor == "gorm" && !updated.ModifiedAt.Before(post.ModifiedAt),
+		"update should fill editor and modified_at, got %+v %v", updated, err)`,
+		"gorm.io/gen", "example.com/app/dao/model")
+}
+
+func TestProtoExport(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, nickname TEXT, score REAL, created_at DATETIME NOT NULL)")
+	config := Config{FieldNullable: true}
+	config.WithProtoExport(filepath.Join(m.dir, "proto"), true)
+	m.generate(config)
+	content := m.read("../proto/users.proto")
+	for _, expect := range []string{
+		`import "google/protobuf/empty.proto";
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/wrappers.proto";`,
+		`message User {
+  int64 id = 1;
+  string name = 2;
+  google.protobuf.StringValue nickname = 3;
+  google.protobuf.DoubleValue score = 4;
+  google.protobuf.Timestamp created_at = 5;
+}`,
+		"rpc DeleteUser(DeleteUserRequest) returns (google.protobuf.Empty);",
+		`message GetUserRequest {
+  int64 id = 1;
+}`,
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("proto should contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	config = Config{}
+	config.WithProtoExport(filepath.Join(m.dir, "proto"), false)
+	m.generate(config)
+	if content = m.read("../proto/users.proto"); strings.Contains(content, "service") || strings.Contains(content, "empty.proto") {
+		t.Errorf("proto without service should contain message only, got:\n%s", content)
+	}
+}
+
+func TestOpenAPIExport(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT NOT NULL, amount REAL NOT NULL, note TEXT, paid_at DATETIME)")
+	config := Config{FieldNullable: true}
+	config.WithOpenAPIExport(filepath.Join(m.dir, "openapi.json"))
+	m.generate(config, FieldGORMTag("status", func(tag field.GormTag) field.GormTag { return tag.Set("type", "enum('pending','paid')") }))
+
+	var doc openAPIComponents
+	if err := json.Unmarshal([]byte(m.read("../openapi.json")), &doc); err != nil {
+		t.Fatalf("decode openapi fail: %s", err)
+	}
+	expect := map[string]*openAPISchema{
+		"id":      {Type: "integer", Format: "int64"},
+		"status":  {Type: "string", Enum: []interface{}{"pending", "paid"}},
+		"amount":  {Type: "number", Format: "double"},
+		"note":    {Type: "string", Nullable: true},
+		"paid_at": {Type: "string", Format: "date-time", Nullable: true},
+	}
+	if order := doc.Components.Schemas["Order"]; order == nil || !reflect.DeepEqual(order.Properties, expect) {
+		t.Errorf("schema of Order should have properties %v, got: %+v", expect, order)
+	}
+
+	config = Config{}
+	config.WithOpenAPIExport(filepath.Join(m.dir, "openapi.yaml"))
+	m.generate(config)
+	if content := m.read("../openapi.yaml"); !strings.HasPrefix(content, "components:\n  schemas:\n    Order:\n") {
+		t.Errorf("openapi of .yaml should be written in YAML, got:\n%s", content)
+	}
+}
+
+func TestTypeScriptExport(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, user_name TEXT NOT NULL, nickname TEXT, payload BLOB, created_at DATETIME NOT NULL)")
+	config := Config{FieldNullable: true}
+	config.WithJSONTagNameStrategy(func(column string) string {
+		if column == "nickname" {
+			return column + ",omitempty"
+		}
+		return strings.ReplaceAll(column, "_n", "N")
+	})
+	config.WithTypeScriptExport(filepath.Join(m.dir, "ts"))
+	m.generate(config)
+	expect := `/** User mapped from table <users> */
+export interface User {
+  id: number;
+  userName: string;
+  nickname?: string | null;
+  payload: string | null;
+  created_at: string;
+}
+`
+	if content := m.read("../ts/users.d.ts"); !strings.HasSuffix(content, expect) {
+		t.Errorf("typescript should end with %q, got:\n%s", expect, content)
+	}
+}
+
+func TestHandlerExport(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER NOT NULL)")
+	m.require("github.com/gin-gonic/gin@v1.9.1", "github.com/labstack/echo/v4@v4.10.2")
+	config := Config{}
+	config.WithHandlerExport(filepath.Join(m.dir, "dao", "ginhandler"), "gin")
+	config.WithHandlerExport(filepath.Join(m.dir, "dao", "echohandler"), "echo")
+	m.generate(config)
+
+	requests := `
+	check(db.Exec("DELETE FROM users").Error == nil, "clear users fail")
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w, req := httptest.NewRecorder(), httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		server.ServeHTTP(w, req)
+		return w
+	}
+	w := serve("POST", "/users", "{\"name\":\"modi\",\"age\":18}")
+	check(w.Code == http.StatusCreated, "create should be 201, got %d %s", w.Code, w.Body)
+	w = serve("POST", "/users", "{\"name\":\"gorm\",\"age\":20}")
+	check(w.Code == http.StatusCreated, "create should be 201, got %d %s", w.Code, w.Body)
+	w = serve("GET", "/users?age=18", "")
+	check(w.Code == http.StatusOK && strings.Contains(w.Body.String(), "\"total\":1") && strings.Contains(w.Body.String(), "modi"), "list filtered by age should find modi, got %d %s", w.Code, w.Body)
+	w = serve("GET", "/users/1", "")
+	check(w.Code == http.StatusOK && strings.Contains(w.Body.String(), "modi"), "get should find modi, got %d %s", w.Code, w.Body)
+	w = serve("GET", "/users/9", "")
+	check(w.Code == http.StatusNotFound, "get of missing record should be 404, got %d %s", w.Code, w.Body)
+	w = serve("GET", "/users/x", "")
+	check(w.Code == http.StatusBadRequest, "get of invalid id should be 400, got %d %s", w.Code, w.Body)
+	w = serve("PUT", "/users/1", "{\"age\":19}")
+	check(w.Code == http.StatusNoContent, "update should be 204, got %d %s", w.Code, w.Body)
+	w = serve("PUT", "/users/9", "{\"age\":19}")
+	check(w.Code == http.StatusNotFound, "update of missing record should be 404, got %d %s", w.Code, w.Body)
+	w = serve("DELETE", "/users/2", "")
+	check(w.Code == http.StatusNoContent, "delete should be 204, got %d %s", w.Code, w.Body)
+	w = serve("GET", "/users?page_size=1", "")
+	check(w.Code == http.StatusOK && strings.Contains(w.Body.String(), "\"total\":1") && strings.Contains(w.Body.String(), "\"age\":19"), "list should find updated modi only, got %d %s", w.Code, w.Body)`
+
+	m.run(`
+	gin.SetMode(gin.ReleaseMode)
+	server := gin.New()
+	ginhandler.NewUserHandler(q).Register(server)`+requests,
+		"net/http", "net/http/httptest", "strings", "github.com/gin-gonic/gin", "example.com/app/dao/ginhandler")
+	m.run(`
+	server := echo.New()
+	echohandler.NewUserHandler(q).Register(server.Group(""))`+requests,
+		"net/http", "net/http/httptest", "strings", "github.com/labstack/echo/v4", "example.com/app/dao/echohandler")
+}
+
+func TestDTOExport(t *testing.T) {
+	m := newGenModule(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, password_hash TEXT NOT NULL, note TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL, note TEXT, password_hash TEXT)")
+	config := Config{}
+	config.WithDTOExport(filepath.Join(m.dir, "dao", "dto"), "password_hash", "orders.note")
+	m.generate(config)
+
+	user, order := m.read("dto/users.gen.go"), m.read("dto/orders.gen.go")
+	for _, unexpected := range []string{"PasswordHash"} {
+		if strings.Contains(user, unexpected) || strings.Contains(order, unexpected) {
+			t.Errorf("dto shouldn't contain excluded column %q, got:\n%s\n%s", unexpected, user, order)
+		}
+	}
+	if !strings.Contains(user, "Note string `json:\"note\"`") {
+		t.Errorf("dto of users should keep note, got:\n%s", user)
+	}
+	if strings.Contains(order, "Note") {
+		t.Errorf("dto of orders shouldn't contain note excluded by table, got:\n%s", order)
+	}
+
+	m.run(`
+	d := dto.UserToDTO(&model.User{ID: 1, Name: "modi", PasswordHash: "secret", Note: "vip"})
+	check(d.ID == 1 && d.Name == "modi" && d.Note == "vip", "UserToDTO should copy fields, got %+v", d)
+	content, err := json.Marshal(d)
+	check(err == nil && string(content) == "{\"id\":1,\"name\":\"modi\",\"note\":\"vip\"}", "json of dto should omit password, got %s %v", content, err)
+	u := dto.UserFromDTO(d)
+	check(u.ID == 1 && u.Name == "modi" && u.PasswordHash == "" && u.Note == "vip", "UserFromDTO should copy fields, got %+v", u)
+	check(dto.UserToDTO(nil) == nil && dto.UserFromDTO(nil) == nil, "converters should keep nil")
+	list := dto.OrderListToDTO([]*model.Order{{ID: 1, UserID: 2}, {ID: 2, UserID: 3}})
+	check(len(list) == 2 && list[0].UserID == 2 && list[1].ID == 2, "OrderListToDTO should convert each order, got %+v", list)`,
+		"encoding/json", "example.com/app/dao/dto", "example.com/app/dao/model")
+}
+
+func TestERDExport(t *testing.T) {
+	m := newGenModule(t,
+		"CREATE TABLE companies (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE UNIQUE INDEX idx_companies_name ON companies (name)",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, company_id INTEGER NOT NULL, name TEXT NOT NULL)",
+		"CREATE INDEX idx_users_name ON users (name)")
+	config := Config{FieldWithIndexTag: true}
+	config.WithERDExport(filepath.Join(m.dir, "erd.mmd"), "mermaid")
+	config.WithERDExport(filepath.Join(m.dir, "erd.dot"), "graphviz")
+	m.generate(config)
+
+	expect := `erDiagram
+    companies {
+        int64 id PK
+        string name UK "idx: idx_companies_name"
+    }
+    users {
+        int64 id PK
+        int32 company_id FK
+        string name "idx: idx_users_name"
+    }
+    companies ||--o{ users : "company_id"
+`
+	if content := m.read("../erd.mmd"); !strings.HasSuffix(content, expect) {
+		t.Errorf("mermaid diagram should end with %q, got:\n%s", expect, content)
+	}
+	content := m.read("../erd.dot")
+	for _, expect := range []string{
+		`<tr><td align="left">company_id</td><td align="left">int32</td><td align="left">FK</td></tr>`,
+		`<tr><td align="left">name</td><td align="left">string</td><td align="left">idx: idx_users_name</td></tr>`,
+		`"companies" -> "users" [label="company_id"];`,
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("graphviz diagram should contain %q, got:\n%s", expect, content)
+		}
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	m := newGenModule(t,
+		"CREATE TABLE companies (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, company_id INTEGER NOT NULL, name TEXT NOT NULL DEFAULT 'modi', age INTEGER)",
+		"CREATE INDEX idx_users_name ON users (name)")
+	schemaFile := filepath.Join(m.dir, "schema.json")
+	config := Config{FieldNullable: true, FieldWithIndexTag: true, FieldWithTypeTag: true}
+	config.WithSchemaJSONExport(schemaFile)
+	m.generate(config)
+
+	var s struct {
+		Dialect string `json:"dialect"`
+		Tables  []struct {
+			Name        string                   `json:"name"`
+			Columns     []map[string]interface{} `json:"columns"`
+			Indexes     []map[string]interface{} `json:"indexes"`
+			ForeignKeys []map[string]interface{} `json:"foreignKeys"`
+		} `json:"tables"`
+	}
+	if err := json.Unmarshal([]byte(m.read("../schema.json")), &s); err != nil {
+		t.Fatalf("parse schema json fail: %s", err)
+	}
+	if s.Dialect != "sqlite" || len(s.Tables) != 2 || s.Tables[1].Name != "users" {
+		t.Fatalf("schema should have tables companies and users of sqlite, got %+v", s)
+	}
+	users := s.Tables[1]
+	if len(users.Columns) != 4 || users.Columns[2]["name"] != "name" || users.Columns[2]["nullable"] != false || users.Columns[3]["nullable"] != true {
+		t.Errorf("schema should have columns of users with nullability, got %+v", users.Columns)
+	}
+	if len(users.Indexes) != 1 || users.Indexes[0]["name"] != "idx_users_name" {
+		t.Errorf("schema should have index of users, got %+v", users.Indexes)
+	}
+	expectFK := map[string]interface{}{"column": "company_id", "refTable": "companies", "refColumn": "id"}
+	if len(users.ForeignKeys) != 1 || !reflect.DeepEqual(users.ForeignKeys[0], expectFK) {
+		t.Errorf("schema should have foreign key of users detected by column name, got %+v", users.ForeignKeys)
+	}
+
+	// code generated from schema json without tables in db is the same as generated from db
+	offline := newGenModule(t)
+	config.OutPath, config.Verify = filepath.Join(offline.dir, "dao", "query"), verifyBuild
+	g := NewGenerator(config)
+	g.UseDB(offline.db)
+	g.UseSchemaJSON(schemaFile)
+	g.ApplyBasic(g.GenerateAllTable()...)
+	g.Execute()
+	for _, name := range []string{"model/companies.gen.go", "model/users.gen.go", "query/users.gen.go"} {
+		if expect, content := m.read(name), offline.read(name); content != expect {
+			t.Errorf("%s generated from schema json should be the same as from db, expect:\n%s\ngot:\n%s", name, expect, content)
+		}
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	m := newGenModule(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL)")
+	config := Config{LockFile: filepath.Join(m.dir, "gen.lock")}
+	files := []string{"model/users.gen.go", "query/users.gen.go", "model/orders.gen.go", "query/orders.gen.go"}
+	// edit marks generated files, the mark is kept if the file is skipped by next generation
+	edit := func() {
+		for _, name := range files {
+			if err := os.WriteFile(filepath.Join(m.dir, "dao", name), []byte(m.read(name)+"// edited\n"), 0o644); err != nil {
+				t.Fatalf("edit %s fail: %s", name, err)
+			}
+		}
+	}
+	expectSkipped := func(step string, skipped ...string) {
+		t.Helper()
+		for _, name := range files {
+			expect := false
+			for _, s := range skipped {
+				expect = expect || s == name
+			}
+			if edited := strings.HasSuffix(m.read(name), "// edited\n"); edited != expect {
+				t.Errorf("%s: %s should be skipped(%v), got %v", step, name, expect, edited)
+			}
+		}
+	}
+
+	m.generate(config)
+	edit()
+	m.generate(config)
+	expectSkipped("unchanged", files...)
+
+	if err := m.db.Exec("ALTER TABLE users ADD COLUMN age INTEGER").Error; err != nil {
+		t.Fatalf("alter table fail: %s", err)
+	}
+	m.generate(config)
+	expectSkipped("schema of users changed", "model/orders.gen.go", "query/orders.gen.go")
+
+	edit()
+	config.TableModelOpts = map[string][]ModelOpt{"orders": {FieldType("user_id", "int64")}}
+	m.generate(config)
+	expectSkipped("options of orders added")
+	if content := m.read("model/orders.gen.go"); !strings.Contains(content, "UserID int64") {
+		t.Errorf("model of orders should be generated with option, got:\n%s", content)
+	}
+
+	edit()
+	m.generate(config)
+	expectSkipped("options unchanged", files...)
+
+	// options without effect on fields of models still change fingerprint of config
+	config.TableModelOpts["orders"] = append(config.TableModelOpts["orders"], FieldIgnore("missing"))
+	m.generate(config)
+	expectSkipped("options of orders changed")
+
+	edit()
+	config.ForceGenerate = true
+	m.generate(config)
+	expectSkipped("force generate")
+
+	if _, err := fingerprint(func() {}); err == nil {
+		t.Errorf("fingerprint of func should fail")
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	var ddl []string
+	for i := 0; i < 12; i++ {
+		ddl = append(ddl, fmt.Sprintf("CREATE TABLE t%02d (id INTEGER PRIMARY KEY, name TEXT, c%d INTEGER)", i, i))
+	}
+	m := newGenModule(t, ddl...)
+
+	g := NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), Concurrency: 4})
+	g.UseDB(m.db)
+	tables := []string{"t11", "t03", "t07", "t00", "t10", "t05", "t01", "t09", "t02", "t08", "t04", "t06"}
+	for i, model := range g.GenerateModels(tables) {
+		if meta := model.(*generate.QueryStructMeta); meta.TableName != tables[i] {
+			t.Errorf("models should be in order of tables, got %s at %d", meta.TableName, i)
+		}
+	}
+
+	m.generate(Config{Concurrency: 1})
+	serial := m.files()
+	m.generate(Config{Concurrency: 8})
+	if parallel := m.files(); !reflect.DeepEqual(parallel, serial) {
+		t.Errorf("code generated concurrently should be the same as generated serially")
+	}
+	if len(serial) != 25 { // model and query files of tables, and gen.go
+		t.Errorf("all tables should be generated, got %d files", len(serial))
+	}
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	tables := []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER, created_at DATETIME)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, paid_at DATETIME)",
+	}
+	indexes := []string{
+		"CREATE INDEX idx_users_name_age ON users (name, age)",
+		"CREATE INDEX idx_users_age ON users (age)",
+		"CREATE UNIQUE INDEX idx_users_name ON users (name)",
+	}
+	m := newGenModule(t, append(tables, indexes...)...)
+	m.generate(Config{FieldWithIndexTag: true, FieldWithTypeTag: true})
+	// tables and indexes created in another order are generated into the same code
+	reversed := newGenModule(t, tables[1], tables[0], indexes[2], indexes[1], indexes[0])
+	reversed.generate(Config{FieldWithIndexTag: true, FieldWithTypeTag: true})
+	if expect, got := m.files(), reversed.files(); !reflect.DeepEqual(got, expect) {
+		for name, content := range expect {
+			if got[name] != content {
+				t.Errorf("%s should be the same regardless of order of tables and indexes, expect:\n%s\ngot:\n%s", name, content, got[name])
+			}
+		}
+	}
+
+	expect := `gorm:"column:age;type:INTEGER;index:idx_users_age,priority:1;index:idx_users_name_age,priority:2"`
+	if content := m.read("model/users.gen.go"); !strings.Contains(content, expect) {
+		t.Errorf("indexes of column should be sorted by name, expect %s, got:\n%s", expect, content)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	m := newGenModule(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER)")
+	config := Config{Mode: WithFakeDAO, WithUnitTest: true, LockFile: filepath.Join(m.dir, "gen.lock")}
+	m.generate(config)
+	handWritten := "package model\n\n// Custom hand-written code\ntype Custom struct{}\n"
+	if err := os.WriteFile(filepath.Join(m.dir, "dao", "model", "custom.gen.go"), []byte(handWritten), 0o644); err != nil {
+		t.Fatalf("write hand-written file fail: %s", err)
+	}
+	if err := m.db.Exec("DROP TABLE orders").Error; err != nil {
+		t.Fatalf("drop table fail: %s", err)
+	}
+
+	// stale files are kept without Prune
+	m.generate(config)
+	if _, ok := m.files()["model/orders.gen.go"]; !ok {
+		t.Errorf("files of dropped table should be kept without Prune")
+	}
+
+	config.Prune = true
+	m.generate(config)
+	files := m.files()
+	for _, name := range []string{"model/orders.gen.go", "query/orders.gen.go", "query/orders.gen_test.go", "query/orders.fake.gen.go"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("%s of dropped table should be pruned", name)
+		}
+	}
+	for _, name := range []string{"model/users.gen.go", "query/users.gen.go", "query/users.gen_test.go", "query/users.fake.gen.go", "query/gen.go"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s of table should be kept", name)
+		}
+	}
+	if files["model/custom.gen.go"] != handWritten {
+		t.Errorf("hand-written file without generated mark should be kept")
+	}
+	if lock := m.read("../gen.lock"); strings.Contains(lock, "orders") {
+		t.Errorf("lock file should drop pruned table, got:\n%s", lock)
+	}
+}
+
+func TestModelTemplateDir(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	dir := filepath.Join(m.dir, "tmpl")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("make dir fail: %s", err)
+	}
+	writeModelTemplate := func(comment string) {
+		content := `// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package {{.StructInfo.Package}}
+
+// {{.ModelStructName}} ` + comment + ` of table {{.TableName}}
+type {{.ModelStructName}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}} ` + "`{{.Tags}}`" + `
+{{end}}}
+
+{{template "table_name" .}}
+`
+		if err := os.WriteFile(filepath.Join(dir, "model.tmpl"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write template fail: %s", err)
+		}
+	}
+	// templates can use templates defined in other files of dir
+	tableName := `{{define "table_name"}}// TableName{{.ModelStructName}} table name of {{.ModelStructName}}
+const TableName{{.ModelStructName}} = "{{.TableName}}"{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "table_name.tmpl"), []byte(tableName), 0o644); err != nil {
+		t.Fatalf("write template fail: %s", err)
+	}
+	writeModelTemplate("custom model")
+
+	config := Config{ModelTemplateDir: dir, LockFile: filepath.Join(m.dir, "gen.lock")}
+	m.generate(config)
+	expect := `// User custom model of table users
+type User struct {
+	ID   int64  ` + "`gorm:\"column:id;primaryKey;autoIncrement:true\" json:\"id\"`" + `
+	Name string ` + "`gorm:\"column:name;not null\" json:\"name\"`" + `
+}
+
+// TableNameUser table name of User
+const TableNameUser = "users"
+`
+	if content := m.read("model/users.gen.go"); !strings.Contains(content, expect) {
+		t.Errorf("model should be rendered by custom template, expect:\n%s\ngot:\n%s", expect, content)
+	}
+	m.run(`
+	check(q.User.WithContext(ctx).Create(&model.User{Name: "modi"}) == nil, "create by custom model fail")
+	user, err := q.User.WithContext(ctx).Take()
+	check(err == nil && user.Name == "modi", "take by custom model should find created user, got %+v %v", user, err)`, "example.com/app/dao/model")
+
+	// changes of templates are detected by lock file
+	writeModelTemplate("changed model")
+	m.generate(config)
+	if content := m.read("model/users.gen.go"); !strings.Contains(content, "// User changed model of table users") {
+		t.Errorf("model should be regenerated once template changed, got:\n%s", content)
+	}
+}
+
+func TestQueryTemplateDir(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	dir := filepath.Join(m.dir, "tmpl")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("make dir fail: %s", err)
+	}
+	extra := `
+// Label label of table {{.TableName}}
+func (x {{.QueryStructName}}) Label() string { return "{{upper .TableName}}" }
+`
+	if err := os.WriteFile(filepath.Join(dir, "query_extra.tmpl"), []byte(extra), 0o644); err != nil {
+		t.Fatalf("write template fail: %s", err)
+	}
+	config := Config{QueryTemplateDir: dir}
+	config.WithTemplateFuncs(template.FuncMap{"upper": strings.ToUpper})
+	m.generate(config)
+	if content := m.read("query/users.gen.go"); !strings.Contains(content, "// Label label of table users") {
+		t.Errorf("query should contain extra code, got:\n%s", content)
+	}
+	m.run(`
+	check(q.User.Label() == "USERS", "extra method should be rendered with template funcs, got %s", q.User.Label())
+	check(q.User.WithContext(ctx).Create(&model.User{Name: "modi"}) == nil, "create by built-in CRUD methods fail")`, "example.com/app/dao/model")
+}
+
+func TestFileHeader(t *testing.T) {
+	for _, tt := range []struct{ header, buildTags, expect string }{
+		{"", "", ""},
+		{"Copyright 2024 Gen", "", "// Copyright 2024 Gen\n\n"},
+		{"  Copyright\n\n// Licensed under MIT  \n", "", "// Copyright\n//\n// Licensed under MIT\n\n"},
+		{"/* Copyright */", "!integration", "/* Copyright */\n\n//go:build !integration\n\n"},
+		{"", "linux && amd64", "//go:build linux && amd64\n\n"},
+	} {
+		if got := string(fileHeader(tt.header, tt.buildTags)); got != tt.expect {
+			t.Errorf("header of %q with tags %q should be %q, got %q", tt.header, tt.buildTags, tt.expect, got)
+		}
+	}
+
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	headerFile := filepath.Join(m.dir, "header.txt")
+	if err := os.WriteFile(headerFile, []byte("Copyright 2024 Gen\nAll rights reserved.\n"), 0o644); err != nil {
+		t.Fatalf("write header fail: %s", err)
+	}
+	m.generate(Config{FileHeader: headerFile, BuildTags: "!integration", WithUnitTest: true})
+	expect := "// Copyright 2024 Gen\n// All rights reserved.\n\n//go:build !integration\n\n"
+	for name, content := range m.files() {
+		if strings.HasSuffix(name, ".go") && !strings.HasPrefix(content, expect) {
+			t.Errorf("%s should start with header and build constraint, got:\n%s", name, content)
+		}
+	}
+
+	cmd := exec.Command("go", "build", "-tags", "integration", "./dao/...")
+	cmd.Dir = m.dir
+	if output, err := cmd.CombinedOutput(); err != nil || !strings.Contains(string(output), "matched no packages") {
+		t.Errorf("generated files should be excluded by build constraint, got %v %s", err, output)
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)")
+	if opts := gofumptOptions(filepath.Join(m.dir, "dao", "query")); opts.ModulePath != "example.com/app" || opts.LangVersion != "1.18" {
+		t.Errorf("gofumpt options should be read from go.mod of module, got %+v", opts)
+	}
+
+	for _, formatter := range []string{"gofmt", "gofumpt"} {
+		m.generate(Config{Formatter: formatter, WithUnitTest: true})
+		for name, content := range m.files() {
+			if !strings.HasSuffix(name, ".go") {
+				continue
+			}
+			formatted, err := format.Source([]byte(content))
+			if formatter == "gofumpt" {
+				formatted, err = gofumpt.Source([]byte(content), gofumpt.Options{ModulePath: "example.com/app", LangVersion: "1.18"})
+			}
+			if err != nil || string(formatted) != content {
+				t.Errorf("%s should be formatted by %s, got %v:\n%s", name, formatter, err, content)
+			}
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("generator of unknown formatter should panic")
+		}
+	}()
+	NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), Formatter: "prettier"})
+}
+
+func TestProvenance(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	m.generate(Config{Provenance: true})
+	pattern := regexp.MustCompile(`^// Generator: gorm\.io/gen (\S+)
+// Config: ([0-9a-f]{64})
+// Table: users
+// Schema: ([0-9a-f]{64})
+// Generated at: \d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ
+`)
+	model, query := m.read("model/users.gen.go"), m.read("query/users.gen.go")
+	modelSums, querySums := pattern.FindStringSubmatch(model), pattern.FindStringSubmatch(query)
+	if modelSums == nil || querySums == nil {
+		t.Fatalf("files of table should start with provenance, got:\n%s\n%s", model, query)
+	}
+	if modelSums[2] != querySums[2] || modelSums[3] != querySums[3] {
+		t.Errorf("files of table should have the same fingerprints, got %v and %v", modelSums[2:], querySums[2:])
+	}
+	if content := m.read("query/gen.go"); strings.Contains(content, "// Table:") || !strings.Contains(content, "// Config: "+modelSums[2]) {
+		t.Errorf("query file of all tables should have provenance without table, got:\n%s", content)
+	}
+
+	m.generate(Config{Provenance: true, OmitTimestamp: true})
+	if content := m.read("model/users.gen.go"); strings.Contains(content, "// Generated at:") {
+		t.Errorf("provenance should omit timestamp, got:\n%s", content)
+	}
+	if err := m.db.Exec("ALTER TABLE users ADD COLUMN age INTEGER").Error; err != nil {
+		t.Fatalf("alter table fail: %s", err)
+	}
+	m.generate(Config{Provenance: true})
+	if sums := pattern.FindStringSubmatch(m.read("model/users.gen.go")); sums == nil || sums[2] != modelSums[2] || sums[3] == modelSums[3] {
+		t.Errorf("schema fingerprint should change with schema only, got %v and %v", sums, modelSums)
+	}
+
+	m.generate(Config{})
+	if content := m.read("model/users.gen.go"); strings.Contains(content, "// Generator:") {
+		t.Errorf("provenance should be absent by default, got:\n%s", content)
+	}
+}
+
+func TestEnumType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT NOT NULL, review TEXT)")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"orders.status": columnType("enum('pending','paid','in-review')"),
+		"orders.review": columnType("enum('good','good ','bad')"),
+	})
+	m.generate(Config{FieldNullable: true})
+	if content := m.read("model/orders.gen.go"); !strings.Contains(content, "Status string ") {
+		t.Errorf("enum column should be string without FieldWithEnumType, got:\n%s", content)
+	}
+
+	m.generate(Config{FieldWithEnumType: true, FieldNullable: true})
+	content := m.read("model/orders.gen.go")
+	for _, expect := range []string{
+		"Status OrderStatus ", "Review *OrderReview ", "type OrderStatus string",
+		`OrderStatusInReview OrderStatus = "in-review"`,
+		// constants of labels duplicated after converted to identifier are numbered
+		`OrderReviewGood  OrderReview = "good"`, `OrderReviewGood2 OrderReview = "good "`,
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	check(model.OrderStatusPaid.Valid() && !model.OrderStatus("refunded").Valid(), "Valid should check enum values")
+	review := model.OrderReviewGood2
+	o := q.Order.WithContext(ctx)
+	check(o.Create(&model.Order{Status: model.OrderStatusPaid, Review: &review}) == nil, "create order fail")
+	order, err := o.Where(q.Order.Status.Eq(string(model.OrderStatusPaid))).Take()
+	check(err == nil && order.Status == model.OrderStatusPaid && *order.Review == model.OrderReviewGood2, "enum values should be read back, got %+v %v", order, err)`,
+		"example.com/app/dao/model")
+}
+
+func TestCommentEnum(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, status INTEGER NOT NULL, level INTEGER, name TEXT)")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"users.status": columnComment("status: 1-active, 2:disabled，-1=deleted 2-duplicated"),
+		"users.level":  columnComment("level: 1-basic"), // single value isn't an enum
+		"users.name":   columnComment("name: 1-a 2-b"),  // only integer columns are enums
+	})
+	m.generate(Config{FieldWithCommentEnum: true})
+	content := m.read("model/users.gen.go")
+	for _, expect := range []string{
+		"Status UserStatus ", "type UserStatus int32",
+		"UserStatusActive   UserStatus = 1", "UserStatusDisabled UserStatus = 2", "UserStatusDeleted  UserStatus = -1",
+		"Level  int32 ", "Name   string ",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	if strings.Contains(content, "Duplicated") {
+		t.Errorf("duplicated value should be ignored, got:\n%s", content)
+	}
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Status: model.UserStatusDisabled}) == nil, "create user fail")
+	user, err := u.Where(q.User.Status.Eq(int32(model.UserStatusDisabled))).Take()
+	check(err == nil && user.Status == model.UserStatusDisabled && user.Status.Valid() && !model.UserStatus(3).Valid(), "comment enum should be read back, got %+v %v", user, err)`,
+		"example.com/app/dao/model")
+}
+
+func TestSetType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE posts (id INTEGER PRIMARY KEY, tags TEXT NOT NULL, flags TEXT)")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"posts.tags":  columnType("set('go','gorm','sql')"),
+		"posts.flags": columnType("set('top','hot')"),
+	})
+	m.generate(Config{FieldWithEnumType: true, FieldNullable: true})
+	content := m.read("model/posts.gen.go")
+	for _, expect := range []string{"Tags  PostTags ", "Flags *PostFlags ", "type PostTags []string", `PostTagsGorm = "gorm"`} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	p := q.Post.WithContext(ctx)
+	flags := model.PostFlags{}
+	check(p.Create(&model.Post{Tags: model.PostTags{model.PostTagsGo, model.PostTagsSql}, Flags: &flags}) == nil, "create post fail")
+	post, err := p.Take()
+	check(err == nil && len(post.Tags) == 2 && post.Tags.Contains(model.PostTagsSql) && !post.Tags.Contains(model.PostTagsGorm), "set values should be read back, got %+v %v", post, err)
+	check(post.Flags != nil && len(*post.Flags) == 0, "empty set should be read as empty, got %v", post.Flags)
+	var raw string
+	check(db.Raw("SELECT tags FROM posts").Scan(&raw).Error == nil && raw == "go,sql", "set should be stored comma-separated, got %s", raw)
+	check(post.Tags.Valid() && !(model.PostTags{"go", "rust"}).Valid(), "Valid should check set values")`,
+		"example.com/app/dao/model")
+}
+
+func TestArrayType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE posts (id INTEGER PRIMARY KEY, tags TEXT NOT NULL, scores TEXT, matrix TEXT, ids TEXT)")
+	m.require("github.com/lib/pq@v1.10.9")
+	m.useSchema("postgres", map[string]func(*model.ColumnSchema){
+		"posts.tags":   func(col *model.ColumnSchema) { col.DatabaseType, col.GoType = "character varying(64)[]", "" },
+		"posts.scores": columnType("_int4"),
+		"posts.matrix": columnType("point[]"), // unknown element type is generated by DataTypeMap
+		"posts.ids":    columnType("bigint[]"),
+	})
+	config := Config{ArrayTypeMap: map[string]string{"bigint": "pq.Int32Array"}}
+	m.generate(config)
+	content := m.read("model/posts.gen.go")
+	for _, expect := range []string{"Tags   pq.StringArray ", "Scores pq.Int32Array ", "Matrix string ", "Ids    pq.Int32Array "} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	p := q.Post.WithContext(ctx)
+	check(p.Create(&model.Post{Tags: pq.StringArray{"go", "gorm"}, Scores: pq.Int32Array{1, 2}}) == nil, "create post fail")
+	post, err := p.Take()
+	check(err == nil && len(post.Tags) == 2 && post.Tags[1] == "gorm" && len(post.Scores) == 2 && post.Scores[1] == 2, "arrays should be read back, got %+v %v", post, err)`,
+		"github.com/lib/pq", "example.com/app/dao/model")
+}
+
+func TestJSONType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, profile JSON, settings JSON)")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"users.profile":  columnType("json"),
+		"users.settings": columnType("json"),
+	})
+	m.generate(Config{})
+	if content := m.read("model/users.gen.go"); !strings.Contains(content, "Profile  string ") {
+		t.Errorf("json column should be string without FieldWithJSONType, got:\n%s", content)
+	}
+
+	m.generate(Config{FieldWithJSONType: true}, FieldJSONType("settings", "map[string]string"))
+	content := m.read("model/users.gen.go")
+	for _, expect := range []string{"Profile  datatypes.JSON ", "Settings map[string]string ", `serializer:json`} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	if content = m.read("query/users.gen.go"); !strings.Contains(content, "ProfileJSONQuery()") || !strings.Contains(content, "SettingsJSONQuery()") {
+		t.Errorf("query should contain JSON query helpers, got:\n%s", content)
+	}
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Profile: datatypes.JSON(`+"`"+`{"name":"gen"}`+"`"+`), Settings: map[string]string{"theme": "dark"}}) == nil, "create user fail")
+	check(u.Create(&model.User{Profile: datatypes.JSON(`+"`"+`{}`+"`"+`)}) == nil, "create user fail")
+	users, err := u.Where(gen.Cond(q.User.ProfileJSONQuery().HasKey("name"))...).Find()
+	check(err == nil && len(users) == 1 && users[0].Settings["theme"] == "dark", "json should be queried and read back, got %+v %v", users, err)`,
+		"gorm.io/gen", "gorm.io/datatypes", "example.com/app/dao/model")
+}
+
+func TestUUIDType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE accounts (id TEXT NOT NULL PRIMARY KEY, owner BLOB, ref TEXT)")
+	m.require("github.com/google/uuid@v1.3.0")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"accounts.id":    columnType("char(36)"),
+		"accounts.owner": columnType("binary(16)"),
+		"accounts.ref":   columnType("char(36)"),
+	})
+	config := Config{FieldWithUUIDType: true, UUIDColumnTypes: []string{"char(36)", "binary(16)"}, UUIDHook: true, FieldNullable: true}
+	m.generate(config)
+	content := m.read("model/accounts.gen.go")
+	for _, expect := range []string{
+		"ID    uuid.UUID ", "Owner *AccountOwner ", "Ref   *uuid.UUID ", "type AccountOwner uuid.UUID",
+		"func (a *Account) BeforeCreate(tx *gorm.DB) error",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	owner := model.AccountOwner(uuid.New())
+	a := q.Account.WithContext(ctx)
+	check(a.Create(&model.Account{Owner: &owner}) == nil, "create account fail")
+	account, err := a.Take()
+	check(err == nil && account.ID != uuid.Nil && *account.Owner == owner && account.Ref == nil, "uuid should be populated and read back, got %+v %v", account, err)
+	var size int
+	check(q.Account.WithContext(ctx).UnderlyingDB().Raw("SELECT length(owner) FROM accounts").Scan(&size).Error == nil && size == 16, "binary uuid should be stored as 16 bytes, got %d", size)`,
+		"github.com/google/uuid", "example.com/app/dao/model")
+}
+
+func TestDecimalType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE invoices (id INTEGER PRIMARY KEY, amount TEXT NOT NULL, tax TEXT, rate REAL)")
+	m.require("github.com/shopspring/decimal@v1.3.1")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"invoices.amount": columnType("decimal(18,4)"),
+		"invoices.tax":    columnType("numeric(18,4)"),
+		"invoices.rate":   columnType("decimal(5,2)"),
+	})
+	m.generate(Config{})
+	if content := m.read("model/invoices.gen.go"); !strings.Contains(content, "Amount float64 ") {
+		t.Errorf("decimal column should be float64 without DecimalType, got:\n%s", content)
+	}
+
+	// column type mapped by DataTypeMap is left alone
+	config := Config{DecimalType: "shopspring", FieldNullable: true}
+	config.WithDataTypeMap(map[string]func(gorm.ColumnType) string{"numeric": func(gorm.ColumnType) string { return "float64" }})
+	m.generate(config)
+	content := m.read("model/invoices.gen.go")
+	for _, expect := range []string{"Amount decimal.Decimal ", `type:decimal(18,4)`, "Tax    *float64 ", "Rate   *decimal.Decimal "} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	i := q.Invoice.WithContext(ctx)
+	amount := decimal.RequireFromString("12345678901234.5678")
+	check(i.Create(&model.Invoice{Amount: amount}) == nil, "create invoice fail")
+	invoice, err := i.Take()
+	check(err == nil && invoice.Amount.Equal(amount) && invoice.Tax == nil && invoice.Rate == nil, "decimal should keep precision, got %+v %v", invoice, err)`,
+		"github.com/shopspring/decimal", "example.com/app/dao/model")
+
+	m.generate(Config{DecimalType: "string"})
+	if content = m.read("model/invoices.gen.go"); !strings.Contains(content, "Amount string ") || !strings.Contains(content, "Tax    string ") {
+		t.Errorf("decimal column should be string, got:\n%s", content)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("generator of unknown decimal type should panic")
+		}
+	}()
+	NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), DecimalType: "big"})
+}
+
+func TestNullableStyle(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER, score REAL, born DATETIME)")
+	m.generate(Config{FieldNullable: true, NullableStyle: "sqlnull"})
+	content := m.read("model/users.gen.go")
+	for _, expect := range []string{"Name  sql.NullString ", "Age   sql.NullInt32 ", "Score sql.NullFloat64 ", "Born  sql.NullTime "} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: sql.NullString{String: "gen", Valid: true}}, &model.User{}) == nil, "create users fail")
+	user, err := u.Where(q.User.Name.Eq("gen")).Take()
+	check(err == nil && user.Name.String == "gen" && !user.Age.Valid, "null fields should be read back, got %+v %v", user, err)
+	count, err := u.Where(q.User.Name.IsNull()).Count()
+	check(err == nil && count == 1, "invalid null field should be stored as NULL, got %d %v", count, err)`,
+		"database/sql", "example.com/app/dao/model")
+
+	// sql.Null[T] requires go1.22
+	goMod := filepath.Join(m.dir, "go.mod")
+	mod, err := os.ReadFile(goMod)
+	if err == nil {
+		err = os.WriteFile(goMod, []byte(strings.Replace(string(mod), "go 1.18", "go 1.22", 1)), 0o644)
+	}
+	if err != nil {
+		t.Fatalf("update go version of go.mod fail: %s", err)
+	}
+	m.generate(Config{FieldNullable: true, NullableStyle: "genericNull"})
+	if content = m.read("model/users.gen.go"); !strings.Contains(content, "Name  sql.Null[string] ") || !strings.Contains(content, "Born  sql.Null[time.Time] ") {
+		t.Errorf("nullable field should be sql.Null[T], got:\n%s", content)
+	}
+	m.run(`
+	u := q.User.WithContext(ctx)
+	user, err := u.Where(q.User.Name.Eq("gen")).Take()
+	check(err == nil && user.Name.V == "gen" && user.Name.Valid && !user.Age.Valid, "null fields should be read back, got %+v %v", user, err)`)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("generator of unknown nullable style should panic")
+		}
+	}()
+	NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), NullableStyle: "optional"})
+}
+
+func TestTimeType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE events (id INTEGER PRIMARY KEY, day DATE NOT NULL, at TIME NOT NULL, created DATETIME NOT NULL, updated DATETIME)")
+	m.generate(Config{DateType: "datatypes", TimeType: "string", TimeUTC: true, FieldNullable: true})
+	content := m.read("model/events.gen.go")
+	for _, expect := range []string{
+		"Day     datatypes.Date ", "At      string ", "type:TIME", "Created time.Time ", "Updated *time.Time ",
+		"func (e *Event) BeforeSave(tx *gorm.DB) error", "func (e *Event) AfterFind(tx *gorm.DB) error",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	zone := time.FixedZone("UTC+8", 8*3600)
+	created := time.Date(2024, 1, 2, 8, 30, 0, 0, zone)
+	e := q.Event.WithContext(ctx)
+	check(e.Create(&model.Event{Day: datatypes.Date(created), At: "08:30:00", Created: created, Updated: &created}) == nil, "create event fail")
+	event, err := e.Take()
+	check(err == nil && event.At == "08:30:00" && event.Created.Equal(created), "time fields should be read back, got %+v %v", event, err)
+	check(event.Created.Location() == time.UTC && event.Updated.Location() == time.UTC, "time fields should be converted to UTC, got %s %s", event.Created, event.Updated)
+	var stored string
+	check(e.UnderlyingDB().Raw("SELECT created FROM events").Scan(&stored).Error == nil && strings.Contains(stored, "00:30:00"), "time should be saved as UTC, got %s", stored)`,
+		"strings", "time", "gorm.io/datatypes", "example.com/app/dao/model")
+
+	m.generate(Config{DateTimeType: "string"})
+	if content = m.read("model/events.gen.go"); !strings.Contains(content, "Day     time.Time ") || !strings.Contains(content, "Created string ") || strings.Contains(content, "BeforeSave") {
+		t.Errorf("datetime column should be string without UTC hooks, got:\n%s", content)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("generator of unknown datetime type should panic")
+		}
+	}()
+	NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), DateTimeType: "datatypes"})
+}
+
+func TestGeometryType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE shops (id INTEGER PRIMARY KEY, location BLOB NOT NULL, area BLOB, shape BLOB)")
+	m.require("github.com/paulmach/orb@v0.11.1")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"shops.location": columnType("point"),
+		"shops.area":     columnType("geometry"),
+		"shops.shape":    columnType("geometry"),
+	})
+	m.generate(Config{FieldWithGeometryType: true, FieldNullable: true}, FieldGeometryType("area", "orb.Polygon"))
+	content := m.read("model/shops.gen.go")
+	for _, expect := range []string{
+		"Location ShopLocation ", "Area     *ShopArea ", "Shape    *ShopShape ", "type:point",
+		"type ShopLocation struct {\n\torb.Point\n", "type ShopArea struct {\n\torb.Polygon\n", "type ShopShape struct {\n\torb.Geometry\n",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	s := q.Shop.WithContext(ctx)
+	area := orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}
+	check(s.Create(&model.Shop{Location: model.ShopLocation{Point: orb.Point{121.5, 31.2}, SRID: 4326}, Area: &model.ShopArea{Polygon: area}, Shape: &model.ShopShape{Geometry: orb.LineString{{0, 0}, {2, 2}}}}) == nil, "create shop fail")
+	shop, err := s.Take()
+	check(err == nil && shop.Location.Point == orb.Point{121.5, 31.2} && shop.Location.SRID == 4326 && len(shop.Area.Polygon[0]) == 4, "geometry should be read back, got %+v %v", shop, err)
+	line, isLine := shop.Shape.Geometry.(orb.LineString)
+	check(isLine && len(line) == 2, "geometry interface should be decoded to concrete type, got %#v", shop.Shape.Geometry)`,
+		"github.com/paulmach/orb", "example.com/app/dao/model")
+
+	// postgis column stores hex ewkb, srid of column type is used if it's not set
+	m.useSchema("postgres", map[string]func(*model.ColumnSchema){
+		"shops.location": columnType("geometry(Point,4326)"),
+		"shops.area":     columnType("geography(POLYGON)"),
+		"shops.shape":    columnType("text"),
+	})
+	m.generate(Config{FieldWithGeometryType: true})
+	if content = m.read("model/shops.gen.go"); !strings.Contains(content, "Area     ShopArea ") || !strings.Contains(content, "Shape    string ") || !strings.Contains(content, "ewkb.Scanner(") {
+		t.Errorf("postgis column should be named geometry type, got:\n%s", content)
+	}
+	m.run(`
+	s := q.Shop.WithContext(ctx)
+	check(s.Create(&model.Shop{Location: model.ShopLocation{Point: orb.Point{1, 2}}, Area: model.ShopArea{Polygon: orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}}) == nil, "create shop fail")
+	shop, err := s.Last()
+	check(err == nil && shop.Location.Point == orb.Point{1, 2} && shop.Location.SRID == 4326 && shop.Area.SRID == 4326, "srid of column type should be used, got %+v %v", shop, err)
+	var stored string
+	check(s.UnderlyingDB().Raw("SELECT location FROM shops ORDER BY id DESC LIMIT 1").Scan(&stored).Error == nil && strings.HasPrefix(stored, "0101000020e6100000"), "point should be stored as hex ewkb, got %s", stored)`,
+		"strings", "github.com/paulmach/orb", "example.com/app/dao/model")
+}
+
+func TestClickHouseType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE visits (id INTEGER PRIMARY KEY, city TEXT NOT NULL, hits INTEGER NOT NULL, score INTEGER, "+
+		"tags TEXT NOT NULL, counts TEXT NOT NULL, amount TEXT NOT NULL, ip TEXT NOT NULL, location TEXT NOT NULL, total TEXT NOT NULL)")
+	m.require("github.com/shopspring/decimal@v1.3.1", "github.com/paulmach/orb@v0.11.1")
+	// data type names of clickhouse are case-sensitive and not split by "("
+	chType := func(typ string) func(*model.ColumnSchema) {
+		return func(col *model.ColumnSchema) { col.DatabaseType, col.ColumnType, col.GoType = typ, typ, "" }
+	}
+	m.useSchema("clickhouse", map[string]func(*model.ColumnSchema){
+		"visits.id":       chType("UInt64"),
+		"visits.city":     chType("LowCardinality(String)"),
+		"visits.hits":     chType("SimpleAggregateFunction(sum, UInt32)"),
+		"visits.score":    chType("Nullable(Int8)"),
+		"visits.tags":     chType("Array(Nullable(Int32))"),
+		"visits.counts":   chType("Map(String, UInt64)"),
+		"visits.amount":   chType("Decimal(18, 4)"),
+		"visits.ip":       chType("IPv4"),
+		"visits.location": chType("Point"),
+		"visits.total":    chType("Int128"),
+	})
+	m.generate(Config{FieldNullable: true})
+	content := m.read("model/visits.gen.go")
+	for _, expect := range []string{
+		"ID       uint64 ", "City     string ", "Hits     uint32 ", "Score    *int8 ", "Tags     []*int32 ",
+		"Counts   map[string]uint64 ", "Amount   decimal.Decimal ", "IP       net.IP ", "Location orb.Point ", "Total    *big.Int ",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+}
+
+func TestIntegerType(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE items (id INTEGER PRIMARY KEY, flag INTEGER NOT NULL, level INTEGER NOT NULL, code INTEGER NOT NULL, stock INTEGER NOT NULL)")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"items.flag":  columnType("tinyint(1)"),
+		"items.level": columnType("tinyint(3) unsigned"),
+		"items.code":  columnType("int(8) zerofill"),
+		"items.stock": columnType("mediumint"),
+	})
+	m.generate(Config{FieldSignable: true})
+	content := m.read("model/items.gen.go")
+	for _, expect := range []string{"Flag  bool ", "Level uint32 ", "Code  uint32 ", "Stock int32 "} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	// map of other dialects is ignored
+	m.generate(Config{FieldSignable: true, IntegerTypeMap: map[string]map[string]string{
+		"mysql":    {"tinyint": "int8", "tinyint(1)": "int8", "mediumint": "int64"},
+		"postgres": {"int": "int16"},
+	}})
+	content = m.read("model/items.gen.go")
+	for _, expect := range []string{"Flag  int8 ", "Level uint8 ", "Code  uint32 ", "Stock int64 "} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	i := q.Item.WithContext(ctx)
+	check(i.Create(&model.Item{Flag: -1, Level: 200, Code: 7, Stock: 1 << 40}) == nil, "create item fail")
+	item, err := i.Where(q.Item.Level.Gt(100)).Take()
+	check(err == nil && item.Flag == -1 && item.Level == 200 && item.Stock == 1<<40, "integers should be read back, got %+v %v", item, err)`,
+		"example.com/app/dao/model")
+}
+
+func TestGeneratedColumn(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE lines (id INTEGER PRIMARY KEY, price INTEGER NOT NULL, qty INTEGER NOT NULL, "+
+		"total INTEGER GENERATED ALWAYS AS (price * qty) VIRTUAL, label TEXT GENERATED ALWAYS AS ('x' || qty) STORED)")
+	m.generate(Config{})
+	content := m.read("model/lines.gen.go")
+	for _, expect := range []string{`gorm:"column:total;->"`, `gorm:"column:label;->"`, `gorm:"column:qty;not null"`} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	l := q.Line.WithContext(ctx)
+	check(l.Create(&model.Line{Price: 3, Qty: 4, Total: 100}) == nil, "generated columns should not be inserted")
+	line, err := l.Take()
+	check(err == nil && line.Total == 12 && line.Label == "x4", "generated columns should be read, got %+v %v", line, err)
+	line.Qty = 5
+	check(l.Save(line) == nil, "generated columns should not be updated")
+	line, err = l.Take()
+	check(err == nil && line.Total == 15, "generated columns should be read, got %+v %v", line, err)`,
+		"example.com/app/dao/model")
+
+	config := Config{OutPath: filepath.Join(m.dir, "dao", "query")}
+	config.WithSchemaJSONExport(filepath.Join(m.dir, "schema.json"))
+	g := NewGenerator(config)
+	g.UseDB(m.db)
+	g.GenerateAllTable()
+	g.Export()
+	if schema := m.read("../schema.json"); strings.Count(schema, `"generated": true`) != 2 {
+		t.Errorf("generated columns should be marked in schema json, got:\n%s", schema)
+	}
+	m.schema = filepath.Join(m.dir, "schema.json")
+	m.generate(Config{})
+	if content = m.read("model/lines.gen.go"); !strings.Contains(content, `gorm:"column:total;->"`) {
+		t.Errorf("generated columns of schema json should be read-only, got:\n%s", content)
+	}
+}
+
+func TestDefaultTag(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE tasks (id INTEGER PRIMARY KEY, status INTEGER NOT NULL DEFAULT 1, priority INTEGER NOT NULL DEFAULT 0, "+
+		"note TEXT NOT NULL DEFAULT '', state TEXT NOT NULL, retries INTEGER NOT NULL, ratio REAL NOT NULL DEFAULT 0.0, seq INTEGER)")
+	m.generate(Config{FieldCoverable: true})
+	content := m.read("model/tasks.gen.go")
+	for _, expect := range []string{"Status   *int32 ", "Priority int32 ", "Note     string ", "Ratio    float64 "} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	defaultValue := func(value string) func(*model.ColumnSchema) {
+		return func(col *model.ColumnSchema) { col.Default = &value }
+	}
+	m.useSchema("postgres", map[string]func(*model.ColumnSchema){
+		"tasks.seq":     defaultValue("nextval('tasks_seq_seq'::regclass)"),
+		"tasks.state":   defaultValue("'pending'::character varying"),
+		"tasks.retries": defaultValue("'-1'::integer"),
+	})
+	m.generate(Config{FieldCoverable: true})
+	content = m.read("model/tasks.gen.go")
+	for _, expect := range []string{
+		`gorm:"column:seq" json`, `gorm:"column:status;not null;default:1"`, `gorm:"column:priority;not null"`, `gorm:"column:note;not null"`,
+		`gorm:"column:state;not null;default:'pending'"`, `gorm:"column:retries;not null;default:-1"`, `gorm:"column:ratio;not null"`,
+		// field of non-zero default is pointer, so zero value can be written
+		"Status   *int32 ", "Priority int32 ", "State    *string ", "Retries  *int32 ",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	zero := int32(0)
+	t := q.Task.WithContext(ctx)
+	check(t.Create(&model.Task{}, &model.Task{Status: &zero, Retries: &zero}) == nil, "create tasks fail")
+	tasks, err := t.Order(q.Task.ID).Find()
+	check(err == nil && len(tasks) == 2, "find tasks fail: %v", err)
+	check(*tasks[0].Status == 1 && *tasks[0].State == "pending" && *tasks[0].Retries == -1, "default values should be inserted, got %+v", tasks[0])
+	check(*tasks[1].Status == 0 && *tasks[1].Retries == 0, "zero values should be written by pointer, got %+v", tasks[1])`,
+		"example.com/app/dao/model")
+}
+
+func TestIndexOrder(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE posts (id INTEGER PRIMARY KEY, author INTEGER NOT NULL, created INTEGER NOT NULL, slug TEXT NOT NULL)",
+		"CREATE INDEX idx_created_author ON posts (created, author)", "CREATE UNIQUE INDEX idx_slug ON posts (slug)")
+	m.generate(Config{FieldWithIndexTag: true})
+	content := m.read("model/posts.gen.go")
+	for _, expect := range []string{
+		`gorm:"column:author;not null;index:idx_created_author,priority:2"`,
+		`gorm:"column:created;not null;index:idx_created_author,priority:1"`,
+		`gorm:"column:slug;not null;uniqueIndex:idx_slug,priority:1"`,
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+}
+
+func TestConstraintTag(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL, user_id INTEGER REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL)")
+	postsWithUser := func(g *Generator) []interface{} {
+		users := g.GenerateModel("users")
+		return []interface{}{users, g.GenerateModel("posts", FieldRelate(field.BelongsTo, "User", users,
+			&field.RelateConfig{GORMTag: field.GormTag{"foreignKey": []string{"UserID"}}}))}
+	}
+	m.generateModels(Config{}, postsWithUser)
+	if content := m.read("model/posts.gen.go"); strings.Contains(content, "constraint:") {
+		t.Errorf("constraint tag should require FieldWithConstraintTag, got:\n%s", content)
+	}
+	m.generateModels(Config{FieldWithConstraintTag: true}, postsWithUser)
+	if content := m.read("model/posts.gen.go"); !strings.Contains(content, `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:UserID"`) {
+		t.Errorf("relation field should have constraint tag of foreign key actions, got:\n%s", content)
+	}
+
+	// check constraints of schema json, actions of sqlserver are normalized
+	m.useSchema("sqlserver", map[string]func(*model.ColumnSchema){
+		"posts.title": func(col *model.ColumnSchema) {
+			col.Checks = []*model.CheckConstraint{{Name: "chk_title", Clause: "length([title]) > 0"}, {Name: "multi;line", Clause: "title <> 'x'"}}
+		},
+		"posts.user_id": func(col *model.ColumnSchema) {
+			col.ForeignKey = &model.ForeignKey{Name: "fk_user", RefTable: "users", RefColumn: "id", OnDelete: "SET_NULL"}
+		},
+	})
+	m.generateModels(Config{FieldWithConstraintTag: true}, postsWithUser)
+	content := m.read("model/posts.gen.go")
+	// gorm supports one check of each field, checks of column are merged
+	for _, expect := range []string{`check:(length([title]) > 0) AND (title <> 'x')"`, `constraint:OnDelete:SET NULL;`} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	migrated, err := gorm.Open(sqlite.Open(os.Args[1]+".migrated"), &gorm.Config{})
+	check(err == nil && migrated.AutoMigrate(&model.User{}, &model.Post{}) == nil, "migrate models fail: %v", err)
+	var onDelete string
+	check(migrated.Raw("SELECT on_delete FROM pragma_foreign_key_list('posts')").Scan(&onDelete).Error == nil && onDelete == "SET NULL", "foreign key should keep action, got %q", onDelete)
+	check(migrated.Create(&model.Post{Title: ""}).Error != nil && migrated.Create(&model.Post{Title: "x"}).Error != nil, "check constraints should be migrated")
+	check(migrated.Create(&model.Post{Title: "gen"}).Error == nil, "create post fail")`,
+		"example.com/app/dao/model")
+
+	// name of check not accepted by gorm(letters, - and _) is dropped, gorm takes it as part of clause otherwise
+	m.useSchema("sqlserver", map[string]func(*model.ColumnSchema){
+		"posts.title": func(col *model.ColumnSchema) {
+			col.Checks = []*model.CheckConstraint{{Name: "chk_title2", Clause: "title <> ''"}}
+		},
+	})
+	m.generate(Config{FieldWithConstraintTag: true})
+	if content = m.read("model/posts.gen.go"); !strings.Contains(content, `check:title <> ''"`) {
+		t.Errorf("check should be unnamed, got:\n%s", content)
+	}
+}
+
+func TestBaseModel(t *testing.T) {
+	m := newGenModule(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, created_at DATETIME, updated_at DATETIME)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL, created_at DATETIME, updated_at DATETIME)",
+		"CREATE TABLE logs (id INTEGER PRIMARY KEY, message TEXT NOT NULL, created_at DATETIME)")
+	config := Config{BaseModelColumns: []string{"id", "created_at", "updated_at"}, Prune: true}
+	config.WithDTOExport(filepath.Join(m.dir, "dao", "dto"))
+	m.generate(config)
+
+	base := m.read("model/base_model.gen.go")
+	for _, expect := range []string{"type BaseModel struct {", "ID        int64 ", "UpdatedAt time.Time "} {
+		if !strings.Contains(base, expect) {
+			t.Errorf("base model should contain %q, got:\n%s", expect, base)
+		}
+	}
+	for _, name := range []string{"users", "posts"} {
+		if content := m.read("model/" + name + ".gen.go"); !strings.Contains(content, "\tBaseModel\n") || strings.Contains(content, "CreatedAt") {
+			t.Errorf("model of %s should embed base model, got:\n%s", name, content)
+		}
+	}
+	if content := m.read("model/logs.gen.go"); strings.Contains(content, "BaseModel") || !strings.Contains(content, "CreatedAt time.Time ") {
+		t.Errorf("model without all base columns should declare them, got:\n%s", content)
+	}
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: "gen"}) == nil, "create user fail")
+	user, err := u.Where(q.User.ID.Eq(1), q.User.CreatedAt.IsNotNull()).Take()
+	check(err == nil && user.ID == 1 && user.Name == "gen" && !user.UpdatedAt.IsZero(), "fields of base model should be read back, got %+v %v", user, err)
+	d := dto.UserToDTO(user)
+	check(d.ID == 1 && d.Name == "gen" && dto.UserFromDTO(d).ID == 1, "dto should copy fields of base model, got %+v", d)
+	var base model.BaseModel = user.BaseModel
+	check(base.ID == 1, "base model should be embedded, got %+v", base)`,
+		"example.com/app/dao/dto", "example.com/app/dao/model")
+
+	// base model is pruned once models don't embed it
+	m.generate(Config{Prune: true})
+	if files := m.files(); files["model/base_model.gen.go"] != "" {
+		t.Errorf("base model should be pruned without BaseModelColumns")
+	}
+}
+
+func TestExtraModelMethods(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, nick TEXT, avatar BLOB NOT NULL)",
+		"CREATE TABLE tags (post_id INTEGER NOT NULL, tag TEXT NOT NULL, string TEXT, PRIMARY KEY (post_id, tag))")
+	m.generate(Config{FieldNullable: true, ExtraModelMethods: []string{"String", "IsZero", "PrimaryKey", "Clone"}})
+	user, tag := m.read("model/users.gen.go"), m.read("model/tags.gen.go")
+	for _, expect := range []string{"func (u *User) String() string", "func (u *User) IsZero() bool", "func (u *User) PrimaryKey() int64", "func (u *User) Clone() *User"} {
+		if !strings.Contains(user, expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, user)
+		}
+	}
+	// String conflicts with field, tags has composite primary key
+	if strings.Contains(tag, ") String() string") || strings.Contains(tag, "PrimaryKey()") || !strings.Contains(tag, "func (t *Tag) Clone() *Tag") {
+		t.Errorf("conflicting methods and PrimaryKey of composite key should be skipped, got:\n%s", tag)
+	}
+	m.run(`
+	nick := "g"
+	user := &model.User{ID: 7, Name: "gen", Nick: &nick, Avatar: []byte{1, 2}}
+	check(user.String() == "User{ID:7, Name:gen, Nick:g, Avatar:[1 2]}", "String should format fields, got %s", user)
+	check(fmt.Sprint(&model.User{}) == "User{ID:0, Name:, Nick:<nil>, Avatar:[]}", "String should format nil pointer, got %s", &model.User{})
+	check((*model.User)(nil).IsZero() && (&model.User{}).IsZero() && !user.IsZero(), "IsZero should check fields")
+	check(user.PrimaryKey() == 7, "PrimaryKey should return id, got %d", user.PrimaryKey())
+	c := user.Clone()
+	*c.Nick, c.Avatar[0], c.Name = "x", 9, "y"
+	check(*user.Nick == "g" && user.Avatar[0] == 1 && user.Name == "gen" && c.ID == 7, "Clone should copy deeply, got %s %s", user, c)
+	check((*model.User)(nil).Clone() == nil, "Clone should keep nil")`,
+		"example.com/app/dao/model")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("generator of unknown model method should panic")
+		}
+	}()
+	NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), ExtraModelMethods: []string{"Equal"}})
+}
+
+func TestHookStubs(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, created DATETIME NOT NULL)")
+	config := Config{WithHooks: []string{"BeforeCreate", "BeforeSave"}, TimeUTC: true, Prune: true}
+	m.generate(config)
+	stub := m.read("model/users_hooks.go")
+	for _, expect := range []string{"func (u *User) BeforeCreate(tx *gorm.DB) error", "func (u *User) BeforeSave(tx *gorm.DB) error"} {
+		if !strings.Contains(stub, expect) {
+			t.Errorf("hook stubs should contain %q, got:\n%s", expect, stub)
+		}
+	}
+	// BeforeSave is generated by TimeUTC
+	if stub = m.read("model/events_hooks.go"); !strings.Contains(stub, "BeforeCreate") || strings.Contains(stub, "BeforeSave") {
+		t.Errorf("hook generated by TimeUTC should be skipped, got:\n%s", stub)
+	}
+
+	// stubs edited by users are kept by regeneration and prune
+	stubFile := filepath.Join(m.dir, "dao", "model", "users_hooks.go")
+	edited := strings.Replace(m.read("model/users_hooks.go"), "func (u *User) BeforeCreate(tx *gorm.DB) error {\n",
+		"func (u *User) BeforeCreate(tx *gorm.DB) error {\n\tu.Name = \"hooked \" + u.Name\n", 1)
+	if err := os.WriteFile(stubFile, []byte(edited), 0o644); err != nil {
+		t.Fatalf("write hook stubs fail: %s", err)
+	}
+	m.generate(config)
+	if m.read("model/users_hooks.go") != edited {
+		t.Errorf("hook stubs should not be regenerated")
+	}
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: "gen"}) == nil, "create user fail")
+	user, err := u.Take()
+	check(err == nil && user.Name == "hooked gen", "edited hook should run, got %+v %v", user, err)`,
+		"example.com/app/dao/model")
+}
+
+func TestTableMethods(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)", "CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL)")
+	common := `package common
+
+import "fmt"
+
+type Labeled struct{ ID int64 }
+
+// Label label of record
+func (l *Labeled) Label() string { return fmt.Sprintf("#%d", l.ID) }
+
+type Named struct{ Name string }
+
+// Greet greeting of user
+func (n Named) Greet() string { return "hi " + n.Name }
+`
+	if err := os.MkdirAll(filepath.Join(m.dir, "common"), 0o755); err != nil {
+		t.Fatalf("make dir fail: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.dir, "common", "common.go"), []byte(common), 0o644); err != nil {
+		t.Fatalf("write methods fail: %s", err)
+	}
+	// relative dir and import path are resolved from working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("get working directory fail: %s", err)
+	}
+	if err = os.Chdir(m.dir); err != nil {
+		t.Fatalf("change working directory fail: %s", err)
+	}
+	defer os.Chdir(wd) // nolint
+
+	m.generate(Config{TableMethods: map[string][]string{"*": {"./common.Labeled"}, "users": {"example.com/app/common.Named"}}})
+	user, post := m.read("model/users.gen.go"), m.read("model/posts.gen.go")
+	if !strings.Contains(user, "func (l *User) Label() string") || !strings.Contains(user, "func (n User) Greet() string") {
+		t.Errorf("methods should be copied onto users, got:\n%s", user)
+	}
+	if !strings.Contains(post, "func (l *Post) Label() string") || strings.Contains(post, "Greet") {
+		t.Errorf("only methods of all tables should be copied onto posts, got:\n%s", post)
+	}
+	m.run(`
+	check((&model.User{ID: 1, Name: "gen"}).Label() == "#1" && model.User{Name: "gen"}.Greet() == "hi gen", "copied methods should work")`,
+		"example.com/app/dao/model")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("generator of invalid method type should panic")
+		}
+	}()
+	g := NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), TableMethods: map[string][]string{"users": {"Labeled"}}})
+	g.UseDB(m.db)
+	g.GenerateModel("users")
+}
+
+func TestPIIColumns(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL, phone TEXT, name TEXT NOT NULL)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, email TEXT NOT NULL)")
+	m.generate(Config{Mode: WithCacheMethod, FieldNullable: true, PIIColumns: []string{"users.email", "phone"}})
+	if content := m.read("model/users.gen.go"); !strings.Contains(content, "Email gen.PII ") || !strings.Contains(content, "Phone *gen.PII ") ||
+		!strings.Contains(content, `gorm:"column:email;not null;serializer:pii"`) {
+		t.Errorf("pii columns should be gen.PII with pii serializer, got:\n%s", content)
+	}
+	if content := m.read("model/orders.gen.go"); !strings.Contains(content, "Email string ") {
+		t.Errorf("pii column of another table should be left alone, got:\n%s", content)
+	}
+	m.run(`
+	c, err := gen.NewAESCipher([]byte("0123456789abcdef"))
+	check(err == nil, "new cipher fail: %v", err)
+	check(q.User.WithContext(ctx).Create(&model.User{Email: "bob@example.com"}) != nil, "pii column should not be written without cipher")
+	gen.SetPIICipher(c)
+
+	// records cached by GetByID keep plain pii
+	cq := query.Use(db, gen.WithCache(cache.NewMemory(), time.Minute))
+	u := cq.User.WithContext(ctx)
+	check(u.Create(&model.User{Email: "alice@example.com", Name: "alice"}) == nil, "create user fail")
+	var stored string
+	check(db.Raw("SELECT email FROM users").Scan(&stored).Error == nil && !strings.Contains(stored, "alice"), "pii should be stored encrypted, got %s", stored)
+	for i := 0; i < 2; i++ {
+		user, err := u.GetByID(1)
+		check(err == nil && user.Email.Plain() == "alice@example.com" && user.Phone == nil, "pii of record should be plain, got %+v %v", user, err)
+		content, _ := json.Marshal(user)
+		check(strings.Contains(string(content), "a***************m") && user.Email.String() == "a***************m", "pii should be masked in output, got %s", content)
+	}`,
+		"encoding/json", "strings", "time", "gorm.io/gen", "gorm.io/gen/cache", "example.com/app/dao/model")
+}
+
+func TestValidateMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, nick TEXT, level INTEGER NOT NULL, "+
+		"score INTEGER NOT NULL DEFAULT 0, status TEXT NOT NULL, born DATETIME NOT NULL, created_at DATETIME NOT NULL)")
+	withLength := func(typ string, length int64) func(*model.ColumnSchema) {
+		return func(col *model.ColumnSchema) {
+			columnType(typ)(col)
+			col.Length = &length
+		}
+	}
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"users.name":   withLength("varchar(8)", 8),
+		"users.nick":   withLength("varchar(4)", 4),
+		"users.level":  columnType("tinyint(3) unsigned"),
+		"users.score":  columnType("smallint"),
+		"users.status": columnType("enum('active','banned')"),
+		"users.born":   columnType("datetime"),
+	})
+	config := Config{FieldNullable: true, FieldSignable: true, FieldWithEnumType: true, ValidateNotNullZero: true, ExtraModelMethods: []string{"Validate"}}
+	m.generate(config)
+	m.run(`
+	nick := "nickname"
+	user := &model.User{Name: "", Nick: &nick, Level: 300, Score: -40000, Status: "deleted"}
+	var errs gen.ValidationErrors
+	check(errors.As(user.Validate(), &errs), "Validate should return ValidationErrors, got %v", user.Validate())
+	rules := map[string]gen.ValidationRule{}
+	for _, e := range errs {
+		rules[e.Field] = e.Rule
+	}
+	expect := map[string]gen.ValidationRule{"Name": gen.ValidationRequired, "Nick": gen.ValidationMaxLength, "Level": gen.ValidationMax,
+		"Score": gen.ValidationMin, "Status": gen.ValidationEnum, "Born": gen.ValidationRequired}
+	check(reflect.DeepEqual(rules, expect), "violations should be reported, got %v", errs)
+
+	valid := &model.User{Name: "中文名字", Level: 255, Status: model.UserStatusActive, Born: time.Now()}
+	check(valid.Validate() == nil, "valid user should pass, got %v", valid.Validate())
+	valid.Name = "九个字符的名字啊啊"
+	check(valid.Validate() != nil && strings.Contains(valid.Validate().Error(), "User.Name: value exceeds max length 8 of column name"), "length should be counted in characters, got %v", valid.Validate())`,
+		"errors", "reflect", "strings", "time", "gorm.io/gen", "example.com/app/dao/model")
+
+	// required requires ValidateNotNullZero
+	config.ValidateNotNullZero = false
+	m.generate(config)
+	if content := m.read("model/users.gen.go"); strings.Contains(content, "gen.ValidationRequired") {
+		t.Errorf("required should not be checked without ValidateNotNullZero, got:\n%s", content)
+	}
+}
+
+func TestRelations(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL, author_id INTEGER REFERENCES users(id))",
+		"CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE user_roles (user_id INTEGER NOT NULL, role_id INTEGER NOT NULL, PRIMARY KEY (user_id, role_id))")
+	m.generate(Config{})
+	if content := m.read("model/users.gen.go"); strings.Contains(content, "Posts") {
+		t.Errorf("relation fields should require FieldWithRelation or Relations, got:\n%s", content)
+	}
+
+	m.generate(Config{FieldWithRelation: true, Relations: map[string][]string{"users": {"many_to_many:Roles:roles:many2many:user_roles"}}})
+	for name, expect := range map[string]string{
+		"model/posts.gen.go": "Author *User `gorm:\"foreignKey:AuthorID;references:ID\" json:\"author\"`",
+		"model/users.gen.go": "Posts []Post `gorm:\"foreignKey:AuthorID;references:ID\" json:\"posts\"`",
+	} {
+		if content := m.read(name); !strings.Contains(strings.Join(strings.Fields(content), " "), expect) {
+			t.Errorf("%s should contain %q, got:\n%s", name, expect, content)
+		}
+	}
+	m.run(`
+	user := &model.User{Name: "modi", Posts: []model.Post{{Title: "gen"}}, Roles: []model.Role{{Name: "admin"}}}
+	check(q.User.WithContext(ctx).Create(user) == nil, "create user with relations fail")
+
+	check(q.User.Posts.Model(user).Append(&model.Post{Title: "gorm"}) == nil, "append post fail")
+	count := q.User.Posts.Model(user).Count()
+	check(count == 2, "user should have 2 posts, got %d", count)
+
+	found, err := q.User.WithContext(ctx).Preload(q.User.Posts, q.User.Roles).Where(q.User.ID.Eq(user.ID)).First()
+	check(err == nil && len(found.Posts) == 2 && len(found.Roles) == 1 && found.Roles[0].Name == "admin", "preload relations fail: %v %+v", err, found)
+
+	post, err := q.Post.WithContext(ctx).Preload(q.Post.Author).Where(q.Post.Title.Eq("gorm")).First()
+	check(err == nil && post.Author != nil && post.Author.Name == "modi", "preload belongs to fail: %v %+v", err, post)
+
+	check(q.User.Roles.Model(user).Replace(&model.Role{Name: "editor"}) == nil, "replace roles fail")
+	var roles []model.Role
+	check(db.Model(user).Association("Roles").Find(&roles) == nil && len(roles) == 1 && roles[0].Name == "editor", "roles should be replaced, got %+v", roles)
+	check(q.User.Roles.Model(user).Delete(&roles[0]) == nil && q.User.Roles.Model(user).Count() == 0, "delete role association fail")`,
+		"example.com/app/dao/model")
+
+	// relations to models not generated are skipped
+	m.generateModels(Config{FieldWithRelation: true}, func(g *Generator) []interface{} {
+		return []interface{}{g.GenerateModel("posts")}
+	})
+	if content := m.read("model/posts.gen.go"); strings.Contains(content, "*User") {
+		t.Errorf("relation to model not generated should be skipped, got:\n%s", content)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "unknown relationship") {
+				t.Errorf("unknown relationship should be rejected, got %v", r)
+			}
+		}()
+		NewGenerator(Config{OutPath: filepath.Join(m.dir, "dao", "query"), Relations: map[string][]string{"users": {"has_some:Posts:posts"}}})
+	}()
+}
+
+func TestPreloadMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL, user_id INTEGER REFERENCES users(id))",
+		"CREATE TABLE comments (id INTEGER PRIMARY KEY, content TEXT NOT NULL, post_id INTEGER REFERENCES posts(id))")
+	m.generate(Config{FieldWithRelation: true})
+	if content := m.read("query/users.gen.go"); strings.Contains(content, "WithPosts") {
+		t.Errorf("preload methods should require WithPreloadMethod, got:\n%s", content)
+	}
+
+	m.generate(Config{Mode: WithPreloadMethod | WithQueryInterface, FieldWithRelation: true})
+	if content := m.read("query/users.gen.go"); !strings.Contains(content, "WithPosts(nested ...gen.Preload) IUserDo") {
+		t.Errorf("query interface should contain WithPosts, got:\n%s", content)
+	}
+	m.run(`
+	user := &model.User{Name: "modi", Posts: []model.Post{{Title: "gen", Comments: []model.Comment{{Content: "nice"}, {Content: "+1"}}}}}
+	check(q.User.WithContext(ctx).Create(user) == nil, "create user fail")
+
+	found, err := q.User.WithContext(ctx).WithPosts().First()
+	check(err == nil && len(found.Posts) == 1 && len(found.Posts[0].Comments) == 0, "WithPosts should preload posts only, got %v %+v", err, found)
+
+	found, err = q.User.WithContext(ctx).WithPosts(query.WithComments(query.WithPost())).First()
+	check(err == nil && len(found.Posts) == 1 && len(found.Posts[0].Comments) == 2, "nested preload should load comments, got %v %+v", err, found)
+	check(found.Posts[0].Comments[0].Post != nil && found.Posts[0].Comments[0].Post.Title == "gen", "nested preload should load post of comments, got %+v", found.Posts[0].Comments[0])
+
+	comment, err := q.Comment.WithContext(ctx).WithPost(query.WithUser()).Where(q.Comment.Content.Eq("+1")).First()
+	check(err == nil && comment.Post != nil && comment.Post.User != nil && comment.Post.User.Name == "modi", "preload belongs to chain fail: %v %+v", err, comment)`,
+		"example.com/app/dao/model")
+}
+
+func TestFilterMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, active BOOLEAN NOT NULL, age INTEGER NOT NULL, "+
+		"team_id INTEGER NOT NULL, status TEXT NOT NULL, born DATETIME NOT NULL, avatar BLOB)")
+	m.useSchema("mysql", map[string]func(*model.ColumnSchema){
+		"users.active": columnType("tinyint(1)"),
+		"users.status": columnType("enum('active','banned','deleted')"),
+		"users.born":   columnType("datetime"),
+	})
+	m.generate(Config{Mode: WithFilterMethod | WithQueryInterface, FieldWithEnumType: true})
+	content := strings.Join(strings.Fields(m.read("query/users.gen.go")), " ")
+	for _, expect := range []string{"ID *int32", "Name *string", "Active *bool", "Age *gen.Range[int32]", "TeamID *int32",
+		"Status []model.UserStatus", "Born *gen.Range[time.Time]", "ApplyFilter(f *UserFilter) IUserDo"} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("filter should contain %q, got:\n%s", expect, content)
+		}
+	}
+	if strings.Contains(content, "Avatar *") {
+		t.Errorf("filter should skip bytes field, got:\n%s", content)
+	}
+
+	m.run(`
+	born := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := []*model.User{
+		{Name: "modi", Active: true, Age: 18, TeamID: 1, Status: model.UserStatusActive, Born: born},
+		{Name: "gorm", Active: false, Age: 30, TeamID: 1, Status: model.UserStatusBanned, Born: born.AddDate(10, 0, 0)},
+		{Name: "gen", Active: true, Age: 40, TeamID: 2, Status: model.UserStatusDeleted, Born: born.AddDate(20, 0, 0)},
+	}
+	check(q.User.WithContext(ctx).Create(users...) == nil, "create users fail")
+
+	names := func(f *query.UserFilter) []string {
+		found, err := q.User.WithContext(ctx).ApplyFilter(f).Order(q.User.ID).Find()
+		check(err == nil, "find with filter fail: %v", err)
+		var names []string
+		for _, u := range found {
+			names = append(names, u.Name)
+		}
+		return names
+	}
+	active, teamID, name := true, int32(1), "gen"
+	for _, c := range []struct {
+		filter *query.UserFilter
+		expect []string
+	}{
+		{nil, []string{"modi", "gorm", "gen"}},
+		{&query.UserFilter{}, []string{"modi", "gorm", "gen"}},
+		{&query.UserFilter{Name: &name}, []string{"gen"}},
+		{&query.UserFilter{Active: &active}, []string{"modi", "gen"}},
+		{&query.UserFilter{Age: gen.Between[int32](18, 30)}, []string{"modi", "gorm"}},
+		{&query.UserFilter{Age: gen.AtLeast[int32](30), TeamID: &teamID}, []string{"gorm"}},
+		{&query.UserFilter{Born: gen.AtMost(born.AddDate(10, 0, 0))}, []string{"modi", "gorm"}},
+		{&query.UserFilter{Status: []model.UserStatus{model.UserStatusActive, model.UserStatusDeleted}}, []string{"modi", "gen"}},
+	} {
+		got := names(c.filter)
+		check(fmt.Sprint(got) == fmt.Sprint(c.expect), "filter %+v should find %v, got %v", c.filter, c.expect, got)
+	}`,
+		"time", "gorm.io/gen", "example.com/app/dao/model")
+}
+
+func TestSortMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER NOT NULL)")
+	m.generate(Config{Mode: WithSortMethod | WithQueryInterface})
+	content := m.read("query/users.gen.go")
+	for _, expect := range []string{`UserFieldName UserField = "name"`, "OrderBy(orders ...gen.Order[UserField]) IUserDo", "SelectFields(fields ...UserField) IUserDo"} {
+		if !strings.Contains(strings.Join(strings.Fields(content), " "), expect) {
+			t.Errorf("query should contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: "b", Age: 18}, &model.User{Name: "a", Age: 18}, &model.User{Name: "c", Age: 30}) == nil, "create users fail")
+
+	orders, err := gen.ParseOrders("age:DESC, name", query.ParseUserField)
+	check(err == nil && len(orders) == 2, "parse orders fail: %v %v", err, orders)
+	fields, err := gen.ParseFields("id,name", query.ParseUserField)
+	check(err == nil && len(fields) == 2, "parse fields fail: %v %v", err, fields)
+	found, err := u.OrderBy(orders...).SelectFields(fields...).Find()
+	check(err == nil && len(found) == 3, "find fail: %v", err)
+	var got []string
+	for _, user := range found {
+		got = append(got, user.Name)
+		check(user.Age == 0, "unselected age should be zero, got %d", user.Age)
+	}
+	check(fmt.Sprint(got) == "[c a b]", "users should be ordered by age desc and name, got %v", got)
+
+	first, err := u.OrderBy(gen.Desc(query.UserFieldName)).First()
+	check(err == nil && first.Name == "c", "order by typed field fail: %v %+v", err, first)
+
+	for _, s := range []string{"name;DROP TABLE users", "password", "name:sideways"} {
+		_, err = gen.ParseOrders(s, query.ParseUserField)
+		check(err != nil, "sort %q should be rejected", s)
+	}
+	_, err = gen.ParseFields("id,(SELECT 1)", query.ParseUserField)
+	check(errors.Is(err, gen.ErrUnknownField), "unknown field should be rejected, got %v", err)`,
+		"errors", "gorm.io/gen", "example.com/app/dao/model")
+}
+
+func TestBatchMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER NOT NULL)")
+	m.generate(Config{Mode: WithBatchMethod | WithQueryInterface, BatchSize: 2})
+	if content := m.read("query/users.gen.go"); !strings.Contains(content, "DeleteByIDs(values ...int64) (info gen.ResultInfo, err error)") ||
+		!strings.Contains(content, "UseBatchSize(2)") {
+		t.Errorf("query should contain batch methods of batch size 2, got:\n%s", content)
+	}
+	m.run(`
+	statements := 0
+	count := func(tx *gorm.DB) {
+		if !tx.DryRun {
+			statements++
+		}
+	}
+	check(db.Callback().Create().After("*").Register("count_create", count) == nil, "register callback fail")
+	check(db.Callback().Update().After("*").Register("count_update", count) == nil, "register callback fail")
+	check(db.Callback().Delete().After("*").Register("count_delete", count) == nil, "register callback fail")
+
+	u := q.User.WithContext(ctx)
+	users := []*model.User{{Name: "a", Age: 1}, {Name: "b", Age: 2}, {Name: "c", Age: 3}, {Name: "d", Age: 4}, {Name: "e", Age: 5}}
+	check(u.CreateInBatches(users, 0) == nil && statements == 3, "create should take 3 batches of configured size, got %d", statements)
+
+	statements = 0
+	for _, user := range users {
+		user.Name, user.Age = user.Name+user.Name, 0
+	}
+	info, err := u.Where(q.User.Age.Gt(1)).UpdateColumnsInBatches(users, q.User.Name)
+	check(err == nil && info.RowsAffected == 4 && statements == 3, "update should take 3 batches and keep conditions, got %v %d %d", err, info.RowsAffected, statements)
+	found, err := u.Order(q.User.ID).Find()
+	check(err == nil && found[0].Name == "a" && found[4].Name == "ee" && found[4].Age == 5, "only name of matched records should be updated, got %v %+v", err, found)
+
+	statements = 0
+	info, err = u.DeleteByIDs(users[0].ID, users[1].ID, users[2].ID, users[3].ID)
+	check(err == nil && info.RowsAffected == 4 && statements == 2, "delete should take 2 batches, got %v %d %d", err, info.RowsAffected, statements)
+	left, err := u.Count()
+	check(err == nil && left == 1, "one user should be left, got %v %d", err, left)`,
+		"example.com/app/dao/model")
+}
+
+func TestReturningMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER NOT NULL)")
+	m.generate(Config{Mode: WithReturningMethod | WithQueryInterface})
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: "a", Age: 1}, &model.User{Name: "b", Age: 2}, &model.User{Name: "c", Age: 3}) == nil, "create users fail")
+
+	updated, err := u.Where(q.User.Age.Gte(2)).Order(q.User.ID).UpdateReturning(q.User.Age.Add(10), q.User.Name.Value("x"))
+	check(err == nil && len(updated) == 2, "update should return 2 records, got %v %d", err, len(updated))
+	for _, user := range updated {
+		check(user.ID > 1 && user.Age > 10 && user.Name == "x", "updated record should carry new values, got %+v", user)
+	}
+	none, err := u.Where(q.User.Age.Gt(100)).UpdateReturning(q.User.Age.Add(1))
+	check(err == nil && len(none) == 0, "update matching nothing should return no record, got %v %d", err, len(none))
+
+	deleted, err := u.Where(q.User.Name.Eq("x")).DeleteReturning()
+	check(err == nil && len(deleted) == 2 && deleted[0].Name == "x" && deleted[0].Age > 10, "delete should return deleted records, got %v %+v", err, deleted)
+	left, err := u.Find()
+	check(err == nil && len(left) == 1 && left[0].Name == "a" && left[0].Age == 1, "unmatched record should be left, got %v %+v", err, left)`,
+		"example.com/app/dao/model")
+
+	// mysql doesn't support RETURNING
+	m.useSchema("mysql", nil)
+	m.generate(Config{Mode: WithReturningMethod | WithQueryInterface})
+	if content := m.read("query/users.gen.go"); strings.Contains(content, "UpdateReturning") {
+		t.Errorf("returning methods should not be generated for mysql, got:\n%s", content)
+	}
+}
+
+func TestAffectedMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER NOT NULL)")
+	m.generate(Config{Mode: WithAffectedMethod | WithQueryInterface})
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: "a", Age: 1}, &model.User{Name: "b", Age: 2}) == nil, "create users fail")
+	rows, err := u.Where(q.User.Age.Gt(0)).UpdateAffected(q.User.Age, 10)
+	check(err == nil && rows == 2, "update should affect 2 rows, got %d %v", rows, err)
+	rows, err = u.Where(q.User.Name.Eq("x")).UpdateSimpleAffected(q.User.Age.Add(1))
+	check(err == nil && rows == 0, "update matching nothing should affect no row without error, got %d %v", rows, err)
+	rows, err = u.Where(q.User.Name.Eq("a")).DeleteAffected()
+	check(err == nil && rows == 1, "delete should affect 1 row, got %d %v", rows, err)`,
+		"example.com/app/dao/model")
+
+	// DIY methods returning gen.RowsAffected are strict too
+	diy := `package main
+
+import (
+	"os"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+type Renamer interface {
+	// UPDATE @@table SET name=@to WHERE name=@from
+	Rename(from, to string) (gen.RowsAffected, error)
+}
+
+func main() {
+	db, err := gorm.Open(sqlite.Open(os.Args[1]), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	g := gen.NewGenerator(gen.Config{OutPath: os.Args[2], Mode: gen.WithAffectedMethod, StrictRowsAffected: true})
+	g.UseDB(db)
+	g.ApplyInterface(func(Renamer) {}, g.GenerateModel("users"))
+	g.Execute()
+}
+`
+	m.generateBy(diy)
+	m.run(`
+	u := q.User.WithContext(ctx)
+	rows, err := u.Where(q.User.Name.Eq("x")).UpdateAffected(q.User.Age, 1)
+	check(errors.Is(err, gen.ErrNoRowsAffected) && rows == 0, "strict update matching nothing should fail, got %d %v", rows, err)
+	rows, err = u.Where(q.User.Age.Gt(0)).UpdateAffected(q.User.Age, 1)
+	check(err == nil && rows == 1, "strict update should affect 1 row, got %d %v", rows, err)
+	_, err = u.Where(q.User.Name.Eq("x")).DeleteAffected()
+	check(errors.Is(err, gen.ErrNoRowsAffected), "strict delete matching nothing should fail, got %v", err)
+
+	renamed, err := u.Rename("b", "c")
+	check(err == nil && renamed == 1, "rename should affect 1 row, got %d %v", renamed, err)
+	renamed, err = u.Rename("b", "c")
+	check(errors.Is(err, gen.ErrNoRowsAffected) && renamed == 0, "strict rename matching nothing should fail, got %d %v", renamed, err)`,
+		"errors", "gorm.io/gen")
+}
+
+func TestStatementPolicy(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	m.generateBy(`package main
+
+import (
+	"os"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+type Querier interface {
+	// SELECT * FROM @@table WHERE name = @name
+	//
+	// @retry 3 1ms
+	FindByName(name string) ([]*gen.T, error)
+
+	// SELECT * FROM @@table WHERE unknown = 1
+	FindUnknown() ([]*gen.T, error)
+
+	// UPDATE @@table SET name = @name
+	//
+	// @timeout 1ms
+	RenameAll(name string) error
+}
+
+func main() {
+	db, err := gorm.Open(sqlite.Open(os.Args[1]), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	g := gen.NewGenerator(gen.Config{OutPath: os.Args[2], StatementPolicy: gen.StatementPolicy{Timeout: time.Minute}})
+	g.UseDB(db)
+	g.ApplyInterface(func(Querier) {}, g.GenerateModel("users"))
+	g.Execute()
+}
+`)
+	if content := m.read("query/users.gen.go"); !strings.Contains(content, "gen.StatementPolicy{Timeout: 1 * time.Minute, MaxAttempts: 3, Backoff: 1 * time.Millisecond}") ||
+		!strings.Contains(content, "gen.StatementPolicy{Timeout: 1 * time.Millisecond}") {
+		t.Errorf("methods should be run with annotated and default policy, got:\n%s", content)
+	}
+	m.run(`
+	// deadlocks fail the first 2 queries
+	attempts := 0
+	check(db.Callback().Query().Before("gorm:query").Register("deadlock", func(tx *gorm.DB) {
+		if attempts++; attempts <= 2 {
+			_ = tx.AddError(&mysql.MySQLError{Number: 1213})
+		}
+	}) == nil, "register callback fail")
+
+	check(q.User.WithContext(ctx).Create(&model.User{Name: "modi"}) == nil, "create fail")
+	users, err := q.User.WithContext(ctx).FindByName("modi")
+	check(err == nil && len(users) == 1 && attempts == 3, "deadlock should be retried, got %v %d", err, attempts)
+
+	attempts = 0
+	err = q.Transaction(func(tx *query.Query) error {
+		_, err := tx.User.WithContext(ctx).FindByName("modi")
+		return err
+	})
+	var deadlock *mysql.MySQLError
+	check(errors.As(err, &deadlock) && attempts == 1, "statement in transaction should not be retried, got %v %d", err, attempts)
+
+	attempts = 10
+	_, err = q.User.WithContext(ctx).FindUnknown()
+	check(err != nil && strings.Contains(err.Error(), "unknown"), "error of policy run should be returned, got %v", err)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = q.User.WithContext(cancelled).RenameAll("gen")
+	check(errors.Is(err, context.Canceled), "error of cancelled context should be returned, got %v", err)`,
+		"errors", "strings", "github.com/go-sql-driver/mysql", "example.com/app/dao/model")
+}
+
+func TestTransactionContext(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	m.generate(Config{})
+	m.run(`
+	names := func() []string {
+		var names []string
+		check(db.Table("users").Order("id").Pluck("name", &names).Error == nil, "pluck names fail")
+		return names
+	}
+	create := func(tx *query.Query, name string) error { return tx.User.WithContext(ctx).Create(&model.User{Name: name}) }
+
+	err := q.TransactionContext(ctx, func(tx *query.Query) error {
+		if err := create(tx, "committed"); err != nil {
+			return err
+		}
+		if err := tx.SavePoint("before_rolled_back"); err != nil {
+			return err
+		}
+		if err := create(tx, "rolled back to savepoint"); err != nil {
+			return err
+		}
+		if err := tx.RollbackTo("before_rolled_back"); err != nil {
+			return err
+		}
+		// failed nested transaction is rolled back alone
+		nestedErr := tx.TransactionContext(ctx, func(nested *query.Query) error {
+			_ = create(nested, "nested rolled back")
+			return errors.New("rollback nested")
+		})
+		check(nestedErr != nil, "nested transaction should return its error")
+		return tx.Transaction(func(nested *query.Query) error { return create(nested, "nested committed") })
+	})
+	check(err == nil, "transaction fail: %v", err)
+	check(fmt.Sprint(names()) == "[committed nested committed]", "only committed records should be kept, got %v", names())
+
+	err = q.TransactionContext(ctx, func(tx *query.Query) error {
+		_ = create(tx, "rolled back")
+		return errors.New("rollback")
+	})
+	check(err != nil && len(names()) == 2, "failed transaction should be rolled back, got %v %v", err, names())
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = q.TransactionContext(cancelled, func(tx *query.Query) error { return create(tx, "cancelled") })
+	check(errors.Is(err, context.Canceled) && len(names()) == 2, "transaction of cancelled context should fail, got %v %v", err, names())`,
+		"errors", "example.com/app/dao/model")
+}
+
+func TestDIExport(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)", "CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL)")
+	m.require("github.com/google/wire@v0.7.0")
+	config := Config{Mode: WithQueryInterface}
+	config.WithDIExport("wire")
+	m.generate(config)
+	m.run(`
+	_ = query.ProviderSet
+	var users query.IUserDo = query.NewUserDAO(query.NewQuery(db))
+	var posts query.IPostDo = query.NewPostDAO(query.NewQuery(db))
+	check(users.Create(&model.User{Name: "modi"}) == nil && posts.Create(&model.Post{Title: "gen"}) == nil, "create by provided DAOs fail")
+	count, err := users.Count()
+	check(err == nil && count == 1, "provided DAO should query table, got %d %v", count, err)`,
+		"example.com/app/dao/model")
+
+	// DI requires DAO interfaces
+	func() {
+		config := Config{OutPath: filepath.Join(m.dir, "dao", "query")}
+		config.WithDIExport("wire")
+		g := NewGenerator(config)
+		g.UseDB(m.db)
+		g.ApplyBasic(g.GenerateModel("users"))
+		if err := g.export(); err == nil || !strings.Contains(err.Error(), "require WithQueryInterface") {
+			t.Errorf("DI export without WithQueryInterface should fail, got %v", err)
+		}
+	}()
+
+	m.require("go.uber.org/fx@v1.18.2")
+	config = Config{Mode: WithQueryInterface}
+	config.WithDIExport("fx")
+	m.generate(config)
+	m.run(`
+	var count int64
+	app := fx.New(query.Module, fx.Supply(db), fx.NopLogger, fx.Invoke(func(users query.IUserDo, posts query.IPostDo) (err error) {
+		count, err = users.Count()
+		return err
+	}))
+	check(app.Err() == nil && count == 1, "fx should inject provided DAOs, got %v %d", app.Err(), count)`,
+		"go.uber.org/fx")
+}
+
+func TestHintMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER NOT NULL)",
+		"CREATE INDEX idx_users_name ON users (name)", "CREATE UNIQUE INDEX idx_users_name_age ON users (name, age)")
+	m.generate(Config{Mode: WithHintMethod | WithQueryInterface, FieldWithIndexTag: true})
+	content := strings.Join(strings.Fields(m.read("query/users.gen.go")), " ")
+	for _, expect := range []string{`UserIndexIdxUsersName UserIndex = "idx_users_name"`, `UserIndexIdxUsersNameAge UserIndex = "idx_users_name_age"`,
+		"UseIndex(indexes ...UserIndex) IUserDo"} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("query should contain %q, got:\n%s", expect, content)
+		}
+	}
+	m.run(`
+	var sqls []string
+	record := func(tx *gorm.DB) { sqls = append(sqls, strings.ReplaceAll(tx.Statement.SQL.String(), "\x60", "")) }
+	check(db.Callback().Query().After("*").Register("record", record) == nil, "register callback fail")
+	dry := query.Use(db.Session(&gorm.Session{DryRun: true})).User.WithContext(ctx)
+	_, _ = dry.UseIndex(query.UserIndexIdxUsersName).Find()
+	_, _ = dry.ForceIndex(query.UserIndexIdxUsersName, query.UserIndexIdxUsersNameAge).Find()
+	_, _ = dry.IgnoreIndex(query.UserIndexIdxUsersNameAge).Find()
+	expect := []string{
+		"SELECT * FROM users USE INDEX (idx_users_name)",
+		"SELECT * FROM users FORCE INDEX (idx_users_name,idx_users_name_age)",
+		"SELECT * FROM users IGNORE INDEX (idx_users_name_age)",
+	}
+	check(fmt.Sprint(sqls) == fmt.Sprint(expect), "index hints should be added, got %q", sqls)
+
+	// optimizer hint is a comment for sqlite
+	check(q.User.WithContext(ctx).Create(&model.User{Name: "modi", Age: 18}) == nil, "create fail")
+	users, err := q.User.WithContext(ctx).OptimizerHint("MAX_EXECUTION_TIME(1000)", "NO_INDEX_MERGE(users)").Find()
+	check(err == nil && len(users) == 1, "query with optimizer hint fail: %v", err)
+	check(strings.HasPrefix(sqls[len(sqls)-1], "SELECT /*+ MAX_EXECUTION_TIME(1000) NO_INDEX_MERGE(users) */ *"), "optimizer hint should follow SELECT, got %q", sqls[len(sqls)-1])`,
+		"strings", "example.com/app/dao/model")
+
+	// hint methods of indexes require index tags
+	m.generate(Config{Mode: WithHintMethod})
+	if content := m.read("query/users.gen.go"); strings.Contains(content, "UseIndex(") || !strings.Contains(content, "OptimizerHint(") {
+		t.Errorf("only optimizer hint should be generated without index tags, got:\n%s", content)
+	}
+}
+
+func TestNameConst(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER NOT NULL)",
+		"CREATE INDEX idx_users_name ON users (name)")
+	m.generate(Config{FieldWithIndexTag: true})
+	if content := m.read("model/users.gen.go"); strings.Contains(content, "ColumnUserName") {
+		t.Errorf("name constants should require FieldWithNameConst, got:\n%s", content)
+	}
+
+	m.generate(Config{FieldWithIndexTag: true, FieldWithNameConst: true})
+	m.run(`
+	check(model.TableNameUser == "users" && model.ColumnUserID == "id" && model.ColumnUserName == "name" && model.ColumnUserAge == "age",
+		"column name constants should be generated")
+	check(model.IndexUserIdxUsersName == "idx_users_name", "index name constant should be generated")
+
+	check(q.User.WithContext(ctx).Create(&model.User{Name: "modi", Age: 18}) == nil, "create fail")
+	var ages []int
+	err := db.Table(model.TableNameUser).Where(model.ColumnUserName+" = ?", "modi").Pluck(model.ColumnUserAge, &ages).Error
+	check(err == nil && len(ages) == 1 && ages[0] == 18, "raw query by name constants fail: %v %v", err, ages)
+	var indexes []string
+	check(db.Raw("SELECT name FROM pragma_index_list(?)", model.TableNameUser).Scan(&indexes).Error == nil && len(indexes) == 1 &&
+		indexes[0] == model.IndexUserIdxUsersName, "index name constant should match index of table, got %v", indexes)`,
+		"example.com/app/dao/model")
+}
+
+func TestExplainSlowQuery(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	m.generate(Config{})
+	m.run(`
+	var buf bytes.Buffer
+	eq := query.Use(db, gen.WithExplainSlowQuery(time.Nanosecond),
+		gen.WithLogger(logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Warn})))
+	_, err := eq.User.WithContext(ctx).Where(eq.User.Name.Eq("modi")).Find()
+	check(err == nil, "find fail: %v", err)
+	check(strings.Contains(buf.String(), "slow query of query.userDo.Find took") && strings.Contains(buf.String(), "users"),
+		"plan should be logged with generated method, got:\n%s", &buf)`,
+		"bytes", "log", "strings", "time", "gorm.io/gen", "gorm.io/gorm/logger")
+}
+
+// genModule module in temp dir depending on gen of this repo, code generated into it is type-checked and can be run
+type genModule struct {
+	t      *testing.T
+	dir    string
+	dsn    string
+	db     *gorm.DB
+	runs   int
+	schema string // schema json used to generate instead of db, see useSchema
+}
+
+// newGenModule create module in temp dir with sqlite db of tables created by ddl
+func newGenModule(t *testing.T, ddl ...string) *genModule {
+	t.Helper()
+	root, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatalf("get path of gen fail: %s", err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatalf("read go.sum fail: %s", err)
+	}
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.18\n\nrequire gorm.io/gen v0.0.0\n\nreplace gorm.io/gen => " + filepath.ToSlash(root) + "\n"
+	if err = os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0o644); err != nil {
+		t.Fatalf("write go.sum fail: %s", err)
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	dsn := filepath.Join(dir, "gen.db")
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Error)})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	for _, sql := range ddl {
+		if err = db.Exec(sql).Error; err != nil {
+			t.Fatalf("exec %s fail: %s", sql, err)
+		}
+	}
+	return &genModule{t: t, dir: dir, dsn: dsn, db: db}
+}
+
+// generate generate models and query code of all tables into dao of module, generated code is type-checked
+func (m *genModule) generate(config Config, opts ...ModelOpt) {
+	m.t.Helper()
+	m.generateModels(config, func(g *Generator) []interface{} { return g.GenerateAllTable(opts...) })
+}
+
+// generateModels generate models returned by models and their query code into dao of module, e.g. models with relations
+func (m *genModule) generateModels(config Config, models func(g *Generator) []interface{}) {
+	m.t.Helper()
+	config.OutPath, config.Verify = filepath.Join(m.dir, "dao", "query"), verifyBuild
+	g := NewGenerator(config)
+	g.UseDB(m.db)
+	if m.schema != "" {
+		g.UseSchemaJSON(m.schema)
+	}
+	g.ApplyBasic(models(g)...)
+	g.Execute()
+}
+
+// generateBy run program generating code into dao of module, it's called with dsn of db and output path of query code.
+// It's for DIY methods of ApplyInterface, whose interface is declared in source of program
+func (m *genModule) generateBy(program string) {
+	m.t.Helper()
+	dir := filepath.Join(m.dir, "cmd", "gen")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		m.t.Fatalf("make dir fail: %s", err)
+	}
+	defer os.RemoveAll(dir) // nolint
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(program), 0o644); err != nil {
+		m.t.Fatalf("write program fail: %s", err)
+	}
+	cmd := exec.Command("go", "run", ".", m.dsn, filepath.Join(m.dir, "dao", "query"))
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		m.t.Fatalf("generate by program fail: %s\n%s", err, output)
+	}
+}
+
+// useSchema generate from schema of tables in db as if it's db of dialect with columns edited by edits, e.g.
+// {"orders.status": columnType("enum('pending','paid')")}, so types sqlite doesn't support are generated and run on sqlite
+func (m *genModule) useSchema(dialect string, edits map[string]func(*model.ColumnSchema)) {
+	m.t.Helper()
+	m.schema = ""
+	schemaFile := filepath.Join(m.dir, "schema.json")
+	config := Config{OutPath: filepath.Join(m.dir, "dao", "query")}
+	config.WithSchemaJSONExport(schemaFile)
+	g := NewGenerator(config)
+	g.UseDB(m.db)
+	g.GenerateAllTable()
+	g.Export()
+
+	var s model.Schema
+	if err := json.Unmarshal([]byte(m.read("../schema.json")), &s); err != nil {
+		m.t.Fatalf("parse schema json fail: %s", err)
+	}
+	s.Dialect = dialect
+	for _, table := range s.Tables {
+		for _, col := range table.Columns {
+			if edit, ok := edits[table.Name+"."+col.Name]; ok {
+				edit(col)
+			}
+		}
+	}
+	content, err := json.Marshal(&s)
+	if err != nil {
+		m.t.Fatalf("marshal schema json fail: %s", err)
+	}
+	if err = os.WriteFile(schemaFile, content, 0o644); err != nil {
+		m.t.Fatalf("write schema json fail: %s", err)
+	}
+	m.schema = schemaFile
+}
+
+// columnType edit type of column in schema, e.g. enum('pending','paid')
+func columnType(typ string) func(*model.ColumnSchema) {
+	return func(col *model.ColumnSchema) {
+		col.DatabaseType, col.ColumnType, col.GoType = strings.SplitN(typ, "(", 2)[0], typ, ""
+	}
+}
+
+// columnComment edit comment of column in schema
+func columnComment(comment string) func(*model.ColumnSchema) {
+	return func(col *model.ColumnSchema) { col.Comment = &comment }
+}
+
+func TestExistsMethod(t *testing.T) {
+	m := newGenModule(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER)", "CREATE INDEX idx_users_age ON users (age)")
+	m.generate(Config{Mode: WithExistsMethod | WithQueryInterface, FieldWithIndexTag: true})
+	for _, expect := range []string{"Exists(conds ...gen.Condition) (bool, error)", "ExistsByID(value int64) (bool, error)", "CountByAge(value int32) (int64, error)"} {
+		if content := m.read("query/users.gen.go"); !strings.Contains(content, expect) {
+			t.Errorf("query should contain %q, got:\n%s", expect, content)
+		}
+	}
+
+	m.generate(Config{Mode: WithExistsMethod, FieldWithIndexTag: true})
+	m.run(`
+	u := q.User.WithContext(ctx)
+	check(u.Create(&model.User{Name: "modi", Age: 18}) == nil, "create fail")
+	exists, err := u.ExistsByID(1)
+	check(err == nil && exists, "ExistsByID of created record should be true, got %v %v", exists, err)
+	exists, err = u.Exists(q.User.Name.Eq("gorm"))
+	check(err == nil && !exists, "Exists of unmatched condition should be false, got %v %v", exists, err)
+	count, err := u.CountByAge(18)
+	check(err == nil && count == 1, "CountByAge should count created record, got %v %v", count, err)`, "example.com/app/dao/model")
+}
+
+// read return content of generated file, name is relative to dao of module
+func (m *genModule) read(name string) string {
+	m.t.Helper()
+	content, err := os.ReadFile(filepath.Join(m.dir, "dao", name))
+	if err != nil {
+		m.t.Fatalf("read generated file fail: %s", err)
+	}
+	return string(content)
+}
+
+// files return content of all generated files by name relative to dao of module
+func (m *genModule) files() map[string]string {
+	m.t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(filepath.Join(m.dir, "dao"), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		files[strings.TrimPrefix(path, filepath.Join(m.dir, "dao")+string(filepath.Separator))] = string(content)
+		return err
+	})
+	if err != nil {
+		m.t.Fatalf("read generated files fail: %s", err)
+	}
+	return files
+}
+
+// run run body of main func using generated query code, q is query.Use of db and ctx is context.Background().
+// Failures reported by check(ok, format, args...) of body fail the test
+func (m *genModule) run(body string, imports ...string) {
+	m.t.Helper()
+	m.runs++
+	dir := filepath.Join(m.dir, "cmd", fmt.Sprintf("run%d", m.runs))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		m.t.Fatalf("make dir fail: %s", err)
+	}
+	for i, path := range imports {
+		imports[i] = fmt.Sprintf("\t%q\n", path)
+	}
+	program := fmt.Sprintf(runProgram, strings.Join(imports, ""), body)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(program), 0o644); err != nil {
+		m.t.Fatalf("write program fail: %s", err)
+	}
+
+	cmd := exec.Command("go", "run", ".", m.dsn)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		m.t.Fatalf("run program fail: %s\n%s\n%s", err, output, program)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "FAIL: ") {
+			m.t.Error(strings.TrimPrefix(line, "FAIL: "))
+		}
+	}
+}
+
+// require add modules required by generated code to module, the test is skipped if they can't be downloaded
+func (m *genModule) require(modules ...string) {
+	m.t.Helper()
+	cmd := exec.Command("go", append([]string{"get"}, modules...)...)
+	cmd.Dir = m.dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		m.t.Skipf("get %s fail: %s\n%s", strings.Join(modules, " "), err, output)
+	}
+}
+
+// test run generated unit tests of query code
+func (m *genModule) test() {
+	m.t.Helper()
+	cmd := exec.Command("go", "test", "./dao/query/")
+	cmd.Dir = m.dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		m.t.Errorf("generated unit tests fail: %s\n%s", err, output)
+	}
+}
+
+const runProgram = `package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"example.com/app/dao/query"
+%s)
+
+var _ = fmt.Sprint
+
+func main() {
+	db, openErr := gorm.Open(sqlite.Open(os.Args[1]), &gorm.Config{})
+	if openErr != nil {
+		panic(openErr)
+	}
+	q, ctx := query.Use(db), context.Background()
+	_, _ = q, ctx
+
+%s
+}
+
+func check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		fmt.Printf("FAIL: "+format+"\n", args...)
+	}
+}
+`
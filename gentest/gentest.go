@@ -0,0 +1,261 @@
+// Package gentest provides golden file testing of generation, which renders code of a fixture schema and compares
+// it with golden files, so template overrides and config changes can be regression-tested:
+//
+//	func TestGenerate(t *testing.T) {
+//		gentest.Run(t, gentest.Case{
+//			Config:    gen.Config{Mode: gen.WithDefaultQuery, QueryTemplateDir: "templates"},
+//			SchemaSQL: "testdata/schema.sql",
+//		})
+//	}
+//
+// Golden files(testdata/golden by default) are written by running the test with -gentest.update, review and commit
+// them, later changes of generated code fail the test with the first differing line of each file.
+package gentest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"gorm.io/gen"
+)
+
+var update = flag.Bool("gentest.update", false, "write generated code into golden files of gentest instead of comparing")
+
+// Case generation of fixture schema checked against golden files
+type Case struct {
+	// config of generation, OutPath(default query), relative ModelPkgPath, OutFile, LockFile and ManifestFile are
+	// in output directory, templates and other paths are relative to the test as usual. Timestamp is always omitted
+	Config gen.Config
+
+	SchemaSQL  string // file of DDL statements executed in embedded SQLite as fixture schema
+	SchemaJSON string // metadata file exported by WithSchemaJSONExport used as fixture schema instead of SchemaSQL
+
+	Tables    []string // tables to generate, default all tables
+	OnlyModel bool     // generate models only
+
+	// called before models are generated to apply more options, exports should be written into dir(output directory)
+	Setup func(g *gen.Generator, dir string)
+
+	Golden string // directory of golden files, default testdata/golden
+	Module string // module path of generated code, default gentest
+}
+
+// Run generate code of c and compare it with golden files, golden files are written instead with -gentest.update
+func Run(t testing.TB, c Case) {
+	t.Helper()
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	if err := generate(dir, out, c); err != nil {
+		t.Fatalf("generate fail: %s", err)
+	}
+
+	golden := c.Golden
+	if golden == "" {
+		golden = filepath.Join("testdata", "golden")
+	}
+	if *update {
+		if err := updateGolden(out, golden); err != nil {
+			t.Fatalf("update golden files fail: %s", err)
+		}
+		t.Logf("golden files in %s are updated", golden)
+		return
+	}
+
+	diffs, err := compare(out, golden)
+	if err != nil {
+		t.Fatalf("compare with golden files fail: %s", err)
+	}
+	for _, diff := range diffs {
+		t.Error(diff)
+	}
+	if len(diffs) > 0 {
+		t.Logf("review the changes and run with -gentest.update to update golden files in %s", golden)
+	}
+}
+
+// generate code of c into out, fixture database is created in dir
+func generate(dir, out string, c Case) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	module := c.Module
+	if module == "" {
+		module = "gentest"
+	}
+	if err = os.MkdirAll(out, os.ModePerm); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(filepath.Join(out, "go.mod"), []byte("module "+module+"\n"), 0o644); err != nil {
+		return err
+	}
+
+	cfg := c.Config
+	if cfg.OutPath == "" {
+		cfg.OutPath = "query"
+	}
+	cfg.OutPath = filepath.Join(out, cfg.OutPath)
+	for _, path := range []*string{&cfg.ModelPkgPath, &cfg.OutFile} {
+		if strings.ContainsRune(*path, os.PathSeparator) && !filepath.IsAbs(*path) {
+			*path = filepath.Join(out, *path)
+		}
+	}
+	for _, path := range []*string{&cfg.LockFile, &cfg.ManifestFile} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(out, *path)
+		}
+	}
+	cfg.OmitTimestamp = true
+
+	g := gen.NewGenerator(cfg)
+	switch {
+	case c.SchemaJSON != "":
+		g.UseSchemaJSON(c.SchemaJSON)
+	case c.SchemaSQL != "":
+		db, err := openSchema(filepath.Join(dir, "schema.db"), c.SchemaSQL)
+		if err != nil {
+			return err
+		}
+		g.UseDB(db)
+	default:
+		return errors.New("fixture schema is required, set SchemaSQL or SchemaJSON")
+	}
+	if c.Setup != nil {
+		c.Setup(g, out)
+	}
+
+	var models []interface{}
+	if len(c.Tables) > 0 {
+		models = g.GenerateModels(c.Tables)
+	} else {
+		models = g.GenerateAllTable()
+	}
+	if !c.OnlyModel {
+		g.ApplyBasic(models...)
+	}
+	g.Execute()
+	return nil
+}
+
+// openSchema create SQLite database of file by DDL statements in schemaFile
+func openSchema(file, schemaFile string) (*gorm.DB, error) {
+	ddl, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(sqlite.Open(file))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite fail: %w", err)
+	}
+	if err = db.Exec(string(ddl)).Error; err != nil {
+		return nil, fmt.Errorf("execute %s fail: %w", schemaFile, err)
+	}
+	return db, nil
+}
+
+// readFiles slash separated path relative to dir -> content of files in dir, go.mod of output is ignored
+func readFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "go.mod" {
+			return err
+		}
+		files[filepath.ToSlash(rel)], err = ioutil.ReadFile(path)
+		return err
+	})
+	return files, err
+}
+
+// compare generated files in out with golden files, return differences of files sorted by path
+func compare(out, golden string) (diffs []string, err error) {
+	got, err := readFiles(out)
+	if err != nil {
+		return nil, err
+	}
+	want, err := readFiles(golden)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(got)+len(want))
+	for path := range got {
+		paths = append(paths, path)
+	}
+	for path := range want {
+		if _, ok := got[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		g, generated := got[path]
+		w, ok := want[path]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s: generated but not in golden files", path))
+		case !generated:
+			diffs = append(diffs, fmt.Sprintf("%s: in golden files but not generated", path))
+		case !bytes.Equal(g, w):
+			diffs = append(diffs, fmt.Sprintf("%s: %s", path, diffLine(w, g)))
+		}
+	}
+	return diffs, nil
+}
+
+// diffLine first differing line of want and got
+func diffLine(want, got []byte) string {
+	wantLines, gotLines := strings.Split(string(want), "\n"), strings.Split(string(got), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g || i >= len(wantLines) || i >= len(gotLines) {
+			return fmt.Sprintf("line %d\n\twant: %q\n\tgot:  %q", i+1, w, g)
+		}
+	}
+	return "content differs"
+}
+
+// updateGolden replace golden files with generated files in out
+func updateGolden(out, golden string) error {
+	files, err := readFiles(out)
+	if err != nil {
+		return err
+	}
+	if err = os.RemoveAll(golden); err != nil {
+		return err
+	}
+	for path, content := range files {
+		fileName := filepath.Join(golden, filepath.FromSlash(path))
+		if err = os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(fileName, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package gentest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gen"
+)
+
+func TestRunSchemaSQL(t *testing.T) {
+	Run(t, Case{Config: gen.Config{FieldNullable: true}, SchemaSQL: "testdata/schema.sql", OnlyModel: true})
+}
+
+func TestRunSchemaJSON(t *testing.T) {
+	Run(t, Case{Config: gen.Config{FieldNullable: true}, SchemaJSON: "testdata/schema.json", OnlyModel: true})
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	out, golden := filepath.Join(dir, "out"), filepath.Join(dir, "golden")
+	if err := generate(dir, out, Case{SchemaSQL: "testdata/schema.sql", Tables: []string{"users"}}); err != nil {
+		t.Fatalf("generate fail: %s", err)
+	}
+	if err := updateGolden(out, golden); err != nil {
+		t.Fatalf("update golden files fail: %s", err)
+	}
+	if diffs, err := compare(out, golden); err != nil || len(diffs) != 0 {
+		t.Fatalf("generated files should equal golden files, got: %v %v", diffs, err)
+	}
+
+	modelFile := filepath.Join(golden, "model", "users.gen.go")
+	content, err := ioutil.ReadFile(modelFile)
+	if err != nil {
+		t.Fatalf("read golden file fail: %s", err)
+	}
+	if err = ioutil.WriteFile(modelFile, []byte(strings.Replace(string(content), "Email", "Mail", 1)), 0o644); err != nil {
+		t.Fatalf("write golden file fail: %s", err)
+	}
+	if err = os.Remove(filepath.Join(golden, "query", "gen.go")); err != nil {
+		t.Fatalf("remove golden file fail: %s", err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(golden, "query", "orders.gen.go"), nil, 0o644); err != nil {
+		t.Fatalf("write golden file fail: %s", err)
+	}
+
+	diffs, err := compare(out, golden)
+	if err != nil {
+		t.Fatalf("compare fail: %s", err)
+	}
+	expects := []string{"model/users.gen.go: line ", "query/gen.go: generated but not in golden files", "query/orders.gen.go: in golden files but not generated"}
+	if len(diffs) != len(expects) {
+		t.Fatalf("expect %d differences, got: %q", len(expects), diffs)
+	}
+	for i, expect := range expects {
+		if !strings.HasPrefix(diffs[i], expect) {
+			t.Errorf("difference %d should start with %q, got: %q", i, expect, diffs[i])
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package model
+
+const TableNameOrder = "orders"
+
+// Order mapped from table <orders>
+type Order struct {
+	ID     int64   `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	UserID int32   `gorm:"column:user_id;not null" json:"user_id"`
+	Amount float64 `gorm:"column:amount;not null" json:"amount"`
+}
+
+// TableName Order's table name
+func (*Order) TableName() string {
+	return TableNameOrder
+}
@@ -0,0 +1,39 @@
+package gen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+)
+
+// fileHeader build header of generated go files, header is text or path of file, see Config.FileHeader
+func fileHeader(header, buildTags string) []byte {
+	var buf bytes.Buffer
+	if header = strings.TrimSpace(header); header != "" {
+		if !strings.Contains(header, "\n") {
+			if content, err := ioutil.ReadFile(header); err == nil {
+				header = strings.TrimSpace(string(content))
+			}
+		}
+		if strings.HasPrefix(header, "/*") {
+			buf.WriteString(header + "\n")
+		} else {
+			for _, line := range strings.Split(header, "\n") {
+				line = strings.TrimRight(line, " \t\r")
+				switch {
+				case strings.HasPrefix(line, "//"):
+				case line == "":
+					line = "//"
+				default:
+					line = "// " + line
+				}
+				buf.WriteString(line + "\n")
+			}
+		}
+		buf.WriteString("\n")
+	}
+	if buildTags = strings.TrimSpace(buildTags); buildTags != "" {
+		buf.WriteString("//go:build " + buildTags + "\n\n")
+	}
+	return buf.Bytes()
+}
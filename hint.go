@@ -0,0 +1,10 @@
+package gen
+
+// IndexNames convert <Model>Index constants generated by WithHintMethod into index names of index hints
+func IndexNames[T ~string](indexes []T) []string {
+	names := make([]string, len(indexes))
+	for i, index := range indexes {
+		names[i] = string(index)
+	}
+	return names
+}
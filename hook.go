@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gorm.io/gen/internal/generate"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// hookStubs hooks of model whose stubs are generated, see Config.WithHooks
+type hookStubs struct {
+	*generate.QueryStructMeta
+	Hooks []string
+}
+
+// generateHookStubs generate stubs of WithHooks into <model file>_hooks.go of models if it doesn't exist
+func (g *Generator) generateHookStubs(modelOutPath string) error {
+	if len(g.WithHooks) == 0 {
+		return nil
+	}
+	for _, data := range g.models {
+		if data == nil || !data.Generated {
+			continue
+		}
+		stubFile := g.modelDir(modelOutPath, data) + data.FileName + "_hooks.go"
+		if _, err := os.Stat(stubFile); err == nil || !os.IsNotExist(err) {
+			continue
+		}
+
+		stubs := hookStubs{QueryStructMeta: data}
+		for _, hook := range g.WithHooks {
+			if !g.generatedHook(data, hook) {
+				stubs.Hooks = append(stubs.Hooks, hook)
+			}
+		}
+		if len(stubs.Hooks) == 0 {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := g.modelTemplates.render(modelHookStubTemplate, tmpl.ModelHookStub, &buf, stubs); err != nil {
+			return err
+		}
+		if err := g.output(stubFile, buf.Bytes()); err != nil {
+			return err
+		}
+		g.info(fmt.Sprintf("generate hook stub file(%v of {%s.%s}): %s", stubs.Hooks, data.StructInfo.Package, data.StructInfo.Type, stubFile))
+	}
+	return nil
+}
+
+// generatedHook whether hook of model is declared by model methods or generated by UUIDHook and TimeUTC
+func (g *Generator) generatedHook(data *generate.QueryStructMeta, hook string) bool {
+	switch {
+	case data.HasModelMethod(hook):
+		return true
+	case hook == "BeforeCreate":
+		return g.UUIDHook && len(data.UUIDKeys()) > 0
+	case hook == "BeforeSave" || hook == "AfterFind":
+		return g.TimeUTC && len(data.UTCFields()) > 0 && !data.HasModelMethod("BeforeSave") && !data.HasModelMethod("AfterFind")
+	default:
+		return false
+	}
+}
@@ -15,8 +15,10 @@ var (
 		"gorm.io/gen",
 		"gorm.io/gen/field",
 		"gorm.io/gen/helper",
+		"gorm.io/gen/tracing",
 		"",
 		"gorm.io/plugin/dbresolver",
+		"gorm.io/hints",
 	)
 	unitTestImportList = new(importPkgS).Add(
 		"context",
@@ -26,6 +28,16 @@ var (
 		"",
 		"gorm.io/driver/sqlite",
 		"gorm.io/gorm",
+		"",
+		"gorm.io/gen",
+	)
+	fakeImportList = new(importPkgS).Add(
+		"fmt",
+		"sync",
+		"",
+		"gorm.io/gorm",
+		"",
+		"gorm.io/gen",
 	)
 )
 
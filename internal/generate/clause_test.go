@@ -151,6 +151,61 @@ func TestClause(t *testing.T) {
 				"helper.JoinWhereBuilder(&generateSQL,whereSQL0)",
 			},
 		},
+		{
+			SQL: "select * from @@table {{where}}{{for name in names}}name=@name{{end}}{{end}}",
+			SplitResult: []string{
+				"\"select * from \"",
+				"\"users\"",
+				"where",
+				"for _, name := range names",
+				"\"name=\"",
+				"name",
+				"end",
+				"end",
+			},
+			GenerateResult: []string{
+				"generateSQL.WriteString(\"select * from users \")",
+				"var whereSQL0 strings.Builder",
+				"for _, name := range names{",
+				"params = append(params,name)",
+				"whereSQL0.WriteString(\"name=? \")",
+				"}",
+				"helper.JoinWhereBuilder(&generateSQL,whereSQL0)",
+			},
+		},
+		{
+			SQL: "select * from @@table where {{trim}}{{if id > 0}}id=@id{{else if name != \"\"}}name=@name{{end}}{{trim}}{{end}}{{end}}",
+			SplitResult: []string{
+				"\"select * from \"",
+				"\"users\"",
+				"\" where \"",
+				"trim",
+				"if id > 0",
+				"\"id=\"",
+				"id",
+				"else if name != \"\"",
+				"\"name=\"",
+				"name",
+				"end",
+				"trim",
+				"end",
+				"end",
+			},
+			GenerateResult: []string{
+				"generateSQL.WriteString(\"select * from users where \")",
+				"var trimSQL0 strings.Builder",
+				"if id > 0 {",
+				"params = append(params,id)",
+				"trimSQL0.WriteString(\"id=? \")",
+				"} else if name != \"\" {",
+				"params = append(params,name)",
+				"trimSQL0.WriteString(\"name=? \")",
+				"}",
+				"var trimSQL1 strings.Builder",
+				"helper.JoinTrimAllBuilder(&trimSQL0,trimSQL1)",
+				"helper.JoinTrimAllBuilder(&generateSQL,trimSQL0)",
+			},
+		},
 	}
 	inface := m()
 	for _, testcase := range testcases {
@@ -180,3 +235,40 @@ var m = func() *InterfaceMethod {
 	return m
 
 }
+
+func TestDialectSQL(t *testing.T) {
+	doc := "select * from @@table\n-- dialect: postgres, sqlite\nselect * from @@table where name ILIKE @name\n-- dialect: mysql\nselect * from @@table where name LIKE @name"
+
+	inface := m()
+	inface.Doc = doc
+	if err := inface.checkSQL(); err != nil {
+		t.Fatalf("check sql fail: %s", err)
+	}
+	if inface.SQLString != "select * from @@table" || len(inface.Dialects) != 2 {
+		t.Fatalf("expect unmarked sql and 2 variants, got: %q %d", inface.SQLString, len(inface.Dialects))
+	}
+	if cases := inface.Dialects[0].Cases(); cases != `"postgres", "sqlite"` {
+		t.Errorf("expect cases of postgres and sqlite, got: %s", cases)
+	}
+	if _, err := inface.Dialects[1].Section.BuildSQL(); err != nil {
+		t.Fatalf("build sql fail: %s", err)
+	}
+	if tmpl := inface.Dialects[1].Section.Tmpls[1]; tmpl != "generateSQL.WriteString(\"select * from users where name LIKE ? \")" {
+		t.Errorf("unexpected sql of mysql: %s", tmpl)
+	}
+
+	inface = m()
+	inface.Doc, inface.dialect = doc, "mysql"
+	if err := inface.checkSQL(); err != nil {
+		t.Fatalf("check sql fail: %s", err)
+	}
+	if inface.SQLString != "select * from @@table where name LIKE @name" || inface.Dialects != nil {
+		t.Errorf("expect only sql of mysql, got: %q %d", inface.SQLString, len(inface.Dialects))
+	}
+
+	inface = m()
+	inface.Doc, inface.dialect = "-- dialect: mysql\nselect 1", "postgres"
+	if err := inface.checkSQL(); err == nil {
+		t.Errorf("expect error of missing sql of dialect")
+	}
+}
@@ -18,7 +18,7 @@ import (
 
 // GetQueryStructMeta generate db model by table name
 func GetQueryStructMeta(db *gorm.DB, conf *model.Config) (*QueryStructMeta, error) {
-	if _, ok := db.Config.Dialector.(tests.DummyDialector); ok {
+	if _, ok := db.Config.Dialector.(tests.DummyDialector); ok && conf.Schema == nil {
 		return nil, fmt.Errorf("UseDB() is necessary to generate model struct [%s] from database table [%s]", conf.ModelName, conf.TableName)
 	}
 
@@ -31,11 +31,26 @@ func GetQueryStructMeta(db *gorm.DB, conf *model.Config) (*QueryStructMeta, erro
 		return nil, fmt.Errorf("model name %q is invalid: %w", structName, err)
 	}
 
-	columns, err := getTableColumns(db, conf.GetSchemaName(db), tableName, conf.FieldWithIndexTag)
-	if err != nil {
-		return nil, err
+	var columns []*model.Column
+	if conf.Schema != nil {
+		tableSchema := conf.Schema.Table(tableName)
+		if tableSchema == nil {
+			return nil, fmt.Errorf("table [%s] is not found in schema", tableName)
+		}
+		columns = tableSchema.GetColumns(conf.FieldWithIndexTag, conf.FieldWithConstraintTag, conf.FieldWithRelation)
+		for _, c := range columns {
+			c.Dialect = conf.Schema.Dialect
+		}
+	} else {
+		var err error
+		columns, err = getTableColumns(db, conf.GetSchemaName(db), tableName, conf.FieldWithIndexTag, conf.FieldWithEnumType,
+			conf.FieldWithConstraintTag, conf.FieldWithRelation)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	fields := getFields(db, conf, structName, columns)
 	return (&QueryStructMeta{
 		db:              db,
 		Source:          model.Table,
@@ -46,8 +61,9 @@ func GetQueryStructMeta(db *gorm.DB, conf *model.Config) (*QueryStructMeta, erro
 		QueryStructName: uncaptialize(structName),
 		S:               strings.ToLower(structName[0:1]),
 		StructInfo:      parser.Param{Type: structName, Package: conf.ModelPkg},
-		ImportPkgPaths:  conf.ImportPkgPaths,
-		Fields:          getFields(db, conf, columns),
+		ImportPkgPaths:  append(typeImportPaths(fields), conf.ImportPkgPaths...),
+		Fields:          fields,
+		tableSchema:     model.NewTableSchema(tableName, columns),
 	}).addMethodFromAddMethodOpt(conf.GetModelMethods()...), nil
 }
 
@@ -173,8 +189,9 @@ func isNil(i interface{}) bool {
 	return v.Kind() == reflect.Ptr && v.IsNil()
 }
 
-// BuildDIYMethod check the legitimacy of interfaces
-func BuildDIYMethod(f *parser.InterfaceSet, s *QueryStructMeta, data []*InterfaceMethod) (checkResults []*InterfaceMethod, err error) {
+// BuildDIYMethod check the legitimacy of interfaces, fragments are named SQL fragments included by {{include "name"}},
+// only SQL variants of dialect annotated by "-- dialect: <name>" are generated if dialect is not empty
+func BuildDIYMethod(f *parser.InterfaceSet, s *QueryStructMeta, data []*InterfaceMethod, fragments map[string]string, dialect string) (checkResults []*InterfaceMethod, err error) {
 	for _, interfaceInfo := range f.Interfaces {
 		if interfaceInfo.MatchStruct(s.ModelStructName) {
 			for _, method := range interfaceInfo.Methods {
@@ -188,6 +205,8 @@ func BuildDIYMethod(f *parser.InterfaceSet, s *QueryStructMeta, data []*Interfac
 					Table:         s.TableName,
 					InterfaceName: interfaceInfo.Name,
 					Package:       getPackageName(interfaceInfo.Package),
+					fragments:     fragments,
+					dialect:       dialect,
 				}
 				if err = t.checkMethod(data, s); err != nil {
 					return nil, err
@@ -206,6 +225,12 @@ func BuildDIYMethod(f *parser.InterfaceSet, s *QueryStructMeta, data []*Interfac
 					err = fmt.Errorf("sql [%s] build err:%w", t.SQLString, err)
 					return
 				}
+				for _, variant := range t.Dialects {
+					if _, err = variant.Section.BuildSQL(); err != nil {
+						err = fmt.Errorf("sql [%s] build err:%w", variant.SQLString, err)
+						return
+					}
+				}
 				checkResults = append(checkResults, t)
 			}
 		}
@@ -1,6 +1,7 @@
 package generate
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -8,28 +9,73 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 
+	"gorm.io/gen/field"
 	"gorm.io/gen/internal/model"
 )
 
+// knownTypePkgs package name -> import path of well-known packages used by generated field types
+var knownTypePkgs = map[string]string{
+	"pq":      `"github.com/lib/pq"`,
+	"pgtype":  `"github.com/jackc/pgtype"`,
+	"uuid":    `"github.com/google/uuid"`,
+	"decimal": `"github.com/shopspring/decimal"`,
+	"orb":     `"github.com/paulmach/orb"`,
+	"mssql":   `mssql "github.com/microsoft/go-mssqldb"`,
+	"gen":     `"gorm.io/gen"`,
+}
+
+// ewkbPkgPath import path of package scanning and storing named geometry types
+const ewkbPkgPath = `"github.com/paulmach/orb/encoding/ewkb"`
+
 /*
 ** The feature of mapping table from database server to Golang struct
 ** Provided by @qqxhb
  */
 
-func getFields(db *gorm.DB, conf *model.Config, columns []*model.Column) (fields []*model.Field) {
+func getFields(db *gorm.DB, conf *model.Config, structName string, columns []*model.Column) (fields []*model.Field) {
+	foreignKeys := make(map[string]*model.ForeignKey) // field name -> foreign key of column
 	for _, col := range columns {
 		col.SetDataTypeMap(conf.DataTypeMap)
+		col.SetArrayTypeMap(conf.ArrayTypeMap)
+		col.SetIntegerTypeMap(conf.IntegerTypeMap)
 		col.WithNS(conf.FieldJSONTagNS)
 
 		m := col.ToField(conf.FieldNullable, conf.FieldCoverable, conf.FieldSignable)
+		if col.UnknownType() {
+			db.Logger.Warn(context.Background(), "unknown type %s of column %s.%s, fall back to %s", col.DatabaseTypeName(), conf.TableName, col.Name(), m.Type)
+		}
+		typ := strings.TrimLeft(m.Type, "*")
+		if conf.FieldWithJSONType && typ == "string" && col.IsJSON() {
+			m.Type = strings.TrimSuffix(m.Type, typ) + "datatypes.JSON"
+		}
+		_, mapped := conf.DataTypeMap[col.DatabaseTypeName()]
+		isDecimal := conf.DecimalType != "" && !mapped && col.IsDecimal()
+		if isDecimal {
+			m.Type = strings.TrimSuffix(m.Type, typ) + conf.DecimalType
+		}
+		timeType := map[string]string{"date": conf.DateType, "time": conf.TimeType, "datetime": conf.DateTimeType}[col.TimeKind()]
+		if timeType != "" && !mapped && typ == "time.Time" {
+			m.Type = strings.TrimSuffix(m.Type, typ) + timeType
+		}
 
 		if filterField(m, conf.FilterOpts) == nil {
 			continue
 		}
-		if _, ok := col.ColumnType.ColumnType(); ok && !conf.FieldWithTypeTag { // remove type tag if FieldWithTypeTag == false
+		_, isArray := col.ArrayElemType()
+		binaryUUID, isUUID := col.UUIDKind(conf.UUIDColumnTypes)
+		isUUID = isUUID && conf.FieldWithUUIDType
+		geometry, isGeometry := col.GeometryKind()
+		// type of array, uuid, decimal, spatial and date/time stored as string cannot be inferred from go type, keep its type tag
+		keepType := isArray || isUUID || isDecimal || isGeometry || (timeType == "string" && !mapped)
+		if _, ok := col.ColumnType.ColumnType(); ok && !conf.FieldWithTypeTag && !keepType { // remove type tag if FieldWithTypeTag == false
 			m.GORMTag.Remove("type")
 		}
 
+		m.DocComment = conf.FieldWithDocComment
+		if _, deprecated := m.Deprecated(); deprecated && conf.FieldOmitDeprecated {
+			m.GORMTag.Set(field.TagKeyGormWritePermission, "update")
+		}
+
 		m = modifyField(m, conf.ModifyOpts)
 		if ns, ok := db.NamingStrategy.(schema.NamingStrategy); ok {
 			ns.SingularTable = true
@@ -38,6 +84,65 @@ func getFields(db *gorm.DB, conf *model.Config, columns []*model.Column) (fields
 			m.Name = db.NamingStrategy.SchemaName(m.Name)
 		}
 
+		if typ := strings.TrimLeft(m.Type, "*"); isUUID && (typ == "string" || typ == "[]byte") {
+			uuidType := model.UUIDType
+			if binaryUUID {
+				m.BinaryUUID = &model.BinaryUUID{Name: structName + m.Name, ColumnName: m.ColumnName}
+				uuidType = m.BinaryUUID.Name
+			}
+			m.Type = strings.TrimSuffix(m.Type, typ) + uuidType
+		}
+
+		// orb type specified by FieldGeometryType
+		if typ := strings.TrimLeft(m.Type, "*"); isGeometry && (conf.FieldWithGeometryType || strings.HasPrefix(typ, "orb.")) {
+			if strings.HasPrefix(typ, "orb.") {
+				geometry.Type = typ
+			}
+			geometry.Name, geometry.ColumnName = structName+m.Name, m.ColumnName
+			geometry.PostGIS = geometry.PostGIS || db.Dialector.Name() == "postgres"
+			m.Geometry = geometry
+			m.Type = strings.TrimSuffix(m.Type, typ) + geometry.Name
+		}
+
+		switch typ := strings.TrimLeft(m.Type, "*"); {
+		case conf.FieldWithEnumType && typ == "string":
+			if values := col.GetEnumValues(); len(values) > 0 {
+				withEnum(m, model.NewStringEnum(structName+m.Name, m.ColumnName, values))
+			} else if values := col.GetSetValues(); len(values) > 0 {
+				withEnum(m, model.NewStringSet(structName+m.Name, m.ColumnName, values))
+			}
+		case conf.FieldWithCommentEnum && isInteger(typ):
+			if values, labels := model.ParseCommentEnum(m.ColumnComment); len(values) > 0 {
+				enum := &model.Enum{Name: structName + m.Name, Type: typ, ColumnName: m.ColumnName}
+				for i, v := range values {
+					enum.Append(v, v, labels[i], "")
+				}
+				withEnum(m, enum)
+			}
+		}
+
+		// sensitive column encrypted by serializer and masked in output
+		if typ := strings.TrimLeft(m.Type, "*"); typ == "string" && conf.IsPIIColumn(m.ColumnName) {
+			if m.CustomGenType == "" {
+				m.CustomGenType = m.GenType()
+			}
+			m.Type = strings.TrimSuffix(m.Type, typ) + model.PIIType
+			m.GORMTag.Set(field.TagKeyGormSerializer, "pii")
+		}
+
+		if n, ok := col.Nullable(); ok && n && conf.FieldNullable && strings.HasPrefix(m.Type, "*") {
+			if typ, ok := nullType(conf.NullableStyle, strings.TrimPrefix(m.Type, "*")); ok {
+				if m.CustomGenType == "" {
+					m.CustomGenType = m.GenType()
+				}
+				m.Type = typ
+			}
+		}
+
+		if col.ForeignKey != nil {
+			foreignKeys[m.Name] = col.ForeignKey
+		}
+		m.Validation = col.Validation(conf.ValidateNotNullZero)
 		fields = append(fields, m)
 	}
 	for _, create := range conf.CreateOpts {
@@ -51,6 +156,9 @@ func getFields(db *gorm.DB, conf *model.Config, columns []*model.Column) (fields
 				}
 			}
 			m.Type = strings.ReplaceAll(m.Type, conf.ModelPkg+".", "") // remove modelPkg in field's Type, avoid import error
+			if conf.FieldWithConstraintTag && m.Relation.Relationship() == field.BelongsTo {
+				withConstraint(m, foreignKeys)
+			}
 		}
 
 		fields = append(fields, m)
@@ -58,6 +166,101 @@ func getFields(db *gorm.DB, conf *model.Config, columns []*model.Column) (fields
 	return fields
 }
 
+// withConstraint set constraint tag of belongs to relation field by referential actions of its foreign key column,
+// which is <field name>ID or specified by foreignKey tag, AutoMigrate creates foreign key without actions otherwise
+func withConstraint(m *model.Field, foreignKeys map[string]*model.ForeignKey) {
+	foreignKey := m.Name + "ID"
+	for k, v := range m.GORMTag {
+		switch {
+		case strings.EqualFold(k, field.TagKeyGormConstraint):
+			return
+		case strings.EqualFold(k, field.TagKeyGormForeignKey) && len(v) > 0:
+			foreignKey = v[0]
+		}
+	}
+	fk, ok := foreignKeys[foreignKey]
+	if !ok {
+		return
+	}
+	if value := fk.ConstraintTagValue(); value != "" {
+		tag := make(field.GormTag, len(m.GORMTag)+1) // GORMTag is shared by fields created by the same RelateConfig
+		for k, v := range m.GORMTag {
+			tag[k] = v
+		}
+		m.GORMTag = tag.Set(field.TagKeyGormConstraint, value)
+	}
+}
+
+// withEnum use named type enum as field's type, field is still generated as field of its underlying type
+func withEnum(m *model.Field, enum *model.Enum) {
+	if m.CustomGenType == "" {
+		m.CustomGenType = m.GenType()
+	}
+	m.Enum = enum
+	m.Type = strings.TrimSuffix(m.Type, enum.Type) + enum.Name
+}
+
+// sqlNullTypes go type -> sql.NullXXX type used by nullable field in NullableStyle sqlnull
+var sqlNullTypes = map[string]string{
+	"string":    "sql.NullString",
+	"int64":     "sql.NullInt64",
+	"int32":     "sql.NullInt32",
+	"int16":     "sql.NullInt16",
+	"uint8":     "sql.NullByte",
+	"float64":   "sql.NullFloat64",
+	"bool":      "sql.NullBool",
+	"time.Time": "sql.NullTime",
+}
+
+// nullType return type of nullable field of typ in style replacing pointer, ok is false if pointer is kept
+func nullType(style, typ string) (string, bool) {
+	switch style {
+	case "sqlnull":
+		nullTyp, ok := sqlNullTypes[typ]
+		return nullTyp, ok
+	case "genericNull":
+		return "sql.Null[" + typ + "]", true
+	default:
+		return "", false
+	}
+}
+
+// typeImportPaths import paths of packages which field types are from, only well-known packages are detected
+func typeImportPaths(fields []*model.Field) (paths []string) {
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		typ := strings.TrimLeft(f.Type, "*[]")
+		if f.BinaryUUID != nil {
+			typ = model.UUIDType
+		}
+		if f.Geometry != nil {
+			typ = f.Geometry.Type
+			if !seen[ewkbPkgPath] {
+				seen[ewkbPkgPath] = true
+				paths = append(paths, ewkbPkgPath)
+			}
+		}
+		i := strings.IndexByte(typ, '.')
+		if i < 0 {
+			continue
+		}
+		if path, ok := knownTypePkgs[typ[:i]]; ok && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func isInteger(typ string) bool {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	default:
+		return false
+	}
+}
+
 func filterField(m *model.Field, opts []model.FieldOption) *model.Field {
 	for _, opt := range opts {
 		if opt.Operator()(m) == nil {
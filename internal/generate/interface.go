@@ -2,8 +2,10 @@ package generate
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gen/internal/model"
 	"gorm.io/gen/internal/parser"
@@ -27,6 +29,47 @@ type InterfaceMethod struct { // feature will replace InterfaceMethod to parser.
 	InterfaceName string         // origin interface name
 	Package       string         // interface package name
 	HasForParams  bool           //
+
+	StrictRowsAffected bool // return gen.ErrNoRowsAffected when no row is affected
+
+	// statement policy annotated by "@timeout <duration>" and "@retry <max attempts> [backoff]" lines of comment,
+	// see gen.StatementPolicy
+	Timeout     time.Duration
+	MaxAttempts int
+	Backoff     time.Duration
+
+	// SQL variants of other dialects annotated by "-- dialect: <name>" lines, selected at runtime by name of
+	// connected dialector, Section is used for dialects without variant
+	Dialects []*DialectSQL
+
+	fragments map[string]string // named SQL fragments included by {{include "name"}}
+	dialect   string            // only SQL variant of dialect is generated if it's set
+}
+
+// DialectSQL SQL variant of dialects annotated by "-- dialect: <name>[, <name>...]" line
+type DialectSQL struct {
+	Names     []string
+	SQLString string
+	Section   *Section
+}
+
+// Has whether variant is SQL of dialect
+func (d *DialectSQL) Has(dialect string) bool {
+	for _, name := range d.Names {
+		if name == dialect {
+			return true
+		}
+	}
+	return false
+}
+
+// Cases quoted dialect names of switch case
+func (d *DialectSQL) Cases() string {
+	cases := make([]string, len(d.Names))
+	for i, name := range d.Names {
+		cases[i] = strconv.Quote(name)
+	}
+	return strings.Join(cases, ", ")
 }
 
 // FuncSign function signature
@@ -269,15 +312,131 @@ func (m *InterfaceMethod) checkResult(result []parser.Param) (err error) {
 	return
 }
 
+// HasPolicy whether method is run with statement timeout or retry policy
+func (m *InterfaceMethod) HasPolicy() bool { return m.Timeout > 0 || m.MaxAttempts > 1 }
+
+// Policy gen.StatementPolicy literal of method
+func (m *InterfaceMethod) Policy() string {
+	var fields []string
+	if m.Timeout > 0 {
+		fields = append(fields, "Timeout: "+durationLiteral(m.Timeout))
+	}
+	if m.MaxAttempts > 1 {
+		fields = append(fields, "MaxAttempts: "+strconv.Itoa(m.MaxAttempts))
+	}
+	if m.Backoff > 0 {
+		fields = append(fields, "Backoff: "+durationLiteral(m.Backoff))
+	}
+	return "gen.StatementPolicy{" + strings.Join(fields, ", ") + "}"
+}
+
+// DefaultPolicy set statement policy which is not annotated
+func (m *InterfaceMethod) DefaultPolicy(timeout time.Duration, maxAttempts int, backoff time.Duration) {
+	if m.Timeout == 0 {
+		m.Timeout = timeout
+	}
+	if m.MaxAttempts == 0 {
+		m.MaxAttempts, m.Backoff = maxAttempts, backoff
+	}
+}
+
+// parsePolicy parse and remove "@timeout <duration>" and "@retry <max attempts> [backoff]" lines of comment
+func (m *InterfaceMethod) parsePolicy() (err error) {
+	lines := strings.Split(m.Doc, "\n")
+	doc := lines[:0]
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 2 && fields[0] == "@timeout":
+			if m.Timeout, err = time.ParseDuration(fields[1]); err != nil || m.Timeout <= 0 {
+				return fmt.Errorf("invalid timeout %q", line)
+			}
+		case (len(fields) == 2 || len(fields) == 3) && fields[0] == "@retry":
+			if m.MaxAttempts, err = strconv.Atoi(fields[1]); err != nil || m.MaxAttempts < 1 {
+				return fmt.Errorf("invalid retry %q", line)
+			}
+			if len(fields) == 3 {
+				if m.Backoff, err = time.ParseDuration(fields[2]); err != nil || m.Backoff < 0 {
+					return fmt.Errorf("invalid retry %q", line)
+				}
+			}
+		default:
+			doc = append(doc, line)
+		}
+	}
+	m.Doc = strings.Join(doc, "\n")
+	return nil
+}
+
 // checkSQL get sql from comment and check it
 func (m *InterfaceMethod) checkSQL() (err error) {
-	m.SQLString = m.parseDocString()
-	if err = m.sqlStateCheckAndSplit(); err != nil {
+	if err = m.parsePolicy(); err != nil {
+		return fmt.Errorf("interface %s member method %s annotation err:%w", m.InterfaceName, m.MethodName, err)
+	}
+	sql, err := m.includeFragments(m.parseDocString(), nil)
+	if err != nil {
+		return fmt.Errorf("interface %s member method %s include sql fragment err:%w", m.InterfaceName, m.MethodName, err)
+	}
+	if err = m.checkDialects(splitDialects(sql)); err != nil {
 		err = fmt.Errorf("interface %s member method %s check sql err:%w", m.InterfaceName, m.MethodName, err)
 	}
 	return
 }
 
+// dialectRegexp line starting SQL variant of dialects, e.g. "-- dialect: postgres" or "-- dialect: mysql, sqlite"
+var dialectRegexp = regexp.MustCompile(`(?m)^[ \t]*--[ \t]*dialect:[ \t]*(\w+(?:[ \t]*,[ \t]*\w+)*)[ \t]*$`)
+
+// splitDialects split sql into unmarked SQL before the first "-- dialect: <name>" line and variants of dialects
+func splitDialects(sql string) (string, []*DialectSQL) {
+	marks := dialectRegexp.FindAllStringSubmatchIndex(sql, -1)
+	if len(marks) == 0 {
+		return sql, nil
+	}
+	variants := make([]*DialectSQL, len(marks))
+	for i, mark := range marks {
+		end := len(sql)
+		if i+1 < len(marks) {
+			end = marks[i+1][0]
+		}
+		variants[i] = &DialectSQL{SQLString: strings.TrimSpace(sql[mark[1]:end])}
+		for _, name := range strings.Split(sql[mark[2]:mark[3]], ",") {
+			variants[i].Names = append(variants[i].Names, strings.TrimSpace(name))
+		}
+	}
+	return strings.TrimSpace(sql[:marks[0][0]]), variants
+}
+
+// checkDialects check unmarked sql and variants of dialects. Only the variant of m.dialect(or unmarked sql) is
+// generated if it's set, otherwise variants are selected at runtime and the first variant is used for other dialects
+// if there's no unmarked sql
+func (m *InterfaceMethod) checkDialects(sql string, variants []*DialectSQL) error {
+	switch {
+	case len(variants) == 0:
+	case m.dialect != "":
+		for _, variant := range variants {
+			if variant.Has(m.dialect) {
+				sql = variant.SQLString
+				break
+			}
+		}
+		if sql == "" {
+			return fmt.Errorf("no sql of dialect %s", m.dialect)
+		}
+		variants = nil
+	case sql == "":
+		sql, variants = variants[0].SQLString, variants[1:]
+	}
+	for _, variant := range variants {
+		m.SQLString = variant.SQLString
+		if err := m.sqlStateCheckAndSplit(); err != nil {
+			return fmt.Errorf("dialect %s: %w", strings.Join(variant.Names, ","), err)
+		}
+		variant.Section = m.Section
+	}
+	m.SQLString, m.Dialects = sql, variants
+	return m.sqlStateCheckAndSplit()
+}
+
 func (m *InterfaceMethod) parseDocString() string {
 	docString := strings.TrimSpace(m.getSQLDocString())
 	switch {
@@ -304,6 +463,35 @@ func (m *InterfaceMethod) parseDocString() string {
 	return docString
 }
 
+// includeRegexp template including named SQL fragment, e.g. {{include "activeUsers"}}
+var includeRegexp = regexp.MustCompile(`\{\{\s*include\s+"?(\w+)"?\s*}}`)
+
+// includeFragments replace {{include "name"}} in sql with named fragment, fragments can include other fragments,
+// stack is names of fragments being included to detect include cycle
+func (m *InterfaceMethod) includeFragments(sql string, stack []string) (string, error) {
+	var err error
+	result := includeRegexp.ReplaceAllStringFunc(sql, func(include string) string {
+		name := includeRegexp.FindStringSubmatch(include)[1]
+		fragment, ok := m.fragments[name]
+		switch {
+		case err != nil:
+			return include
+		case !ok:
+			err = fmt.Errorf("unknown sql fragment %q", name)
+			return include
+		}
+		for _, including := range stack {
+			if including == name {
+				err = fmt.Errorf("sql fragment %q includes itself", name)
+				return include
+			}
+		}
+		fragment, err = m.includeFragments(fragment, append(stack, name))
+		return fragment
+	})
+	return result, err
+}
+
 func (m *InterfaceMethod) getSQLDocString() string {
 	docString := strings.TrimSpace(m.Doc)
 	/*
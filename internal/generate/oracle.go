@@ -0,0 +1,118 @@
+package generate
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm/migrator"
+
+	"gorm.io/gen/internal/model"
+)
+
+// oracleColumnsSQL query columns of @table in @schema(current schema if empty) with comments and primary keys,
+// DATA_DEFAULT is LONG which can't be used in expressions, so it's selected as is
+const oracleColumnsSQL = `SELECT c.COLUMN_NAME AS "column_name", c.DATA_TYPE AS "data_type", c.CHAR_LENGTH AS "char_length", ` +
+	`c.DATA_LENGTH AS "data_length", c.DATA_PRECISION AS "data_precision", c.DATA_SCALE AS "data_scale", ` +
+	`c.NULLABLE AS "nullable", c.DATA_DEFAULT AS "data_default", cc.COMMENTS AS "comments", ` +
+	`CASE WHEN EXISTS (SELECT 1 FROM ALL_CONSTRAINTS k JOIN ALL_CONS_COLUMNS kc ON kc.OWNER = k.OWNER AND kc.CONSTRAINT_NAME = k.CONSTRAINT_NAME ` +
+	`WHERE k.OWNER = c.OWNER AND k.TABLE_NAME = c.TABLE_NAME AND k.CONSTRAINT_TYPE = 'P' AND kc.COLUMN_NAME = c.COLUMN_NAME) ` +
+	`THEN 'YES' ELSE 'NO' END AS "is_primary" ` +
+	`FROM ALL_TAB_COLUMNS c LEFT JOIN ALL_COL_COMMENTS cc ON cc.OWNER = c.OWNER AND cc.TABLE_NAME = c.TABLE_NAME AND cc.COLUMN_NAME = c.COLUMN_NAME ` +
+	`WHERE c.OWNER = NVL(@schema, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND c.TABLE_NAME = @table ORDER BY c.COLUMN_ID`
+
+// oracleTriggersSQL query bodies of enabled insert triggers of @table in @schema(current schema if empty),
+// primary keys of tables created before 12c are usually filled by sequence in such trigger
+const oracleTriggersSQL = `SELECT TRIGGER_BODY FROM ALL_TRIGGERS WHERE TABLE_OWNER = NVL(@schema, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) ` +
+	`AND TABLE_NAME = @table AND STATUS = 'ENABLED' AND TRIGGERING_EVENT LIKE '%INSERT%'`
+
+// oracleSequenceTrigger matches column filled by sequence in trigger body, e.g.
+// SELECT users_seq.NEXTVAL INTO :NEW.id FROM dual, :NEW.id := users_seq.NEXTVAL
+var oracleSequenceTrigger = regexp.MustCompile(`(?is)SELECT\s+[\w$#".]+\.NEXTVAL\s+INTO\s+:NEW\."?([\w$#]+)"?` +
+	`|:NEW\."?([\w$#]+)"?\s*:=\s*[\w$#".]+\.NEXTVAL`)
+
+// oracleColumn column of oracleColumnsSQL
+type oracleColumn struct {
+	ColumnName    string
+	DataType      string
+	CharLength    sql.NullInt64
+	DataLength    sql.NullInt64
+	DataPrecision sql.NullInt64
+	DataScale     sql.NullInt64
+	Nullable      string
+	DataDefault   sql.NullString
+	Comments      sql.NullString
+	IsPrimary     string
+}
+
+// columnType full column type, e.g. VARCHAR2(64), NUMBER(10,2), NUMBER(*,0) of INTEGER
+func (c *oracleColumn) columnType() string {
+	switch c.DataType {
+	case "CHAR", "NCHAR", "VARCHAR2", "NVARCHAR2":
+		return fmt.Sprintf("%s(%d)", c.DataType, c.CharLength.Int64)
+	case "RAW":
+		return fmt.Sprintf("RAW(%d)", c.DataLength.Int64)
+	case "FLOAT":
+		if c.DataPrecision.Valid {
+			return fmt.Sprintf("FLOAT(%d)", c.DataPrecision.Int64)
+		}
+	case "NUMBER":
+		switch {
+		case !c.DataPrecision.Valid && !c.DataScale.Valid:
+		case !c.DataPrecision.Valid:
+			return fmt.Sprintf("NUMBER(*,%d)", c.DataScale.Int64)
+		case c.DataScale.Int64 == 0:
+			return fmt.Sprintf("NUMBER(%d)", c.DataPrecision.Int64)
+		default:
+			return fmt.Sprintf("NUMBER(%d,%d)", c.DataPrecision.Int64, c.DataScale.Int64)
+		}
+	}
+	return c.DataType
+}
+
+// oracleColumns columns of oracle table read from ALL_TAB_COLUMNS, oracle driver doesn't report them by migrator.
+// Primary key filled by sequence, by DEFAULT seq.NEXTVAL, identity column or insert trigger, is auto increment
+func (t *tableInfo) oracleColumns(schemaName string, tableName string) (result []*model.Column, err error) {
+	params := map[string]interface{}{"schema": schemaName, "table": tableName}
+	var columns []*oracleColumn
+	if err = t.Raw(oracleColumnsSQL, params).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+
+	var bodies []string
+	if err = t.Raw(oracleTriggersSQL, params).Scan(&bodies).Error; err != nil {
+		return nil, err
+	}
+	sequenced := make(map[string]bool)
+	for _, body := range bodies {
+		for _, m := range oracleSequenceTrigger.FindAllStringSubmatch(body, -1) {
+			sequenced[strings.ToUpper(m[1]+m[2])] = true
+		}
+	}
+
+	for _, c := range columns {
+		defaultValue := strings.TrimSpace(c.DataDefault.String)
+		isPrimary := c.IsPrimary == "YES"
+		ct := migrator.ColumnType{
+			NameValue:          sql.NullString{String: c.ColumnName, Valid: true},
+			DataTypeValue:      sql.NullString{String: c.DataType, Valid: true},
+			ColumnTypeValue:    sql.NullString{String: c.columnType(), Valid: true},
+			PrimaryKeyValue:    sql.NullBool{Bool: isPrimary, Valid: true},
+			AutoIncrementValue: sql.NullBool{Bool: model.IsSequenceDefault(defaultValue) || (isPrimary && sequenced[strings.ToUpper(c.ColumnName)]), Valid: true},
+			NullableValue:      sql.NullBool{Bool: c.Nullable == "Y", Valid: true},
+			CommentValue:       sql.NullString{String: c.Comments.String, Valid: c.Comments.Valid},
+			DefaultValueValue:  sql.NullString{String: defaultValue, Valid: c.DataDefault.Valid},
+		}
+		switch c.DataType {
+		case "CHAR", "NCHAR", "VARCHAR2", "NVARCHAR2":
+			ct.LengthValue = c.CharLength
+		case "RAW":
+			ct.LengthValue = c.DataLength
+		case "NUMBER":
+			ct.DecimalSizeValue, ct.ScaleValue = c.DataPrecision, c.DataScale
+		}
+		result = append(result, &model.Column{ColumnType: resultlessColumnType{ct}, TableName: tableName, Dialect: "oracle"})
+	}
+	return result, nil
+}
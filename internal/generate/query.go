@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
@@ -37,8 +39,23 @@ type QueryStructMeta struct {
 	Source          model.SourceCode
 	ImportPkgPaths  []string
 	ModelMethods    []*parser.Method // user custom method bind to db base struct
+	BaseModel       string           // name of embedded base model declaring fields marked Embedded
+	Lookup          *model.Enum      // constants of rows of lookup table, see gen.Config.LookupTables
+	Temporal        bool             // system-versioned temporal table queried by AsOf
 
-	interfaceMode bool
+	tableSchema *model.TableSchema
+
+	softDeleteColumn string // column of gorm.DeletedAt field of parsed struct
+
+	interfaceMode  bool
+	genericMode    bool
+	versionColumn  string
+	tenantColumn   string
+	shardPattern   string
+	auditColumns   [2]string
+	batchSize      int
+	strictAffected bool
+	unscopedLookup bool
 }
 
 // parseStruct get all elements of struct with gorm's Parse, ignore unexported elements
@@ -56,11 +73,23 @@ func (b *QueryStructMeta) parseStruct(st interface{}) error {
 	if fps, ok := st.(FieldParser); ok && fps != nil {
 		fp = fps
 	}
+	indexTags := parseIndexTags(stmt.Schema)
 	for _, f := range stmt.Schema.Fields {
+		gormTag := indexTags[f.DBName]
+		if gormTag == nil {
+			gormTag = field.GormTag{}
+		}
+		if f.PrimaryKey {
+			gormTag.Set(field.TagKeyGormPrimaryKey, "")
+		}
+		if f.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+			b.softDeleteColumn = f.DBName
+		}
 		b.appendOrUpdateField(&model.Field{
 			Name:          f.Name,
 			Type:          b.getFieldRealType(f.FieldType),
 			ColumnName:    f.DBName,
+			GORMTag:       gormTag,
 			CustomGenType: fp.GetFieldGenType(f),
 		})
 	}
@@ -71,6 +100,34 @@ func (b *QueryStructMeta) parseStruct(st interface{}) error {
 	return nil
 }
 
+// parseIndexTags build index tags of struct's fields, grouped by column name
+func parseIndexTags(s *schema.Schema) map[string]field.GormTag {
+	tags := make(map[string]field.GormTag)
+	indexes := s.ParseIndexes()
+	names := make([]string, 0, len(indexes))
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		idx := indexes[name]
+		tagKey := field.TagKeyGormIndex
+		if idx.Class == "UNIQUE" {
+			tagKey = field.TagKeyGormUniqueIndex
+		}
+		for i, opt := range idx.Fields {
+			if opt.Field == nil {
+				continue
+			}
+			if tags[opt.DBName] == nil {
+				tags[opt.DBName] = field.GormTag{}
+			}
+			tags[opt.DBName].Append(tagKey, fmt.Sprintf("%s,priority:%d", idx.Name, i+1))
+		}
+	}
+	return tags
+}
+
 // getFieldRealType  get basic type of field
 func (b *QueryStructMeta) getFieldRealType(f reflect.Type) string {
 	serializerInterface := reflect.TypeOf((*schema.SerializerInterface)(nil)).Elem()
@@ -125,6 +182,71 @@ func (b *QueryStructMeta) appendOrUpdateField(f *model.Field) {
 
 func (b *QueryStructMeta) appendField(f *model.Field) { b.Fields = append(b.Fields, f) }
 
+// PrimaryKey return the primary key field, return nil if table has no or composite primary key
+func (b *QueryStructMeta) PrimaryKey() *model.Field {
+	var pk *model.Field
+	for _, f := range b.Fields {
+		if !f.IsPrimaryKey() {
+			continue
+		}
+		if pk != nil {
+			return nil
+		}
+		pk = f
+	}
+	return pk
+}
+
+// CompositeKey return fields of composite primary key in column order, return nil if table has no or single column
+// primary key, or any of them is not comparable by generated field's Eq method
+func (b *QueryStructMeta) CompositeKey() (fields []*model.Field) {
+	for _, f := range b.Fields {
+		if f.ColumnName == "" || !f.IsPrimaryKey() {
+			continue
+		}
+		if f.GenValueType() == "" {
+			return nil
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) < 2 {
+		return nil
+	}
+	return fields
+}
+
+// FakeKey primary key of fake DAO, return nil if there is no single primary key of comparable builtin type
+func (b *QueryStructMeta) FakeKey() *model.Field {
+	pk := b.PrimaryKey()
+	if pk == nil || pk.GenValueType() == "" || pk.GenValueType() == "[]byte" || pk.Type != pk.GenValueType() {
+		return nil
+	}
+	return pk
+}
+
+// FakeAutoIncrement whether zero primary key of record created by fake DAO is auto incremented, true for integers
+func (b *QueryStructMeta) FakeAutoIncrement() bool {
+	pk := b.FakeKey()
+	if pk == nil {
+		return false
+	}
+	switch pk.Type {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// IndexedFields fields which are the only or the first column of an index, primary key excluded
+func (b *QueryStructMeta) IndexedFields() (fields []*model.Field) {
+	for _, f := range b.Fields {
+		if f.ColumnName != "" && !f.IsPrimaryKey() && f.IsIndexLeading() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
 // HasField check if BaseStruct has fields
 func (b *QueryStructMeta) HasField() bool { return len(b.Fields) > 0 }
 
@@ -149,6 +271,288 @@ func (b *QueryStructMeta) Relations() (result []field.Relation) {
 	return result
 }
 
+// HasJSONField whether any field is mapped to json column
+func (b *QueryStructMeta) HasJSONField() bool {
+	for _, f := range b.Fields {
+		if f.IsJSON() && f.ColumnName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Enums named types generated for fields
+func (b *QueryStructMeta) Enums() (result []*model.Enum) {
+	for _, f := range b.Fields {
+		if f.Enum != nil {
+			result = append(result, f.Enum)
+		}
+	}
+	return result
+}
+
+// Geometries named orb geometry types generated for spatial fields
+func (b *QueryStructMeta) Geometries() (result []*model.Geometry) {
+	for _, f := range b.Fields {
+		if f.Geometry != nil {
+			result = append(result, f.Geometry)
+		}
+	}
+	return result
+}
+
+// BinaryUUIDs named uuid types generated for binary(16) fields
+func (b *QueryStructMeta) BinaryUUIDs() (result []*model.BinaryUUID) {
+	for _, f := range b.Fields {
+		if f.BinaryUUID != nil {
+			result = append(result, f.BinaryUUID)
+		}
+	}
+	return result
+}
+
+// UUIDKey uuid primary key populated by BeforeCreate hook
+type UUIDKey struct {
+	Name    string
+	Type    string // type without pointer, uuid.UUID or named uuid type
+	Pointer bool
+}
+
+// Nil expression of zero uuid
+func (k UUIDKey) Nil() string { return k.convert("uuid.Nil") }
+
+// New expression of new random uuid
+func (k UUIDKey) New() string { return k.convert("uuid.New()") }
+
+func (k UUIDKey) convert(expr string) string {
+	if k.Type == model.UUIDType {
+		return expr
+	}
+	return k.Type + "(" + expr + ")"
+}
+
+// UUIDKeys uuid primary keys of model
+func (b *QueryStructMeta) UUIDKeys() (keys []UUIDKey) {
+	for _, f := range b.Fields {
+		typ := strings.TrimPrefix(f.Type, "*")
+		if !f.IsPrimaryKey() || (typ != model.UUIDType && (f.BinaryUUID == nil || typ != f.BinaryUUID.Name)) {
+			continue
+		}
+		keys = append(keys, UUIDKey{Name: f.Name, Type: typ, Pointer: strings.HasPrefix(f.Type, "*")})
+	}
+	return keys
+}
+
+// UTCField time.Time field converted to UTC by hooks
+type UTCField struct {
+	Name    string
+	Pointer bool
+	Value   string // selector of time value in sql.NullTime or sql.Null[time.Time]: .Time or .V
+}
+
+// UTCFields time.Time fields of model
+func (b *QueryStructMeta) UTCFields() (fields []UTCField) {
+	for _, f := range b.Fields {
+		if f.IsRelation() || f.ColumnName == "" {
+			continue
+		}
+		switch f.Type {
+		case "time.Time":
+			fields = append(fields, UTCField{Name: f.Name})
+		case "*time.Time":
+			fields = append(fields, UTCField{Name: f.Name, Pointer: true})
+		case "sql.NullTime":
+			fields = append(fields, UTCField{Name: f.Name, Value: ".Time"})
+		case "sql.Null[time.Time]":
+			fields = append(fields, UTCField{Name: f.Name, Value: ".V"})
+		}
+	}
+	return fields
+}
+
+// MethodField field of model used by extra model methods, see Config.ExtraModelMethods
+type MethodField struct {
+	Name     string
+	Type     string
+	Pointer  bool // e.g. *string, copied by value in Clone
+	Slice    bool // e.g. []byte, datatypes.JSON, pq.StringArray, copied element-wise in Clone
+	Map      bool
+	Relation bool
+}
+
+// MethodFields fields of model used by extra model methods
+func (b *QueryStructMeta) MethodFields() (fields []MethodField) {
+	for _, f := range b.Fields {
+		typ := f.Type
+		fields = append(fields, MethodField{
+			Name:    f.Name,
+			Type:    typ,
+			Pointer: strings.HasPrefix(typ, "*"),
+			Slice: strings.HasPrefix(typ, "[]") || typ == "datatypes.JSON" || typ == "json.RawMessage" ||
+				(strings.HasPrefix(typ, "pq.") && strings.HasSuffix(typ, "Array")) || (f.Enum != nil && f.Enum.Set && typ == f.Enum.Name),
+			Map:      strings.HasPrefix(typ, "map["),
+			Relation: f.IsRelation(),
+		})
+	}
+	return fields
+}
+
+// ValidateField field checked by Validate method of model against constraints of its column
+type ValidateField struct {
+	Name       string
+	ColumnName string
+	Pointer    bool
+	Required   string // kind of zero value check of required non-pointer field: string, time or slice
+	MaxLength  int64
+	Min, Max   string // literals of range of integer column which are not covered by go type
+	Enum       bool   // value must be one of enum values
+	EnumZero   string // literal of zero value of enum which is not checked, empty for set
+}
+
+// ValidateFields fields of model checked by Validate method
+func (b *QueryStructMeta) ValidateFields() (fields []ValidateField) {
+	for _, f := range b.Fields {
+		if f.IsRelation() || f.ColumnName == "" || (f.Validation == nil && f.Enum == nil) {
+			continue
+		}
+		typ := strings.TrimPrefix(f.Type, "*")
+		v := ValidateField{Name: f.Name, ColumnName: f.ColumnName, Pointer: typ != f.Type, Enum: f.Enum != nil && typ == f.Enum.Name}
+		if v.Enum && !f.Enum.Set {
+			v.EnumZero = "0"
+			if f.Enum.Type == "string" {
+				v.EnumZero = `""`
+			}
+		}
+		if rule := f.Validation; rule != nil {
+			switch {
+			case !rule.Required || v.Pointer:
+			case typ == "string" || typ == model.PIIType || (v.Enum && f.Enum.Type == "string" && !f.Enum.Set):
+				v.Required = "string"
+			case typ == "time.Time":
+				v.Required = "time"
+			case strings.HasPrefix(typ, "[]") || typ == "datatypes.JSON" || typ == "json.RawMessage" || (v.Enum && f.Enum.Set):
+				v.Required = "slice"
+			}
+			if typ == "string" { // length of encrypted and enum value is not checked
+				v.MaxLength = rule.MaxLength
+			}
+			if rule.Range != nil {
+				v.Min, v.Max, _ = rule.Range.Bounds(typ)
+			}
+		}
+		if v.Required != "" || v.MaxLength > 0 || v.Min != "" || v.Max != "" || v.Enum {
+			fields = append(fields, v)
+		}
+	}
+	return fields
+}
+
+// PreloadRelations names of relation fields preloaded by With<Relation> methods, relations conflicting with
+// WithContext and WithResult are skipped
+func (b *QueryStructMeta) PreloadRelations() (names []string) {
+	for _, r := range b.Relations() {
+		if name := r.Name(); name != "Context" && name != "Result" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FilterField field of <Model>Filter generated by WithFilterMethod
+type FilterField struct {
+	Name       string
+	ColumnName string
+	GenType    string // e.g. Int32, constructor of query field is field.New<GenType>
+	ValueType  string // e.g. int32, time.Time
+	Kind       string // eq(pointer field), bool(pointer field), range(*gen.Range) or in(slice of enum)
+	EnumType   string // enum type of in field, e.g. model.OrderStatus
+}
+
+// FilterFields fields of <Model>Filter, enum fields are filtered by In, time and number fields by range,
+// primary key and <name>_id number fields, string and bool fields by equality
+func (b *QueryStructMeta) FilterFields() (fields []FilterField) {
+	for _, f := range b.Fields {
+		if f.IsRelation() || f.ColumnName == "" || strings.TrimPrefix(f.Type, "*") == model.PIIType {
+			continue
+		}
+		ff := FilterField{Name: f.Name, ColumnName: f.ColumnName, GenType: f.GenType(), ValueType: f.GenValueType()}
+		switch {
+		case ff.GenType == "Bool":
+			ff.Kind, ff.ValueType = "bool", "bool"
+		case ff.ValueType == "" || ff.ValueType == "[]byte":
+			continue
+		case f.Enum != nil && !f.Enum.Set:
+			ff.Kind, ff.EnumType = "in", b.StructInfo.Package+"."+f.Enum.Name
+		case ff.ValueType == "string" || f.IsPrimaryKey() || strings.HasSuffix(f.ColumnName, "_id"):
+			ff.Kind = "eq"
+		default: // time and number
+			ff.Kind = "range"
+		}
+		fields = append(fields, ff)
+	}
+	return fields
+}
+
+// SortFields column fields, used by <Model>Field enum of WithSortMethod and column name constants of FieldWithNameConst
+func (b *QueryStructMeta) SortFields() (fields []*model.Field) {
+	for _, f := range b.Fields {
+		if !f.IsRelation() && f.ColumnName != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// NamedIndex index of <Model>Index constant generated by WithHintMethod and Index<Model><Name> of FieldWithNameConst
+type NamedIndex struct {
+	Name  string // constant name suffix, e.g. IdxName of idx_name
+	Index string
+}
+
+// NamedIndexes named indexes of fields in order of appearance
+func (b *QueryStructMeta) NamedIndexes() (indexes []NamedIndex) {
+	seen, consts := make(map[string]bool), make(map[string]bool)
+	for _, f := range b.Fields {
+		for _, name := range f.IndexNames() {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			constName := model.Identifier(name)
+			for i, unique := 2, constName; ; i++ { // e.g. idx-name and idx_name
+				if !consts[unique] {
+					constName = unique
+					break
+				}
+				unique = constName + strconv.Itoa(i)
+			}
+			consts[constName] = true
+			indexes = append(indexes, NamedIndex{Name: constName, Index: name})
+		}
+	}
+	return indexes
+}
+
+// HasFieldNamed whether model has field named name
+func (b *QueryStructMeta) HasFieldNamed(name string) bool {
+	for _, f := range b.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasModelMethod whether model has method named name
+func (b *QueryStructMeta) HasModelMethod(name string) bool {
+	for _, m := range b.ModelMethods {
+		if m.MethodName == name {
+			return true
+		}
+	}
+	return false
+}
+
 // StructComment struct comment
 func (b *QueryStructMeta) StructComment() string {
 	if b.TableName != "" {
@@ -208,12 +612,147 @@ func (b *QueryStructMeta) addMethodFromAddMethodOpt(methods ...interface{}) *Que
 	return b
 }
 
+// TableSchema return introspected metadata of table, return nil if model is not generated from table
+func (b *QueryStructMeta) TableSchema() *model.TableSchema { return b.tableSchema }
+
 // IfaceMode object mode
 func (b QueryStructMeta) IfaceMode(on bool) *QueryStructMeta {
 	b.interfaceMode = on
 	return &b
 }
 
+// GenericMode query struct embeds generic core of CRUD methods
+func (b QueryStructMeta) GenericMode(on bool) *QueryStructMeta {
+	b.genericMode = on
+	return &b
+}
+
+// GenericDAO whether query struct embeds generic core of CRUD methods
+func (b *QueryStructMeta) GenericDAO() bool { return b.genericMode }
+
+// VersionMode specify column used for optimistic locking
+func (b QueryStructMeta) VersionMode(column string) *QueryStructMeta {
+	b.versionColumn = column
+	return &b
+}
+
+// VersionField return the version field used for optimistic locking, return nil if absent or not a plain integer field
+func (b *QueryStructMeta) VersionField() *model.Field {
+	if b.versionColumn == "" {
+		return nil
+	}
+	for _, f := range b.Fields {
+		if f.ColumnName != b.versionColumn || f.IsRelation() {
+			continue
+		}
+		switch f.Type {
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return f
+		}
+		return nil
+	}
+	return nil
+}
+
+// TenantMode specify column used for tenant scope
+func (b QueryStructMeta) TenantMode(column string) *QueryStructMeta {
+	b.tenantColumn = column
+	return &b
+}
+
+// TenantField return the tenant field, return nil if absent
+func (b *QueryStructMeta) TenantField() *model.Field {
+	if b.tenantColumn == "" {
+		return nil
+	}
+	for _, f := range b.Fields {
+		if f.ColumnName == b.tenantColumn && !f.IsRelation() {
+			return f
+		}
+	}
+	return nil
+}
+
+// ShardMode specify suffix pattern of sharded tables
+func (b QueryStructMeta) ShardMode(pattern string) *QueryStructMeta {
+	b.shardPattern = pattern
+	return &b
+}
+
+// ShardPattern return suffix pattern of sharded tables, empty if table is not sharded
+func (b *QueryStructMeta) ShardPattern() string { return b.shardPattern }
+
+// ShardByTime whether suffix pattern is a time layout, otherwise it's a fmt format of shard number
+func (b *QueryStructMeta) ShardByTime() bool { return !strings.Contains(b.shardPattern, "%") }
+
+// AuditMode specify columns filled with operator on create and update
+func (b QueryStructMeta) AuditMode(createdBy, updatedBy string) *QueryStructMeta {
+	b.auditColumns = [2]string{createdBy, updatedBy}
+	return &b
+}
+
+// CreatedByColumn return audit column filled on create, empty if absent
+func (b *QueryStructMeta) CreatedByColumn() string { return b.existColumn(b.auditColumns[0]) }
+
+// UpdatedByColumn return audit column filled on create and update, empty if absent
+func (b *QueryStructMeta) UpdatedByColumn() string { return b.existColumn(b.auditColumns[1]) }
+
+// HasAuditColumn whether table has audit columns
+func (b *QueryStructMeta) HasAuditColumn() bool {
+	return b.CreatedByColumn() != "" || b.UpdatedByColumn() != ""
+}
+
+func (b *QueryStructMeta) existColumn(column string) string {
+	if column == "" {
+		return ""
+	}
+	for _, f := range b.Fields {
+		if f.ColumnName == column && !f.IsRelation() {
+			return column
+		}
+	}
+	return ""
+}
+
+// BatchMode specify default batch size of batch methods
+func (b QueryStructMeta) BatchMode(size int) *QueryStructMeta {
+	b.batchSize = size
+	return &b
+}
+
+// BatchSize return default batch size of batch methods, 0 means gen.DefaultBatchSize
+func (b *QueryStructMeta) BatchSize() int { return b.batchSize }
+
+// RowsAffectedMode specify whether <Method>Affected methods return gen.ErrNoRowsAffected when no row is affected
+func (b QueryStructMeta) RowsAffectedMode(strict bool) *QueryStructMeta {
+	b.strictAffected = strict
+	return &b
+}
+
+// StrictRowsAffected whether <Method>Affected methods return gen.ErrNoRowsAffected when no row is affected
+func (b *QueryStructMeta) StrictRowsAffected() bool { return b.strictAffected }
+
+// LookupMode specify whether unique lookup methods also find soft-deleted records
+func (b QueryStructMeta) LookupMode(unscoped bool) *QueryStructMeta {
+	b.unscopedLookup = unscoped
+	return &b
+}
+
+// UnscopedLookup whether unique lookup methods of soft-deleted model also find soft-deleted records
+func (b *QueryStructMeta) UnscopedLookup() bool {
+	return b.unscopedLookup && b.SoftDeleteField() != nil
+}
+
+// SoftDeleteField return gorm.DeletedAt field of soft delete, return nil if model isn't soft-deleted
+func (b *QueryStructMeta) SoftDeleteField() *model.Field {
+	for _, f := range b.Fields {
+		if f.ColumnName != "" && (f.Type == "gorm.DeletedAt" || f.ColumnName == b.softDeleteColumn) {
+			return f
+		}
+	}
+	return nil
+}
+
 // ReturnObject return object in generated code
 func (b *QueryStructMeta) ReturnObject() string {
 	if b.interfaceMode {
@@ -459,6 +459,14 @@ func (s *Section) parseTrim() (res TrimClause, err error) {
 			}
 			res.Value = append(res.Value, whereClause)
 			s.appendTmpl(whereClause.Finish(res.VarName))
+		case model.TRIM:
+			var trimClause TrimClause
+			trimClause, err = s.parseTrim()
+			if err != nil {
+				return
+			}
+			res.Value = append(res.Value, trimClause)
+			s.appendTmpl(trimClause.Finish(res.VarName))
 		case model.END:
 			return
 		default:
@@ -648,15 +656,13 @@ func (s *section) checkTemplate() error {
 	}
 
 	if s.Type == model.FOR {
-		if len(s.splitList) != 5 {
-			return fmt.Errorf("for range syntax error: %s", s.Value)
+		if err := s.ForRange.parse(s.splitList); err != nil {
+			return fmt.Errorf("%w: %s", err, s.Value)
 		}
-		if s.SQLSlice.hasSameName(s.splitList[2]) {
+		if s.SQLSlice.hasSameName(s.ForRange.value) {
 			return fmt.Errorf("cannot use the same value name in different for loops")
 		}
-		s.ForRange.index = s.splitList[1]
-		s.ForRange.value = s.splitList[2]
-		s.ForRange.rangeList = s.splitList[4]
+		s.Value = s.ForRange.String()
 	}
 	return nil
 }
@@ -695,6 +701,19 @@ type ForRange struct {
 	rangeList string
 }
 
+// parse parse for loop in form of "for index, value := range list", "for value in list" or "for index, value in list"
+func (f *ForRange) parse(splitList []string) error {
+	switch {
+	case len(splitList) == 5 && (splitList[3] == "range" || splitList[3] == "in"):
+		f.index, f.value, f.rangeList = splitList[1], splitList[2], splitList[4]
+	case len(splitList) == 4 && splitList[2] == "in":
+		f.index, f.value, f.rangeList = "_", splitList[1], splitList[3]
+	default:
+		return fmt.Errorf("for range syntax error")
+	}
+	return nil
+}
+
 func (f *ForRange) String() string {
 	return fmt.Sprintf("for %s, %s := range %s", f.index, f.value, f.rangeList)
 }
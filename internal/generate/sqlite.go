@@ -0,0 +1,85 @@
+package generate
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/migrator"
+
+	"gorm.io/gen/internal/model"
+)
+
+// sqliteColumnsSQL query columns of @table by PRAGMA table_xinfo, hidden columns of virtual table are skipped,
+// generated columns are marked by fillGenerated
+const sqliteColumnsSQL = `SELECT name, type, "notnull" AS not_null, dflt_value, pk FROM pragma_table_xinfo(@table) ` +
+	`WHERE hidden <> 1 ORDER BY cid`
+
+// sqliteTableOptions matches options after column definitions of CREATE TABLE, e.g. ) STRICT, WITHOUT ROWID
+var sqliteTableOptions = regexp.MustCompile(`(?i)\)\s*((?:(?:STRICT|WITHOUT\s+ROWID)\s*,?\s*)+);?\s*$`)
+
+// sqliteSizeRegexp matches size of declared type, e.g. VARCHAR(64), DECIMAL(10, 2)
+var sqliteSizeRegexp = regexp.MustCompile(`\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+
+// sqliteColumn column of sqliteColumnsSQL
+type sqliteColumn struct {
+	Name      string
+	Type      string
+	NotNull   bool
+	DfltValue sql.NullString
+	Pk        int
+}
+
+// sqliteColumns columns of sqlite table read from PRAGMA table_xinfo, which is reliable for tables the DDL parser of
+// sqlite migrator fails on, e.g. STRICT tables. INTEGER PRIMARY KEY of rowid table is alias of rowid, so it's
+// auto increment whether AUTOINCREMENT is declared or not
+func (t *tableInfo) sqliteColumns(tableName string) (result []*model.Column, err error) {
+	var columns []*sqliteColumn
+	if err = t.Raw(sqliteColumnsSQL, map[string]interface{}{"table": tableName}).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+	var ddl string
+	if err = t.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Row().Scan(&ddl); err != nil {
+		return nil, err
+	}
+	var strict, withoutRowID bool
+	if m := sqliteTableOptions.FindStringSubmatch(ddl); m != nil {
+		options := strings.ToUpper(m[1])
+		strict, withoutRowID = strings.Contains(options, "STRICT"), strings.Contains(options, "ROWID")
+	}
+
+	pkCount := 0
+	for _, c := range columns {
+		if c.Pk > 0 {
+			pkCount++
+		}
+	}
+	for _, c := range columns {
+		dataType := strings.TrimSpace(c.Type)
+		if i := strings.IndexByte(dataType, '('); i >= 0 {
+			dataType = strings.TrimSpace(dataType[:i])
+		}
+		rowIDAlias := c.Pk > 0 && pkCount == 1 && !withoutRowID && strings.EqualFold(dataType, "INTEGER")
+		ct := migrator.ColumnType{
+			NameValue:          sql.NullString{String: c.Name, Valid: true},
+			DataTypeValue:      sql.NullString{String: dataType, Valid: true},
+			ColumnTypeValue:    sql.NullString{String: c.Type, Valid: true},
+			PrimaryKeyValue:    sql.NullBool{Bool: c.Pk > 0, Valid: true},
+			AutoIncrementValue: sql.NullBool{Bool: rowIDAlias, Valid: true},
+			NullableValue:      sql.NullBool{Bool: !c.NotNull && !rowIDAlias, Valid: true},
+			DefaultValueValue:  sql.NullString{String: strings.Trim(c.DfltValue.String, `"`), Valid: c.DfltValue.Valid},
+		}
+		if m := sqliteSizeRegexp.FindStringSubmatch(c.Type); m != nil {
+			size, _ := strconv.ParseInt(m[1], 10, 64)
+			if m[2] == "" {
+				ct.LengthValue = sql.NullInt64{Int64: size, Valid: true}
+			} else {
+				scale, _ := strconv.ParseInt(m[2], 10, 64)
+				ct.DecimalSizeValue, ct.ScaleValue = sql.NullInt64{Int64: size, Valid: true}, sql.NullInt64{Int64: scale, Valid: true}
+			}
+		}
+		result = append(result, &model.Column{ColumnType: resultlessColumnType{ct}, TableName: tableName, Dialect: "sqlite", Strict: strict})
+	}
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package generate
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/migrator"
+
+	"gorm.io/gen/internal/model"
+)
+
+// sqlServerColumnsSQL query columns of @table by sys.columns, @table can be qualified by schema(e.g. sales.orders),
+// otherwise it's resolved in default schema of user. Comments are MS_Description extended properties
+const sqlServerColumnsSQL = "SELECT c.name AS column_name, TYPE_NAME(c.system_type_id) AS data_type, c.max_length, c.precision, c.scale, " +
+	"c.is_nullable, c.is_identity, OBJECT_DEFINITION(c.default_object_id) AS column_default, " +
+	"CAST(ep.value AS nvarchar(4000)) AS comment, CASE WHEN EXISTS (SELECT 1 FROM sys.indexes i " +
+	"JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id " +
+	"WHERE i.object_id = c.object_id AND i.is_primary_key = 1 AND ic.column_id = c.column_id) THEN 1 ELSE 0 END AS is_primary " +
+	"FROM sys.columns c LEFT JOIN sys.extended_properties ep ON ep.class = 1 AND ep.major_id = c.object_id " +
+	"AND ep.minor_id = c.column_id AND ep.name = 'MS_Description' " +
+	"WHERE c.object_id = OBJECT_ID(@table) ORDER BY c.column_id"
+
+// sqlServerColumn column of sqlServerColumnsSQL
+type sqlServerColumn struct {
+	ColumnName    string
+	DataType      string
+	MaxLength     int64
+	Precision     int64
+	Scale         int64
+	IsNullable    bool
+	IsIdentity    bool
+	ColumnDefault sql.NullString
+	Comment       sql.NullString
+	IsPrimary     bool
+}
+
+// length length of char and binary column, -1 of max
+func (c *sqlServerColumn) length() int64 {
+	if c.MaxLength > 0 && (c.DataType == "nchar" || c.DataType == "nvarchar") {
+		return c.MaxLength / 2
+	}
+	return c.MaxLength
+}
+
+// columnType full column type, e.g. nvarchar(64), varbinary(max), decimal(10,2), datetime2(7)
+func (c *sqlServerColumn) columnType() string {
+	switch c.DataType {
+	case "char", "varchar", "nchar", "nvarchar", "binary", "varbinary":
+		if c.MaxLength == -1 {
+			return c.DataType + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", c.DataType, c.length())
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", c.DataType, c.Precision, c.Scale)
+	case "datetime2", "datetimeoffset", "time":
+		return fmt.Sprintf("%s(%d)", c.DataType, c.Scale)
+	}
+	return c.DataType
+}
+
+// sqlServerDefault default value without parentheses wrapping definition, e.g. ((0)) -> 0, (N'abc') -> 'abc'
+func sqlServerDefault(definition string) string {
+	v := strings.TrimSpace(definition)
+	for len(v) >= 2 && v[0] == '(' && v[len(v)-1] == ')' && balancedParens(v[1:len(v)-1]) {
+		v = strings.TrimSpace(v[1 : len(v)-1])
+	}
+	if strings.HasPrefix(v, "N'") {
+		v = v[1:]
+	}
+	return v
+}
+
+// balancedParens whether parentheses of s are balanced, e.g. false of 1) + (2
+func balancedParens(s string) bool {
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// sqlServerColumns columns of sql server table read from sys.columns, which supports schema-qualified table and
+// reports IDENTITY columns as auto increment
+func (t *tableInfo) sqlServerColumns(tableName string) (result []*model.Column, err error) {
+	var columns []*sqlServerColumn
+	if err = t.Raw(sqlServerColumnsSQL, map[string]interface{}{"table": tableName}).Scan(&columns).Error; err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s is not found", tableName)
+	}
+
+	for _, c := range columns {
+		ct := migrator.ColumnType{
+			NameValue:          sql.NullString{String: c.ColumnName, Valid: true},
+			DataTypeValue:      sql.NullString{String: c.DataType, Valid: true},
+			ColumnTypeValue:    sql.NullString{String: c.columnType(), Valid: true},
+			PrimaryKeyValue:    sql.NullBool{Bool: c.IsPrimary, Valid: true},
+			AutoIncrementValue: sql.NullBool{Bool: c.IsIdentity, Valid: true},
+			NullableValue:      sql.NullBool{Bool: c.IsNullable, Valid: true},
+			CommentValue:       sql.NullString{String: c.Comment.String, Valid: c.Comment.Valid},
+			DefaultValueValue:  sql.NullString{String: sqlServerDefault(c.ColumnDefault.String), Valid: c.ColumnDefault.Valid},
+		}
+		switch c.DataType {
+		case "char", "varchar", "nchar", "nvarchar", "binary", "varbinary":
+			ct.LengthValue = sql.NullInt64{Int64: c.length(), Valid: true}
+		case "decimal", "numeric":
+			ct.DecimalSizeValue, ct.ScaleValue = sql.NullInt64{Int64: c.Precision, Valid: true}, sql.NullInt64{Int64: c.Scale, Valid: true}
+		}
+		result = append(result, &model.Column{ColumnType: resultlessColumnType{ct}, TableName: tableName, Dialect: "sqlserver"})
+	}
+	return result, nil
+}
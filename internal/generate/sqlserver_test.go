@@ -0,0 +1,35 @@
+package generate
+
+import "testing"
+
+func TestSQLServerColumnType(t *testing.T) {
+	for _, c := range []struct {
+		column sqlServerColumn
+		expect string
+	}{
+		{sqlServerColumn{DataType: "nvarchar", MaxLength: 128}, "nvarchar(64)"},
+		{sqlServerColumn{DataType: "varbinary", MaxLength: -1}, "varbinary(max)"},
+		{sqlServerColumn{DataType: "decimal", Precision: 10, Scale: 2}, "decimal(10,2)"},
+		{sqlServerColumn{DataType: "datetimeoffset", Precision: 34, Scale: 7}, "datetimeoffset(7)"},
+		{sqlServerColumn{DataType: "uniqueidentifier", MaxLength: 16}, "uniqueidentifier"},
+	} {
+		if got := c.column.columnType(); got != c.expect {
+			t.Errorf("column type of %+v expect %s, got %s", c.column, c.expect, got)
+		}
+	}
+
+	for definition, expect := range map[string]string{
+		"((0))":                    "0",
+		"(N'pending')":             "'pending'",
+		"(getdate())":              "getdate()",
+		"((1)+(2))":                "(1)+(2)",
+		"(newsequentialid())":      "newsequentialid()",
+		"(CONVERT([bit],(0)))":     "CONVERT([bit],(0))",
+		"(sysdatetimeoffset())":    "sysdatetimeoffset()",
+		"('2020-01-01T00:00:00Z')": "'2020-01-01T00:00:00Z'",
+	} {
+		if got := sqlServerDefault(definition); got != expect {
+			t.Errorf("default of %s expect %s, got %s", definition, expect, got)
+		}
+	}
+}
@@ -2,9 +2,14 @@ package generate
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"reflect"
+	"regexp"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
 
 	"gorm.io/gen/internal/model"
 )
@@ -20,7 +25,7 @@ func getTableInfo(db *gorm.DB) ITableInfo {
 	return &tableInfo{db}
 }
 
-func getTableColumns(db *gorm.DB, schemaName string, tableName string, indexTag bool) (result []*model.Column, err error) {
+func getTableColumns(db *gorm.DB, schemaName string, tableName string, indexTag, enumType, constraintTag, foreignKey bool) (result []*model.Column, err error) {
 	if db == nil {
 		return nil, errors.New("gorm db is nil")
 	}
@@ -30,9 +35,25 @@ func getTableColumns(db *gorm.DB, schemaName string, tableName string, indexTag
 	if err != nil {
 		return nil, err
 	}
+	if enumType {
+		if err = fillEnumValues(db, result); err != nil { //ignore find enum err
+			db.Logger.Warn(context.Background(), "get enum values for %s,err=%s", tableName, err.Error())
+		}
+	}
+	if err = fillGenerated(db, schemaName, tableName, result); err != nil { //ignore find generated columns err
+		db.Logger.Warn(context.Background(), "get generated columns for %s,err=%s", tableName, err.Error())
+	}
+	if constraintTag || foreignKey {
+		if err = fillConstraints(db, schemaName, tableName, result, constraintTag); err != nil { //ignore find constraints err
+			db.Logger.Warn(context.Background(), "get constraints for %s,err=%s", tableName, err.Error())
+		}
+	}
 	if !indexTag || len(result) == 0 {
 		return result, nil
 	}
+	if db.Dialector.Name() == "clickhouse" { // clickhouse migrator doesn't support GetIndexes
+		return result, nil
+	}
 
 	index, err := mt.GetTableIndex(schemaName, tableName)
 	if err != nil { //ignore find index err
@@ -50,21 +71,272 @@ func getTableColumns(db *gorm.DB, schemaName string, tableName string, indexTag
 	return result, nil
 }
 
+// fillEnumValues fill values of postgres enum columns, values of mysql enum columns are in column type
+func fillEnumValues(db *gorm.DB, columns []*model.Column) error {
+	if db.Dialector.Name() != "postgres" || len(columns) == 0 {
+		return nil
+	}
+	var labels []struct {
+		TypeName string
+		Label    string
+	}
+	err := db.Raw("SELECT t.typname AS type_name, e.enumlabel AS label FROM pg_type t JOIN pg_enum e ON t.oid = e.enumtypid ORDER BY t.typname, e.enumsortorder").
+		Scan(&labels).Error
+	if err != nil {
+		return err
+	}
+	values := make(map[string][]string)
+	for _, l := range labels {
+		values[l.TypeName] = append(values[l.TypeName], l.Label)
+	}
+	for _, c := range columns {
+		c.EnumValues = values[c.DatabaseTypeName()]
+	}
+	return nil
+}
+
+// generatedColumnsSQL query names of generated/virtual columns of @table in @schema(current schema if empty) by dialect,
+// sql server rowversion columns are read-only like computed columns
+var generatedColumnsSQL = map[string]string{
+	"mysql": "SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = COALESCE(NULLIF(@schema, ''), DATABASE()) " +
+		"AND TABLE_NAME = @table AND (EXTRA LIKE '%VIRTUAL GENERATED%' OR EXTRA LIKE '%STORED GENERATED%')",
+	"postgres": "SELECT column_name FROM information_schema.columns WHERE table_schema = COALESCE(NULLIF(@schema, ''), CURRENT_SCHEMA()) " +
+		"AND table_name = @table AND is_generated = 'ALWAYS'",
+	"sqlserver": "SELECT name FROM sys.columns WHERE object_id = OBJECT_ID(@table) AND (is_computed = 1 OR TYPE_NAME(system_type_id) = 'timestamp')",
+	"sqlite":    "SELECT name FROM pragma_table_xinfo(@table) WHERE hidden IN (2, 3)",
+	"clickhouse": "SELECT name FROM system.columns WHERE database = COALESCE(NULLIF(@schema, ''), currentDatabase()) " +
+		"AND table = @table AND default_kind IN ('MATERIALIZED', 'ALIAS')",
+	"oracle": "SELECT COLUMN_NAME FROM ALL_TAB_COLS WHERE OWNER = NVL(@schema, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) " +
+		"AND TABLE_NAME = @table AND VIRTUAL_COLUMN = 'YES' AND HIDDEN_COLUMN = 'NO'",
+}
+
+// fillGenerated mark generated/virtual columns, e.g. mysql GENERATED ALWAYS AS, clickhouse MATERIALIZED columns
+func fillGenerated(db *gorm.DB, schemaName string, tableName string, columns []*model.Column) error {
+	query, ok := generatedColumnsSQL[db.Dialector.Name()]
+	if !ok || len(columns) == 0 {
+		return nil
+	}
+	var names []string
+	err := db.Raw(query, map[string]interface{}{"schema": schemaName, "table": tableName}).Scan(&names).Error
+	if err != nil {
+		return err
+	}
+	generated := make(map[string]bool, len(names))
+	for _, name := range names {
+		generated[name] = true
+	}
+	for _, c := range columns {
+		c.Generated = generated[c.Name()]
+	}
+	return nil
+}
+
+// checkConstraintsSQL query name and clause of check constraints of @table in @schema(current schema if empty) by dialect
+var checkConstraintsSQL = map[string]string{
+	"mysql": "SELECT tc.CONSTRAINT_NAME AS name, cc.CHECK_CLAUSE AS clause FROM information_schema.TABLE_CONSTRAINTS tc " +
+		"JOIN information_schema.CHECK_CONSTRAINTS cc ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME " +
+		"WHERE tc.TABLE_SCHEMA = COALESCE(NULLIF(@schema, ''), DATABASE()) AND tc.TABLE_NAME = @table AND tc.CONSTRAINT_TYPE = 'CHECK'",
+	"postgres": "SELECT con.conname AS name, pg_get_constraintdef(con.oid) AS clause FROM pg_constraint con " +
+		"JOIN pg_class c ON c.oid = con.conrelid JOIN pg_namespace n ON n.oid = c.relnamespace " +
+		"WHERE con.contype = 'c' AND c.relname = @table AND n.nspname = COALESCE(NULLIF(@schema, ''), CURRENT_SCHEMA())",
+	"sqlserver": "SELECT name, definition AS clause FROM sys.check_constraints WHERE parent_object_id = OBJECT_ID(@table)",
+}
+
+// foreignKeysSQL query single column foreign keys of @table in @schema(current schema if empty) by dialect
+var foreignKeysSQL = map[string]string{
+	"mysql": "SELECT k.COLUMN_NAME AS column_name, k.CONSTRAINT_NAME AS name, k.REFERENCED_TABLE_NAME AS ref_table, " +
+		"k.REFERENCED_COLUMN_NAME AS ref_column, r.UPDATE_RULE AS on_update, r.DELETE_RULE AS on_delete " +
+		"FROM information_schema.KEY_COLUMN_USAGE k JOIN information_schema.REFERENTIAL_CONSTRAINTS r " +
+		"ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.TABLE_NAME = k.TABLE_NAME AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME " +
+		"WHERE k.TABLE_SCHEMA = COALESCE(NULLIF(@schema, ''), DATABASE()) AND k.TABLE_NAME = @table AND k.REFERENCED_TABLE_NAME IS NOT NULL " +
+		"AND (SELECT COUNT(*) FROM information_schema.KEY_COLUMN_USAGE c WHERE c.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA " +
+		"AND c.TABLE_NAME = k.TABLE_NAME AND c.CONSTRAINT_NAME = k.CONSTRAINT_NAME) = 1",
+	"postgres": "SELECT a.attname AS column_name, con.conname AS name, rc.relname AS ref_table, ra.attname AS ref_column, " +
+		"CASE con.confupdtype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END AS on_update, " +
+		"CASE con.confdeltype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END AS on_delete " +
+		"FROM pg_constraint con JOIN pg_class c ON c.oid = con.conrelid JOIN pg_namespace n ON n.oid = c.relnamespace " +
+		"JOIN pg_class rc ON rc.oid = con.confrelid " +
+		"JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = con.conkey[1] " +
+		"JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = con.confkey[1] " +
+		"WHERE con.contype = 'f' AND array_length(con.conkey, 1) = 1 AND c.relname = @table AND n.nspname = COALESCE(NULLIF(@schema, ''), CURRENT_SCHEMA())",
+	"sqlserver": "SELECT pc.name AS column_name, fk.name AS name, OBJECT_NAME(fk.referenced_object_id) AS ref_table, rc.name AS ref_column, " +
+		"fk.update_referential_action_desc AS on_update, fk.delete_referential_action_desc AS on_delete FROM sys.foreign_keys fk " +
+		"JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id " +
+		"JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id " +
+		"JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id " +
+		"WHERE fk.parent_object_id = OBJECT_ID(@table) " +
+		"AND (SELECT COUNT(*) FROM sys.foreign_key_columns c WHERE c.constraint_object_id = fk.object_id) = 1",
+	"sqlite": `SELECT "from" AS column_name, '' AS name, "table" AS ref_table, COALESCE("to", '') AS ref_column, on_update, on_delete ` +
+		`FROM pragma_foreign_key_list(@table) WHERE id IN (SELECT id FROM pragma_foreign_key_list(@table) GROUP BY id HAVING COUNT(*) = 1)`,
+}
+
+// checkClauseRegexp matches check constraint definition, e.g. postgres CHECK ((age >= 0)) NOT VALID
+var checkClauseRegexp = regexp.MustCompile(`(?is)^\s*CHECK\s*(.*?)(?:\s+NOT\s+VALID)?\s*$`)
+
+// identifierRegexp matches identifiers in check clause
+var identifierRegexp = regexp.MustCompile(`\w+`)
+
+// fillConstraints fill foreign keys and check constraints(if checks is true) of columns, check constraint belongs to
+// the first column in its clause. sqlite check constraints are not introspected
+func fillConstraints(db *gorm.DB, schemaName string, tableName string, columns []*model.Column, checks bool) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	params := map[string]interface{}{"schema": schemaName, "table": tableName}
+	byName := make(map[string]*model.Column, len(columns))
+	for _, c := range columns {
+		byName[c.Name()] = c
+	}
+
+	if query, ok := checkConstraintsSQL[db.Dialector.Name()]; ok && checks {
+		var checks []*model.CheckConstraint
+		if err := db.Raw(query, params).Scan(&checks).Error; err != nil {
+			return err
+		}
+		for _, check := range checks {
+			if m := checkClauseRegexp.FindStringSubmatch(check.Clause); m != nil {
+				check.Clause = m[1]
+			}
+			for _, name := range identifierRegexp.FindAllString(strings.NewReplacer("[", " ", "]", " ").Replace(check.Clause), -1) {
+				if c, ok := byName[name]; ok {
+					c.Checks = append(c.Checks, check)
+					break
+				}
+			}
+		}
+	}
+
+	if query, ok := foreignKeysSQL[db.Dialector.Name()]; ok {
+		var foreignKeys []struct {
+			ColumnName string
+			model.ForeignKey
+		}
+		if err := db.Raw(query, params).Scan(&foreignKeys).Error; err != nil {
+			return err
+		}
+		for i, fk := range foreignKeys {
+			if c, ok := byName[fk.ColumnName]; ok {
+				c.ForeignKey = &foreignKeys[i].ForeignKey
+			}
+		}
+	}
+	return nil
+}
+
 type tableInfo struct{ *gorm.DB }
 
 // GetTableColumns  struct
 func (t *tableInfo) GetTableColumns(schemaName string, tableName string) (result []*model.Column, err error) {
+	switch t.Dialector.Name() {
+	case "oracle":
+		return t.oracleColumns(schemaName, tableName)
+	case "sqlite":
+		return t.sqliteColumns(tableName)
+	case "sqlserver":
+		return t.sqlServerColumns(tableName)
+	}
 	types, err := t.Migrator().ColumnTypes(tableName)
 	if err != nil {
 		return nil, err
 	}
 	for _, column := range types {
-		result = append(result, &model.Column{ColumnType: column, TableName: tableName, UseScanType: t.Dialector.Name() != "mysql" && t.Dialector.Name() != "sqlite"})
+		if ct, ok := column.(migrator.ColumnType); ok && ct.SQLColumnType == nil && t.Dialector.Name() == "clickhouse" {
+			ct.NullableValue = sql.NullBool{Bool: model.IsClickHouseNullable(ct.DatabaseTypeName()), Valid: true}
+			column = resultlessColumnType{ct}
+		}
+		result = append(result, &model.Column{ColumnType: column, TableName: tableName, Dialect: t.Dialector.Name(), UseScanType: t.Dialector.Name() != "mysql" && t.Dialector.Name() != "sqlite"})
 	}
 	return result, nil
 }
 
+// resultlessColumnType type of column which is not in result set, e.g. clickhouse MATERIALIZED and ALIAS columns are not
+// selected by SELECT *, oracle, sqlite and sql server columns are read from data dictionary, values read from result set are unknown instead of panic
+type resultlessColumnType struct{ migratorColumnType }
+
+// migratorColumnType alias of migrator.ColumnType, embedded field named ColumnType would shadow its ColumnType method
+type migratorColumnType = migrator.ColumnType
+
+// Length ...
+func (ct resultlessColumnType) Length() (length int64, ok bool) {
+	return ct.LengthValue.Int64, ct.LengthValue.Valid
+}
+
+// DecimalSize ...
+func (ct resultlessColumnType) DecimalSize() (precision int64, scale int64, ok bool) {
+	return ct.DecimalSizeValue.Int64, ct.ScaleValue.Int64, ct.DecimalSizeValue.Valid
+}
+
+// Nullable ...
+func (ct resultlessColumnType) Nullable() (nullable bool, ok bool) {
+	return ct.NullableValue.Bool, ct.NullableValue.Valid
+}
+
+// ScanType ...
+func (ct resultlessColumnType) ScanType() reflect.Type { return ct.ScanTypeValue }
+
+// GetTableIndexes indexes of table in schema(current schema if empty), columns are in their order in index
+func GetTableIndexes(db *gorm.DB, schemaName string, tableName string) ([]gorm.Index, error) {
+	return getTableInfo(db).GetTableIndex(schemaName, tableName)
+}
+
 // GetTableIndex  index
 func (t *tableInfo) GetTableIndex(schemaName string, tableName string) (indexes []gorm.Index, err error) {
+	if query, ok := indexColumnsSQL[t.Dialector.Name()]; ok {
+		return t.queryIndexes(query, schemaName, tableName)
+	}
 	return t.Migrator().GetIndexes(tableName)
 }
+
+// indexColumnsSQL query columns of indexes of @table in @schema(current schema if empty) ordered by their position in index,
+// used for dialects whose migrator doesn't report order of columns(postgres) or doesn't support GetIndexes(sqlite, sqlserver, oracle)
+var indexColumnsSQL = map[string]string{
+	"postgres": "SELECT i.relname AS index_name, a.attname AS column_name, ix.indisunique AS is_unique, ix.indisprimary AS is_primary " +
+		"FROM pg_index ix JOIN pg_class t ON t.oid = ix.indrelid JOIN pg_class i ON i.oid = ix.indexrelid " +
+		"JOIN pg_namespace n ON n.oid = t.relnamespace " +
+		"CROSS JOIN LATERAL unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord) " +
+		"JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum " +
+		"WHERE t.relname = @table AND n.nspname = COALESCE(NULLIF(@schema, ''), CURRENT_SCHEMA()) ORDER BY i.relname, k.ord",
+	"sqlite": "SELECT il.name AS index_name, ii.name AS column_name, il.\"unique\" AS is_unique, il.origin = 'pk' AS is_primary " +
+		"FROM pragma_index_list(@table) AS il, pragma_index_info(il.name) AS ii WHERE ii.name IS NOT NULL ORDER BY il.name, ii.seqno",
+	"sqlserver": "SELECT i.name AS index_name, c.name AS column_name, i.is_unique AS is_unique, i.is_primary_key AS is_primary " +
+		"FROM sys.indexes i JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id " +
+		"JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id " +
+		"WHERE i.object_id = OBJECT_ID(@table) AND ic.is_included_column = 0 ORDER BY i.name, ic.key_ordinal",
+	"oracle": `SELECT i.INDEX_NAME AS "index_name", ic.COLUMN_NAME AS "column_name", ` +
+		`CASE i.UNIQUENESS WHEN 'UNIQUE' THEN '1' ELSE '0' END AS "is_unique", ` +
+		`CASE WHEN EXISTS (SELECT 1 FROM ALL_CONSTRAINTS k WHERE k.OWNER = i.TABLE_OWNER AND k.TABLE_NAME = i.TABLE_NAME ` +
+		`AND k.CONSTRAINT_TYPE = 'P' AND k.INDEX_NAME = i.INDEX_NAME) THEN '1' ELSE '0' END AS "is_primary" ` +
+		`FROM ALL_INDEXES i JOIN ALL_IND_COLUMNS ic ON ic.INDEX_OWNER = i.OWNER AND ic.INDEX_NAME = i.INDEX_NAME ` +
+		`WHERE i.TABLE_OWNER = NVL(@schema, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')) AND i.TABLE_NAME = @table ` +
+		`ORDER BY i.INDEX_NAME, ic.COLUMN_POSITION`,
+}
+
+// queryIndexes query indexes of table by query of indexColumnsSQL
+func (t *tableInfo) queryIndexes(query string, schemaName string, tableName string) (indexes []gorm.Index, err error) {
+	var rows []struct {
+		IndexName  string
+		ColumnName string
+		IsUnique   bool
+		IsPrimary  bool
+	}
+	err = t.Raw(query, map[string]interface{}{"schema": schemaName, "table": tableName}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	indexMap := make(map[string]*migrator.Index)
+	for _, row := range rows {
+		idx, ok := indexMap[row.IndexName]
+		if !ok {
+			idx = &migrator.Index{
+				TableName:       tableName,
+				NameValue:       row.IndexName,
+				PrimaryKeyValue: sql.NullBool{Bool: row.IsPrimary, Valid: true},
+				UniqueValue:     sql.NullBool{Bool: row.IsUnique, Valid: true},
+			}
+			indexMap[row.IndexName] = idx
+			indexes = append(indexes, idx)
+		}
+		idx.ColumnList = append(idx.ColumnList, row.ColumnName)
+	}
+	return indexes, nil
+}
@@ -1,7 +1,9 @@
 package generate
 
 import (
+	"strconv"
 	"strings"
+	"time"
 )
 
 func isCapitalize(s string) bool {
@@ -59,3 +61,16 @@ func uncaptialize(s string) string {
 
 	return strings.ToLower(s[:1]) + s[1:]
 }
+
+// durationLiteral go expression of d, e.g. 3 * time.Second
+func durationLiteral(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{{time.Hour, "time.Hour"}, {time.Minute, "time.Minute"}, {time.Second, "time.Second"}, {time.Millisecond, "time.Millisecond"}} {
+		if d%unit.d == 0 {
+			return strconv.FormatInt(int64(d/unit.d), 10) + " * " + unit.name
+		}
+	}
+	return "time.Duration(" + strconv.FormatInt(int64(d), 10) + ")"
+}
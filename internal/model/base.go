@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strings"
 
+	"gorm.io/gorm/schema"
+
 	"gorm.io/gen/field"
 )
 
@@ -151,15 +153,49 @@ var (
 	}
 )
 
+// arrayDataType element data type of postgres array column -> go type of array
+var arrayDataType = map[string]string{
+	"smallint":          "pq.Int32Array",
+	"int2":              "pq.Int32Array",
+	"integer":           "pq.Int32Array",
+	"int":               "pq.Int32Array",
+	"int4":              "pq.Int32Array",
+	"bigint":            "pq.Int64Array",
+	"int8":              "pq.Int64Array",
+	"real":              "pq.Float32Array",
+	"float4":            "pq.Float32Array",
+	"double precision":  "pq.Float64Array",
+	"float8":            "pq.Float64Array",
+	"numeric":           "pq.Float64Array",
+	"decimal":           "pq.Float64Array",
+	"boolean":           "pq.BoolArray",
+	"bool":              "pq.BoolArray",
+	"text":              "pq.StringArray",
+	"character varying": "pq.StringArray",
+	"varchar":           "pq.StringArray",
+	"character":         "pq.StringArray",
+	"char":              "pq.StringArray",
+	"bpchar":            "pq.StringArray",
+	"citext":            "pq.StringArray",
+	"uuid":              "pq.StringArray",
+	"bytea":             "pq.ByteaArray",
+}
+
 type dataTypeMapping func(detailType string) (finalType string)
 
 type dataTypeMap map[string]dataTypeMapping
 
 func (m dataTypeMap) Get(dataType, detailType string) string {
+	typ, _ := m.lookup(dataType, detailType)
+	return typ
+}
+
+// lookup go type of data type, defaultDataType and false if data type is unknown
+func (m dataTypeMap) lookup(dataType, detailType string) (string, bool) {
 	if convert, ok := m[strings.ToLower(dataType)]; ok {
-		return convert(detailType)
+		return convert(detailType), true
 	}
-	return defaultDataType
+	return defaultDataType, false
 }
 
 // Field user input structures
@@ -173,6 +209,13 @@ type Field struct {
 	GORMTag          field.GormTag
 	CustomGenType    string
 	Relation         *field.Relation
+	Enum             *Enum       // named type generated for field
+	BinaryUUID       *BinaryUUID // named uuid type generated for binary(16) column
+	Geometry         *Geometry   // named orb geometry type generated for spatial column
+	Validation       *Validation // constraints of column checked by Validate method
+	DocComment       bool        // column comment is rendered as doc comment above field instead of trailing it
+
+	Embedded bool // declared in embedded base model instead of model, see Config.BaseModelColumns
 }
 
 // Tags ...
@@ -187,9 +230,51 @@ func (m *Field) Tags() string {
 	return m.Tag.Build()
 }
 
+// deprecatedPrefix prefix of comment of deprecated column, matched case-insensitively
+const deprecatedPrefix = "deprecated:"
+
+// Deprecated notice of column whose comment starts with DEPRECATED:, e.g. "DEPRECATED: use nick" -> "use nick"
+func (m *Field) Deprecated() (notice string, ok bool) {
+	if len(m.ColumnComment) < len(deprecatedPrefix) || !strings.EqualFold(m.ColumnComment[:len(deprecatedPrefix)], deprecatedPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(m.ColumnComment[len(deprecatedPrefix):]), true
+}
+
+// HasDoc whether field has doc comment, which is rendered for deprecated column even if DocComment is false
+func (m *Field) HasDoc() bool {
+	_, deprecated := m.Deprecated()
+	return deprecated || (m.DocComment && m.ColumnComment != "")
+}
+
+// Doc doc comment of field from column comment, deprecated column is marked with "Deprecated:" paragraph
+func (m *Field) Doc() string {
+	if notice, ok := m.Deprecated(); ok {
+		if notice == "" {
+			notice = "column " + m.ColumnName + " is deprecated."
+		}
+		return "// Deprecated: " + strings.ReplaceAll(notice, "\n", "\n// ")
+	}
+	lines := strings.Split(m.ColumnComment, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("// "+line, " ")
+	}
+	lines[0] = "// " + m.Name + " " + strings.TrimPrefix(lines[0], "// ")
+	return strings.Join(lines, "\n")
+}
+
 // IsRelation ...
 func (m *Field) IsRelation() bool { return m.Relation != nil }
 
+// IsJSON whether field is mapped to json column as datatypes.JSON or with json serializer
+func (m *Field) IsJSON() bool {
+	if strings.TrimLeft(m.Type, "*") == "datatypes.JSON" {
+		return true
+	}
+	serializer := m.GORMTag[field.TagKeyGormSerializer]
+	return len(serializer) > 0 && serializer[0] == "json"
+}
+
 // GenType ...
 func (m *Field) GenType() string {
 	if m.IsRelation() {
@@ -219,6 +304,78 @@ func (m *Field) GenType() string {
 	}
 }
 
+// GenValueType type of the value accepted by generated field's Eq method, empty if not comparable
+func (m *Field) GenValueType() string {
+	switch genType := m.GenType(); genType {
+	case "Int", "Int8", "Int16", "Int32", "Int64", "Uint", "Uint8", "Uint16", "Uint32", "Uint64", "Float32", "Float64", "String":
+		return strings.ToLower(genType)
+	case "Bytes":
+		return "[]byte"
+	case "Time":
+		return "time.Time"
+	default:
+		return ""
+	}
+}
+
+// IsPrimaryKey ...
+func (m *Field) IsPrimaryKey() bool {
+	if m.IsRelation() {
+		return false
+	}
+	settings := m.gormTagSettings()
+	_, pk := settings["PRIMARYKEY"]
+	_, pkAlias := settings["PRIMARY_KEY"]
+	return pk || pkAlias
+}
+
+// IsIndexLeading whether field is the only or the first column of an index
+func (m *Field) IsIndexLeading() bool {
+	if m.IsRelation() {
+		return false
+	}
+	for _, key := range []string{field.TagKeyGormUniqueIndex, field.TagKeyGormIndex} {
+		for _, v := range m.GORMTag[key] {
+			priority := "1"
+			for _, opt := range strings.Split(v, ",") {
+				if strings.HasPrefix(opt, "priority:") {
+					priority = strings.TrimPrefix(opt, "priority:")
+				}
+			}
+			if priority == "1" {
+				return true
+			}
+		}
+	}
+	settings := m.gormTagSettings()
+	_, unique := settings["UNIQUE"]
+	return unique
+}
+
+// IndexNames names of indexes containing field, declared by index and uniqueIndex of gorm tag
+func (m *Field) IndexNames() (names []string) {
+	if m.IsRelation() {
+		return nil
+	}
+	for _, key := range []string{field.TagKeyGormUniqueIndex, field.TagKeyGormIndex} {
+		for _, v := range m.GORMTag[key] {
+			if name := strings.TrimSpace(strings.Split(v, ",")[0]); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// gormTagSettings parse gorm tag into settings with upper case keys
+func (m *Field) gormTagSettings() map[string]string {
+	tag, ok := m.Tag[field.TagKeyGorm]
+	if !ok {
+		tag = m.GORMTag.Build()
+	}
+	return schema.ParseTagSetting(tag, ";")
+}
+
 // EscapeKeyword escape keyword
 func (m *Field) EscapeKeyword() *Field {
 	return m.EscapeKeywordFor(GormKeywords)
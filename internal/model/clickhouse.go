@@ -0,0 +1,125 @@
+package model
+
+import "strings"
+
+// clickHouseDataTypes clickhouse data type -> go type scanned by clickhouse-go, data type names are case-sensitive
+var clickHouseDataTypes = map[string]string{
+	"Int8":         "int8",
+	"Int16":        "int16",
+	"Int32":        "int32",
+	"Int64":        "int64",
+	"Int128":       "*big.Int",
+	"Int256":       "*big.Int",
+	"UInt8":        "uint8",
+	"UInt16":       "uint16",
+	"UInt32":       "uint32",
+	"UInt64":       "uint64",
+	"UInt128":      "*big.Int",
+	"UInt256":      "*big.Int",
+	"Float32":      "float32",
+	"Float64":      "float64",
+	"Bool":         "bool",
+	"Boolean":      "bool",
+	"String":       "string",
+	"FixedString":  "string",
+	"UUID":         "string",
+	"Enum8":        "string",
+	"Enum16":       "string",
+	"Date":         "time.Time",
+	"Date32":       "time.Time",
+	"DateTime":     "time.Time",
+	"DateTime64":   "time.Time",
+	"Decimal":      "decimal.Decimal",
+	"Decimal32":    "decimal.Decimal",
+	"Decimal64":    "decimal.Decimal",
+	"Decimal128":   "decimal.Decimal",
+	"Decimal256":   "decimal.Decimal",
+	"IPv4":         "net.IP",
+	"IPv6":         "net.IP",
+	"Point":        "orb.Point",
+	"Ring":         "orb.Ring",
+	"Polygon":      "orb.Polygon",
+	"MultiPolygon": "orb.MultiPolygon",
+}
+
+// clickHouseGoType go type of clickhouse data type, e.g. LowCardinality(String) -> string, Array(Nullable(Int32)) -> []*int32,
+// Map(String, UInt64) -> map[string]uint64. Nullable(T) is T since pointer of nullable column is decided by FieldNullable,
+// ok is false if typ is not clickhouse data type or its go type is unknown, e.g. Tuple
+func clickHouseGoType(typ string) (goType string, ok bool) {
+	name, args := splitClickHouseType(typ)
+	switch name {
+	case "Nullable", "LowCardinality":
+		return clickHouseGoType(args)
+	case "SimpleAggregateFunction": // SimpleAggregateFunction(func, T)
+		if params := splitClickHouseArgs(args); len(params) == 2 {
+			return clickHouseGoType(params[1])
+		}
+		return "", false
+	case "Array":
+		elem, ok := clickHouseElemType(args)
+		return "[]" + elem, ok
+	case "Map":
+		params := splitClickHouseArgs(args)
+		if len(params) != 2 {
+			return "", false
+		}
+		key, ok := clickHouseGoType(params[0])
+		if !ok {
+			return "", false
+		}
+		value, ok := clickHouseElemType(params[1])
+		return "map[" + key + "]" + value, ok
+	default:
+		goType, ok = clickHouseDataTypes[name]
+		return goType, ok
+	}
+}
+
+// clickHouseElemType go type of element of Array and Map, nullable element is pointer
+func clickHouseElemType(typ string) (string, bool) {
+	goType, ok := clickHouseGoType(typ)
+	if ok && IsClickHouseNullable(typ) && !strings.HasPrefix(goType, "*") {
+		goType = "*" + goType
+	}
+	return goType, ok
+}
+
+// IsClickHouseNullable whether clickhouse data type is Nullable(T) or LowCardinality(Nullable(T))
+func IsClickHouseNullable(typ string) bool {
+	name, args := splitClickHouseType(typ)
+	if name == "LowCardinality" {
+		name, _ = splitClickHouseType(args)
+	}
+	return name == "Nullable"
+}
+
+// splitClickHouseType split clickhouse data type into name and arguments, e.g. Decimal(10, 2) -> Decimal, "10, 2"
+func splitClickHouseType(typ string) (name, args string) {
+	typ = strings.TrimSpace(typ)
+	i := strings.IndexByte(typ, '(')
+	if i < 0 || !strings.HasSuffix(typ, ")") {
+		return typ, ""
+	}
+	return strings.TrimSpace(typ[:i]), strings.TrimSpace(typ[i+1 : len(typ)-1])
+}
+
+// splitClickHouseArgs split arguments of clickhouse data type by top level comma, e.g. "String, Array(UInt8)"
+func splitClickHouseArgs(args string) (params []string) {
+	var depth, start int
+	var quoted bool
+	for i, r := range args {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			params = append(params, strings.TrimSpace(args[start:i]))
+			start = i + 1
+		}
+	}
+	return append(params, strings.TrimSpace(args[start:]))
+}
@@ -17,6 +17,8 @@ type Config struct {
 	ImportPkgPaths []string
 	ModelOpts      []Option
 
+	Schema *Schema // generate from metadata instead of db when it's not nil
+
 	NameStrategy
 	FieldConfig
 	MethodConfig
@@ -31,6 +33,9 @@ type NameStrategy struct {
 	FileNameNS  func(tableName string) string
 }
 
+// PIIType go type of sensitive column, masked in String and JSON output
+const PIIType = "gen.PII"
+
 // FieldConfig field configuration
 type FieldConfig struct {
 	DataTypeMap map[string]func(columnType gorm.ColumnType) (dataType string)
@@ -40,6 +45,35 @@ type FieldConfig struct {
 	FieldSignable     bool // detect integer field's unsigned type, adjust generated data type
 	FieldWithIndexTag bool // generate with gorm index tag
 	FieldWithTypeTag  bool // generate with gorm column type tag
+	FieldWithEnumType bool // generate named type with constants for enum and set column
+	// generate named type with constants for integer column declared in comment, e.g. status: 1-active 2-disabled
+	FieldWithCommentEnum bool
+	ArrayTypeMap         map[string]string // go type of postgres array column by element data type
+	FieldWithJSONType    bool              // generate json/jsonb column as datatypes.JSON
+	FieldWithUUIDType    bool              // generate uuid column as uuid.UUID
+	UUIDColumnTypes      []string          // column types also generated as uuid.UUID, e.g. char(36), binary(16)
+	DecimalType          string            // go type of decimal/numeric column, e.g. decimal.Decimal
+	NullableStyle        string            // type of nullable field: pointer, sqlnull or genericNull
+	DateType             string            // go type of date column, e.g. datatypes.Date
+	TimeType             string            // go type of time column, e.g. datatypes.Time
+	DateTimeType         string            // go type of datetime/timestamp column, e.g. string
+
+	FieldWithGeometryType bool // generate spatial column as named type of orb geometry
+
+	IntegerTypeMap map[string]string // go type of integer column of current dialect by column type or data type
+
+	FieldWithConstraintTag bool // generate with gorm check tag and constraint tag of belongs to relation fields
+	FieldWithRelation      bool // introspect foreign keys of columns to generate relation fields
+
+	PIIColumns []string // sensitive columns of table generated as PIIType with pii serializer
+
+	ValidateNotNullZero bool // Validate reports zero value of non-pointer field of NOT NULL column as required
+
+	// render column comment as doc comment above field, comment starting with DEPRECATED: is always rendered as
+	// Deprecated: paragraph
+	FieldWithDocComment bool
+	// tag field of deprecated column with <-:update, so it's not written by create
+	FieldOmitDeprecated bool
 
 	FieldJSONTagNS func(columnName string) string
 
@@ -100,6 +134,16 @@ func (cfg *Config) GetModelMethods() (methods []interface{}) {
 	return
 }
 
+// IsPIIColumn whether column is sensitive column in PIIColumns
+func (cfg *FieldConfig) IsPIIColumn(columnName string) bool {
+	for _, c := range cfg.PIIColumns {
+		if c == columnName {
+			return true
+		}
+	}
+	return false
+}
+
 // GetSchemaName get schema name
 func (cfg *Config) GetSchemaName(db *gorm.DB) string {
 	if cfg == nil {
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// checkNameRegexp name of check constraint recognized by gorm check tag, other names are dropped from tag
+var checkNameRegexp = regexp.MustCompile(`^[A-Za-z_-]+$`)
+
+// CheckConstraint check constraint of column
+type CheckConstraint struct {
+	Name   string `json:"name"`
+	Clause string `json:"clause"` // expression without CHECK, e.g. age >= 0
+}
+
+// TagValue value of gorm check tag: name,clause or clause, ok is false if clause cannot be written in struct tag
+func (c *CheckConstraint) TagValue() (value string, ok bool) {
+	clause := strings.NewReplacer("`", "", `"`, "").Replace(strings.TrimSpace(c.Clause)) // quoted identifiers
+	if clause == "" || strings.ContainsAny(clause, ";\\\n") {
+		return "", false
+	}
+	if checkNameRegexp.MatchString(c.Name) {
+		return c.Name + "," + clause, true
+	}
+	return clause, true
+}
+
+// CheckTagValue value of gorm check tag of column, gorm supports one check tag of each field, so multiple checks are
+// merged into an unnamed check of their clauses joined by AND. ok is false if no clause can be written in struct tag
+func CheckTagValue(checks []*CheckConstraint) (value string, ok bool) {
+	var clauses []string
+	for _, check := range checks {
+		if v, ok := check.TagValue(); ok {
+			value = v
+			clauses = append(clauses, "("+strings.TrimSpace(check.Clause)+")")
+		}
+	}
+	switch len(clauses) {
+	case 0:
+		return "", false
+	case 1:
+		return value, true
+	default:
+		return strings.NewReplacer("`", "", `"`, "").Replace(strings.Join(clauses, " AND ")), true
+	}
+}
+
+// ForeignKey foreign key constraint of column
+type ForeignKey struct {
+	Name      string `json:"name"`
+	RefTable  string `json:"refTable"`
+	RefColumn string `json:"refColumn"`
+	OnUpdate  string `json:"onUpdate,omitempty"` // referential action, e.g. CASCADE, SET NULL
+	OnDelete  string `json:"onDelete,omitempty"`
+}
+
+// ConstraintTagValue value of gorm constraint tag of relation field, e.g. OnUpdate:CASCADE,OnDelete:SET NULL,
+// empty if both actions are default(NO ACTION/RESTRICT)
+func (fk *ForeignKey) ConstraintTagValue() string {
+	var actions []string
+	if action := referentialAction(fk.OnUpdate); action != "" {
+		actions = append(actions, "OnUpdate:"+action)
+	}
+	if action := referentialAction(fk.OnDelete); action != "" {
+		actions = append(actions, "OnDelete:"+action)
+	}
+	return strings.Join(actions, ",")
+}
+
+// referentialAction normalize referential action, e.g. SET_NULL(sqlserver) -> SET NULL, NO ACTION and RESTRICT are empty
+func referentialAction(action string) string {
+	switch action = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(action), "_", " ")); action {
+	case "", "NO ACTION", "RESTRICT":
+		return ""
+	default:
+		return action
+	}
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Enum named type generated for column with a fixed set of values, e.g. enum column
+type Enum struct {
+	Name       string // type name, e.g. OrderStatus
+	Type       string // underlying type, e.g. string
+	ColumnName string
+	Set        bool // values are stored comma-separated like mysql set column, named type is slice of Type
+	Values     []*EnumValue
+}
+
+// EnumValue constant of enum type
+type EnumValue struct {
+	Name    string // constant name, e.g. OrderStatusPaid
+	Value   string // value in db, e.g. paid
+	Literal string // value in go, e.g. "paid"
+	Comment string
+}
+
+var (
+	enumTypeRegexp = regexp.MustCompile(`(?is)^\s*enum\s*\((.*)\)\s*$`)
+	setTypeRegexp  = regexp.MustCompile(`(?is)^\s*set\s*\((.*)\)\s*$`)
+	// commentEnumRegexp value and its label declared in column comment, e.g. 1-active, 2:disabled, 3=deleted
+	commentEnumRegexp = regexp.MustCompile(`(?:^|[\s,;|:，；、：])(-?\d+)\s*[-=:：]\s*([\p{L}_][\p{L}\p{N}_]*)`)
+)
+
+// NewStringEnum build enum type of string values, constants are named by name and values
+func NewStringEnum(name, columnName string, values []string) *Enum {
+	e := &Enum{Name: name, Type: "string", ColumnName: columnName}
+	for _, v := range values {
+		e.Append(v, strconv.Quote(v), v, "")
+	}
+	return e
+}
+
+// NewStringSet build set type of string values, it's stored as comma-separated values
+func NewStringSet(name, columnName string, values []string) *Enum {
+	e := NewStringEnum(name, columnName, values)
+	e.Set = true
+	return e
+}
+
+// Append add constant named by enum name and label, named with sequence number if label duplicates
+func (e *Enum) Append(value, literal, label, comment string) {
+	name := e.Name + Identifier(label)
+	for i, unique := 2, name; ; i++ {
+		if !e.hasConst(unique) {
+			name = unique
+			break
+		}
+		unique = name + strconv.Itoa(i)
+	}
+	e.Values = append(e.Values, &EnumValue{Name: name, Value: value, Literal: literal, Comment: comment})
+}
+
+func (e *Enum) hasConst(name string) bool {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Identifier convert label into exported identifier part, e.g. in-progress -> InProgress
+func Identifier(label string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(label, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(word)
+		b.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+	if b.Len() == 0 {
+		return "Empty"
+	}
+	return b.String()
+}
+
+// ParseEnumValues parse values of mysql enum column type, e.g. enum('pending','paid'), return nil if it's not enum
+func ParseEnumValues(columnType string) []string {
+	matches := enumTypeRegexp.FindStringSubmatch(columnType)
+	if matches == nil {
+		return nil
+	}
+	return parseQuotedList(matches[1])
+}
+
+// ParseSetValues parse values of mysql set column type, e.g. set('read','write'), return nil if it's not set
+func ParseSetValues(columnType string) []string {
+	matches := setTypeRegexp.FindStringSubmatch(columnType)
+	if matches == nil {
+		return nil
+	}
+	return parseQuotedList(matches[1])
+}
+
+// ParseCommentEnum parse values and their labels declared in column comment, e.g. status: 1-active 2-disabled,
+// return nil if comment declares less than 2 values
+func ParseCommentEnum(comment string) (values, labels []string) {
+	seen := make(map[string]bool)
+	for _, matches := range commentEnumRegexp.FindAllStringSubmatch(comment, -1) {
+		if seen[matches[1]] {
+			continue
+		}
+		seen[matches[1]] = true
+		values = append(values, matches[1])
+		labels = append(labels, matches[2])
+	}
+	if len(values) < 2 {
+		return nil, nil
+	}
+	return values, labels
+}
+
+// parseQuotedList parse list of single quoted values separated by comma, quote in value is escaped by doubling it
+func parseQuotedList(list string) (values []string) {
+	var (
+		b      strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(list); i++ {
+		switch c := list[i]; {
+		case c == '\'' && quoted && i+1 < len(list) && list[i+1] == '\'': // escaped quote
+			b.WriteByte(c)
+			i++
+		case c == '\\' && quoted && i+1 < len(list):
+			b.WriteByte(list[i+1])
+			i++
+		case c == '\'':
+			if quoted {
+				values = append(values, b.String())
+				b.Reset()
+			}
+			quoted = !quoted
+		case quoted:
+			b.WriteByte(c)
+		}
+	}
+	return values
+}
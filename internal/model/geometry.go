@@ -0,0 +1,69 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// geometryTypes column type of mysql spatial column or subtype of postgis column -> orb type
+var geometryTypes = map[string]string{
+	"point":              "orb.Point",
+	"linestring":         "orb.LineString",
+	"polygon":            "orb.Polygon",
+	"multipoint":         "orb.MultiPoint",
+	"multilinestring":    "orb.MultiLineString",
+	"multipolygon":       "orb.MultiPolygon",
+	"geometrycollection": "orb.Collection",
+	"geomcollection":     "orb.Collection",
+	"geometry":           "orb.Geometry",
+}
+
+// postGISTypeRegexp matches postgis column type with type modifier, e.g. geometry(Point,4326), geography(POINTZ)
+var postGISTypeRegexp = regexp.MustCompile(`(?i)^(?:geometry|geography)\((\w+?)z?m?(?:,\s*(\d+))?\)$`)
+
+// Geometry named type of orb geometry generated for spatial column, scanned from and stored as (e)wkb
+type Geometry struct {
+	Name       string // type name, e.g. ShopLocation
+	ColumnName string
+	Type       string // orb type, e.g. orb.Point, orb.Geometry
+	PostGIS    bool   // postgis column stores ewkb, mysql column stores wkb prefixed with 4 bytes srid
+	SRID       int    // srid declared in column type, used when value's SRID is 0
+}
+
+// Field name of embedded orb type in named type, e.g. Point
+func (g *Geometry) Field() string { return strings.TrimPrefix(g.Type, "orb.") }
+
+// Interface whether orb type is orb.Geometry interface, whose concrete type is decided when scanning
+func (g *Geometry) Interface() bool { return g.Type == "orb.Geometry" }
+
+// GeometryKind orb type of spatial column: mysql point/linestring/polygon/geometry etc. and postgis geometry/geography,
+// PostGIS is unknown for geometry column without type modifier. ok is false if it's not spatial column
+func (c *Column) GeometryKind() (geometry *Geometry, ok bool) {
+	if _, ok := clickHouseDataTypes[c.DatabaseTypeName()]; ok { // clickhouse Point, Polygon, etc. are scanned as orb types
+		return nil, false
+	}
+	switch typ := strings.ToLower(c.DatabaseTypeName()); typ {
+	case "geometry", "geography":
+		// geometry column without type modifier can be mysql column, see Geometry.PostGIS
+		geometry = &Geometry{Type: geometryTypes["geometry"], PostGIS: typ == "geography"}
+		if typ == "geography" {
+			geometry.SRID = 4326
+		}
+		if m := postGISTypeRegexp.FindStringSubmatch(c.columnType()); m != nil {
+			if t, ok := geometryTypes[strings.ToLower(m[1])]; ok {
+				geometry.Type = t
+			}
+			geometry.PostGIS = true
+			if m[2] != "" {
+				geometry.SRID, _ = strconv.Atoi(m[2])
+			}
+		}
+		return geometry, true
+	default:
+		if t, ok := geometryTypes[typ]; ok {
+			return &Geometry{Type: t}, true
+		}
+		return nil, false
+	}
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// oracleDataTypes oracle data type -> go type, NUMBER is decided by precision and scale, see oracleGoType
+var oracleDataTypes = map[string]string{
+	"FLOAT":         "float64",
+	"BINARY_FLOAT":  "float32",
+	"BINARY_DOUBLE": "float64",
+	"CHAR":          "string",
+	"NCHAR":         "string",
+	"VARCHAR2":      "string",
+	"NVARCHAR2":     "string",
+	"CLOB":          "string",
+	"NCLOB":         "string",
+	"LONG":          "string",
+	"ROWID":         "string",
+	"UROWID":        "string",
+	"RAW":           "[]byte",
+	"LONG RAW":      "[]byte",
+	"BLOB":          "[]byte",
+	"DATE":          "time.Time",
+	"TIMESTAMP":     "time.Time",
+}
+
+// oracleNumberType matches precision and scale of oracle NUMBER column type, e.g. NUMBER(10), NUMBER(10,2), NUMBER(*,0)
+var oracleNumberType = regexp.MustCompile(`(?i)^NUMBER\(\s*(\*|\d+)\s*(?:,\s*(-?\d+)\s*)?\)$`)
+
+// oracleGoType go type of oracle data type, e.g. NUMBER(9) -> int32, NUMBER(10,2) -> float64, TIMESTAMP(6) WITH TIME ZONE -> time.Time.
+// NUMBER without precision and scale is float64, ok is false if typ is not oracle data type
+func (c *Column) oracleGoType() (goType string, ok bool) {
+	typ := strings.ToUpper(strings.TrimSpace(c.DatabaseTypeName()))
+	if typ == "NUMBER" {
+		m := oracleNumberType.FindStringSubmatch(strings.TrimSpace(c.columnType()))
+		if m == nil || (m[2] != "" && m[2] != "0") { // NUMBER, NUMBER(p,s)
+			return "float64", true
+		}
+		if precision, err := strconv.Atoi(m[1]); err == nil && precision <= 9 {
+			return "int32", true
+		}
+		return "int64", true // NUMBER(p) of p > 18 overflows int64, which should be mapped by IntegerTypeMap
+	}
+	if strings.HasPrefix(typ, "INTERVAL") {
+		return "string", true
+	}
+	if i := strings.IndexByte(typ, '('); i >= 0 { // TIMESTAMP(6) WITH LOCAL TIME ZONE
+		typ = typ[:i]
+	}
+	goType, ok = oracleDataTypes[typ]
+	return goType, ok
+}
+
+// oracleSequenceDefault matches default value of column filled by sequence, e.g. "APP"."USERS_SEQ"."NEXTVAL",
+// "ISEQ$$_73135".nextval of identity column
+var oracleSequenceDefault = regexp.MustCompile(`(?i)\.\s*nextval\s*$`)
+
+// IsSequenceDefault whether default value of column is next value of oracle sequence
+func IsSequenceDefault(value string) bool {
+	return oracleSequenceDefault.MatchString(strings.TrimSpace(value))
+}
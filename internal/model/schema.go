@@ -0,0 +1,263 @@
+package model
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema introspected metadata of tables, exported as JSON and used to generate without db
+type Schema struct {
+	Dialect string         `json:"dialect"`
+	Tables  []*TableSchema `json:"tables"`
+}
+
+// Table return metadata of table, return nil if absent
+func (s *Schema) Table(tableName string) *TableSchema {
+	if s == nil {
+		return nil
+	}
+	for _, t := range s.Tables {
+		if t.Name == tableName {
+			return t
+		}
+	}
+	return nil
+}
+
+// TableNames return names of all tables
+func (s *Schema) TableNames() []string {
+	names := make([]string, len(s.Tables))
+	for i, t := range s.Tables {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// TableSchema metadata of table
+type TableSchema struct {
+	Name        string              `json:"name"`
+	Columns     []*ColumnSchema     `json:"columns"`
+	Indexes     []*IndexSchema      `json:"indexes,omitempty"`
+	ForeignKeys []*ForeignKeySchema `json:"foreignKeys,omitempty"`
+}
+
+// ColumnSchema metadata of column, optional properties are nil when db doesn't report them
+type ColumnSchema struct {
+	Name          string  `json:"name"`
+	DatabaseType  string  `json:"databaseType"`
+	ColumnType    string  `json:"columnType,omitempty"`
+	GoType        string  `json:"goType,omitempty"` // mapped go type, for reading only
+	ScanType      string  `json:"scanType,omitempty"`
+	UseScanType   bool    `json:"useScanType,omitempty"`
+	PrimaryKey    *bool   `json:"primaryKey,omitempty"`
+	AutoIncrement *bool   `json:"autoIncrement,omitempty"`
+	Nullable      *bool   `json:"nullable,omitempty"`
+	Unique        *bool   `json:"unique,omitempty"`
+	Length        *int64  `json:"length,omitempty"`
+	Precision     *int64  `json:"precision,omitempty"`
+	Scale         *int64  `json:"scale,omitempty"`
+	Default       *string `json:"default,omitempty"`
+	Comment       *string `json:"comment,omitempty"`
+
+	EnumValues []string `json:"enumValues,omitempty"`
+	Generated  bool     `json:"generated,omitempty"`
+	Strict     bool     `json:"strict,omitempty"` // column of sqlite STRICT table
+
+	Checks     []*CheckConstraint `json:"checks,omitempty"`
+	ForeignKey *ForeignKey        `json:"foreignKey,omitempty"`
+}
+
+// IndexSchema metadata of index
+type IndexSchema struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	PrimaryKey bool     `json:"primaryKey,omitempty"`
+	Unique     bool     `json:"unique,omitempty"`
+}
+
+// ForeignKeySchema foreign key detected by column name
+type ForeignKeySchema struct {
+	Column    string `json:"column"`
+	RefTable  string `json:"refTable"`
+	RefColumn string `json:"refColumn"`
+}
+
+// NewTableSchema build metadata of table from its columns
+func NewTableSchema(tableName string, columns []*Column) *TableSchema {
+	t := &TableSchema{Name: tableName}
+	indexes := make(map[string]*IndexSchema)
+	for _, c := range columns {
+		col := &ColumnSchema{
+			Name:         c.Name(),
+			DatabaseType: c.DatabaseTypeName(),
+			GoType:       c.GetDataType(),
+			UseScanType:  c.UseScanType,
+			EnumValues:   c.EnumValues,
+			Generated:    c.Generated,
+			Strict:       c.Strict,
+			Checks:       c.Checks,
+			ForeignKey:   c.ForeignKey,
+		}
+		if v, ok := c.ColumnType.ColumnType(); ok {
+			col.ColumnType = v
+		}
+		if typ := c.ScanType(); typ != nil {
+			col.ScanType = typ.String()
+		}
+		if v, ok := c.PrimaryKey(); ok {
+			col.PrimaryKey = &v
+		}
+		if v, ok := c.AutoIncrement(); ok {
+			col.AutoIncrement = &v
+		}
+		if v, ok := c.Nullable(); ok {
+			col.Nullable = &v
+		}
+		if v, ok := c.Unique(); ok {
+			col.Unique = &v
+		}
+		if v, ok := c.Length(); ok {
+			col.Length = &v
+		}
+		if p, s, ok := c.DecimalSize(); ok {
+			col.Precision, col.Scale = &p, &s
+		}
+		if v, ok := c.DefaultValue(); ok {
+			col.Default = &v
+		}
+		if v, ok := c.Comment(); ok {
+			col.Comment = &v
+		}
+		t.Columns = append(t.Columns, col)
+
+		for _, idx := range c.Indexes {
+			if idx == nil || indexes[idx.Name()] != nil {
+				continue
+			}
+			pk, _ := idx.PrimaryKey()
+			unique, _ := idx.Unique()
+			indexes[idx.Name()] = &IndexSchema{Name: idx.Name(), Columns: idx.Columns(), PrimaryKey: pk, Unique: unique}
+			t.Indexes = append(t.Indexes, indexes[idx.Name()])
+		}
+	}
+	return t
+}
+
+// GetColumns convert metadata into columns of table, with their indexes if withIndex is true,
+// check constraints if withConstraint is true and foreign keys if withConstraint or withForeignKey is true
+func (t *TableSchema) GetColumns(withIndex, withConstraint, withForeignKey bool) []*Column {
+	var indexes []gorm.Index
+	if withIndex {
+		for _, idx := range t.Indexes {
+			indexes = append(indexes, schemaIndex{table: t.Name, index: idx})
+		}
+	}
+	im := GroupByColumn(indexes)
+
+	columns := make([]*Column, len(t.Columns))
+	for i, c := range t.Columns {
+		columns[i] = &Column{
+			ColumnType:  schemaColumnType{c},
+			TableName:   t.Name,
+			UseScanType: c.UseScanType,
+			Indexes:     im[c.Name],
+			EnumValues:  c.EnumValues,
+			Generated:   c.Generated,
+			Strict:      c.Strict,
+		}
+		if withConstraint {
+			columns[i].Checks = c.Checks
+		}
+		if withConstraint || withForeignKey {
+			columns[i].ForeignKey = c.ForeignKey
+		}
+	}
+	return columns
+}
+
+// scanTypes type name -> scan type of column
+var scanTypes = make(map[string]reflect.Type)
+
+func init() {
+	for _, v := range []interface{}{
+		false, "", []byte(nil), time.Time{}, new(interface{}),
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), sql.RawBytes(nil),
+		sql.NullBool{}, sql.NullByte{}, sql.NullFloat64{}, sql.NullInt16{}, sql.NullInt32{}, sql.NullInt64{},
+		sql.NullString{}, sql.NullTime{},
+	} {
+		typ := reflect.TypeOf(v)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		scanTypes[typ.String()] = typ
+	}
+}
+
+// schemaColumnType gorm.ColumnType described by metadata
+type schemaColumnType struct{ c *ColumnSchema }
+
+func (t schemaColumnType) Name() string             { return t.c.Name }
+func (t schemaColumnType) DatabaseTypeName() string { return t.c.DatabaseType }
+func (t schemaColumnType) ColumnType() (string, bool) {
+	return t.c.ColumnType, t.c.ColumnType != ""
+}
+func (t schemaColumnType) PrimaryKey() (bool, bool)    { return boolValue(t.c.PrimaryKey) }
+func (t schemaColumnType) AutoIncrement() (bool, bool) { return boolValue(t.c.AutoIncrement) }
+func (t schemaColumnType) Nullable() (bool, bool)      { return boolValue(t.c.Nullable) }
+func (t schemaColumnType) Unique() (bool, bool)        { return boolValue(t.c.Unique) }
+func (t schemaColumnType) Length() (int64, bool) {
+	if t.c.Length == nil {
+		return 0, false
+	}
+	return *t.c.Length, true
+}
+func (t schemaColumnType) DecimalSize() (int64, int64, bool) {
+	if t.c.Precision == nil || t.c.Scale == nil {
+		return 0, 0, false
+	}
+	return *t.c.Precision, *t.c.Scale, true
+}
+func (t schemaColumnType) ScanType() reflect.Type {
+	if typ, ok := scanTypes[t.c.ScanType]; ok {
+		return typ
+	}
+	return scanTypes["interface {}"]
+}
+func (t schemaColumnType) ScanTypeName() string { return t.c.ScanType }
+func (t schemaColumnType) Comment() (string, bool) {
+	if t.c.Comment == nil {
+		return "", false
+	}
+	return *t.c.Comment, true
+}
+func (t schemaColumnType) DefaultValue() (string, bool) {
+	if t.c.Default == nil {
+		return "", false
+	}
+	return *t.c.Default, true
+}
+
+func boolValue(v *bool) (bool, bool) {
+	if v == nil {
+		return false, false
+	}
+	return *v, true
+}
+
+// schemaIndex gorm.Index described by metadata
+type schemaIndex struct {
+	table string
+	index *IndexSchema
+}
+
+func (i schemaIndex) Table() string            { return i.table }
+func (i schemaIndex) Name() string             { return i.index.Name }
+func (i schemaIndex) Columns() []string        { return i.index.Columns }
+func (i schemaIndex) PrimaryKey() (bool, bool) { return i.index.PrimaryKey, true }
+func (i schemaIndex) Unique() (bool, bool)     { return i.index.Unique, true }
+func (i schemaIndex) Option() string           { return "" }
@@ -0,0 +1,54 @@
+package model
+
+import "strings"
+
+// sqliteStrictTypes type of column in sqlite STRICT table -> go type, integers of sqlite are 64-bit
+var sqliteStrictTypes = map[string]string{
+	"INT":     "int64",
+	"INTEGER": "int64",
+	"REAL":    "float64",
+	"TEXT":    "string",
+	"BLOB":    "[]byte",
+	"ANY":     "interface{}",
+}
+
+// IsRowIDAlias whether column is alias of sqlite rowid, which is INTEGER PRIMARY KEY of rowid table,
+// e.g. id INTEGER PRIMARY KEY, but not id INT PRIMARY KEY
+func (c *Column) IsRowIDAlias() bool {
+	if c.Dialect != "sqlite" || !strings.EqualFold(strings.TrimSpace(c.DatabaseTypeName()), "INTEGER") {
+		return false
+	}
+	pk, _ := c.PrimaryKey()
+	autoIncrement, _ := c.AutoIncrement()
+	return pk && autoIncrement
+}
+
+// sqliteGoType go type of rowid alias and columns of STRICT table, ok is false for other columns,
+// which are mapped by data type
+func (c *Column) sqliteGoType() (goType string, ok bool) {
+	if c.IsRowIDAlias() {
+		return "int64", true
+	}
+	if c.Strict {
+		goType, ok = sqliteStrictTypes[strings.ToUpper(strings.TrimSpace(c.DatabaseTypeName()))]
+	}
+	return goType, ok
+}
+
+// sqliteAffinityType go type by type affinity of sqlite for declared types not in data type map, e.g. INT8,
+// UNSIGNED BIG INT, NVARCHAR(20), DOUBLE PRECISION. ok is false for NUMERIC affinity, whose values are often text
+// like UUID, and for columns without type, see https://www.sqlite.org/datatype3.html
+func sqliteAffinityType(typ string) (goType string, ok bool) {
+	typ = strings.ToUpper(strings.TrimSpace(typ))
+	switch {
+	case strings.Contains(typ, "INT"):
+		return "int64", true
+	case strings.Contains(typ, "CHAR"), strings.Contains(typ, "CLOB"), strings.Contains(typ, "TEXT"):
+		return "string", true
+	case strings.Contains(typ, "BLOB"):
+		return "[]byte", true
+	case strings.Contains(typ, "REAL"), strings.Contains(typ, "FLOA"), strings.Contains(typ, "DOUB"):
+		return "float64", true
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package model
+
+import "strings"
+
+// sqlServerDataTypes sql server data type -> go type scanned by go-mssqldb, integers are scanned as int64,
+// timestamp is rowversion rather than date and time
+var sqlServerDataTypes = map[string]string{
+	"bit":              "bool",
+	"tinyint":          "int64",
+	"smallint":         "int64",
+	"int":              "int64",
+	"bigint":           "int64",
+	"real":             "float64",
+	"float":            "float64",
+	"decimal":          "[]byte",
+	"numeric":          "[]byte",
+	"money":            "[]byte",
+	"smallmoney":       "[]byte",
+	"char":             "string",
+	"varchar":          "string",
+	"nchar":            "string",
+	"nvarchar":         "string",
+	"text":             "string",
+	"ntext":            "string",
+	"xml":              "string",
+	"sysname":          "string",
+	"binary":           "[]byte",
+	"varbinary":        "[]byte",
+	"image":            "[]byte",
+	"timestamp":        "[]byte",
+	"rowversion":       "[]byte",
+	"date":             "time.Time",
+	"time":             "time.Time",
+	"datetime":         "time.Time",
+	"datetime2":        "time.Time",
+	"smalldatetime":    "time.Time",
+	"datetimeoffset":   "time.Time",
+	"uniqueidentifier": "mssql.UniqueIdentifier", // go-mssqldb reads it in byte order of sql server, which is converted by UniqueIdentifier
+	"sql_variant":      "interface{}",
+}
+
+// sqlServerGoType go type of sql server data type, ok is false if typ is not sql server data type
+func (c *Column) sqlServerGoType() (goType string, ok bool) {
+	goType, ok = sqlServerDataTypes[strings.ToLower(strings.TrimSpace(c.DatabaseTypeName()))]
+	return goType, ok
+}
+
+// IsRowVersion whether column is sql server rowversion, which is set by database on every write
+func (c *Column) IsRowVersion() bool {
+	typ := strings.ToLower(c.DatabaseTypeName())
+	return c.Dialect == "sqlserver" && (typ == "timestamp" || typ == "rowversion")
+}
@@ -3,6 +3,8 @@ package model
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"gorm.io/gen/field"
@@ -13,8 +15,16 @@ import (
 type Column struct {
 	gorm.ColumnType
 	TableName   string                                                        `gorm:"column:TABLE_NAME"`
+	Dialect     string                                                        `gorm:"-"` // name of dialector the column is introspected by
 	Indexes     []*Index                                                      `gorm:"-"`
 	UseScanType bool                                                          `gorm:"-"`
+	EnumValues  []string                                                      `gorm:"-"` // values of enum type which are not in column type, e.g. postgres enum
+	Generated   bool                                                          `gorm:"-"` // generated/virtual column, which is read-only
+	Strict      bool                                                          `gorm:"-"` // column of sqlite STRICT table
+	Checks      []*CheckConstraint                                            `gorm:"-"`
+	ForeignKey  *ForeignKey                                                   `gorm:"-"`
+	arrayTypes  map[string]string                                             `gorm:"-"`
+	intTypes    map[string]string                                             `gorm:"-"`
 	dataTypeMap map[string]func(columnType gorm.ColumnType) (dataType string) `gorm:"-"`
 	jsonTagNS   func(columnName string) string                                `gorm:"-"`
 }
@@ -24,15 +34,167 @@ func (c *Column) SetDataTypeMap(m map[string]func(columnType gorm.ColumnType) (d
 	c.dataTypeMap = m
 }
 
+// SetArrayTypeMap set go type of array column by element data type, merged into default mapping
+func (c *Column) SetArrayTypeMap(m map[string]string) {
+	c.arrayTypes = m
+}
+
+// SetIntegerTypeMap set go type of integer column by data type or column type, overriding default mapping
+func (c *Column) SetIntegerTypeMap(m map[string]string) {
+	c.intTypes = m
+}
+
 // GetDataType get data type
 func (c *Column) GetDataType() (fieldtype string) {
+	fieldtype, _ = c.dataType()
+	return fieldtype
+}
+
+// UnknownType whether data type of column is not mapped, so its go type falls back to string
+func (c *Column) UnknownType() bool {
+	_, known := c.dataType()
+	return !known
+}
+
+func (c *Column) dataType() (fieldtype string, known bool) {
 	if mapping, ok := c.dataTypeMap[c.DatabaseTypeName()]; ok {
-		return mapping(c.ColumnType)
+		return mapping(c.ColumnType), true
+	}
+	if typ, ok := c.integerType(); ok {
+		return typ, true
+	}
+	if elem, ok := c.ArrayElemType(); ok {
+		typ, ok := c.arrayTypes[elem]
+		if !ok {
+			typ = arrayDataType[elem]
+		}
+		if typ != "" {
+			return typ, true
+		}
+	}
+	if typ, ok := clickHouseGoType(c.DatabaseTypeName()); ok {
+		return typ, true
+	}
+	if c.Dialect == "oracle" {
+		if typ, ok := c.oracleGoType(); ok {
+			return typ, true
+		}
+	}
+	if c.Dialect == "sqlite" {
+		if typ, ok := c.sqliteGoType(); ok {
+			return typ, true
+		}
+	}
+	if c.Dialect == "sqlserver" && !c.UseScanType { // columns read from sys.columns have no scan type
+		if typ, ok := c.sqlServerGoType(); ok {
+			return typ, true
+		}
 	}
 	if c.UseScanType && c.ScanType() != nil {
-		return c.ScanType().String()
+		if named, ok := c.ColumnType.(interface{ ScanTypeName() string }); ok { // scan type recorded in schema
+			return named.ScanTypeName(), true
+		}
+		return c.ScanType().String(), true
+	}
+	typ, known := dataType.lookup(c.DatabaseTypeName(), c.columnType())
+	if !known && c.Dialect == "sqlite" {
+		if affinity, ok := sqliteAffinityType(c.DatabaseTypeName()); ok {
+			return affinity, true
+		}
+	}
+	return typ, known
+}
+
+// integerType go type of integer column in integer type map, column type without unsigned/zerofill(e.g. tinyint(1))
+// is matched before data type(e.g. tinyint)
+func (c *Column) integerType() (typ string, ok bool) {
+	if len(c.intTypes) == 0 {
+		return "", false
+	}
+	columnType := strings.NewReplacer("unsigned", "", "zerofill", "").Replace(strings.ToLower(c.columnType()))
+	if typ, ok = c.intTypes[strings.TrimSpace(columnType)]; ok {
+		return typ, true
+	}
+	typ, ok = c.intTypes[strings.ToLower(c.DatabaseTypeName())]
+	return typ, ok
+}
+
+// isUnsigned whether column is unsigned integer column, zerofill column is unsigned too
+func (c *Column) isUnsigned() bool {
+	columnType := strings.ToLower(c.columnType())
+	return strings.Contains(columnType, "unsigned") || strings.Contains(columnType, "zerofill")
+}
+
+// ArrayElemType get element data type of postgres array column, e.g. character varying(64)[] -> character varying,
+// ok is false if it's not array
+func (c *Column) ArrayElemType() (elem string, ok bool) {
+	typ := strings.ToLower(strings.TrimSpace(c.DatabaseTypeName()))
+	switch {
+	case strings.HasSuffix(typ, "[]"):
+		typ = strings.TrimSuffix(typ, "[]")
+	case strings.HasPrefix(typ, "_"): // internal name of array type, e.g. _int4
+		typ = strings.TrimPrefix(typ, "_")
+	default:
+		return "", false
 	}
-	return dataType.Get(c.DatabaseTypeName(), c.columnType())
+	if i := strings.IndexByte(typ, '('); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.TrimSpace(typ), true
+}
+
+// IsJSON whether column is json or jsonb column
+func (c *Column) IsJSON() bool {
+	switch strings.ToLower(c.DatabaseTypeName()) {
+	case "json", "jsonb":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDecimal whether column is decimal or numeric column, or oracle NUMBER column with scale
+func (c *Column) IsDecimal() bool {
+	switch strings.ToLower(c.DatabaseTypeName()) {
+	case "decimal", "numeric":
+		return true
+	case "number":
+		typ, _ := c.oracleGoType()
+		return c.Dialect == "oracle" && typ == "float64"
+	default:
+		return false
+	}
+}
+
+// TimeKind kind of date/time column: date, time or datetime(datetime and timestamp), "" if it's not date/time column
+func (c *Column) TimeKind() string {
+	switch typ := strings.ToLower(c.DatabaseTypeName()); {
+	case typ == "date" && c.Dialect == "oracle": // oracle DATE has time part
+		return "datetime"
+	case c.IsRowVersion():
+		return ""
+	case typ == "date":
+		return "date"
+	case typ == "time" || typ == "timetz" || strings.HasPrefix(typ, "time "):
+		return "time"
+	case strings.HasPrefix(typ, "datetime") || strings.HasPrefix(typ, "timestamp") || typ == "smalldatetime":
+		return "datetime"
+	default:
+		return ""
+	}
+}
+
+// GetEnumValues get values of enum column, return nil if it's not enum
+func (c *Column) GetEnumValues() []string {
+	if len(c.EnumValues) > 0 {
+		return c.EnumValues
+	}
+	return ParseEnumValues(c.columnType())
+}
+
+// GetSetValues get values of mysql set column, return nil if it's not set
+func (c *Column) GetSetValues() []string {
+	return ParseSetValues(c.columnType())
 }
 
 // WithNS with name strategy
@@ -46,7 +208,7 @@ func (c *Column) WithNS(jsonTagNS func(columnName string) string) {
 // ToField convert to field
 func (c *Column) ToField(nullable, coverable, signable bool) *Field {
 	fieldType := c.GetDataType()
-	if signable && strings.Contains(c.columnType(), "unsigned") && strings.HasPrefix(fieldType, "int") {
+	if signable && c.isUnsigned() && strings.HasPrefix(fieldType, "int") {
 		fieldType = "u" + fieldType
 	}
 	switch {
@@ -114,6 +276,12 @@ func (c *Column) buildGormTag() field.GormTag {
 	if dtValue := c.defaultTagValue(); c.needDefaultTag(dtValue) { // cannot set default tag for primary key
 		tag.Set(field.TagKeyGormDefault, dtValue)
 	}
+	if c.Generated {
+		tag.Set(field.TagKeyGormReadOnly)
+	}
+	if value, ok := CheckTagValue(c.Checks); ok {
+		tag.Set(field.TagKeyGormCheck, value)
+	}
 	if comment, ok := c.Comment(); ok && comment != "" {
 		if c.multilineComment() {
 			comment = strings.ReplaceAll(comment, "\n", "\\n")
@@ -128,25 +296,56 @@ func (c *Column) needDefaultTag(defaultTagValue string) bool {
 	if defaultTagValue == "" {
 		return false
 	}
-	switch c.ScanType().Kind() {
+	switch c.valueKind() {
 	case reflect.Bool:
-		return defaultTagValue != "false"
+		return defaultTagValue != "false" && defaultTagValue != "0"
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
-		return defaultTagValue != "0"
+		f, err := strconv.ParseFloat(defaultTagValue, 64)
+		return err != nil || f != 0
 	case reflect.String:
-		return defaultTagValue != ""
+		return defaultTagValue != "''"
 	case reflect.Struct:
 		return strings.Trim(defaultTagValue, "'0:- ") != ""
 	}
 	return c.Name() != "created_at" && c.Name() != "updated_at"
 }
 
+// valueKind kind of column value, by scan type or go type of columns without scan type(e.g. read from data dictionary
+// or schema json), value of sql.NullXXX scan type is checked
+func (c *Column) valueKind() reflect.Kind {
+	typ := c.ScanType()
+	if typ == nil || typ.Kind() == reflect.Interface {
+		if typ = scanTypes[c.GetDataType()]; typ == nil {
+			return reflect.Invalid
+		}
+	}
+	if typ.Kind() == reflect.Struct && typ.PkgPath() == "database/sql" && strings.HasPrefix(typ.Name(), "Null") {
+		return typ.Field(0).Type.Kind()
+	}
+	return typ.Kind()
+}
+
+// pgCastRegexp matches type cast at the end of postgres default value, e.g. ::character varying(16), ::text[]
+var pgCastRegexp = regexp.MustCompile(`::[\w" .]+(\(\d+(,\s*\d+)?\))?(\[\])*$`)
+
 // defaultTagValue return gorm default tag's value
 func (c *Column) defaultTagValue() string {
 	value, ok := c.DefaultValue()
 	if !ok {
 		return ""
 	}
+	if strings.HasPrefix(value, "nextval(") || IsSequenceDefault(value) { // postgres serial or oracle sequence column, which is auto increment
+		return ""
+	}
+	for v := pgCastRegexp.ReplaceAllString(value, ""); v != value; v = pgCastRegexp.ReplaceAllString(value, "") {
+		value = v // remove postgres type cast, e.g. 'pending'::character varying, '{}'::jsonb
+	}
+	// numeric default is unquoted, otherwise gorm fails to parse it, e.g. '-1'::integer, (0)::numeric
+	if number := strings.Trim(value, "'()"); number != "" {
+		if _, err := strconv.ParseFloat(number, 64); err == nil {
+			return number
+		}
+	}
 	if value != "" && strings.TrimSpace(value) == "" {
 		return "'" + value + "'"
 	}
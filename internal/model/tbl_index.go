@@ -1,6 +1,10 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"sort"
+
+	"gorm.io/gorm"
+)
 
 // Index table index info
 type Index struct {
@@ -8,7 +12,7 @@ type Index struct {
 	Priority int32 `gorm:"column:SEQ_IN_INDEX"`
 }
 
-// GroupByColumn group columns
+// GroupByColumn group columns, indexes of each column are sorted by name
 func GroupByColumn(indexList []gorm.Index) map[string][]*Index {
 	columnIndexMap := make(map[string][]*Index, len(indexList))
 	if len(indexList) == 0 {
@@ -26,5 +30,8 @@ func GroupByColumn(indexList []gorm.Index) map[string][]*Index {
 			})
 		}
 	}
+	for _, indexes := range columnIndexMap {
+		sort.SliceStable(indexes, func(i, j int) bool { return indexes[i].Name() < indexes[j].Name() })
+	}
 	return columnIndexMap
 }
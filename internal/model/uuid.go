@@ -0,0 +1,27 @@
+package model
+
+import "strings"
+
+// UUIDType go type of uuid column
+const UUIDType = "uuid.UUID"
+
+// BinaryUUID named type of uuid.UUID generated for binary(16) column, which is stored as 16 bytes instead of string
+type BinaryUUID struct {
+	Name       string // type name, e.g. UserID
+	ColumnName string
+}
+
+// UUIDKind whether column stores uuid: postgres uuid column, or column whose type is one of columnTypes,
+// e.g. char(36), binary(16). binary is true if uuid is stored as 16 bytes
+func (c *Column) UUIDKind(columnTypes []string) (binary, ok bool) {
+	if strings.EqualFold(c.DatabaseTypeName(), "uuid") {
+		return false, true
+	}
+	typ := strings.ToLower(strings.ReplaceAll(c.columnType(), " ", ""))
+	for _, t := range columnTypes {
+		if typ == strings.ToLower(strings.ReplaceAll(t, " ", "")) {
+			return strings.HasPrefix(typ, "binary") || strings.HasPrefix(typ, "varbinary"), true
+		}
+	}
+	return false, false
+}
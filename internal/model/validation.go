@@ -0,0 +1,91 @@
+package model
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Validation constraints of column checked by generated Validate method of model
+type Validation struct {
+	Required  bool          // NOT NULL column without default value, zero value of non-pointer field is invalid
+	MaxLength int64         // max length in characters of char/varchar column
+	Range     *IntegerRange // value range of tinyint/smallint/mediumint or unsigned integer column
+}
+
+// IntegerRange value range of integer column
+type IntegerRange struct {
+	Min int64
+	Max uint64
+}
+
+// integerRanges signed and unsigned value ranges of integer data types
+var integerRanges = map[string][2]IntegerRange{
+	"tinyint":   {{math.MinInt8, math.MaxInt8}, {0, math.MaxUint8}},
+	"smallint":  {{math.MinInt16, math.MaxInt16}, {0, math.MaxUint16}},
+	"mediumint": {{-1 << 23, 1<<23 - 1}, {0, 1<<24 - 1}},
+	"int":       {{math.MinInt32, math.MaxInt32}, {0, math.MaxUint32}},
+	"integer":   {{math.MinInt32, math.MaxInt32}, {0, math.MaxUint32}},
+	"bigint":    {{math.MinInt64, math.MaxInt64}, {0, math.MaxUint64}},
+}
+
+// goIntegerRanges value ranges of go integer types, int and uint are 64-bit
+var goIntegerRanges = map[string]IntegerRange{
+	"int8":   {math.MinInt8, math.MaxInt8},
+	"int16":  {math.MinInt16, math.MaxInt16},
+	"int32":  {math.MinInt32, math.MaxInt32},
+	"int64":  {math.MinInt64, math.MaxInt64},
+	"int":    {math.MinInt64, math.MaxInt64},
+	"uint8":  {0, math.MaxUint8},
+	"uint16": {0, math.MaxUint16},
+	"uint32": {0, math.MaxUint32},
+	"uint64": {0, math.MaxUint64},
+	"uint":   {0, math.MaxUint64},
+}
+
+// Bounds literals of min and max of r which are not covered by go integer type goType,
+// ok is false if goType is not integer type
+func (r *IntegerRange) Bounds(goType string) (min, max string, ok bool) {
+	typ, ok := goIntegerRanges[goType]
+	if !ok {
+		return "", "", false
+	}
+	if r.Min > typ.Min {
+		min = strconv.FormatInt(r.Min, 10)
+	}
+	if r.Max < typ.Max {
+		max = strconv.FormatUint(r.Max, 10)
+	}
+	return min, max, true
+}
+
+// Validation constraints of column, required is reported only if requireNotNull is true,
+// primary key, auto increment and generated columns are never required
+func (c *Column) Validation(requireNotNull bool) *Validation {
+	v := &Validation{}
+	if requireNotNull && !c.Generated && c.Name() != "created_at" && c.Name() != "updated_at" {
+		n, ok := c.Nullable()
+		pk, _ := c.PrimaryKey()
+		autoIncrement, _ := c.AutoIncrement()
+		_, hasDefault := c.DefaultValue()
+		v.Required = ok && !n && !pk && !autoIncrement && !hasDefault
+	}
+	typ := strings.ToLower(c.DatabaseTypeName())
+	if strings.HasSuffix(typ, "char") || typ == "character varying" || typ == "character" {
+		if length, ok := c.Length(); ok && length > 0 && length < math.MaxInt32 { // -1 or max of varchar(max)
+			v.MaxLength = length
+		}
+	}
+	if ranges, ok := integerRanges[typ]; ok {
+		switch {
+		case c.isUnsigned():
+			v.Range = &ranges[1]
+		case typ == "tinyint" || typ == "smallint" || typ == "mediumint": // int and bigint are covered by go type
+			v.Range = &ranges[0]
+		}
+	}
+	if !v.Required && v.MaxLength == 0 && v.Range == nil {
+		return nil
+	}
+	return v
+}
@@ -68,6 +68,23 @@ func GetInterfacePath(v interface{}) (paths []*InterfacePath, err error) {
 	return
 }
 
+// importMethodPkg import package of method type by directory(starting with . or /) or import path resolved from
+// working directory, so that packages of current module are found
+func importMethodPkg(ctx build.Context, path string) (*build.Package, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case filepath.IsAbs(path):
+		return ctx.ImportDir(path, build.ImportComment)
+	case strings.HasPrefix(path, "."):
+		return ctx.ImportDir(filepath.Join(wd, path), build.ImportComment)
+	default:
+		return ctx.Import(path, wd, build.ImportComment)
+	}
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -89,15 +106,23 @@ func GetModelMethod(v interface{}) (method *DIYMethods, err error) {
 	case reflect.Struct:
 		method.pkgPath = value.Type().PkgPath()
 		method.BaseStructType = value.Type().Name()
+	case reflect.String: // <import path or dir>.<Type>, e.g. github.com/acme/app/common.CommonMethod, ./common.CommonMethod
+		i := strings.LastIndex(value.String(), ".")
+		if i <= 0 || i == value.Len()-1 {
+			return nil, fmt.Errorf("method type %q must be in form of <import path or dir>.<Type>", value.String())
+		}
+		method.pkgPath, method.BaseStructType = value.String()[:i], value.String()[i+1:]
 	default:
-		return nil, fmt.Errorf("method param must be a function or struct")
+		return nil, fmt.Errorf("method param must be a function, struct or type name")
 	}
 
 	var p *build.Package
 
 	// if struct in main file
 	ctx := build.Default
-	if method.pkgPath == "main" {
+	if value.Kind() == reflect.String {
+		p, err = importMethodPkg(ctx, method.pkgPath)
+	} else if method.pkgPath == "main" {
 		var skip int
 		var file string
 		for {
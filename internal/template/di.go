@@ -0,0 +1,48 @@
+package template
+
+// DIWire wire provider set of query package
+const DIWire = NotEditMark + `
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	"gorm.io/gorm"
+)
+
+// ProviderSet provide *Query and DAO of each table, e.g. wire.Build({{.Package}}.ProviderSet)
+var ProviderSet = wire.NewSet(
+	NewQuery,
+	{{range .DAOs}}New{{.Name}}DAO,
+	{{end}}
+)
+` + diProviders
+
+// DIFx fx module of query package
+const DIFx = NotEditMark + `
+package {{.Package}}
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// Module provide *Query and DAO of each table, e.g. fx.New({{.Package}}.Module, ...)
+var Module = fx.Module("{{.Package}}", fx.Provide(
+	NewQuery,
+	{{range .DAOs}}New{{.Name}}DAO,
+	{{end}}
+))
+` + diProviders
+
+const diProviders = `
+// NewQuery provide *Query of db
+func NewQuery(db *gorm.DB) *Query { return Use(db) }
+{{range .DAOs}}
+// New{{.Name}}DAO provide DAO of {{if .Table}}table {{.Table}}{{else}}{{.Name}}{{end}}, bind context of each call by WithContext
+func New{{.Name}}DAO(q *Query) {{.Iface}} { return q.{{.Name}}.WithContext(context.Background()) }
+{{end}}
+`
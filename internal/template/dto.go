@@ -0,0 +1,58 @@
+package template
+
+// DTO API-facing struct of model with converters
+const DTO = NotEditMark + `
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	{{range .ImportPaths}}{{.}}` + "\n" + `{{end}}
+)
+
+// {{.Name}} API-facing struct of {{.Model}}{{if .TableName}} mapped from table <{{.TableName}}>{{end}}
+type {{.Name}} struct {
+	{{range .Fields}}
+	{{.Name}} {{.Type}} ` + "`{{.Tag}}`" + `{{end}}
+}
+
+// {{.Name}}ToDTO convert {{.Model}} into {{.Name}}, return nil if m is nil
+func {{.Name}}ToDTO(m *{{.Model}}) *{{.Name}} {
+	if m == nil {
+		return nil
+	}
+	return &{{.Name}}{
+		{{range .Fields}}{{.Name}}: m.{{.Name}},
+		{{end}}
+	}
+}
+
+// {{.Name}}FromDTO convert {{.Name}} into {{.Model}}, return nil if d is nil
+func {{.Name}}FromDTO(d *{{.Name}}) *{{.Model}} {
+	if d == nil {
+		return nil
+	}
+	{{if .Embedded}}m := &{{.Model}}{
+		{{range .Fields}}{{if not .Embedded}}{{.Name}}: d.{{.Name}},
+		{{end}}{{end}}
+	}
+	{{range .Fields}}{{if .Embedded}}m.{{.Name}} = d.{{.Name}}
+	{{end}}{{end}}return m{{else}}return &{{.Model}}{
+		{{range .Fields}}{{.Name}}: d.{{.Name}},
+		{{end}}
+	}{{end}}
+}
+
+// {{.Name}}ListToDTO convert list of {{.Model}} into list of {{.Name}}
+func {{.Name}}ListToDTO(ms []*{{.Model}}) []*{{.Name}} {
+	list := make([]*{{.Name}}, len(ms))
+	for i, m := range ms {
+		list[i] = {{.Name}}ToDTO(m)
+	}
+	return list
+}
+`
@@ -0,0 +1,96 @@
+package template
+
+// Proto protobuf definition of model, with optional CRUD service
+const Proto = `// Code generated by gorm.io/gen. DO NOT EDIT.
+
+syntax = "proto3";
+
+package {{.Package}};
+
+{{range .Imports}}import "{{.}}";
+{{end}}{{if .Imports}}
+{{end}}// {{.Message}} mapped from table <{{.TableName}}>
+message {{.Message}} {
+{{- range .Fields}}{{if .Comment}}
+  // {{.Comment}}{{end}}
+  {{.Type}} {{.Name}} = {{.Number}};
+{{- end}}
+}
+{{if .Service}}
+service {{.Message}}Service {
+  rpc List{{.Message}}(List{{.Message}}Request) returns (List{{.Message}}Response);
+  rpc Create{{.Message}}({{.Message}}) returns ({{.Message}});
+{{- with .PK}}
+  rpc Get{{$.Message}}(Get{{$.Message}}Request) returns ({{$.Message}});
+  rpc Update{{$.Message}}({{$.Message}}) returns ({{$.Message}});
+  rpc Delete{{$.Message}}(Delete{{$.Message}}Request) returns (google.protobuf.Empty);
+{{- end}}
+}
+
+message List{{.Message}}Request {
+  int32 page = 1;
+  int32 page_size = 2;
+}
+
+message List{{.Message}}Response {
+  repeated {{.Message}} items = 1;
+  int64 total = 2;
+}
+{{- with .PK}}
+
+message Get{{$.Message}}Request {
+  {{.Type}} {{.Name}} = 1;
+}
+
+message Delete{{$.Message}}Request {
+  {{.Type}} {{.Name}} = 1;
+}
+{{- end}}
+{{end -}}
+`
+
+// TypeScript typescript declaration of model
+const TypeScript = `// Code generated by gorm.io/gen. DO NOT EDIT.
+
+/** {{.Name}} mapped from table <{{.TableName}}> */
+export interface {{.Name}} {
+{{- range .Fields}}{{if .Comment}}
+  /** {{.Comment}} */{{end}}
+  {{.Name}}{{if .Optional}}?{{end}}: {{.Type}};
+{{- end}}
+}
+`
+
+// ERDMermaid mermaid entity-relationship diagram of models
+const ERDMermaid = `%% Code generated by gorm.io/gen. DO NOT EDIT.
+erDiagram
+{{- range .Entities}}
+    {{.Name}} {
+{{- range .Columns}}
+        {{.Type}} {{.Name}}{{if .Keys}} {{.Keys}}{{end}}{{if .Comment}} "{{.Comment}}"{{end}}
+{{- end}}
+    }
+{{- end}}
+{{- range .Relations}}
+    {{.Left}} {{.Cardinality}} {{.Right}} : "{{.Label}}"
+{{- end}}
+`
+
+// ERDGraphviz graphviz entity-relationship diagram of models
+const ERDGraphviz = `// Code generated by gorm.io/gen. DO NOT EDIT.
+digraph erd {
+  rankdir=LR;
+  node [shape=plaintext];
+{{- range .Entities}}
+  "{{.Name}}" [label=<<table border="0" cellborder="1" cellspacing="0">
+    <tr><td bgcolor="lightgrey" colspan="3"><b>{{.Name}}</b></td></tr>
+{{- range .Columns}}
+    <tr><td align="left">{{.Name}}</td><td align="left">{{.Type}}</td><td align="left">{{.Keys}}{{if .Comment}}{{if .Keys}} {{end}}{{.Comment}}{{end}}</td></tr>
+{{- end}}
+  </table>>];
+{{- end}}
+{{- range .Relations}}
+  "{{.Left}}" -> "{{.Right}}" [label="{{.Label}}"];
+{{- end}}
+}
+`
@@ -0,0 +1,185 @@
+package template
+
+// FakeDAO in-memory fake of table's DAO keyed by primary key, methods are named and typed as generated ones, so
+// interfaces declared by callers are satisfied by both
+const FakeDAO = `
+{{with .FakeKey}}
+// {{$.ModelStructName}}Fake in-memory fake of DAO of {{$.TableName}} keyed by primary key for unit tests without database,
+// records are copied in and out, so changes of returned records don't affect stored ones
+type {{$.ModelStructName}}Fake struct {
+	mu      sync.Mutex
+	records map[{{.Type}}]*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}
+	keys    []{{.Type}} // primary keys in insertion order
+	{{- if $.FakeAutoIncrement}}
+	lastKey {{.Type}} // max primary key, zero primary key of created record is auto incremented from it{{end}}
+}
+
+// New{{$.ModelStructName}}Fake create fake of {{$.TableName}} containing values
+func New{{$.ModelStructName}}Fake(values ...*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) *{{$.ModelStructName}}Fake {
+	f := &{{$.ModelStructName}}Fake{records: make(map[{{.Type}}]*{{$.StructInfo.Package}}.{{$.StructInfo.Type}})}
+	_ = f.Save(values...)
+	return f
+}
+
+// Create insert values, gorm.ErrDuplicatedKey is returned if primary key exists
+func (f *{{$.ModelStructName}}Fake) Create(values ...*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, value := range values {
+		{{- if $.FakeAutoIncrement}}
+		if value.{{.Name}} == 0 {
+			value.{{.Name}} = f.lastKey + 1
+		}{{end}}
+		if _, ok := f.records[value.{{.Name}}]; ok {
+			return gorm.ErrDuplicatedKey
+		}
+		f.put(value)
+	}
+	return nil
+}
+
+// Save insert or replace values by primary key
+func (f *{{$.ModelStructName}}Fake) Save(values ...*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, value := range values {
+		{{- if $.FakeAutoIncrement}}
+		if value.{{.Name}} == 0 {
+			value.{{.Name}} = f.lastKey + 1
+		}{{end}}
+		f.put(value)
+	}
+	return nil
+}
+
+func (f *{{$.ModelStructName}}Fake) put(value *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) {
+	if _, ok := f.records[value.{{.Name}}]; !ok {
+		f.keys = append(f.keys, value.{{.Name}})
+	}
+	{{- if $.FakeAutoIncrement}}
+	if value.{{.Name}} > f.lastKey {
+		f.lastKey = value.{{.Name}}
+	}{{end}}
+	record := *value
+	f.records[value.{{.Name}}] = &record
+}
+
+// GetBy{{.Name}} query record by primary key, gorm.ErrRecordNotFound is returned if it doesn't exist
+func (f *{{$.ModelStructName}}Fake) GetBy{{.Name}}(value {{.Type}}) (*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record, ok := f.records[value]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	result := *record
+	return &result, nil
+}
+
+// ExistsBy{{.Name}} whether record of primary key exists
+func (f *{{$.ModelStructName}}Fake) ExistsBy{{.Name}}(value {{.Type}}) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.records[value]
+	return ok, nil
+}
+
+// Find query all records in insertion order
+func (f *{{$.ModelStructName}}Fake) Find() ([]*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error) {
+	return f.FindWhere(nil)
+}
+
+// Count count all records
+func (f *{{$.ModelStructName}}Fake) Count() (int64, error) {
+	return f.CountWhere(nil)
+}
+
+// FindWhere query records in insertion order whose columns equal values of conds, e.g. {"name": "modi"},
+// values are compared by gen.FakeEqual
+func (f *{{$.ModelStructName}}Fake) FindWhere(conds map[string]interface{}) (results []*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range f.keys {
+		record := f.records[key]
+		if ok, err := f.match(record, conds); err != nil {
+			return nil, err
+		} else if ok {
+			result := *record
+			results = append(results, &result)
+		}
+	}
+	return results, nil
+}
+
+// FirstWhere query first record matching conds as FindWhere, gorm.ErrRecordNotFound is returned if no record matches
+func (f *{{$.ModelStructName}}Fake) FirstWhere(conds map[string]interface{}) (*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error) {
+	results, err := f.FindWhere(conds)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return results[0], nil
+}
+
+// CountWhere count records matching conds as FindWhere
+func (f *{{$.ModelStructName}}Fake) CountWhere(conds map[string]interface{}) (int64, error) {
+	results, err := f.FindWhere(conds)
+	return int64(len(results)), err
+}
+{{end}}
+{{range .IndexedFields}}{{if .GenValueType}}
+// CountBy{{.Name}} count records by indexed column {{.ColumnName}}
+func (f *{{$.ModelStructName}}Fake) CountBy{{.Name}}(value {{.GenValueType}}) (int64, error) {
+	return f.CountWhere(map[string]interface{}{"{{.ColumnName}}": value})
+}
+{{end}}{{end}}
+{{with .FakeKey}}
+// Delete delete records by primary keys of values
+func (f *{{$.ModelStructName}}Fake) Delete(values ...*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) (info gen.ResultInfo, err error) {
+	keys := make([]{{.Type}}, len(values))
+	for i, value := range values {
+		keys[i] = value.{{.Name}}
+	}
+	return f.DeleteBy{{.Name}}s(keys...)
+}
+
+// DeleteBy{{.Name}}s delete records by primary keys
+func (f *{{$.ModelStructName}}Fake) DeleteBy{{.Name}}s(values ...{{.Type}}) (info gen.ResultInfo, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, value := range values {
+		if _, ok := f.records[value]; !ok {
+			continue
+		}
+		delete(f.records, value)
+		for i, key := range f.keys {
+			if key == value {
+				f.keys = append(f.keys[:i], f.keys[i+1:]...)
+				break
+			}
+		}
+		info.RowsAffected++
+	}
+	return info, nil
+}
+{{end}}
+// match whether record matches conds of column -> value
+func (f *{{.ModelStructName}}Fake) match(record *{{.StructInfo.Package}}.{{.StructInfo.Type}}, conds map[string]interface{}) (bool, error) {
+	for column, want := range conds {
+		var value interface{}
+		switch column {
+		{{range .Fields}}{{if .ColumnName}}case "{{.ColumnName}}":
+			value = record.{{.Name}}
+		{{end}}{{end -}}
+		default:
+			return false, fmt.Errorf("unknown column %s of {{.TableName}}", column)
+		}
+		if !gen.FakeEqual(value, want) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+`
@@ -0,0 +1,294 @@
+package template
+
+// HandlerGin gin CRUD handlers of model
+const HandlerGin = NotEditMark + `
+package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	{{range .ImportPaths}}{{.}}` + "\n" + `{{end}}
+)
+
+` + handlerTypes + `
+// Register register routes of {{.Model}} on r
+func (h *{{.Name}}Handler) Register(r gin.IRouter) {
+	r.GET("{{.Route}}", h.List)
+	r.POST("{{.Route}}", h.Create)
+{{- with .PK}}
+	r.GET("{{$.Route}}/:{{.Column}}", h.Get)
+	r.PUT("{{$.Route}}/:{{.Column}}", h.Update)
+	r.DELETE("{{$.Route}}/:{{.Column}}", h.Delete)
+{{- end}}
+}
+
+// List list {{.Model}} with pagination, filtered by query parameters
+func (h *{{.Name}}Handler) List(c *gin.Context) {
+	var req List{{.Name}}Request
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	items, total, err := h.list(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, List{{.Name}}Response{Items: items, Total: total})
+}
+
+// Create create {{.Model}} from request body
+func (h *{{.Name}}Handler) Create(c *gin.Context) {
+	var req {{.Body}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	obj := {{.FromBody "&req"}}
+	if err := h.q.{{.Name}}.WithContext(c.Request.Context()).Create(obj); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, {{.ToBody "obj"}})
+}
+{{- with .PK}}
+
+// Get get {{$.Model}} by {{.Column}}
+func (h *{{$.Name}}Handler) Get(c *gin.Context) {
+	id, err := parse{{$.Name}}PK(c.Param("{{.Column}}"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q := h.q.{{$.Name}}
+	obj, err := q.WithContext(c.Request.Context()).Where(q.{{.Name}}.Eq(id)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, {{$.ToBody "obj"}})
+}
+
+// Update update non-zero fields of {{$.Model}} by {{.Column}} from request body
+func (h *{{$.Name}}Handler) Update(c *gin.Context) {
+	id, err := parse{{$.Name}}PK(c.Param("{{.Column}}"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req {{$.Body}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q := h.q.{{$.Name}}
+	result, err := q.WithContext(c.Request.Context()).Where(q.{{.Name}}.Eq(id)).Updates({{$.FromBody "&req"}})
+	if err == nil && result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Delete delete {{$.Model}} by {{.Column}}
+func (h *{{$.Name}}Handler) Delete(c *gin.Context) {
+	id, err := parse{{$.Name}}PK(c.Param("{{.Column}}"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q := h.q.{{$.Name}}
+	if _, err := q.WithContext(c.Request.Context()).Where(q.{{.Name}}.Eq(id)).Delete(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+{{- end}}
+`
+
+// HandlerEcho echo CRUD handlers of model
+const HandlerEcho = NotEditMark + `
+package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	{{range .ImportPaths}}{{.}}` + "\n" + `{{end}}
+)
+
+` + handlerTypes + `
+// Register register routes of {{.Model}} on g
+func (h *{{.Name}}Handler) Register(g *echo.Group) {
+	g.GET("{{.Route}}", h.List)
+	g.POST("{{.Route}}", h.Create)
+{{- with .PK}}
+	g.GET("{{$.Route}}/:{{.Column}}", h.Get)
+	g.PUT("{{$.Route}}/:{{.Column}}", h.Update)
+	g.DELETE("{{$.Route}}/:{{.Column}}", h.Delete)
+{{- end}}
+}
+
+// List list {{.Model}} with pagination, filtered by query parameters
+func (h *{{.Name}}Handler) List(c echo.Context) error {
+	var req List{{.Name}}Request
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	items, total, err := h.list(c.Request().Context(), &req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, List{{.Name}}Response{Items: items, Total: total})
+}
+
+// Create create {{.Model}} from request body
+func (h *{{.Name}}Handler) Create(c echo.Context) error {
+	var req {{.Body}}
+	if err := (&echo.DefaultBinder{}).BindBody(c, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	obj := {{.FromBody "&req"}}
+	if err := h.q.{{.Name}}.WithContext(c.Request().Context()).Create(obj); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, {{.ToBody "obj"}})
+}
+{{- with .PK}}
+
+// Get get {{$.Model}} by {{.Column}}
+func (h *{{$.Name}}Handler) Get(c echo.Context) error {
+	id, err := parse{{$.Name}}PK(c.Param("{{.Column}}"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	q := h.q.{{$.Name}}
+	obj, err := q.WithContext(c.Request().Context()).Where(q.{{.Name}}.Eq(id)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, {{$.ToBody "obj"}})
+}
+
+// Update update non-zero fields of {{$.Model}} by {{.Column}} from request body
+func (h *{{$.Name}}Handler) Update(c echo.Context) error {
+	id, err := parse{{$.Name}}PK(c.Param("{{.Column}}"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	var req {{$.Body}}
+	if err := (&echo.DefaultBinder{}).BindBody(c, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	q := h.q.{{$.Name}}
+	result, err := q.WithContext(c.Request().Context()).Where(q.{{.Name}}.Eq(id)).Updates({{$.FromBody "&req"}})
+	if err == nil && result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Delete delete {{$.Model}} by {{.Column}}
+func (h *{{$.Name}}Handler) Delete(c echo.Context) error {
+	id, err := parse{{$.Name}}PK(c.Param("{{.Column}}"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	q := h.q.{{$.Name}}
+	if _, err := q.WithContext(c.Request().Context()).Where(q.{{.Name}}.Eq(id)).Delete(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+{{- end}}
+`
+
+// handlerTypes handler struct, request/response types and query shared by frameworks
+const handlerTypes = `
+const (
+	default{{.Name}}PageSize = 20
+	max{{.Name}}PageSize     = 100
+)
+
+// {{.Name}}Handler HTTP handlers of {{.Model}}
+type {{.Name}}Handler struct {
+	q *{{.QueryPackage}}.Query
+}
+
+// New{{.Name}}Handler create handlers of {{.Model}} served by q
+func New{{.Name}}Handler(q *{{.QueryPackage}}.Query) *{{.Name}}Handler {
+	return &{{.Name}}Handler{q: q}
+}
+
+// List{{.Name}}Request query parameters of listing {{.Model}}
+type List{{.Name}}Request struct {
+	Page     int ` + "`form:\"page\" query:\"page\"`" + `
+	PageSize int ` + "`form:\"page_size\" query:\"page_size\"`" + `
+{{range .Filters}}
+	{{.Name}} *{{.Type}} ` + "`form:\"{{.Column}}\" query:\"{{.Column}}\"`" + `{{end}}
+}
+
+// List{{.Name}}Response result of listing {{.Model}}
+type List{{.Name}}Response struct {
+	Items []*{{.Body}} ` + "`json:\"items\"`" + `
+	Total int64 ` + "`json:\"total\"`" + `
+}
+
+func (h *{{.Name}}Handler) list(ctx context.Context, req *List{{.Name}}Request) ([]*{{.Body}}, int64, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = default{{.Name}}PageSize
+	}
+	if req.PageSize > max{{.Name}}PageSize {
+		req.PageSize = max{{.Name}}PageSize
+	}
+
+	q := h.q.{{.Name}}
+	do := q.WithContext(ctx)
+{{- range .Filters}}
+	if req.{{.Name}} != nil {
+		do = do.Where(q.{{.Name}}.{{.Method}}(*req.{{.Name}}))
+	}
+{{- end}}
+	items, total, err := do.FindByPage((req.Page-1)*req.PageSize, req.PageSize)
+	return {{.ToBodyList "items"}}, total, err
+}
+{{with .PK}}
+func parse{{$.Name}}PK(param string) ({{.Type}}, error) {
+	{{.Parse}}
+}
+{{end}}`
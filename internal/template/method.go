@@ -8,7 +8,12 @@ func ({{.S}} {{.TargetStruct}}Do){{.FuncSign}}{
 	{{if .HasSQLData}}var params []interface{}
 
 	{{end}}var generateSQL strings.Builder
-	{{range $line:=.Section.Tmpls}}{{$line}}
+	{{if .Dialects}}switch {{.S}}.UnderlyingDB().Dialector.Name() {
+	{{range .Dialects}}case {{.Cases}}:
+		{{range $line:=.Section.Tmpls}}{{$line}}
+		{{end}}{{end}}default:
+	{{end}}{{range $line:=.Section.Tmpls}}{{$line}}
+	{{end}}{{if .Dialects}}}
 	{{end}}
 
 	{{if .HasNeedNewResult}}result ={{if .ResultData.IsMap}}make{{else}}new{{end}}({{if ne .ResultData.Package ""}}{{.ResultData.Package}}.{{end}}{{.ResultData.Type}}){{end}}
@@ -17,9 +22,16 @@ func ({{.S}} {{.TargetStruct}}Do){{.FuncSign}}{
 	{{else if .ReturnSQLRow}}row = {{.S}}.UnderlyingDB().Raw(generateSQL.String(){{if .HasSQLData}},params...{{end}}).Row() // ignore_security_alert
 	{{else if .ReturnSQLRows}}rows,{{if .ReturnError}}err{{else}}_{{end}} = {{.S}}.UnderlyingDB().Raw(generateSQL.String(){{if .HasSQLData}},params...{{end}}).Rows() // ignore_security_alert
 	{{else}}var executeSQL *gorm.DB
-	executeSQL = {{.S}}.UnderlyingDB().{{.GormOption}}(generateSQL.String(){{if .HasSQLData}},params...{{end}}){{if not .ResultData.IsNull}}.{{.GormRunMethodName}}({{if .HasGotPoint}}&{{end}}{{.ResultData.Name}}){{end}}  // ignore_security_alert
-	{{if .ReturnRowsAffected}}rowsAffected = executeSQL.RowsAffected
-	{{end}}{{if .ReturnError}}err = executeSQL.Error
+	{{if .HasPolicy}}{{if .ReturnError}}err{{else}}_{{end}} = gen.RunWithPolicy({{.S}}.UnderlyingDB(), {{.Policy}}, func(tx *gorm.DB) error {
+		executeSQL = tx.{{.GormOption}}(generateSQL.String(){{if .HasSQLData}},params...{{end}}){{if not .ResultData.IsNull}}.{{.GormRunMethodName}}({{if .HasGotPoint}}&{{end}}{{.ResultData.Name}}){{end}}  // ignore_security_alert
+		return executeSQL.Error
+	})
+	{{else}}executeSQL = {{.S}}.UnderlyingDB().{{.GormOption}}(generateSQL.String(){{if .HasSQLData}},params...{{end}}){{if not .ResultData.IsNull}}.{{.GormRunMethodName}}({{if .HasGotPoint}}&{{end}}{{.ResultData.Name}}){{end}}  // ignore_security_alert
+	{{end}}{{if .ReturnRowsAffected}}rowsAffected = executeSQL.RowsAffected
+	{{end}}{{if and .ReturnError (not .HasPolicy)}}err = executeSQL.Error
+	{{end}}{{if and .StrictRowsAffected .ReturnRowsAffected .ReturnError}}if err == nil && rowsAffected == 0 {
+		err = gen.ErrNoRowsAffected
+	}
 	{{end}}{{if .ReturnNothing}}_ = executeSQL
 	{{end}}{{end}}
 	return
@@ -132,7 +144,7 @@ func ({{.S}} {{.QueryStructName}}Do) CreateInBatches(values []*{{.StructInfo.Pac
 	return {{.S}}.DO.CreateInBatches(values, batchSize)
 }
 
-// Save : !!! underlying implementation is different with GORM
+{{if not .VersionField}}// Save : !!! underlying implementation is different with GORM
 // The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
 func ({{.S}} {{.QueryStructName}}Do) Save(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
 	if len(values) == 0 {
@@ -140,6 +152,7 @@ func ({{.S}} {{.QueryStructName}}Do) Save(values ...*{{.StructInfo.Package}}.{{.
 	}
 	return {{.S}}.DO.Save(values)
 }
+{{end}}
 
 func ({{.S}} {{.QueryStructName}}Do) First() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
 	if result, err := {{.S}}.DO.First(); err != nil {
@@ -261,8 +274,723 @@ func ({{.S}} *{{.QueryStructName}}Do) withDO(do gen.Dao) (*{{.QueryStructName}}D
 
 `
 
+// GenericCRUDMethod CRUD methods of query struct embedding generic core(GenericDAO), only wrapping of DO is per table
+const GenericCRUDMethod = `
+func (*{{.QueryStructName}}Do) wrapDO(do gen.Dao) {{.ReturnObject}} {
+	return new({{.QueryStructName}}Do).withDO(do)
+}
+
+func ({{.S}} *{{.QueryStructName}}Do) withDO(do gen.Dao) *{{.QueryStructName}}Do {
+	{{.S}}.DO = *do.(*gen.DO)
+	return {{.S}}
+}
+`
+
+// GenericDAO generic core of CRUD methods shared by query structs of all tables, M is model, R is object returned
+// by chain methods and W wraps DO into R
+const GenericDAO = `
+// genericDoWrapper wrap DO into object returned by chain methods of genericDo
+type genericDoWrapper[R any] interface {
+	wrapDO(do gen.Dao) R
+}
+
+// genericDo CRUD methods of model M shared by query structs of tables
+type genericDo[M any, R any, W genericDoWrapper[R]] struct{ gen.DO }
+
+func (d genericDo[M, R, W]) wrap(do gen.Dao) R {
+	var w W
+	return w.wrapDO(do)
+}
+
+func (d genericDo[M, R, W]) Debug() R {
+	return d.wrap(d.DO.Debug())
+}
+
+func (d genericDo[M, R, W]) WithContext(ctx context.Context) R {
+	return d.wrap(d.DO.WithContext(ctx))
+}
+
+func (d genericDo[M, R, W]) ReadDB() R {
+	return d.Clauses(dbresolver.Read)
+}
+
+func (d genericDo[M, R, W]) WriteDB() R {
+	return d.Clauses(dbresolver.Write)
+}
+
+func (d genericDo[M, R, W]) Session(config *gorm.Session) R {
+	return d.wrap(d.DO.Session(config))
+}
+
+func (d genericDo[M, R, W]) Clauses(conds ...clause.Expression) R {
+	return d.wrap(d.DO.Clauses(conds...))
+}
+
+func (d genericDo[M, R, W]) Returning(value interface{}, columns ...string) R {
+	return d.wrap(d.DO.Returning(value, columns...))
+}
+
+func (d genericDo[M, R, W]) Not(conds ...gen.Condition) R {
+	return d.wrap(d.DO.Not(conds...))
+}
+
+func (d genericDo[M, R, W]) Or(conds ...gen.Condition) R {
+	return d.wrap(d.DO.Or(conds...))
+}
+
+func (d genericDo[M, R, W]) Select(conds ...field.Expr) R {
+	return d.wrap(d.DO.Select(conds...))
+}
+
+func (d genericDo[M, R, W]) Where(conds ...gen.Condition) R {
+	return d.wrap(d.DO.Where(conds...))
+}
+
+func (d genericDo[M, R, W]) Order(conds ...field.Expr) R {
+	return d.wrap(d.DO.Order(conds...))
+}
+
+func (d genericDo[M, R, W]) Distinct(cols ...field.Expr) R {
+	return d.wrap(d.DO.Distinct(cols...))
+}
+
+func (d genericDo[M, R, W]) Omit(cols ...field.Expr) R {
+	return d.wrap(d.DO.Omit(cols...))
+}
+
+func (d genericDo[M, R, W]) Join(table schema.Tabler, on ...field.Expr) R {
+	return d.wrap(d.DO.Join(table, on...))
+}
+
+func (d genericDo[M, R, W]) LeftJoin(table schema.Tabler, on ...field.Expr) R {
+	return d.wrap(d.DO.LeftJoin(table, on...))
+}
+
+func (d genericDo[M, R, W]) RightJoin(table schema.Tabler, on ...field.Expr) R {
+	return d.wrap(d.DO.RightJoin(table, on...))
+}
+
+func (d genericDo[M, R, W]) Group(cols ...field.Expr) R {
+	return d.wrap(d.DO.Group(cols...))
+}
+
+func (d genericDo[M, R, W]) Having(conds ...gen.Condition) R {
+	return d.wrap(d.DO.Having(conds...))
+}
+
+func (d genericDo[M, R, W]) Limit(limit int) R {
+	return d.wrap(d.DO.Limit(limit))
+}
+
+func (d genericDo[M, R, W]) Offset(offset int) R {
+	return d.wrap(d.DO.Offset(offset))
+}
+
+func (d genericDo[M, R, W]) Scopes(funcs ...func(gen.Dao) gen.Dao) R {
+	return d.wrap(d.DO.Scopes(funcs...))
+}
+
+func (d genericDo[M, R, W]) Unscoped() R {
+	return d.wrap(d.DO.Unscoped())
+}
+
+func (d genericDo[M, R, W]) Create(values ...*M) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.DO.Create(values)
+}
+
+func (d genericDo[M, R, W]) CreateInBatches(values []*M, batchSize int) error {
+	return d.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (d genericDo[M, R, W]) Save(values ...*M) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.DO.Save(values)
+}
+
+func (d genericDo[M, R, W]) First() (*M, error) {
+	return genericResult[M](d.DO.First())
+}
+
+func (d genericDo[M, R, W]) Take() (*M, error) {
+	return genericResult[M](d.DO.Take())
+}
+
+func (d genericDo[M, R, W]) Last() (*M, error) {
+	return genericResult[M](d.DO.Last())
+}
+
+func (d genericDo[M, R, W]) Find() ([]*M, error) {
+	result, err := d.DO.Find()
+	return result.([]*M), err
+}
+
+func (d genericDo[M, R, W]) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*M, err error) {
+	buf := make([]*M, 0, batchSize)
+	err = d.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (d genericDo[M, R, W]) FindInBatches(result *[]*M, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return d.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (d genericDo[M, R, W]) Attrs(attrs ...field.AssignExpr) R {
+	return d.wrap(d.DO.Attrs(attrs...))
+}
+
+func (d genericDo[M, R, W]) Assign(attrs ...field.AssignExpr) R {
+	return d.wrap(d.DO.Assign(attrs...))
+}
+
+func (d genericDo[M, R, W]) Joins(fields ...field.RelationField) R {
+	for _, _f := range fields {
+		d.DO = *d.DO.Joins(_f).(*gen.DO)
+	}
+	return d.wrap(&d.DO)
+}
+
+func (d genericDo[M, R, W]) Preload(fields ...field.RelationField) R {
+	for _, _f := range fields {
+		d.DO = *d.DO.Preload(_f).(*gen.DO)
+	}
+	return d.wrap(&d.DO)
+}
+
+func (d genericDo[M, R, W]) FirstOrInit() (*M, error) {
+	return genericResult[M](d.DO.FirstOrInit())
+}
+
+func (d genericDo[M, R, W]) FirstOrCreate() (*M, error) {
+	return genericResult[M](d.DO.FirstOrCreate())
+}
+
+func (d genericDo[M, R, W]) FindByPage(offset int, limit int) (result []*M, count int64, err error) {
+	rows, err := d.DO.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return nil, 0, err
+	}
+	result = rows.([]*M)
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = d.DO.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (d genericDo[M, R, W]) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = d.DO.Count()
+	if err != nil {
+		return
+	}
+
+	err = d.DO.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (d genericDo[M, R, W]) Scan(result interface{}) (err error) {
+	return d.DO.Scan(result)
+}
+
+func (d genericDo[M, R, W]) Delete(models ...*M) (result gen.ResultInfo, err error) {
+	return d.DO.Delete(models)
+}
+
+func genericResult[M any](result interface{}, err error) (*M, error) {
+	if err != nil {
+		return nil, err
+	}
+	return result.(*M), nil
+}
+`
+
+// ExistsMethod exists and count by index methods
+const ExistsMethod = `
+func ({{.S}} {{.QueryStructName}}Do) Exists(conds ...gen.Condition) (bool, error) {
+	return {{.S}}.DO.Where(conds...).(*gen.DO).Exists()
+}
+{{with .PrimaryKey}}{{if .GenValueType}}
+func ({{$.S}} {{$.QueryStructName}}Do) ExistsBy{{.Name}}(value {{.GenValueType}}) (bool, error) {
+	return {{$.S}}{{if $.UnscopedLookup}}.Unscoped(){{end}}.Exists(field.New{{.GenType}}("", "{{.ColumnName}}").Eq(value))
+}
+{{end}}{{end}}
+{{range .IndexedFields}}{{if .GenValueType}}
+func ({{$.S}} {{$.QueryStructName}}Do) CountBy{{.Name}}(value {{.GenValueType}}) (int64, error) {
+	return {{$.S}}.DO.Where(field.New{{.GenType}}("", "{{.ColumnName}}").Eq(value)).Count()
+}
+{{end}}{{end}}
+`
+
+// EachMethod stream query methods
+const EachMethod = `
+// Each iterate records one by one through database cursor, it's suitable for processing large amount of records
+func ({{.S}} {{.QueryStructName}}Do) Each(ctx context.Context, fc func(*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error) error {
+	return {{.S}}.DO.WithContext(ctx).(*gen.DO).Each(func(result interface{}) error {
+		return fc(result.(*{{.StructInfo.Package}}.{{.StructInfo.Type}}))
+	})
+}
+
+// EachInBatches iterate records batch by batch, the results slice is reused between batches
+func ({{.S}} {{.QueryStructName}}Do) EachInBatches(batchSize int, fc func(results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batch int) error) error {
+	results := make([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, 0, batchSize)
+	return {{.S}}.DO.FindInBatches(&results, batchSize, func(tx gen.Dao, batch int) error { return fc(results, batch) })
+}
+`
+
+// LockingMethod row level locking methods
+const LockingMethod = `
+func ({{.S}} {{.QueryStructName}}Do) ForUpdate(opts ...gen.LockOption) {{.ReturnObject}} {
+	return {{.S}}.withDO({{.S}}.DO.ForUpdate(opts...))
+}
+
+func ({{.S}} {{.QueryStructName}}Do) ForShare(opts ...gen.LockOption) {{.ReturnObject}} {
+	return {{.S}}.withDO({{.S}}.DO.ForShare(opts...))
+}
+`
+
+// PreloadMethod named preload methods of relations, nested preloads are built by With<Relation> functions(PreloadFunc)
+const PreloadMethod = `
+{{range .PreloadRelations}}
+// With{{.}} preload {{.}} with nested preloads, e.g. With{{.}}(With<Relation>())
+func ({{$.S}} {{$.QueryStructName}}Do) With{{.}}(nested ...gen.Preload) {{$.ReturnObject}} {
+	return {{$.S}}.withDO({{$.S}}.DO.PreloadNested(gen.Preload{Name: "{{.}}", Nested: nested}))
+}
+{{end}}`
+
+// PreloadFunc With<Relation> functions of nested preloads, one for each relation name of models
+const PreloadFunc = `
+{{range .}}
+// With{{.}} nested preload of relation {{.}}, passed to With<Relation> method or function of its parent relation
+func With{{.}}(nested ...gen.Preload) gen.Preload {
+	return gen.Preload{Name: "{{.}}", Nested: nested}
+}
+{{end}}`
+
+// FilterMethod <Model>Filter struct of optional filters and ApplyFilter method
+const FilterMethod = `
+// {{.ModelStructName}}Filter optional filters of {{.ModelStructName}} applied by ApplyFilter, nil and empty fields are ignored
+type {{.ModelStructName}}Filter struct {
+	{{range .FilterFields}}{{.Name}} {{if eq .Kind "range"}}*gen.Range[{{.ValueType}}]{{else if eq .Kind "in"}}[]{{.EnumType}}{{else}}*{{.ValueType}}{{end}}
+	{{end}}
+}
+
+// ApplyFilter add conditions of non-nil fields of f, range is inclusive and enum field matches any of its values
+func ({{.S}} {{.QueryStructName}}Do) ApplyFilter(f *{{.ModelStructName}}Filter) {{.ReturnObject}} {
+	if f == nil {
+		return &{{.S}}
+	}
+	var conds []gen.Condition
+	{{- range .FilterFields}}
+	{{- if eq .Kind "eq"}}
+	if f.{{.Name}} != nil {
+		conds = append(conds, field.New{{.GenType}}("", "{{.ColumnName}}").Eq(*f.{{.Name}}))
+	}
+	{{- else if eq .Kind "bool"}}
+	if f.{{.Name}} != nil {
+		conds = append(conds, field.NewBool("", "{{.ColumnName}}").Is(*f.{{.Name}}))
+	}
+	{{- else if eq .Kind "range"}}
+	if f.{{.Name}} != nil && f.{{.Name}}.Min != nil {
+		conds = append(conds, field.New{{.GenType}}("", "{{.ColumnName}}").Gte(*f.{{.Name}}.Min))
+	}
+	if f.{{.Name}} != nil && f.{{.Name}}.Max != nil {
+		conds = append(conds, field.New{{.GenType}}("", "{{.ColumnName}}").Lte(*f.{{.Name}}.Max))
+	}
+	{{- else if eq .Kind "in"}}
+	if len(f.{{.Name}}) > 0 {
+		values := make([]{{.ValueType}}, len(f.{{.Name}}))
+		for i, v := range f.{{.Name}} {
+			values[i] = {{.ValueType}}(v)
+		}
+		conds = append(conds, field.New{{.GenType}}("", "{{.ColumnName}}").In(values...))
+	}
+	{{- end}}
+	{{- end}}
+	return {{.S}}.Where(conds...)
+}
+`
+
+// SortMethod <Model>Field enum of columns, OrderBy and SelectFields methods
+const SortMethod = `
+{{if .SortFields}}
+// {{.ModelStructName}}Field column of {{.TableName}} accepted by OrderBy and SelectFields
+type {{.ModelStructName}}Field string
+
+const (
+	{{range .SortFields}}{{$.ModelStructName}}Field{{.Name}} {{$.ModelStructName}}Field = "{{.ColumnName}}"
+	{{end}}
+)
+
+// Parse{{.ModelStructName}}Field parse column name of {{.TableName}}, return gen.ErrUnknownField if name is not a column,
+// use it with gen.ParseOrders and gen.ParseFields to check sort and fields parameters
+func Parse{{.ModelStructName}}Field(name string) ({{.ModelStructName}}Field, error) {
+	switch f := {{.ModelStructName}}Field(name); f {
+	case {{range $i, $f := .SortFields}}{{if $i}}, {{end}}{{$.ModelStructName}}Field{{$f.Name}}{{end}}:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w %q of {{.TableName}}", gen.ErrUnknownField, name)
+}
+
+// OrderBy order by fields in sequence
+func ({{.S}} {{.QueryStructName}}Do) OrderBy(orders ...gen.Order[{{.ModelStructName}}Field]) {{.ReturnObject}} {
+	exprs := make([]field.Expr, len(orders))
+	for i, o := range orders {
+		if o.Desc {
+			exprs[i] = field.NewField("", string(o.Field)).Desc()
+		} else {
+			exprs[i] = field.NewField("", string(o.Field))
+		}
+	}
+	return {{.S}}.Order(exprs...)
+}
+
+// SelectFields select fields only
+func ({{.S}} {{.QueryStructName}}Do) SelectFields(fields ...{{.ModelStructName}}Field) {{.ReturnObject}} {
+	exprs := make([]field.Expr, len(fields))
+	for i, f := range fields {
+		exprs[i] = field.NewField("", string(f))
+	}
+	return {{.S}}.Select(exprs...)
+}
+{{end}}
+`
+
+// BatchMethod batch update and delete methods
+const BatchMethod = `
+// UpdateColumnsInBatches update columns of records matched by primary key batch by batch, one statement per batch,
+// hooks and auto update time are skipped as UpdateColumns
+func ({{.S}} {{.QueryStructName}}Do) UpdateColumnsInBatches(values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, columns ...field.Expr) (info gen.ResultInfo, err error) {
+	return {{.S}}.DO.UpdateColumnsInBatches(values, 0, columns...)
+}
+{{with .PrimaryKey}}{{if .GenValueType}}
+// DeleteBy{{.Name}}s delete records by primary keys batch by batch
+func ({{$.S}} {{$.QueryStructName}}Do) DeleteBy{{.Name}}s(values ...{{.GenValueType}}) (info gen.ResultInfo, err error) {
+	return {{$.S}}.DO.DeleteByPrimaryKeys(values, 0)
+}
+{{end}}{{end}}
+`
+
+// ReturningMethod update and delete methods returning affected records
+const ReturningMethod = `
+// UpdateReturning update columns and return updated records by RETURNING, without querying them again
+func ({{.S}} {{.QueryStructName}}Do) UpdateReturning(columns ...field.AssignExpr) (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error) {
+	_, err = {{.S}}.DO.UpdateReturning(&results, columns...)
+	return results, err
+}
+
+// DeleteReturning delete records and return deleted records by RETURNING
+func ({{.S}} {{.QueryStructName}}Do) DeleteReturning() (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error) {
+	_, err = {{.S}}.DO.DeleteReturning(&results)
+	return results, err
+}
+`
+
+// AffectedMethod update and delete methods returning number of affected rows
+const AffectedMethod = `
+// UpdateAffected update column, return number of affected rows{{if .StrictRowsAffected}} or gen.ErrNoRowsAffected if no row is affected{{end}}
+func ({{.S}} {{.QueryStructName}}Do) UpdateAffected(column field.Expr, value interface{}) (rowsAffected int64, err error) {
+	info, err := {{.S}}.Update(column, value)
+	return {{.S}}.rowsAffected(info, err)
+}
+
+// UpdateSimpleAffected update columns by assign expressions, return number of affected rows{{if .StrictRowsAffected}} or gen.ErrNoRowsAffected if no row is affected{{end}}
+func ({{.S}} {{.QueryStructName}}Do) UpdateSimpleAffected(columns ...field.AssignExpr) (rowsAffected int64, err error) {
+	info, err := {{.S}}.UpdateSimple(columns...)
+	return {{.S}}.rowsAffected(info, err)
+}
+
+// UpdatesAffected update columns of value, return number of affected rows{{if .StrictRowsAffected}} or gen.ErrNoRowsAffected if no row is affected{{end}}
+func ({{.S}} {{.QueryStructName}}Do) UpdatesAffected(value interface{}) (rowsAffected int64, err error) {
+	info, err := {{.S}}.Updates(value)
+	return {{.S}}.rowsAffected(info, err)
+}
+
+// UpdateColumnAffected update column without hooks and auto update time, return number of affected rows{{if .StrictRowsAffected}} or gen.ErrNoRowsAffected if no row is affected{{end}}
+func ({{.S}} {{.QueryStructName}}Do) UpdateColumnAffected(column field.Expr, value interface{}) (rowsAffected int64, err error) {
+	info, err := {{.S}}.UpdateColumn(column, value)
+	return {{.S}}.rowsAffected(info, err)
+}
+
+// UpdateColumnSimpleAffected update columns by assign expressions without hooks and auto update time, return number of affected rows{{if .StrictRowsAffected}} or gen.ErrNoRowsAffected if no row is affected{{end}}
+func ({{.S}} {{.QueryStructName}}Do) UpdateColumnSimpleAffected(columns ...field.AssignExpr) (rowsAffected int64, err error) {
+	info, err := {{.S}}.UpdateColumnSimple(columns...)
+	return {{.S}}.rowsAffected(info, err)
+}
+
+// UpdateColumnsAffected update columns of value without hooks and auto update time, return number of affected rows{{if .StrictRowsAffected}} or gen.ErrNoRowsAffected if no row is affected{{end}}
+func ({{.S}} {{.QueryStructName}}Do) UpdateColumnsAffected(value interface{}) (rowsAffected int64, err error) {
+	info, err := {{.S}}.UpdateColumns(value)
+	return {{.S}}.rowsAffected(info, err)
+}
+
+// DeleteAffected delete records, return number of affected rows{{if .StrictRowsAffected}} or gen.ErrNoRowsAffected if no row is affected{{end}}
+func ({{.S}} {{.QueryStructName}}Do) DeleteAffected(models ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (rowsAffected int64, err error) {
+	info, err := {{.S}}.Delete(models...)
+	return {{.S}}.rowsAffected(info, err)
+}
+
+func ({{.S}} {{.QueryStructName}}Do) rowsAffected(info gen.ResultInfo, err error) (int64, error) {
+	{{- if .StrictRowsAffected}}
+	if err == nil && info.RowsAffected == 0 {
+		return 0, gen.ErrNoRowsAffected
+	}
+	{{- end}}
+	return info.RowsAffected, err
+}
+`
+
+// HintMethod <Model>Index constants of indexes, index hints and optimizer hints
+const HintMethod = `
+{{if .NamedIndexes}}
+// {{.ModelStructName}}Index index of {{.TableName}} accepted by UseIndex, ForceIndex and IgnoreIndex
+type {{.ModelStructName}}Index string
+
+const (
+	{{range .NamedIndexes}}{{$.ModelStructName}}Index{{.Name}} {{$.ModelStructName}}Index = "{{.Index}}"
+	{{end}}
+)
+
+// UseIndex suggest indexes by USE INDEX
+func ({{.S}} {{.QueryStructName}}Do) UseIndex(indexes ...{{.ModelStructName}}Index) {{.ReturnObject}} {
+	return {{.S}}.Clauses(hints.UseIndex(gen.IndexNames(indexes)...))
+}
+
+// ForceIndex force indexes by FORCE INDEX
+func ({{.S}} {{.QueryStructName}}Do) ForceIndex(indexes ...{{.ModelStructName}}Index) {{.ReturnObject}} {
+	return {{.S}}.Clauses(hints.ForceIndex(gen.IndexNames(indexes)...))
+}
+
+// IgnoreIndex ignore indexes by IGNORE INDEX
+func ({{.S}} {{.QueryStructName}}Do) IgnoreIndex(indexes ...{{.ModelStructName}}Index) {{.ReturnObject}} {
+	return {{.S}}.Clauses(hints.IgnoreIndex(gen.IndexNames(indexes)...))
+}
+{{end}}
+// OptimizerHint add optimizer hints after SELECT/UPDATE/DELETE, e.g. OptimizerHint("MAX_EXECUTION_TIME(1000)")
+// generates SELECT /*+ MAX_EXECUTION_TIME(1000) */
+func ({{.S}} {{.QueryStructName}}Do) OptimizerHint(contents ...string) {{.ReturnObject}} {
+	return {{.S}}.Clauses(hints.New(strings.Join(contents, " ")))
+}
+`
+
+// VersionMethod optimistic locking methods for model with version column
+const VersionMethod = `
+{{with .VersionField}}
+// Updates : value of type *{{$.StructInfo.Package}}.{{$.StructInfo.Type}} is updated with optimistic locking on column <{{.ColumnName}}>,
+// return gen.ErrStaleObject when the record has been modified or deleted
+func ({{$.S}} {{$.QueryStructName}}Do) Updates(value interface{}) (info gen.ResultInfo, err error) {
+	if v, ok := value.(*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}); ok {
+		return {{$.S}}.updateWithVersion(&{{$.S}}.DO, v, gen.Dao.Updates)
+	}
+	return {{$.S}}.DO.Updates(value)
+}
+
+// UpdateColumns : value of type *{{$.StructInfo.Package}}.{{$.StructInfo.Type}} is updated with optimistic locking on column <{{.ColumnName}}> as Updates
+// without hooks and time tracking. Other updates by columns or expressions(Update/UpdateSimple/UpdateColumn...)
+// increase column <{{.ColumnName}}>, so records read before them fail Updates/Save with gen.ErrStaleObject
+func ({{$.S}} {{$.QueryStructName}}Do) UpdateColumns(value interface{}) (info gen.ResultInfo, err error) {
+	if v, ok := value.(*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}); ok {
+		return {{$.S}}.updateWithVersion(&{{$.S}}.DO, v, gen.Dao.UpdateColumns)
+	}
+	return {{$.S}}.DO.UpdateColumns(value)
+}
+
+// Save : record with zero version is created, others are updated with optimistic locking on column <{{.ColumnName}}>,
+// return gen.ErrStaleObject when the record has been modified or deleted
+func ({{$.S}} {{$.QueryStructName}}Do) Save(values ...*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) error {
+	for _, value := range values {
+		if value.{{.Name}} == 0 {
+			value.{{.Name}} = 1
+			if err := {{$.S}}.DO.Create(value); err != nil {
+				value.{{.Name}} = 0
+				return err
+			}
+			continue
+		}
+		if _, err := {{$.S}}.updateWithVersion({{$.S}}.DO.Select(field.ALL), value, gen.Dao.Updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ({{$.S}} {{$.QueryStructName}}Do) updateWithVersion(do gen.Dao, value *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, update func(gen.Dao, interface{}) (gen.ResultInfo, error)) (info gen.ResultInfo, err error) {
+	version := value.{{.Name}}
+	value.{{.Name}}++
+	info, err = update(do.Where(field.New{{.GenType}}("", "{{.ColumnName}}").Eq(version)), value)
+	if err == nil && info.RowsAffected == 0 {
+		err = gen.ErrStaleObject
+	}
+	if err != nil {
+		value.{{.Name}} = version
+	}
+	return info, err
+}
+{{end}}
+`
+
+// CompositeKeyMethod primary key struct and lookup methods for model with composite primary key
+const CompositeKeyMethod = `
+{{with .CompositeKey}}
+// {{$.ModelStructName}}PK composite primary key of {{$.TableName}}
+type {{$.ModelStructName}}PK struct {
+	{{range .}}{{.Name}} {{.GenValueType}}
+	{{end}}
+}
+
+func (pk {{$.ModelStructName}}PK) conds() []gen.Condition {
+	return []gen.Condition{
+		{{range .}}field.New{{.GenType}}("", "{{.ColumnName}}").Eq(pk.{{.Name}}),
+		{{end}}
+	}
+}
+
+// FindByPK query record by composite primary key
+func ({{$.S}} {{$.QueryStructName}}Do) FindByPK(pk {{$.ModelStructName}}PK) (*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error) {
+	return {{$.S}}{{if $.UnscopedLookup}}.Unscoped(){{end}}.Where(pk.conds()...).Take()
+}
+
+// DeleteByPK delete record by composite primary key
+func ({{$.S}} {{$.QueryStructName}}Do) DeleteByPK(pk {{$.ModelStructName}}PK) (info gen.ResultInfo, err error) {
+	return {{$.S}}.Where(pk.conds()...).Delete()
+}
+
+// UpsertByPK insert values, or update all columns of records whose composite primary key exists
+func ({{$.S}} {{$.QueryStructName}}Do) UpsertByPK(values ...*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) error {
+	return {{$.S}}.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{ {{range .}}{Name: "{{.ColumnName}}"}, {{end}} },
+		UpdateAll: true,
+	}).Create(values...)
+}
+{{end}}
+`
+
+// SoftDeleteMethod unscoped lookup, restore and hard delete methods of soft-deleted model
+const SoftDeleteMethod = `
+{{with .PrimaryKey}}{{if .GenValueType}}
+// FindBy{{.Name}}Unscoped query record by primary key including soft-deleted one
+func ({{$.S}} {{$.QueryStructName}}Do) FindBy{{.Name}}Unscoped(value {{.GenValueType}}) (*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error) {
+	return {{$.S}}.Unscoped().Where(field.New{{.GenType}}("", "{{.ColumnName}}").Eq(value)).Take()
+}
+
+// Restore restore soft-deleted records by primary keys
+func ({{$.S}} {{$.QueryStructName}}Do) Restore(values ...{{.GenValueType}}) (info gen.ResultInfo, err error) {
+	if len(values) == 0 {
+		return gen.ResultInfo{}, nil
+	}
+	return {{$.S}}.Unscoped().Where(field.New{{.GenType}}("", "{{.ColumnName}}").In(values...)).Update(field.NewField("", "{{$.SoftDeleteField.ColumnName}}"), nil)
+}
+{{end}}{{end}}
+// HardDelete delete records permanently instead of soft deleting them
+func ({{.S}} {{.QueryStructName}}Do) HardDelete(models ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (result gen.ResultInfo, err error) {
+	return {{.S}}.Unscoped().Delete(models...)
+}
+`
+
+// ImportExportMethod CSV and JSON Lines import/export methods
+const ImportExportMethod = `
+// ExportCSV write records matching conds to w as CSV with header of column names, see gen.DO.ExportCSV
+func ({{.S}} {{.QueryStructName}}Do) ExportCSV(w io.Writer, conds ...gen.Condition) error {
+	return {{.S}}.DO.ExportCSV(w, conds...)
+}
+
+// ImportCSV create records of CSV written by ExportCSV from r in batches, see gen.DO.ImportCSV
+func ({{.S}} {{.QueryStructName}}Do) ImportCSV(r io.Reader, batchSize int) (count int64, err error) {
+	return {{.S}}.DO.ImportCSV(r, batchSize)
+}
+
+// ExportJSONLines write records matching conds to w as JSON Lines
+func ({{.S}} {{.QueryStructName}}Do) ExportJSONLines(w io.Writer, conds ...gen.Condition) error {
+	return {{.S}}.DO.ExportJSONLines(w, conds...)
+}
+
+// ImportJSONLines create records of JSON Lines from r in batches
+func ({{.S}} {{.QueryStructName}}Do) ImportJSONLines(r io.Reader, batchSize int) (count int64, err error) {
+	return {{.S}}.DO.ImportJSONLines(r, batchSize)
+}
+`
+
+// TemporalMethod time travel query methods of system-versioned temporal table
+const TemporalMethod = `
+// AsOf query rows as they were at time t by FOR SYSTEM_TIME AS OF, past rows are read from history of the table
+func ({{.S}} {{.QueryStructName}}Do) AsOf(t time.Time) {{.ReturnObject}} {
+	return {{.S}}.withDO({{.S}}.DO.AsOf(t))
+}
+`
+
+// CacheMethod primary key lookup methods served by cache
+const CacheMethod = `
+{{with .PrimaryKey}}{{if .GenValueType}}
+// GetBy{{.Name}} query record by primary key, record is cached when cache is enabled by gen.WithCache
+func ({{$.S}} {{$.QueryStructName}}Do) GetBy{{.Name}}(value {{.GenValueType}}) (*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error) {
+	if result, err := {{$.S}}.DO.TakeByPrimaryKey(value); err != nil {
+		return nil, err
+	} else {
+		return result.(*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}), nil
+	}
+}
+{{end}}{{end}}
+`
+
+// ResolverMethod read/write splitting methods
+const ResolverMethod = `
+// ReadOnly pin query to replicas registered by dbresolver
+func ({{.S}} {{.QueryStructName}}Do) ReadOnly() {{.ReturnObject}} {
+	return {{.S}}.Clauses(dbresolver.Read)
+}
+
+// Primary pin query to sources registered by dbresolver
+func ({{.S}} {{.QueryStructName}}Do) Primary() {{.ReturnObject}} {
+	return {{.S}}.Clauses(dbresolver.Write)
+}
+
+// UseResolver pin query to resolver registered by dbresolver with name
+func ({{.S}} {{.QueryStructName}}Do) UseResolver(name string) {{.ReturnObject}} {
+	return {{.S}}.withDO({{.S}}.DO.UseResolver(name))
+}
+
+// Sticky pin reads to sources registered by dbresolver after any write with the same context, see gen.Sticky
+func ({{.S}} {{.QueryStructName}}Do) Sticky(ctx context.Context) {{.ReturnObject}} {
+	return {{.S}}.withDO({{.S}}.DO.Sticky(ctx))
+}
+`
+
+// ShardMethod sharded table switching methods
+const ShardMethod = `
+// Suffix switch to sharded table {{.TableName}}<suffix>
+func ({{.S}} {{.QueryStructName}}) Suffix(suffix string) *{{.QueryStructName}} {
+	return {{.S}}.Table("{{.TableName}}" + suffix)
+}
+{{if .ShardByTime}}
+// ForMonth switch to sharded table of time t, suffix is formatted with layout "{{.ShardPattern}}"
+func ({{.S}} {{.QueryStructName}}) ForMonth(t time.Time) *{{.QueryStructName}} {
+	return {{.S}}.Suffix(t.Format("{{.ShardPattern}}"))
+}
+{{else}}
+// ForShard switch to sharded table of shard n, suffix is formatted with "{{.ShardPattern}}"
+func ({{.S}} {{.QueryStructName}}) ForShard(n int) *{{.QueryStructName}} {
+	return {{.S}}.Suffix(fmt.Sprintf("{{.ShardPattern}}", n))
+}
+{{end}}
+`
+
 // CRUDMethodTest CRUD method test
 const CRUDMethodTest = `
+// {{.QueryStructName}}TestCtx context of tests of {{.TableName}}{{with .TenantField}}, carrying tenant required by its tenant scope
+var {{$.QueryStructName}}TestCtx = gen.WithTenant(context.Background(), {{if or (eq .Type "string") (eq .Type "*string")}}"test"{{else}}1{{end}}){{else}}
+var {{.QueryStructName}}TestCtx = context.Background(){{end}}
+
 func init() {
 	InitializeDB()
 	err := db.AutoMigrate(&{{.StructInfo.Package}}.{{.ModelStructName}}{})
@@ -274,7 +1002,7 @@ func init() {
 func Test_{{.QueryStructName}}Query(t *testing.T) {
 	{{.QueryStructName}} := new{{.ModelStructName}}(db)
 	{{.QueryStructName}} = *{{.QueryStructName}}.As({{.QueryStructName}}.TableName())
-	_do := {{.QueryStructName}}.WithContext(context.Background()).Debug()
+	_do := {{.QueryStructName}}.WithContext({{.QueryStructName}}TestCtx).Debug()
 
 	primaryKey := field.NewString({{.QueryStructName}}.TableName(), clause.PrimaryKey)
 	_, err := _do.Unscoped().Where(primaryKey.IsNotNull()).Delete()
@@ -417,7 +1145,7 @@ var {{.OriginStruct.Type}}{{.MethodName}}TestCase = []TestCase{}
 
 func Test_{{.TargetStruct}}_{{.MethodName}}(t *testing.T) {
 	{{.TargetStruct}} := new{{.OriginStruct.Type}}(db)
-	do := {{.TargetStruct}}.WithContext(context.Background()).Debug()
+	do := {{.TargetStruct}}.WithContext({{.TargetStruct}}TestCtx).Debug()
 
 	for i, tt := range {{.OriginStruct.Type}}{{.MethodName}}TestCase {
 		t.Run("{{.MethodName}}_"+strconv.Itoa(i), func(t *testing.T) {
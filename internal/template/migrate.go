@@ -0,0 +1,25 @@
+package template
+
+// AutoMigrate registration of generated models for gorm's AutoMigrate
+const AutoMigrate = NotEditMark + `
+package {{.Package}}
+
+import (
+	"gorm.io/gorm"
+	{{range .ImportPaths}}{{.}}
+	{{end}}
+)
+
+// Models all generated models, e.g. db.AutoMigrate({{.Package}}.Models()...)
+func Models() []interface{} {
+	return []interface{}{
+		{{range .Models}}&{{.}}{},
+		{{end}}
+	}
+}
+
+// AutoMigrate create tables, missing columns and indexes of generated models by gorm's AutoMigrate
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(Models()...)
+}
+`
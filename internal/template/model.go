@@ -17,18 +17,68 @@ import (
 {{if .TableName -}}const TableName{{.ModelStructName}} = "{{.TableName}}"{{- end}}
 
 // {{.ModelStructName}} {{.StructComment}}
+type {{.ModelStructName}} struct {
+    {{if .BaseModel}}{{.BaseModel}}
+    {{end}}{{range .Fields}}{{if not .Embedded}}
+    {{if .HasDoc -}}
+	{{.Doc}}
+	{{else if .MultilineComment -}}
+	/*
+{{.ColumnComment}}
+    */
+	{{end -}}
+    {{.Name}} {{.Type}} ` + "`{{.Tags}}` " +
+	"{{if not .HasDoc}}{{if not .MultilineComment}}{{if .ColumnComment}}// {{.ColumnComment}}{{end}}{{end}}{{end}}" +
+	`{{end}}{{end}}
+}
+
+`
+
+// BaseModel struct of columns shared by models, embedded by models having all of them
+const BaseModel = NotEditMark + `
+package {{.StructInfo.Package}}
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	{{range .ImportPkgPaths}}{{.}} ` + "\n" + `{{end}}
+)
+
+// {{.ModelStructName}} columns shared by models, embedded by models having all of them
 type {{.ModelStructName}} struct {
     {{range .Fields}}
-    {{if .MultilineComment -}}
+    {{if .HasDoc -}}
+	{{.Doc}}
+	{{else if .MultilineComment -}}
 	/*
 {{.ColumnComment}}
     */
 	{{end -}}
     {{.Name}} {{.Type}} ` + "`{{.Tags}}` " +
-	"{{if not .MultilineComment}}{{if .ColumnComment}}// {{.ColumnComment}}{{end}}{{end}}" +
+	"{{if not .HasDoc}}{{if not .MultilineComment}}{{if .ColumnComment}}// {{.ColumnComment}}{{end}}{{end}}{{end}}" +
 	`{{end}}
 }
+`
 
+// ModelNameConst constants of column and index names of model
+const ModelNameConst = `
+{{if .SortFields}}
+// column names of {{.ModelStructName}}
+const (
+	{{range .SortFields}}Column{{$.ModelStructName}}{{.Name}} = "{{.ColumnName}}"
+	{{end}}
+)
+{{end}}{{if .NamedIndexes}}
+// index names of {{.ModelStructName}}
+const (
+	{{range .NamedIndexes}}Index{{$.ModelStructName}}{{.Name}} = "{{.Index}}"
+	{{end}}
+)
+{{end}}
 `
 
 // ModelMethod model struct DIY method
@@ -37,3 +87,346 @@ const ModelMethod = `
 {{if .Doc -}}// {{.DocComment -}}{{end}}
 func ({{.GetBaseStructTmpl}}){{.MethodName}}({{.GetParamInTmpl}})({{.GetResultParamInTmpl}}){{.Body}}
 `
+
+// ModelEnum named type of model field with its constants, set type is slice with Scan/Value of comma-separated values
+const ModelEnum = `
+{{if .Set}}
+// {{.Name}} values of set column {{.ColumnName}}, stored as comma-separated values
+type {{.Name}} []{{.Type}}
+
+const (
+	{{range .Values}}{{if .Comment}}// {{.Name}} {{.Comment}}
+	{{end}}{{.Name}} = {{.Literal}}
+	{{end}}
+)
+
+// Scan implements sql.Scanner, split comma-separated values
+func (s *{{.Name}}) Scan(value interface{}) error {
+	var str string
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return fmt.Errorf("unsupported type %T of {{.Name}}", value)
+	}
+	if str == "" {
+		*s = {{.Name}}{}
+		return nil
+	}
+	*s = strings.Split(str, ",")
+	return nil
+}
+
+// Value implements driver.Valuer, join values with comma
+func (s {{.Name}}) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+// Contains whether value is in s
+func (s {{.Name}}) Contains(value {{.Type}}) bool {
+	for _, v := range s {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid whether all values in s are {{.Name}} values
+func (s {{.Name}}) Valid() bool {
+	for _, v := range s {
+		switch v {
+		case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Name}}{{end}}:
+		default:
+			return false
+		}
+	}
+	return true
+}
+{{else}}
+// {{.Name}} values of column {{.ColumnName}}
+type {{.Name}} {{.Type}}
+
+const (
+	{{range .Values}}{{if .Comment}}// {{.Name}} {{.Comment}}
+	{{end}}{{.Name}} {{$.Name}} = {{.Literal}}
+	{{end}}
+)
+
+// Valid whether v is one of {{.Name}} values
+func (v {{.Name}}) Valid() bool {
+	switch v {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Name}}{{end}}:
+		return true
+	default:
+		return false
+	}
+}
+{{end}}`
+
+// ModelLookup constants of ids of rows of lookup table named by their codes
+const ModelLookup = `
+
+// ids of rows of {{.Name}} by {{.ColumnName}}, loaded from lookup table when generating
+const (
+	{{range .Values}}{{.Name}} = {{.Literal}}
+	{{end}}
+)
+`
+
+// ModelBinaryUUID named uuid type of binary(16) field, which is stored as 16 bytes
+const ModelBinaryUUID = `
+
+// {{.Name}} uuid of column {{.ColumnName}}, stored as 16 bytes
+type {{.Name}} uuid.UUID
+
+// Scan implements sql.Scanner
+func (u *{{.Name}}) Scan(value interface{}) error {
+	return (*uuid.UUID)(u).Scan(value)
+}
+
+// Value implements driver.Valuer, return 16 bytes of uuid
+func (u {{.Name}}) Value() (driver.Value, error) {
+	return uuid.UUID(u).MarshalBinary()
+}
+
+// String return string form of uuid, xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func (u {{.Name}}) String() string {
+	return uuid.UUID(u).String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (u {{.Name}}) MarshalText() ([]byte, error) {
+	return uuid.UUID(u).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (u *{{.Name}}) UnmarshalText(data []byte) error {
+	return (*uuid.UUID)(u).UnmarshalText(data)
+}
+`
+
+// ModelGeometry named orb geometry type of spatial field, postgis stores ewkb, mysql stores wkb prefixed with 4 bytes srid
+const ModelGeometry = `
+
+// {{.Name}} geometry of column {{.ColumnName}}, SRID is spatial reference id of {{.Field}}, e.g. 4326
+type {{.Name}} struct {
+	{{.Type}}
+	SRID int
+}
+
+// Scan implements sql.Scanner, decode {{if .PostGIS}}(hex) ewkb{{else}}wkb prefixed with srid{{end}}
+func (g *{{.Name}}) Scan(value interface{}) error {
+	*g = {{.Name}}{}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		value = []byte(v)
+	}
+	s := ewkb.{{if .PostGIS}}Scanner{{else}}ScannerPrefixSRID{{end}}({{if .Interface}}nil{{else}}&g.{{.Field}}{{end}})
+	if err := s.Scan(value); err != nil {
+		return err
+	}
+	{{if .Interface}}g.Geometry = s.Geometry
+	{{end}}g.SRID = s.SRID
+	return nil
+}
+
+// Value implements driver.Valuer, encode {{if .PostGIS}}hex ewkb{{else}}wkb prefixed with srid{{end}}
+func (g {{.Name}}) Value() (driver.Value, error) {
+	{{if .Interface}}if g.Geometry == nil {
+		return nil, nil
+	}
+	{{end}}srid := g.SRID
+	{{if .SRID}}if srid == 0 {
+		srid = {{.SRID}}
+	}
+	{{end}}{{if .PostGIS}}data, err := ewkb.Marshal(g.{{.Field}}, srid)
+	if err != nil {
+		return nil, err
+	}
+	return hex.EncodeToString(data), nil{{else}}return ewkb.ValuePrefixSRID(g.{{.Field}}, srid).Value(){{end}}
+}
+`
+
+// ModelUUIDHook BeforeCreate hook of model populating zero uuid primary keys
+const ModelUUIDHook = `
+
+// BeforeCreate populate zero uuid primary key with uuid.New()
+func ({{.S}} *{{.ModelStructName}}) BeforeCreate(tx *gorm.DB) error {
+	{{range .UUIDKeys}}{{if .Pointer}}if {{$.S}}.{{.Name}} == nil || *{{$.S}}.{{.Name}} == {{.Nil}} {
+		v := {{.New}}
+		{{$.S}}.{{.Name}} = &v
+	}
+	{{else}}if {{$.S}}.{{.Name}} == {{.Nil}} {
+		{{$.S}}.{{.Name}} = {{.New}}
+	}
+	{{end}}{{end}}return nil
+}
+`
+
+// ModelHookStub stubs of gorm hooks of model, generated once and edited by users
+const ModelHookStub = `
+package {{.StructInfo.Package}}
+
+import "gorm.io/gorm"
+{{range .Hooks}}
+// {{.}} gorm hook of {{$.ModelStructName}}, this file is generated once and not overwritten by regeneration
+func ({{$.S}} *{{$.ModelStructName}}) {{.}}(tx *gorm.DB) error {
+	return nil
+}
+{{end}}`
+
+// ModelUTCHook BeforeSave and AfterFind hooks of model converting time fields to UTC
+const ModelUTCHook = `
+
+// BeforeSave convert time fields to UTC before save
+func ({{.S}} *{{.ModelStructName}}) BeforeSave(tx *gorm.DB) error {
+	{{.S}}.toUTC()
+	return nil
+}
+
+// AfterFind convert time fields to UTC after find
+func ({{.S}} *{{.ModelStructName}}) AfterFind(tx *gorm.DB) error {
+	{{.S}}.toUTC()
+	return nil
+}
+
+func ({{.S}} *{{.ModelStructName}}) toUTC() {
+	{{- range .UTCFields}}
+	{{if .Pointer}}if {{$.S}}.{{.Name}} != nil {
+		t := {{$.S}}.{{.Name}}.UTC()
+		{{$.S}}.{{.Name}} = &t
+	}{{else}}{{$.S}}.{{.Name}}{{.Value}} = {{$.S}}.{{.Name}}{{.Value}}.UTC(){{end}}
+	{{- end}}
+}
+`
+
+// ModelString String method of model formatting column fields for logging
+const ModelString = `
+
+// String implements fmt.Stringer, format column fields of {{.ModelStructName}} for logging
+func ({{.S}} *{{.ModelStructName}}) String() string {
+	if {{.S}} == nil {
+		return "<nil>"
+	}
+	var b strings.Builder
+	b.WriteString("{{.ModelStructName}}{")
+	{{- range $i, $f := .MethodFields}}{{if not .Relation}}
+	b.WriteString("{{if $i}}, {{end}}{{.Name}}:")
+	{{if .Pointer}}if {{$.S}}.{{.Name}} == nil {
+		b.WriteString("<nil>")
+	} else {
+		fmt.Fprint(&b, *{{$.S}}.{{.Name}})
+	}{{else}}fmt.Fprint(&b, {{$.S}}.{{.Name}}){{end}}
+	{{- end}}{{end}}
+	b.WriteString("}")
+	return b.String()
+}
+`
+
+// ModelIsZero IsZero method of model
+const ModelIsZero = `
+
+// IsZero whether {{.ModelStructName}} is nil or all its fields are zero values
+func ({{.S}} *{{.ModelStructName}}) IsZero() bool {
+	return {{.S}} == nil || reflect.ValueOf(*{{.S}}).IsZero()
+}
+`
+
+// ModelPrimaryKey PrimaryKey accessor of model with single column primary key
+const ModelPrimaryKey = `
+
+// PrimaryKey return primary key {{.PrimaryKey.ColumnName}} of {{.ModelStructName}}
+func ({{.S}} *{{.ModelStructName}}) PrimaryKey() {{.PrimaryKey.Type}} {
+	return {{.S}}.{{.PrimaryKey.Name}}
+}
+`
+
+// ModelClone Clone method of model copying pointer, slice and map fields
+const ModelClone = `
+
+// Clone return deep copy of {{.ModelStructName}}, values of pointer fields and elements of slice and map fields
+// are copied, relations are copied shallowly
+func ({{.S}} *{{.ModelStructName}}) Clone() *{{.ModelStructName}} {
+	if {{.S}} == nil {
+		return nil
+	}
+	c := *{{.S}}
+	{{- range .MethodFields}}{{if not .Relation}}
+	{{- if eq .Type "*big.Int"}}
+	if {{$.S}}.{{.Name}} != nil {
+		c.{{.Name}} = new(big.Int).Set({{$.S}}.{{.Name}})
+	}
+	{{- else if .Pointer}}
+	if {{$.S}}.{{.Name}} != nil {
+		v := *{{$.S}}.{{.Name}}
+		c.{{.Name}} = &v
+	}
+	{{- else if .Slice}}
+	c.{{.Name}} = append({{$.S}}.{{.Name}}[:0:0], {{$.S}}.{{.Name}}...)
+	{{- else if .Map}}
+	if {{$.S}}.{{.Name}} != nil {
+		c.{{.Name}} = make({{.Type}}, len({{$.S}}.{{.Name}}))
+		for k, v := range {{$.S}}.{{.Name}} {
+			c.{{.Name}}[k] = v
+		}
+	}
+	{{- end}}{{end}}{{end}}
+	return &c
+}
+`
+
+// ModelValidate Validate method of model checking fields against constraints of their columns
+const ModelValidate = `
+
+// Validate check fields of {{.ModelStructName}} against constraints of {{if .TableName}}table {{.TableName}}{{else}}columns{{end}},
+// return gen.ValidationErrors of all violations
+func ({{.S}} *{{.ModelStructName}}) Validate() error {
+	var errs gen.ValidationErrors
+	{{- range .ValidateFields}}
+	{{- $field := printf "Model: %q, Field: %q, Column: %q" $.ModelStructName .Name .ColumnName}}
+	{{- $value := printf "%s.%s" $.S .Name}}{{if .Pointer}}{{$value = printf "*%s" $value}}{{end}}
+	{{- if eq .Required "string"}}
+	if {{$.S}}.{{.Name}} == "" {
+		errs = append(errs, &gen.ValidationError{ {{- $field}}, Rule: gen.ValidationRequired})
+	}
+	{{- else if eq .Required "time"}}
+	if {{$.S}}.{{.Name}}.IsZero() {
+		errs = append(errs, &gen.ValidationError{ {{- $field}}, Rule: gen.ValidationRequired})
+	}
+	{{- else if eq .Required "slice"}}
+	if len({{$.S}}.{{.Name}}) == 0 {
+		errs = append(errs, &gen.ValidationError{ {{- $field}}, Rule: gen.ValidationRequired})
+	}
+	{{- end}}
+	{{- if .MaxLength}}
+	if {{if .Pointer}}{{$.S}}.{{.Name}} != nil && {{end}}utf8.RuneCountInString({{$value}}) > {{.MaxLength}} {
+		errs = append(errs, &gen.ValidationError{ {{- $field}}, Rule: gen.ValidationMaxLength, Limit: {{.MaxLength}}})
+	}
+	{{- end}}
+	{{- if .Min}}
+	if {{if .Pointer}}{{$.S}}.{{.Name}} != nil && {{end}}{{$value}} < {{.Min}} {
+		errs = append(errs, &gen.ValidationError{ {{- $field}}, Rule: gen.ValidationMin, Limit: {{.Min}}, Value: {{$value}}})
+	}
+	{{- end}}
+	{{- if .Max}}
+	if {{if .Pointer}}{{$.S}}.{{.Name}} != nil && {{end}}{{$value}} > {{.Max}} {
+		errs = append(errs, &gen.ValidationError{ {{- $field}}, Rule: gen.ValidationMax, Limit: {{.Max}}, Value: {{$value}}})
+	}
+	{{- end}}
+	{{- if .Enum}}
+	if {{if .Pointer}}{{$.S}}.{{.Name}} != nil && {{else if .EnumZero}}{{$.S}}.{{.Name}} != {{.EnumZero}} && {{end}}!{{$.S}}.{{.Name}}.Valid() {
+		errs = append(errs, &gen.ValidationError{ {{- $field}}, Rule: gen.ValidationEnum, Value: {{$value}}})
+	}
+	{{- end}}
+	{{- end}}
+	return errs.Err()
+}
+`
@@ -21,7 +21,8 @@ func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 // QueryMethod query method template
 const QueryMethod = `
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
-	return &Query{
+	{{if .Tracing}}opts = append([]gen.DOOption{gen.WithTracing(tracing.Global())}, opts...)
+	{{end}}return &Query{
 		db: db,
 		{{range $name,$d :=.Data -}}
 		{{$d.ModelStructName}}: new{{$d.ModelStructName}}(db,opts...),
@@ -83,6 +84,18 @@ func (q *Query) Transaction(fc func(tx *Query) error, opts ...*sql.TxOptions) er
 	return q.db.Transaction(func(tx *gorm.DB) error { return fc(q.clone(tx)) }, opts...)
 }
 
+func (q *Query) TransactionContext(ctx context.Context, fc func(tx *Query) error, opts ...*sql.TxOptions) error {
+	return q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error { return fc(q.clone(tx)) }, opts...)
+}
+
+func (q *Query) SavePoint(name string) error {
+	return q.db.SavePoint(name).Error
+}
+
+func (q *Query) RollbackTo(name string) error {
+	return q.db.RollbackTo(name).Error
+}
+
 func (q *Query) Begin(opts ...*sql.TxOptions) *QueryTx {
 	tx := q.db.Begin(opts...)
 	return &QueryTx{Query: q.clone(tx), Error: tx.Error}
@@ -101,18 +114,10 @@ func (q *QueryTx) Rollback() error {
 	return q.db.Rollback().Error
 }
 
-func (q *QueryTx) SavePoint(name string) error {
-	return q.db.SavePoint(name).Error
-}
-
-func (q *QueryTx) RollbackTo(name string) error {
-	return q.db.RollbackTo(name).Error
-}
-
 `
 
-// QueryMethodTest query method test template
-const QueryMethodTest = `
+// UnitTestSQLiteDB database of unit tests in SQLite file
+const UnitTestSQLiteDB = `
 
 const dbName = "gen_test.db"
 
@@ -133,7 +138,45 @@ func InitializeDB() {
 		}
 	})
 }
+`
+
+// UnitTestContainerDB database of unit tests started by testcontainers-go, SQLite file is used if it fails
+const UnitTestContainerDB = `
+
+const dbName = "gen_test.db"
+
+var db *gorm.DB
+var once sync.Once
+
+func init() {
+	InitializeDB()
+	db.AutoMigrate(&_another{})
+}
+
+// InitializeDB start {{.Dialect}} by testcontainers-go, SQLite file is used instead if it fails, e.g. docker is
+// unavailable. Container is removed by reaper of testcontainers-go after tests
+func InitializeDB() {
+	once.Do(func() {
+		ctx := context.Background()
+		container, err := {{.Alias}}.Run(ctx, "{{.Image}}"{{range .Options}}, {{.}}{{end}})
+		if err == nil {
+			var dsn string
+			if dsn, err = container.ConnectionString(ctx{{range .ConnArgs}}, "{{.}}"{{end}}); err == nil {
+				db, err = gorm.Open({{.Driver}}.Open(dsn), &gorm.Config{})
+			}
+		}
+		if err != nil {
+			fmt.Printf("start {{.Dialect}} by testcontainers fail, fallback to sqlite: %s\n", err)
+			if db, err = gorm.Open(sqlite.Open(dbName), &gorm.Config{}); err != nil {
+				panic(fmt.Errorf("open sqlite %q fail: %w", dbName, err))
+			}
+		}
+	})
+}
+`
 
+// QueryMethodTest query method test template, database is initialized by UnitTestSQLiteDB or UnitTestContainerDB
+const QueryMethodTest = `
 func assert(t *testing.T, methodName string, res, exp interface{}) {
 	if !reflect.DeepEqual(res, exp) {
 		t.Errorf("%v() gotResult = %v, want %v", methodName, res, exp)
@@ -203,5 +246,19 @@ func Test_Transaction(t *testing.T) {
 	if err != nil {
 		t.Errorf("query tx Rollback fail: %s", err)
 	}
+
+	err = query.TransactionContext(context.Background(), func(tx *Query) error {
+		if err := tx.SavePoint("point"); err != nil {
+			return err
+		}
+		if err := tx.RollbackTo("point"); err != nil {
+			return err
+		}
+		_ = tx.TransactionContext(context.Background(), func(nested *Query) error { return errors.New("rollback nested") })
+		return tx.Transaction(func(nested *Query) error { return nil })
+	})
+	if err != nil {
+		t.Errorf("query.TransactionContext execute fail: %s", err)
+	}
 }
 `
@@ -7,7 +7,7 @@ const (
 		{{.QueryStructName}}Do
 		` + fields + `
 	}
-	` + tableMethod + asMethond + updateFieldMethod + getFieldMethod + fillFieldMapMethod + cloneMethod + replaceMethod + relationship + defineMethodStruct
+	` + tableMethod + asMethond + updateFieldMethod + getFieldMethod + fillFieldMapMethod + cloneMethod + replaceMethod + jsonQueryMethod + relationship + defineMethodStruct
 
 	// TableQueryStructWithContext table query struct with context
 	TableQueryStructWithContext = createMethod + `
@@ -25,7 +25,7 @@ const (
 
 	func ({{.S}} {{.QueryStructName}}) Columns(cols ...field.Expr) gen.Columns { return {{.S}}.{{.QueryStructName}}Do.Columns(cols...) }
 
-	` + getFieldMethod + fillFieldMapMethod + cloneMethod + replaceMethod + relationship + defineMethodStruct
+	` + getFieldMethod + fillFieldMapMethod + cloneMethod + replaceMethod + jsonQueryMethod + relationship + defineMethodStruct
 
 	// TableQueryIface table query interface
 	TableQueryIface = defineDoInterface
@@ -37,7 +37,11 @@ const (
 		_{{.QueryStructName}} := {{.QueryStructName}}{}
 	
 		_{{.QueryStructName}}.{{.QueryStructName}}Do.UseDB(db,opts...)
-		_{{.QueryStructName}}.{{.QueryStructName}}Do.UseModel(&{{.StructInfo.Package}}.{{.StructInfo.Type}}{})
+		_{{.QueryStructName}}.{{.QueryStructName}}Do.UseModel(&{{.StructInfo.Package}}.{{.StructInfo.Type}}{}){{with .TenantField}}
+		_ = _{{$.QueryStructName}}.{{$.QueryStructName}}Do.UseTenant("{{.ColumnName}}"){{end}}{{if .HasAuditColumn}}
+		_ = _{{.QueryStructName}}.{{.QueryStructName}}Do.UseAudit("{{.CreatedByColumn}}", "{{.UpdatedByColumn}}"){{end}}{{if .BatchSize}}
+		_{{.QueryStructName}}.{{.QueryStructName}}Do.UseBatchSize({{.BatchSize}}){{end}}{{with .VersionField}}
+		_ = _{{$.QueryStructName}}.{{$.QueryStructName}}Do.UseVersion("{{.ColumnName}}"){{end}}
 	
 		tableName := _{{.QueryStructName}}.{{.QueryStructName}}Do.TableName()
 		_{{$.QueryStructName}}.ALL = field.NewAsterisk(tableName)
@@ -62,12 +66,14 @@ const (
 	ALL field.Asterisk
 	{{range .Fields -}}
 		{{if not .IsRelation -}}
-			{{if .MultilineComment -}}
+			{{if .HasDoc -}}
+			{{.Doc}}
+			{{else if .MultilineComment -}}
 			/*
 {{.ColumnComment}}
     		*/
 			{{end -}}
-			{{- if .ColumnName -}}{{.Name}} field.{{.GenType}}{{if not .MultilineComment}}{{if .ColumnComment}}// {{.ColumnComment}}{{end}}{{end}}{{- end -}}
+			{{- if .ColumnName -}}{{.Name}} field.{{.GenType}}{{if not .HasDoc}}{{if not .MultilineComment}}{{if .ColumnComment}}// {{.ColumnComment}}{{end}}{{end}}{{end}}{{- end -}}
 		{{- else -}}
 			{{.Relation.Name}} {{$.QueryStructName}}{{.Relation.RelationshipName}}{{.Relation.Name}}
 		{{end}}
@@ -125,11 +131,20 @@ func ({{.S}} *{{.QueryStructName}}) GetFieldByName(fieldName string) (field.Orde
 	return _oe,ok
 }
 `
+	jsonQueryMethod = `{{range .Fields}}{{if and .IsJSON .ColumnName (not .IsRelation)}}
+// {{.Name}}JSONQuery JSON query expression of column {{.ColumnName}}, e.g. Where(gen.Cond({{$.S}}.{{.Name}}JSONQuery().HasKey("name"))...)
+func ({{$.S}} {{$.QueryStructName}}) {{.Name}}JSONQuery() *datatypes.JSONQueryExpression {
+	return datatypes.JSONQuery("{{.ColumnName}}")
+}
+{{end}}{{end}}`
 	relationship = `{{range .Fields}}{{if .IsRelation}}` +
 		`{{- $relation := .Relation }}{{- $relationship := $relation.RelationshipName}}` +
 		relationStruct + relationTx +
 		`{{end}}{{end}}`
-	defineMethodStruct = `type {{.QueryStructName}}Do struct { gen.DO }`
+	defineMethodStruct = `{{if .GenericDAO}}
+type {{.QueryStructName}}Do struct {
+	genericDo[{{.StructInfo.Package}}.{{.StructInfo.Type}}, {{.ReturnObject}}, *{{.QueryStructName}}Do]
+}{{else}}type {{.QueryStructName}}Do struct { gen.DO }{{end}}`
 
 	fillFieldMapMethod = `
 func ({{.S}} *{{.QueryStructName}}) fillFieldMap() {
@@ -203,6 +218,27 @@ type I{{.ModelStructName}}Do interface {
 	Returning(value interface{}, columns ...string) I{{.ModelStructName}}Do
 	UnderlyingDB() *gorm.DB
 	schema.Tabler
+	{{if .HasExistsMethod}}` + existsMethodIface + `{{end}}
+	{{if .HasEachMethod}}` + eachMethodIface + `{{end}}
+	{{if .HasLockingMethod}}` + lockingMethodIface + `{{end}}
+	{{if .HasCacheMethod}}` + cacheMethodIface + `{{end}}
+	{{if .HasResolverMethod}}` + resolverMethodIface + `{{end}}
+	{{if .HasPreloadMethod}}` + preloadMethodIface + `{{end}}
+	{{if .HasFilterMethod}}ApplyFilter(f *{{.ModelStructName}}Filter) I{{.ModelStructName}}Do{{end}}
+	{{if and .HasSortMethod .SortFields}}OrderBy(orders ...gen.Order[{{.ModelStructName}}Field]) I{{.ModelStructName}}Do
+	SelectFields(fields ...{{.ModelStructName}}Field) I{{.ModelStructName}}Do{{end}}
+	{{if .HasBatchMethod}}` + batchMethodIface + `{{end}}
+	{{if .HasReturningMethod}}UpdateReturning(columns ...field.AssignExpr) (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error)
+	DeleteReturning() (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error){{end}}
+	{{if .HasAffectedMethod}}` + affectedMethodIface + `{{end}}
+	{{if .HasImportExportMethod}}` + importExportMethodIface + `{{end}}
+	{{if .CompositeKey}}` + compositeKeyMethodIface + `{{end}}
+	{{if .Temporal}}AsOf(t time.Time) I{{.ModelStructName}}Do{{end}}
+	{{if .SoftDeleteField}}` + softDeleteMethodIface + `{{end}}
+	{{if .HasHintMethod}}{{if .NamedIndexes}}UseIndex(indexes ...{{.ModelStructName}}Index) I{{.ModelStructName}}Do
+	ForceIndex(indexes ...{{.ModelStructName}}Index) I{{.ModelStructName}}Do
+	IgnoreIndex(indexes ...{{.ModelStructName}}Index) I{{.ModelStructName}}Do
+	{{end}}OptimizerHint(contents ...string) I{{.ModelStructName}}Do{{end}}
 
 	{{range .Interfaces -}}
 	{{.FuncSign}}
@@ -211,6 +247,54 @@ type I{{.ModelStructName}}Do interface {
 `
 )
 
+const (
+	existsMethodIface = `
+	Exists(conds ...gen.Condition) (bool, error)
+	{{with .PrimaryKey}}{{if .GenValueType}}ExistsBy{{.Name}}(value {{.GenValueType}}) (bool, error){{end}}{{end}}
+	{{range .IndexedFields}}{{if .GenValueType}}CountBy{{.Name}}(value {{.GenValueType}}) (int64, error)
+	{{end}}{{end}}`
+	eachMethodIface = `
+	Each(ctx context.Context, fc func(*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error) error
+	EachInBatches(batchSize int, fc func(results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batch int) error) error`
+	lockingMethodIface = `
+	ForUpdate(opts ...gen.LockOption) I{{.ModelStructName}}Do
+	ForShare(opts ...gen.LockOption) I{{.ModelStructName}}Do`
+	resolverMethodIface = `
+	ReadOnly() I{{.ModelStructName}}Do
+	Primary() I{{.ModelStructName}}Do
+	UseResolver(name string) I{{.ModelStructName}}Do
+	Sticky(ctx context.Context) I{{.ModelStructName}}Do`
+	preloadMethodIface = `
+	{{range .PreloadRelations}}With{{.}}(nested ...gen.Preload) I{{$.ModelStructName}}Do
+	{{end}}`
+	cacheMethodIface = `
+	{{with .PrimaryKey}}{{if .GenValueType}}GetBy{{.Name}}(value {{.GenValueType}}) (*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error){{end}}{{end}}`
+	affectedMethodIface = `
+	UpdateAffected(column field.Expr, value interface{}) (rowsAffected int64, err error)
+	UpdateSimpleAffected(columns ...field.AssignExpr) (rowsAffected int64, err error)
+	UpdatesAffected(value interface{}) (rowsAffected int64, err error)
+	UpdateColumnAffected(column field.Expr, value interface{}) (rowsAffected int64, err error)
+	UpdateColumnSimpleAffected(columns ...field.AssignExpr) (rowsAffected int64, err error)
+	UpdateColumnsAffected(value interface{}) (rowsAffected int64, err error)
+	DeleteAffected(models ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (rowsAffected int64, err error)`
+	compositeKeyMethodIface = `
+	FindByPK(pk {{.ModelStructName}}PK) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	DeleteByPK(pk {{.ModelStructName}}PK) (info gen.ResultInfo, err error)
+	UpsertByPK(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error`
+	softDeleteMethodIface = `
+	{{with .PrimaryKey}}{{if .GenValueType}}FindBy{{.Name}}Unscoped(value {{.GenValueType}}) (*{{$.StructInfo.Package}}.{{$.StructInfo.Type}}, error)
+	Restore(values ...{{.GenValueType}}) (info gen.ResultInfo, err error){{end}}{{end}}
+	HardDelete(models ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (result gen.ResultInfo, err error)`
+	importExportMethodIface = `
+	ExportCSV(w io.Writer, conds ...gen.Condition) error
+	ImportCSV(r io.Reader, batchSize int) (count int64, err error)
+	ExportJSONLines(w io.Writer, conds ...gen.Condition) error
+	ImportJSONLines(r io.Reader, batchSize int) (count int64, err error)`
+	batchMethodIface = `
+	UpdateColumnsInBatches(values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, columns ...field.Expr) (info gen.ResultInfo, err error)
+	{{with .PrimaryKey}}{{if .GenValueType}}DeleteBy{{.Name}}s(values ...{{.GenValueType}}) (info gen.ResultInfo, err error){{end}}{{end}}`
+)
+
 const (
 	relationStruct = `
 type {{$.QueryStructName}}{{$relationship}}{{$relation.Name}} struct{
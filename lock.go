@@ -0,0 +1,158 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+
+	"gorm.io/gen/internal/generate"
+)
+
+// lockFile fingerprints of generated files, see Config.LockFile
+type lockFile struct {
+	Config string            `json:"config"` // fingerprint of generator config, custom templates and file header
+	Tables map[string]string `json:"tables"` // generated file name -> fingerprint of table schema and methods
+}
+
+// loadLock read fingerprints of last generation and compute current ones
+func (g *Generator) loadLock() error {
+	if g.LockFile == "" {
+		return nil
+	}
+
+	g.lastLock = &lockFile{}
+	content, err := ioutil.ReadFile(g.LockFile)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return fmt.Errorf("read lock file fail: %w", err)
+	default:
+		if err = json.Unmarshal(content, g.lastLock); err != nil {
+			return fmt.Errorf("parse lock file %s fail: %w", g.LockFile, err)
+		}
+	}
+
+	g.lock = &lockFile{Config: g.configSum, Tables: make(map[string]string)}
+	if !g.Prune {
+		for name, sum := range g.lastLock.Tables { // keep tables not generated this time
+			g.lock.Tables[name] = sum
+		}
+	}
+	for _, meta := range g.models {
+		if g.lock.Tables[meta.FileName], err = g.fingerprint(meta); err != nil {
+			return err
+		}
+	}
+	for _, data := range g.Data {
+		if g.lock.Tables[data.FileName], err = g.fingerprint(data.QueryStructMeta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveLock write fingerprints of this generation
+func (g *Generator) saveLock() error {
+	if g.lock == nil {
+		return nil
+	}
+	content, err := json.MarshalIndent(g.lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.writeFile(g.LockFile, append(content, '\n'))
+}
+
+// unchanged whether file generated from meta is up to date, so it can be skipped
+func (g *Generator) unchanged(meta *generate.QueryStructMeta, fileName string) bool {
+	if g.lock == nil || g.ForceGenerate || g.lastLock.Config != g.lock.Config {
+		return false
+	}
+	last, ok := g.lastLock.Tables[meta.FileName]
+	if !ok || last != g.lock.Tables[meta.FileName] {
+		return false
+	}
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+// configFingerprint fingerprint of config affecting generated code, custom templates and file header,
+// paths are excluded to keep it same on different machines
+func (g *Generator) configFingerprint() (string, error) {
+	cfg := g.Config
+	cfg.ForceGenerate, cfg.Prune, cfg.Concurrency, cfg.ManifestFile, cfg.Verify = false, false, 0, "", ""
+	cfg.OutPath, cfg.OutFile, cfg.LockFile, cfg.ModelTemplateDir, cfg.QueryTemplateDir, cfg.FileHeader = "", "", "", "", "", ""
+	return fingerprint(struct {
+		Config
+		// func-valued options can't be marshaled, they are fingerprinted by names of their funcs together with
+		// version of gen implementing them, changes of their arguments are in fingerprint of fields of tables
+		TableModelOpts map[string][]string
+		Version        string
+		Templates      map[string]string
+		Header         string
+	}{
+		Config:         cfg,
+		TableModelOpts: optionNames(cfg.TableModelOpts),
+		Version:        genVersion(),
+		Templates:      templateContents(g.ModelTemplateDir, g.QueryTemplateDir),
+		Header:         string(g.header),
+	})
+}
+
+// optionNames names of options by table name, e.g. gen.FieldType.func1
+func optionNames(tableOpts map[string][]ModelOpt) map[string][]string {
+	names := make(map[string][]string, len(tableOpts))
+	for table, opts := range tableOpts {
+		for _, opt := range opts {
+			name := fmt.Sprintf("%T", opt)
+			if v := reflect.ValueOf(opt); v.Kind() == reflect.Func && !v.IsNil() {
+				name = runtime.FuncForPC(v.Pointer()).Name()
+			}
+			names[table] = append(names[table], name)
+		}
+	}
+	return names
+}
+
+// fingerprint of model's table schema, fields and methods
+func (g *Generator) fingerprint(meta *generate.QueryStructMeta) (string, error) {
+	methods := make([]string, 0, len(meta.ModelMethods))
+	for _, m := range meta.ModelMethods {
+		methods = append(methods, m.FuncSign()+m.Body)
+	}
+	if data, ok := g.Data[meta.ModelStructName]; ok {
+		for _, m := range data.Interfaces {
+			methods = append(methods, m.FuncSign()+m.Doc+m.SQLString)
+		}
+	}
+	for _, f := range meta.Fields {
+		f.Tags() // complete gorm tag as rendering does, so fingerprint is the same before and after rendering
+	}
+	v := map[string]interface{}{
+		"schema":  meta.TableSchema(),
+		"fields":  meta.Fields,
+		"methods": methods,
+	}
+	if meta.Lookup != nil { // rows of lookup table
+		v["lookup"] = meta.Lookup
+	}
+	sum, err := fingerprint(v)
+	if err != nil {
+		return "", fmt.Errorf("fingerprint model %s fail: %w", meta.ModelStructName, err)
+	}
+	return sum, nil
+}
+
+func fingerprint(v interface{}) (string, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
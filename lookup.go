@@ -0,0 +1,76 @@
+package gen
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// lookupMaxRows max rows of lookup table, larger tables are not reference data to be generated as constants
+const lookupMaxRows = 1000
+
+// loadLookup query rows of lookup table of meta into constants of its model, see Config.LookupTables
+func (g *Generator) loadLookup(meta *generate.QueryStructMeta) error {
+	spec, ok := g.LookupTables[meta.TableName]
+	if !ok {
+		return nil
+	}
+	if g.schema != nil {
+		g.db.Logger.Warn(context.Background(), "rows of lookup table %s cannot be loaded from schema json, skip it", meta.TableName)
+		return nil
+	}
+
+	idColumn, codeColumn := "id", "code"
+	if spec != "" {
+		if idColumn, codeColumn, ok = strings.Cut(spec, ":"); !ok {
+			return fmt.Errorf("lookup table %s should be <id column>:<code column>, got %q", meta.TableName, spec)
+		}
+	}
+	var idType string
+	for _, f := range meta.Fields {
+		if f.ColumnName == idColumn {
+			idType = strings.TrimPrefix(f.Type, "*")
+		}
+	}
+	if idType == "" {
+		return fmt.Errorf("lookup table %s has no column %s", meta.TableName, idColumn)
+	}
+
+	rows, err := g.db.Table(meta.TableName).Select([]string{idColumn, codeColumn}).Order(idColumn).Limit(lookupMaxRows + 1).Rows()
+	if err != nil {
+		return fmt.Errorf("query rows of lookup table %s fail: %w", meta.TableName, err)
+	}
+	defer rows.Close()
+
+	lookup := &model.Enum{Name: meta.ModelStructName, Type: idType, ColumnName: codeColumn}
+	for rows.Next() {
+		var id, code sql.NullString
+		if err = rows.Scan(&id, &code); err != nil {
+			return fmt.Errorf("scan rows of lookup table %s fail: %w", meta.TableName, err)
+		}
+		if !id.Valid || !code.Valid {
+			continue
+		}
+		literal := strconv.Quote(id.String)
+		if idType != "string" {
+			if _, err = strconv.ParseFloat(id.String, 64); err != nil {
+				return fmt.Errorf("id %q of lookup table %s is not number of %s", id.String, meta.TableName, idType)
+			}
+			literal = id.String
+		}
+		lookup.Append(id.String, literal, code.String, "")
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("query rows of lookup table %s fail: %w", meta.TableName, err)
+	}
+	if len(lookup.Values) > lookupMaxRows {
+		return fmt.Errorf("lookup table %s has more than %d rows", meta.TableName, lookupMaxRows)
+	}
+	meta.Lookup = lookup
+	return nil
+}
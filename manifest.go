@@ -0,0 +1,122 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Manifest generated files listed in manifest file, see Config.ManifestFile
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile file of generation
+type ManifestFile struct {
+	Path   string `json:"path"`            // slash separated path relative to directory of manifest file
+	Table  string `json:"table,omitempty"` // source table, empty if file is not generated from a table, e.g. gen.go
+	SHA256 string `json:"sha256"`          // hash of content
+}
+
+// manifestSuffixes suffixes of files generated from a table after its file name, e.g. users.gen.go
+var manifestSuffixes = []string{".gen_test.go", ".fake.gen.go", ".gen.go", "_hooks.go", ".proto", ".d.ts"}
+
+// ReadManifest read manifest file written by generator
+func ReadManifest(fileName string) (*Manifest, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err = json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest file %s fail: %w", fileName, err)
+	}
+	return &m, nil
+}
+
+// saveManifest write files written or skipped as unchanged by this generation, files of last manifest which still
+// exist are kept unless Prune is true
+func (g *Generator) saveManifest() error {
+	if g.ManifestFile == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(g.ManifestFile))
+	if err != nil {
+		return err
+	}
+
+	tables := g.fileTables()
+	files := make(map[string]ManifestFile)
+	if last, err := ReadManifest(g.ManifestFile); err == nil && !g.Prune {
+		for _, f := range last.Files {
+			files[f.Path] = f
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	summary := g.Summary()
+	for _, fileName := range append(summary.Written, summary.Skipped...) {
+		abs, err := filepath.Abs(fileName)
+		if err != nil {
+			return err
+		}
+		path, err := filepath.Rel(dir, abs)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(path)] = ManifestFile{Table: tables[manifestFileName(abs)]}
+	}
+
+	m := Manifest{Files: make([]ManifestFile, 0, len(files))}
+	for path, f := range files {
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		f.Path, f.SHA256 = path, hex.EncodeToString(sum[:])
+		m.Files = append(m.Files, f)
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.writeFile(g.ManifestFile, append(content, '\n'))
+}
+
+// fileTables file name of model -> table, see manifestFileName
+func (g *Generator) fileTables() map[string]string {
+	tables := make(map[string]string)
+	for _, meta := range g.models {
+		if meta != nil {
+			tables[meta.FileName] = meta.TableName
+		}
+	}
+	for _, data := range g.Data {
+		if _, ok := tables[data.FileName]; !ok {
+			tables[data.FileName] = data.TableName
+		}
+	}
+	return tables
+}
+
+// manifestFileName file name of model which file is generated from, e.g. users of users.gen_test.go
+func manifestFileName(fileName string) string {
+	base := filepath.Base(fileName)
+	for _, suffix := range manifestSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return strings.TrimSuffix(base, suffix)
+		}
+	}
+	return ""
+}
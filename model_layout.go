@@ -0,0 +1,188 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gorm.io/gen/internal/generate"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// model layouts, see Config.ModelLayout
+const (
+	modelLayoutTable  = "table"
+	modelLayoutSingle = "single"
+)
+
+// singleModelFileName name of file of all models of a package in single layout
+const singleModelFileName = "models"
+
+// modelPackage sub-package directory of model of table by ModelPackages, "" if it's in model package.
+// The longest matched pattern wins, e.g. user_profile_* over user_*
+func (cfg *Config) modelPackage(tableName string) string {
+	best := ""
+	for pattern := range cfg.ModelPackages {
+		if ok, _ := path.Match(pattern, tableName); ok && (len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best)) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return filepath.FromSlash(cfg.ModelPackages[best])
+}
+
+// modelPkgName package name of model of table, base of its sub-package or ModelPkgPath
+func (cfg *Config) modelPkgName(tableName string) string {
+	if dir := cfg.modelPackage(tableName); dir != "" {
+		return filepath.Base(dir)
+	}
+	return cfg.ModelPkgPath
+}
+
+// checkModelPackages check patterns and names of ModelPackages
+func (cfg *Config) checkModelPackages() error {
+	for pattern, dir := range cfg.ModelPackages {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q of model package: %w", pattern, err)
+		}
+		if name := path.Base(dir); dir == "" || path.IsAbs(dir) || strings.HasPrefix(path.Clean(dir), "..") || !token.IsIdentifier(name) {
+			return fmt.Errorf("invalid model package %q of pattern %q, should be relative directory named by identifier", dir, pattern)
+		}
+	}
+	return nil
+}
+
+// modelDir directory of model file of meta, ending with path separator
+func (g *Generator) modelDir(modelOutPath string, meta *generate.QueryStructMeta) string {
+	if dir := g.modelPackage(meta.TableName); dir != "" && meta.TableName != "" {
+		return filepath.Join(modelOutPath, dir) + string(os.PathSeparator)
+	}
+	return modelOutPath
+}
+
+// modelFile path of generated model file of meta, models.gen.go of its directory in single layout
+func (g *Generator) modelFile(modelOutPath string, meta *generate.QueryStructMeta) string {
+	if g.ModelLayout == modelLayoutSingle {
+		return g.modelDir(modelOutPath, meta) + singleModelFileName + ".gen.go"
+	}
+	return g.modelDir(modelOutPath, meta) + meta.FileName + ".gen.go"
+}
+
+// samePackage whether models of tables are in the same package, relations across packages are not generated
+func (g *Generator) samePackage(a, b *generate.QueryStructMeta) bool {
+	return g.modelPackage(a.TableName) == g.modelPackage(b.TableName)
+}
+
+// checkPackageConflicts report sub-package of models named the same as query struct, which is declared
+// in query files importing the sub-package
+func (g *Generator) checkPackageConflicts() error {
+	if len(g.ModelPackages) == 0 || len(g.Data) == 0 {
+		return nil
+	}
+	queryStructs := make(map[string]string, len(g.Data))
+	for _, data := range g.Data {
+		queryStructs[data.QueryStructName] = data.TableName
+	}
+	for _, dir := range g.ModelPackages {
+		name := path.Base(dir)
+		if table, ok := queryStructs[name]; ok {
+			return fmt.Errorf("model package %s conflicts with query struct of table %s, choose another package name, e.g. %smodel", dir, table, name)
+		}
+	}
+	return nil
+}
+
+// fillSubPackagePaths set import path of models in sub-packages by import path of model package, query and exported
+// code import them by it
+func (g *Generator) fillSubPackagePaths() {
+	if g.modelPkgPath == "" {
+		return
+	}
+	for _, meta := range g.models {
+		if meta == nil || !meta.Generated || meta.TableName == "" {
+			continue
+		}
+		if dir := g.modelPackage(meta.TableName); dir != "" {
+			meta.StructInfo.PkgPath = path.Join(g.modelPkgPath, filepath.ToSlash(dir))
+		}
+	}
+}
+
+// mergeModelFiles merge rendered model files of a package into one file, imports are deduplicated and unused
+// ones are removed by formatting
+func mergeModelFiles(pkgName string, files [][]byte) ([]byte, error) {
+	imports := make(map[string]bool)
+	var bodies bytes.Buffer
+	for _, content := range files {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "", content, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parse model file fail: %w", err)
+		}
+		end := f.Name.End()
+		for _, spec := range f.Imports {
+			imports[string(content[fset.Position(spec.Pos()).Offset:fset.Position(spec.End()).Offset])] = true
+		}
+		for _, decl := range f.Decls {
+			end = decl.End()
+		}
+		bodies.Write(content[fset.Position(end).Offset:])
+		bodies.WriteString("\n")
+	}
+
+	specs := make([]string, 0, len(imports))
+	for spec := range imports {
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+
+	var buf bytes.Buffer
+	buf.WriteString(tmpl.NotEditMark)
+	fmt.Fprintf(&buf, "\npackage %s\n\nimport (\n", pkgName)
+	for _, spec := range specs {
+		buf.WriteString("\t" + spec + "\n")
+	}
+	buf.WriteString(")\n")
+	buf.Write(bodies.Bytes())
+	return buf.Bytes(), nil
+}
+
+// outputMergedModels write models.gen.go of each package in single layout, models are sorted by name
+func (g *Generator) outputMergedModels(merged map[string]map[string][]byte) error {
+	for modelFile, models := range merged {
+		names := make([]string, 0, len(models))
+		for name := range models {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		files := make([][]byte, len(names))
+		for i, name := range names {
+			files[i] = models[name]
+		}
+
+		content, err := mergeModelFiles(filepath.Base(filepath.Dir(modelFile)), files)
+		if err != nil {
+			return err
+		}
+		if err = os.MkdirAll(filepath.Dir(modelFile), os.ModePerm); err != nil {
+			return err
+		}
+		header, err := g.provenance(nil)
+		if err != nil {
+			return err
+		}
+		if err = g.output(modelFile, append([]byte(header), content...)); err != nil {
+			return err
+		}
+		g.info(fmt.Sprintf("generate model file(%d models): %s", len(names), modelFile))
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// strategies of tables without primary key, see Config.NoPrimaryKey
+const (
+	noPrimaryKeySkip        = "skip"
+	noPrimaryKeyReadOnly    = "readOnly"
+	noPrimaryKeyUniqueIndex = "uniqueIndex"
+)
+
+// noPrimaryKey apply NoPrimaryKey strategy or logical key of LogicalKeys to model of table without primary key,
+// return false if the table is skipped
+func (g *Generator) noPrimaryKey(meta *generate.QueryStructMeta) (bool, error) {
+	for _, f := range meta.Fields {
+		if f.ColumnName != "" && f.IsPrimaryKey() {
+			return true, nil
+		}
+	}
+
+	strategy, index := g.NoPrimaryKey, g.LogicalKeys[meta.TableName]
+	if index != "" {
+		strategy = noPrimaryKeyUniqueIndex
+	}
+	switch strategy {
+	case noPrimaryKeySkip:
+		g.db.Logger.Warn(context.Background(), "table %s has no primary key, skip it", meta.TableName)
+		return false, nil
+	case noPrimaryKeyUniqueIndex:
+		columns, err := g.logicalKey(meta, index)
+		if err != nil {
+			return false, err
+		}
+		if len(columns) > 0 {
+			for _, f := range meta.Fields {
+				if f.ColumnName != "" && columns[f.ColumnName] {
+					f.GORMTag.Remove(field.TagKeyGormNotNull).Set(field.TagKeyGormPrimaryKey)
+				}
+			}
+			return true, nil
+		}
+		g.db.Logger.Warn(context.Background(), "table %s has no primary key or unique index of NOT NULL columns, generate read-only model", meta.TableName)
+		fallthrough
+	case noPrimaryKeyReadOnly:
+		for _, f := range meta.Fields {
+			if f.ColumnName != "" {
+				f.GORMTag.Remove(field.TagKeyGormWritePermission).Set(field.TagKeyGormReadOnly)
+			}
+		}
+	}
+	return true, nil
+}
+
+// logicalKey columns of unique index used as logical key of table: index of name, or the first unique index by name
+// whose columns are all generated NOT NULL fields if name is empty, return nil if there is no such index
+func (g *Generator) logicalKey(meta *generate.QueryStructMeta, name string) (map[string]bool, error) {
+	notNull := make(map[string]bool, len(meta.Fields))
+	for _, f := range meta.Fields {
+		if f.ColumnName != "" {
+			_, notNull[f.ColumnName] = f.GORMTag[field.TagKeyGormNotNull]
+		}
+	}
+
+indexes:
+	for _, idx := range g.uniqueIndexes(meta.TableName) {
+		if name != "" && idx.Name != name {
+			continue
+		}
+		columns := make(map[string]bool, len(idx.Columns))
+		for _, column := range idx.Columns {
+			if isNotNull, ok := notNull[column]; !ok || (name == "" && !isNotNull) {
+				continue indexes
+			}
+			columns[column] = true
+		}
+		return columns, nil
+	}
+	if name != "" {
+		return nil, fmt.Errorf("logical key %s of table %s is not unique index of generated columns", name, meta.TableName)
+	}
+	return nil, nil
+}
+
+// uniqueIndexes unique indexes of table sorted by name, from schema JSON or db
+func (g *Generator) uniqueIndexes(tableName string) (indexes []*model.IndexSchema) {
+	if g.schema != nil {
+		if t := g.schema.Table(tableName); t != nil {
+			for _, idx := range t.Indexes {
+				if idx.Unique && !idx.PrimaryKey {
+					indexes = append(indexes, idx)
+				}
+			}
+		}
+	} else {
+		dbIndexes, err := generate.GetTableIndexes(g.db, g.schemaName(), tableName)
+		if err != nil { // not supported by some dialects
+			g.db.Logger.Warn(context.Background(), "get indexes of table %s fail: %s", tableName, err)
+		}
+		for _, idx := range dbIndexes {
+			unique, _ := idx.Unique()
+			pk, _ := idx.PrimaryKey()
+			if unique && !pk {
+				indexes = append(indexes, &model.IndexSchema{Name: idx.Name(), Columns: idx.Columns(), Unique: true})
+			}
+		}
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	return indexes
+}
@@ -0,0 +1,63 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// partitionsSQL query partitions of native partitioned tables in @schema(current schema if empty) with their parents
+// by dialect. Partitions of MySQL partitioned table are not tables, which is generated as one model already
+var partitionsSQL = map[string]string{
+	"postgres": "SELECT c.relname AS name, p.relname AS parent FROM pg_inherits i " +
+		"JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class p ON p.oid = i.inhparent " +
+		"JOIN pg_namespace n ON n.oid = c.relnamespace " +
+		"WHERE c.relispartition AND n.nspname = COALESCE(NULLIF(@schema, ''), CURRENT_SCHEMA())",
+}
+
+// skipPartitions remove partitions of native partitioned tables(e.g. events_p2024_01 of events) from tables,
+// the model of partitioned table covers rows of its partitions
+func (g *Generator) skipPartitions(tables []string) []string {
+	query, ok := partitionsSQL[g.db.Dialector.Name()]
+	if !ok || g.schema != nil {
+		return tables
+	}
+	var partitions []struct{ Name, Parent string }
+	if err := g.db.Raw(query, map[string]interface{}{"schema": g.schemaName()}).Scan(&partitions).Error; err != nil {
+		g.db.Logger.Warn(context.Background(), "query partitions of tables fail: %s", err)
+		return tables
+	}
+	parents := make(map[string]string, len(partitions))
+	for _, p := range partitions {
+		parents[p.Name] = p.Parent
+	}
+
+	kept, skipped := withoutPartitions(tables, parents)
+	roots := make([]string, 0, len(skipped))
+	for root := range skipped {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	for _, root := range roots {
+		g.info(fmt.Sprintf("skip %d partitions of partitioned table <%s>", skipped[root], root))
+	}
+	return kept
+}
+
+// withoutPartitions tables except partitions in parents(partition -> parent table), return number of skipped
+// partitions by root partitioned table, sub-partitions are counted for their root
+func withoutPartitions(tables []string, parents map[string]string) (kept []string, skipped map[string]int) {
+	skipped = make(map[string]int)
+	for _, table := range tables {
+		root, ok := parents[table]
+		if !ok {
+			kept = append(kept, table)
+			continue
+		}
+		for parent, ok := parents[root]; ok; parent, ok = parents[root] {
+			root = parent
+		}
+		skipped[root]++
+	}
+	return kept, skipped
+}
@@ -0,0 +1,183 @@
+package gen
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// piiSerializerName serializer of pii columns, see Config.PIIColumns
+const piiSerializerName = "pii"
+
+func init() {
+	schema.RegisterSerializer(piiSerializerName, piiSerializer{})
+}
+
+// PII value of sensitive column, masked in String and JSON output, use Plain to read the value
+type PII string
+
+// Plain return unmasked value
+func (p PII) Plain() string { return string(p) }
+
+// String implements fmt.Stringer, return masked value
+func (p PII) String() string { return MaskPII(string(p)) }
+
+// GoString implements fmt.GoStringer, return masked value for %#v
+func (p PII) GoString() string { return fmt.Sprintf("%q", MaskPII(string(p))) }
+
+// MarshalJSON return masked value
+func (p PII) MarshalJSON() ([]byte, error) {
+	return json.Marshal(MaskPII(string(p)))
+}
+
+// UnmarshalJSON read plain value
+func (p *PII) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*p = PII(s)
+	return nil
+}
+
+// MaskPII mask value of pii column in String and JSON output, keep the first and last characters by default,
+// e.g. alice@example.com -> a***************m
+var MaskPII = func(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 2 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[0]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1])
+}
+
+// Cipher encrypt and decrypt values of pii columns
+type Cipher interface {
+	Encrypt(plaintext []byte) (ciphertext []byte, err error)
+	Decrypt(ciphertext []byte) (plaintext []byte, err error)
+}
+
+var piiCipher struct {
+	sync.RWMutex
+	Cipher
+}
+
+// SetPIICipher set cipher of pii columns, which are stored as base64 of ciphertext
+func SetPIICipher(c Cipher) {
+	piiCipher.Lock()
+	defer piiCipher.Unlock()
+	piiCipher.Cipher = c
+}
+
+func getPIICipher() (Cipher, error) {
+	piiCipher.RLock()
+	defer piiCipher.RUnlock()
+	if piiCipher.Cipher == nil {
+		return nil, ErrMissingPIICipher
+	}
+	return piiCipher.Cipher, nil
+}
+
+// NewAESCipher return AES-GCM cipher with key of 16, 24 or 32 bytes, random nonce is prepended to ciphertext,
+// so the same value is encrypted differently and pii columns cannot be queried by equality
+func NewAESCipher(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesCipher{aead}, nil
+}
+
+type aesCipher struct{ aead cipher.AEAD }
+
+func (c aesCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c aesCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// piiSerializer encrypt value of PII(or string) field on write and decrypt it on read
+type piiSerializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (piiSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var encoded string
+		switch v := dbValue.(type) {
+		case []byte:
+			encoded = string(v)
+		case string:
+			encoded = v
+		default:
+			return fmt.Errorf("unsupported data %#v of pii column %s", dbValue, field.DBName)
+		}
+		c, err := getPIICipher()
+		if err != nil {
+			return err
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("decode pii column %s fail: %w", field.DBName, err)
+		}
+		plaintext, err := c.Decrypt(ciphertext)
+		if err != nil {
+			return fmt.Errorf("decrypt pii column %s fail: %w", field.DBName, err)
+		}
+		elem := fieldValue.Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+		}
+		elem.SetString(string(plaintext))
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value implements schema.SerializerInterface
+func (piiSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(fieldValue)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported type %T of pii column %s", fieldValue, field.DBName)
+	}
+	c, err := getPIICipher()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := c.Encrypt([]byte(rv.String()))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt pii column %s fail: %w", field.DBName, err)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
@@ -0,0 +1,99 @@
+package gen
+
+import (
+	"fmt"
+
+	"gorm.io/gen/internal/generate"
+)
+
+// Plugin hooks of generation lifecycle, which can change tables, models and generated files to apply conventions
+// without patching generator. Hook returning error fails generation, embed NopPlugin to implement some of hooks
+type Plugin interface {
+	// BeforeIntrospect called with tables of GenerateModels and GenerateAllTable, returned tables are introspected instead
+	BeforeIntrospect(tables []string) ([]string, error)
+	// AfterModel called once model is introspected from table, fields and tags of model can be changed
+	AfterModel(m Model) error
+	// BeforeRender called with rendered content of file before it's formatted and written, content can be replaced
+	BeforeRender(f *File) error
+	// AfterWrite called with path of file after it's written
+	AfterWrite(path string) error
+}
+
+// Model model of table passed to plugins
+type Model = *generate.QueryStructMeta
+
+// File generated file passed to plugins
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// NopPlugin Plugin whose hooks change nothing
+type NopPlugin struct{}
+
+// BeforeIntrospect implement Plugin
+func (NopPlugin) BeforeIntrospect(tables []string) ([]string, error) { return tables, nil }
+
+// AfterModel implement Plugin
+func (NopPlugin) AfterModel(Model) error { return nil }
+
+// BeforeRender implement Plugin
+func (NopPlugin) BeforeRender(*File) error { return nil }
+
+// AfterWrite implement Plugin
+func (NopPlugin) AfterWrite(string) error { return nil }
+
+// WithPlugins add plugins hooking generation, hooks are called in order of plugins and never concurrently
+func (cfg *Config) WithPlugins(plugins ...Plugin) {
+	cfg.plugins = append(cfg.plugins, plugins...)
+}
+
+func (g *Generator) beforeIntrospect(tables []string) ([]string, error) {
+	g.pluginMu.Lock()
+	defer g.pluginMu.Unlock()
+	for _, p := range g.plugins {
+		var err error
+		if tables, err = p.BeforeIntrospect(tables); err != nil {
+			return nil, fmt.Errorf("plugin BeforeIntrospect fail: %w", err)
+		}
+	}
+	return tables, nil
+}
+
+func (g *Generator) afterModel(meta *generate.QueryStructMeta) error {
+	g.pluginMu.Lock()
+	defer g.pluginMu.Unlock()
+	for _, p := range g.plugins {
+		if err := p.AfterModel(meta); err != nil {
+			return fmt.Errorf("plugin AfterModel of table <%s> fail: %w", meta.TableName, err)
+		}
+	}
+	return nil
+}
+
+// beforeRender content of file replaced by plugins
+func (g *Generator) beforeRender(fileName string, content []byte) ([]byte, error) {
+	if len(g.plugins) == 0 {
+		return content, nil
+	}
+	g.pluginMu.Lock()
+	defer g.pluginMu.Unlock()
+	f := &File{Path: fileName, Content: content}
+	for _, p := range g.plugins {
+		if err := p.BeforeRender(f); err != nil {
+			return nil, fmt.Errorf("plugin BeforeRender of %s fail: %w", fileName, err)
+		}
+	}
+	return f.Content, nil
+}
+
+func (g *Generator) afterWrite(fileName string) error {
+	g.pluginMu.Lock()
+	defer g.pluginMu.Unlock()
+	for _, p := range g.plugins {
+		if err := p.AfterWrite(fileName); err != nil {
+			return fmt.Errorf("plugin AfterWrite of %s fail: %w", fileName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// StatementPolicy timeout and retry policy of DIY methods generated by ApplyInterface, see Config.StatementPolicy
+type StatementPolicy struct {
+	Timeout     time.Duration // deadline of each attempt, 0 means no timeout
+	MaxAttempts int           // max attempts including the first one when failing with transient error, <= 1 means no retry
+	Backoff     time.Duration // wait before the second attempt, doubled for each later attempt
+}
+
+// IsTransientError whether err is transient by error code of driver, which may succeed if retried: deadlock(1213) and
+// lock wait timeout(1205) of mysql, deadlock(40P01) and serialization failure(40001) of postgres, deadlock victim(1205)
+// of sqlserver, and busy(5) or locked(6) database of sqlite
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	var stateErr interface{ SQLState() string } // pgconn.PgError of pgx, pq.Error of lib/pq
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		return state == "40P01" || state == "40001"
+	}
+	var mssqlErr interface{ SQLErrorNumber() int32 } // mssql.Error of go-mssqldb
+	if errors.As(err, &mssqlErr) {
+		return mssqlErr.SQLErrorNumber() == 1205
+	}
+	var codeErr interface{ Code() int } // sqlite.Error of modernc.org/sqlite, extended code keeps primary code in low byte
+	if errors.As(err, &codeErr) {
+		code := codeErr.Code() & 0xff
+		return code == 5 || code == 6
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		// sqlite3.Error of mattn/go-sqlite3 has code field only, it's read without depending on cgo driver
+		v := reflect.Indirect(reflect.ValueOf(err))
+		if v.Kind() == reflect.Struct && v.Type().PkgPath() == "github.com/mattn/go-sqlite3" {
+			if code := v.FieldByName("Code"); code.IsValid() && code.CanInt() {
+				return code.Int() == 5 || code.Int() == 6
+			}
+		}
+	}
+	return false
+}
+
+// RunWithPolicy run fc with db carrying deadline of policy, fc is attempted again after backoff if it fails with
+// transient error until max attempts is reached or context of db is done, error of the last attempt is returned.
+// fc is attempted once in transaction, which is aborted by the failed statement and should be retried as a whole
+func RunWithPolicy(db *gorm.DB, policy StatementPolicy, fc func(tx *gorm.DB) error) (err error) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); inTransaction {
+		policy.MaxAttempts = 1
+	}
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		if err = policy.attempt(ctx, db, fc); err == nil || attempt >= policy.MaxAttempts || !IsTransientError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (p StatementPolicy) attempt(ctx context.Context, db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	return fc(db.WithContext(ctx))
+}
@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Progress of a stage of generating tables
+type Progress struct {
+	Stage   string // introspect, model or query
+	Done    int    // tables done in stage
+	Total   int    // tables of stage
+	Current string // table just done
+	Elapsed time.Duration
+}
+
+// ETA estimated time to finish the stage, 0 if nothing is done
+func (p Progress) ETA() time.Duration {
+	if p.Done == 0 || p.Done >= p.Total {
+		return 0
+	}
+	return p.Elapsed / time.Duration(p.Done) * time.Duration(p.Total-p.Done)
+}
+
+// Summary files of generation
+type Summary struct {
+	Written []string // files written
+	Skipped []string // files skipped as they are unchanged, see Config.LockFile
+}
+
+// WithProgress report progress of introspecting tables and generating model and query files of them,
+// report is called once a table is done, calls are serialized
+func (cfg *Config) WithProgress(report func(Progress)) {
+	cfg.reportProgress = report
+}
+
+// progress reporter of a stage
+type progress struct {
+	mu     sync.Mutex
+	stage  string
+	done   int
+	total  int
+	start  time.Time
+	report func(Progress)
+}
+
+func (g *Generator) beginProgress(stage string, total int) *progress {
+	return &progress{stage: stage, total: total, start: time.Now(), report: g.reportProgress}
+}
+
+// step report table is done
+func (p *progress) step(table string) {
+	if p.report == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.report(Progress{Stage: p.stage, Done: p.done, Total: p.total, Current: table, Elapsed: time.Since(p.start)})
+}
+
+// Summary files written and skipped by Execute
+func (g *Generator) Summary() Summary {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := Summary{
+		Written: append([]string(nil), g.summary.Written...),
+		Skipped: append([]string(nil), g.summary.Skipped...),
+	}
+	sort.Strings(s.Written)
+	sort.Strings(s.Skipped)
+	return s
+}
+
+func (g *Generator) written(fileName string) {
+	g.mu.Lock()
+	g.summary.Written = append(g.summary.Written, fileName)
+	g.mu.Unlock()
+}
+
+func (g *Generator) skipped(fileName string) {
+	g.mu.Lock()
+	g.summary.Skipped = append(g.summary.Skipped, fileName)
+	g.mu.Unlock()
+}
@@ -0,0 +1,62 @@
+package gen
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"gorm.io/gen/internal/generate"
+)
+
+const genModulePath = "gorm.io/gen"
+
+// provenance header comment of generated go file: generator version, config fingerprint,
+// source table and its schema fingerprint, generation time unless OmitTimestamp.
+// Return "" if Provenance is false, meta is nil for files not generated from a table
+func (g *Generator) provenance(meta *generate.QueryStructMeta) (string, error) {
+	if !g.Provenance {
+		return "", nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Generator: %s %s\n", genModulePath, genVersion())
+	fmt.Fprintf(&b, "// Config: %s\n", g.configSum)
+	if meta != nil {
+		if meta.TableName != "" {
+			fmt.Fprintf(&b, "// Table: %s\n", meta.TableName)
+		}
+		sum, err := g.fingerprint(meta)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "// Schema: %s\n", sum)
+	}
+	if !g.OmitTimestamp {
+		fmt.Fprintf(&b, "// Generated at: %s\n", time.Now().UTC().Format(time.RFC3339))
+	}
+	return b.String() + "\n", nil
+}
+
+// genVersion return version of gorm.io/gen used by current binary
+func genVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == genModulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != genModulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version == "" {
+			return "(devel)"
+		} else if dep.Replace != nil {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "(devel)"
+}
@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// generatedMark first line of files generated by gen
+var generatedMark = []byte(strings.Split(strings.TrimSpace(tmpl.NotEditMark), "\n")[0])
+
+// prune remove generated model and query files which are not generated this time, e.g. files of dropped tables.
+// Only *.gen.go and *.gen_test.go files with generated mark are removed
+func (g *Generator) prune() error {
+	if !g.Prune {
+		return nil
+	}
+
+	var dirs []string
+	expected := make(map[string]bool)
+	if len(g.models) > 0 {
+		modelOutPath, err := g.getModelOutputPath()
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, modelOutPath)
+		for _, meta := range g.models {
+			if meta != nil && meta.Generated {
+				dirs = append(dirs, g.modelDir(modelOutPath, meta))
+				expected[filepath.Clean(g.modelFile(modelOutPath, meta))] = true
+			}
+		}
+		if g.baseModel != nil {
+			expected[filepath.Join(modelOutPath, g.baseModel.FileName+".gen.go")] = true
+		}
+	}
+	if len(g.Data) > 0 {
+		dirs = append(dirs, g.OutPath)
+		for _, data := range g.Data {
+			expected[filepath.Join(g.OutPath, data.FileName+".gen.go")] = true
+			expected[filepath.Join(g.OutPath, data.FileName+".gen_test.go")] = true
+			if g.judgeMode(WithFakeDAO) {
+				expected[filepath.Join(g.OutPath, data.FileName+".fake.gen.go")] = true
+			}
+		}
+	}
+
+	for _, dir := range uniqueStrings(dirs) {
+		for _, pattern := range []string{"*.gen.go", "*.gen_test.go"} {
+			files, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				if expected[file] {
+					continue
+				}
+				if generated, err := isGeneratedFile(file); err != nil || !generated {
+					continue
+				}
+				if err = os.Remove(file); err != nil {
+					return fmt.Errorf("remove stale file %s fail: %w", file, err)
+				}
+				g.info("remove stale file: " + file)
+			}
+		}
+	}
+	return nil
+}
+
+// isGeneratedFile whether file has generated mark before package clause
+func isGeneratedFile(fileName string) (bool, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return false, err
+	}
+	if i := bytes.Index(content, []byte("\npackage ")); i >= 0 {
+		content = content[:i]
+	}
+	return bytes.Contains(content, generatedMark), nil
+}
@@ -0,0 +1,182 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/inflection"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// relation relation field of Config.Relations
+type relation struct {
+	relationship field.RelationshipType
+	fieldName    string
+	table        string
+	gormTag      field.GormTag
+}
+
+// relationships supported relationships of Config.Relations
+var relationships = map[string]field.RelationshipType{
+	string(field.HasOne):    field.HasOne,
+	string(field.HasMany):   field.HasMany,
+	string(field.BelongsTo): field.BelongsTo,
+	string(field.Many2Many): field.Many2Many,
+}
+
+// parseRelation parse relation in form of <relationship>:<field name>:<related table>[:<gorm tag>],
+// e.g. many_to_many:Roles:roles:many2many:user_roles
+func parseRelation(s string) (*relation, error) {
+	parts := strings.SplitN(s, ":", 4)
+	if len(parts) < 3 || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid relation %q (format <relationship>:<field name>:<related table>[:<gorm tag>])", s)
+	}
+	relationship, ok := relationships[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown relationship %q of relation %q (support has_one || has_many || belongs_to || many_to_many)", parts[0], s)
+	}
+	r := &relation{relationship: relationship, fieldName: parts[1], table: parts[2], gormTag: field.GormTag{}}
+	if len(parts) == 4 {
+		for _, setting := range strings.Split(parts[3], ";") {
+			if key, value, _ := strings.Cut(setting, ":"); key != "" {
+				r.gormTag.Set(key, value)
+			}
+		}
+	}
+	return r, nil
+}
+
+// relateModels add relation fields of Relations and foreign keys(FieldWithRelation) to generated models, fields
+// conflicting with existing fields or relating to models not generated are skipped
+func (g *Generator) relateModels() {
+	if !g.FieldWithRelation && len(g.Relations) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(g.models))
+	byTable := make(map[string]*generate.QueryStructMeta, len(g.models))
+	for name, meta := range g.models {
+		if meta != nil && meta.Generated && meta.TableName != "" {
+			names = append(names, name)
+			byTable[meta.TableName] = meta
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		meta := g.models[name]
+		for _, s := range g.Relations[meta.TableName] {
+			r, err := parseRelation(s)
+			if err != nil {
+				continue // checked by Revise
+			}
+			if related := byTable[r.table]; related != nil && g.samePackage(meta, related) {
+				addRelation(meta, r.relationship, r.fieldName, related, r.gormTag)
+			}
+		}
+	}
+	if !g.FieldWithRelation {
+		return
+	}
+	for _, name := range names {
+		meta := g.models[name]
+		if meta.TableSchema() == nil {
+			continue
+		}
+		for _, c := range meta.TableSchema().Columns {
+			g.relateForeignKey(meta, c, byTable)
+		}
+	}
+}
+
+// relateForeignKey add belongs to relation of foreign key column c to meta and has many relation to referenced model
+func (g *Generator) relateForeignKey(meta *generate.QueryStructMeta, c *model.ColumnSchema, byTable map[string]*generate.QueryStructMeta) {
+	if c.ForeignKey == nil {
+		return
+	}
+	ref := byTable[c.ForeignKey.RefTable]
+	if ref != nil && !g.samePackage(meta, ref) {
+		return
+	}
+	foreignKey, references := columnField(meta, c.Name), columnField(ref, c.ForeignKey.RefColumn)
+	if foreignKey == nil || references == nil {
+		return
+	}
+
+	// e.g. AuthorID -> Author, UserID -> User, otherwise named by referenced model
+	belongsTo := ref.ModelStructName
+	if name := strings.TrimSuffix(foreignKey.Name, "ID"); name != foreignKey.Name && name != "" {
+		belongsTo = name
+	}
+	belongsToTag := field.GormTag{}.Set(field.TagKeyGormForeignKey, foreignKey.Name).Set(field.TagKeyGormReferences, references.Name)
+	if value := c.ForeignKey.ConstraintTagValue(); value != "" && g.FieldWithConstraintTag {
+		belongsToTag.Set(field.TagKeyGormConstraint, value)
+	}
+	addRelation(meta, field.BelongsTo, belongsTo, ref, belongsToTag)
+
+	// e.g. User.Posts, or User.EditorPosts if posts has another foreign key referencing users
+	hasMany := inflection.Plural(meta.ModelStructName)
+	if ref.HasFieldNamed(hasMany) {
+		hasMany = belongsTo + hasMany
+	}
+	addRelation(ref, field.HasMany, hasMany, meta,
+		field.GormTag{}.Set(field.TagKeyGormForeignKey, foreignKey.Name).Set(field.TagKeyGormReferences, references.Name))
+}
+
+// addRelation add relation field named fieldName of related model to meta, belongs to and has one fields are pointers
+func addRelation(meta *generate.QueryStructMeta, relationship field.RelationshipType, fieldName string,
+	related *generate.QueryStructMeta, gormTag field.GormTag) {
+	if meta.HasFieldNamed(fieldName) || model.GormKeywords.FullMatch(fieldName) || model.DOKeywords.FullMatch(fieldName) {
+		return
+	}
+	config := &field.RelateConfig{
+		RelatePointer: relationship == field.BelongsTo || relationship == field.HasOne,
+		GORMTag:       gormTag,
+	}
+	meta.Fields = append(meta.Fields, &model.Field{
+		Name:     fieldName,
+		Type:     config.RelateFieldPrefix(relationship) + related.StructInfo.Type,
+		Tag:      config.GetTag(fieldName),
+		GORMTag:  config.GORMTag,
+		Relation: field.NewRelationWithType(relationship, fieldName, related.StructInfo.Package+"."+related.StructInfo.Type),
+	})
+}
+
+// columnField field of meta mapped to column, nil if meta is nil or column is not found
+func columnField(meta *generate.QueryStructMeta, column string) *model.Field {
+	if meta == nil {
+		return nil
+	}
+	for _, f := range meta.Fields {
+		if f.ColumnName == column && !f.IsRelation() {
+			return f
+		}
+	}
+	return nil
+}
+
+// preloadNames sorted names of relations and their child relations of applied models, see WithPreloadMethod
+func (g *Generator) preloadNames() []string {
+	seen := make(map[string]bool)
+	var walk func(relations []field.Relation)
+	walk = func(relations []field.Relation) {
+		for _, r := range relations {
+			seen[r.Name()] = true
+			walk(r.ChildRelations())
+		}
+	}
+	for _, data := range g.Data {
+		walk(data.Relations())
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
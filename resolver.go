@@ -0,0 +1,190 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// WithDBResolver register dbresolver plugin with sources/replicas in config when query code is initialized,
+// datas are the models or tables the config applies to, empty means all.
+// The plugin is registered once for each db, plugin registered by others is kept
+func WithDBResolver(config dbresolver.Config, datas ...interface{}) DOOption {
+	return &resolverOption{config: config, datas: datas}
+}
+
+type resolverOption struct {
+	config dbresolver.Config
+	datas  []interface{}
+}
+
+var resolvedDBs sync.Map // callbacks of db -> struct{}
+
+// Apply update config to new config
+func (o *resolverOption) Apply(*DOConfig) error { return nil }
+
+// AfterInitialize initialize plugins after db connected
+func (o *resolverOption) AfterInitialize(do *DO) error {
+	if _, loaded := resolvedDBs.LoadOrStore(do.db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+	if err := do.db.Use(dbresolver.Register(o.config, o.datas...)); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return err
+	}
+	return registerStickyCallbacks(do.db)
+}
+
+type stickyKey struct{}
+
+// sticky whether statement has been written with sticky context
+type sticky struct{ written int32 }
+
+// Sticky return context which pins reads to sources registered by dbresolver after any write executed with it,
+// so records just created or updated are read back without replication lag, e.g.
+//
+//	ctx = gen.Sticky(ctx)
+//	_ = q.User.WithContext(ctx).Create(user)
+//	_, _ = q.User.WithContext(ctx).Where(q.User.ID.Eq(user.ID)).First() // read from source
+//
+// Callbacks of db doing so are registered by WithDBResolver or the first call of DO.Sticky
+func Sticky(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(stickyKey{}).(*sticky); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, stickyKey{}, new(sticky))
+}
+
+// Sticky execute statements with context returned by Sticky(ctx), callbacks pinning reads are registered to db
+// if it's not initialized with WithDBResolver, e.g. dbresolver is registered by others
+func (d *DO) Sticky(ctx context.Context) Dao {
+	if err := registerStickyCallbacks(d.db); err != nil {
+		return d.withError(err)
+	}
+	return d.WithContext(Sticky(ctx))
+}
+
+var stickyCallbacks sync.Map // callbacks of db -> struct{}
+
+// registerStickyCallbacks register callbacks marking writes and pinning reads of sticky context once for each db
+func registerStickyCallbacks(db *gorm.DB) error {
+	if _, loaded := stickyCallbacks.LoadOrStore(db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+
+	callback := db.Callback()
+	for name, register := range map[string]func(name string, fn func(*gorm.DB)) error{
+		"gen:sticky_after_create": callback.Create().After("*").Register,
+		"gen:sticky_after_update": callback.Update().After("*").Register,
+		"gen:sticky_after_delete": callback.Delete().After("*").Register,
+	} {
+		if err := register(name, markWritten); err != nil {
+			return err
+		}
+	}
+	if err := callback.Raw().After("*").Register("gen:sticky_after_raw", markRawWritten); err != nil {
+		return err
+	}
+	for name, register := range map[string]func(name string, fn func(*gorm.DB)) error{
+		"gen:sticky_before_query": callback.Query().Before("*").Register,
+		"gen:sticky_before_row":   callback.Row().Before("*").Register,
+		"gen:sticky_before_raw":   callback.Raw().Before("*").Register,
+	} {
+		if err := register(name, pinWritten); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markWritten(db *gorm.DB) {
+	if s, ok := db.Statement.Context.Value(stickyKey{}).(*sticky); ok && db.Error == nil {
+		atomic.StoreInt32(&s.written, 1)
+	}
+}
+
+// markRawWritten mark sticky context written by statement executed by Exec unless it only reads
+func markRawWritten(db *gorm.DB) {
+	if !isReadStatement(db.Statement.SQL.String()) {
+		markWritten(db)
+	}
+}
+
+func pinWritten(db *gorm.DB) {
+	if s, ok := db.Statement.Context.Value(stickyKey{}).(*sticky); ok && atomic.LoadInt32(&s.written) == 1 {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// readStatements keywords of statements reading only
+var readStatements = map[string]bool{"SELECT": true, "SHOW": true, "EXPLAIN": true, "DESCRIBE": true, "DESC": true, "VALUES": true, "TABLE": true}
+
+// isReadStatement whether sql only reads by its statement type, which is the first keyword, or the keyword following
+// common table expressions of WITH
+func isReadStatement(sql string) bool {
+	keywords := topLevelKeywords(sql)
+	if len(keywords) == 0 {
+		return false
+	}
+	if keywords[0] != "WITH" {
+		return readStatements[keywords[0]]
+	}
+	for _, keyword := range keywords[1:] {
+		switch keyword {
+		case "SELECT", "VALUES", "TABLE":
+			return true
+		case "INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE":
+			return false
+		}
+	}
+	return false
+}
+
+// topLevelKeywords return upper cased words of sql out of quotes, comments and parentheses, except that the first
+// word is returned even if it's in parentheses, e.g. SELECT of (SELECT ...) UNION (SELECT ...)
+func topLevelKeywords(sql string) (keywords []string) {
+	depth := 0
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"), c == '#':
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case c == '\'' || c == '"' || c == '`':
+			if end := strings.IndexByte(sql[i+1:], c); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sql)
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case (depth == 0 || len(keywords) == 0) && (c == '_' || 'a' <= c|0x20 && c|0x20 <= 'z'):
+			j := i + 1
+			for j < len(sql) && (sql[j] == '_' || 'a' <= sql[j]|0x20 && sql[j]|0x20 <= 'z' || '0' <= sql[j] && sql[j] <= '9') {
+				j++
+			}
+			keywords = append(keywords, strings.ToUpper(sql[i:j]))
+			i = j - 1
+		}
+	}
+	return keywords
+}
+
+// UseResolver pin query to resolver registered with name
+func (d *DO) UseResolver(name string) Dao {
+	return d.getInstance(d.db.Clauses(dbresolver.Use(name)))
+}
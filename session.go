@@ -0,0 +1,129 @@
+package gen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	timeoutSettingKey = "gen:timeout"
+	cancelInstanceKey = "gen:cancel"
+)
+
+// WithPrepareStmt execute statements of query code as cached prepared statements, see gorm.Session.PrepareStmt
+func WithPrepareStmt() DOOption {
+	return sessionOption(func(session *gorm.Session) { session.PrepareStmt = true })
+}
+
+// WithSkipDefaultTransaction not wrap create/update/delete of query code in transaction,
+// see gorm.Config.SkipDefaultTransaction
+func WithSkipDefaultTransaction() DOOption {
+	return sessionOption(func(session *gorm.Session) { session.SkipDefaultTransaction = true })
+}
+
+// WithLogger log statements of query code by logger instead of logger of db
+func WithLogger(logger logger.Interface) DOOption {
+	return sessionOption(func(session *gorm.Session) { session.Logger = logger })
+}
+
+// sessionOption session settings of db used by query code, applied in addition to how db is constructed
+type sessionOption func(*gorm.Session)
+
+// Apply update config to new config
+func (o sessionOption) Apply(config *DOConfig) error {
+	if config.session == nil {
+		config.session = &gorm.Session{}
+	}
+	o(config.session)
+	return nil
+}
+
+// AfterInitialize initialize plugins after db connected
+func (o sessionOption) AfterInitialize(do *DO) error {
+	do.db = do.settingDB(do.db)
+	return nil
+}
+
+// WithDefaultTimeout cancel statements of query code after timeout when their context has no deadline,
+// Row/Rows are not covered because rows are read after statement is executed
+func WithDefaultTimeout(timeout time.Duration) DOOption {
+	return &timeoutOption{timeout: timeout}
+}
+
+type timeoutOption struct{ timeout time.Duration }
+
+// Apply update config to new config
+func (o *timeoutOption) Apply(config *DOConfig) error {
+	config.timeout = o.timeout
+	return nil
+}
+
+// AfterInitialize initialize plugins after db connected
+func (o *timeoutOption) AfterInitialize(do *DO) error {
+	if err := registerTimeoutCallbacks(do.db); err != nil {
+		return err
+	}
+	do.db = do.settingDB(do.db)
+	return nil
+}
+
+var timeoutCallbacks sync.Map // callbacks of db -> struct{}
+
+// registerTimeoutCallbacks register callbacks setting deadline of statement context once for each db,
+// only statements of db marked by settingDB are affected
+func registerTimeoutCallbacks(db *gorm.DB) error {
+	if _, loaded := timeoutCallbacks.LoadOrStore(db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+
+	callback := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("*").Register, callback.Create().After("*").Register},
+		{"query", callback.Query().Before("*").Register, callback.Query().After("*").Register},
+		{"update", callback.Update().Before("*").Register, callback.Update().After("*").Register},
+		{"delete", callback.Delete().Before("*").Register, callback.Delete().After("*").Register},
+		{"raw", callback.Raw().Before("*").Register, callback.Raw().After("*").Register},
+	}
+	for _, p := range processors {
+		if err := p.before("gen:timeout_before_"+p.operation, startTimeout); err != nil {
+			return err
+		}
+		if err := p.after("gen:timeout_after_"+p.operation, endTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startTimeout(db *gorm.DB) {
+	timeout, ok := db.Get(timeoutSettingKey)
+	if !ok || timeout.(time.Duration) <= 0 {
+		return
+	}
+	if _, ok := db.Statement.Context.Deadline(); ok {
+		return
+	}
+	parent := db.Statement.Context
+	ctx, cancel := context.WithTimeout(parent, timeout.(time.Duration))
+	db.Statement.Context = ctx
+	db.InstanceSet(cancelInstanceKey, func() {
+		cancel()
+		db.Statement.Context = parent
+	})
+}
+
+// endTimeout cancel context of timeout and restore context of statement, which is still used by callbacks after it,
+// e.g. explain of slow query
+func endTimeout(db *gorm.DB) {
+	if cancel, ok := db.InstanceGet(cancelInstanceKey); ok {
+		cancel.(func())()
+	}
+}
@@ -0,0 +1,60 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Order field of <Model>Field generated by WithSortMethod with direction, applied by OrderBy
+type Order[F ~string] struct {
+	Field F
+	Desc  bool
+}
+
+// Asc order by f ascending
+func Asc[F ~string](f F) Order[F] { return Order[F]{Field: f} }
+
+// Desc order by f descending
+func Desc[F ~string](f F) Order[F] { return Order[F]{Field: f, Desc: true} }
+
+// ParseOrders parse sort parameter in form of <field>[:asc|desc],... e.g. created_at:desc,name,
+// fields are checked by parse, which is generated Parse<Model>Field
+func ParseOrders[F ~string](s string, parse func(name string) (F, error)) ([]Order[F], error) {
+	var orders []Order[F]
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, direction, _ := strings.Cut(item, ":")
+		f, err := parse(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(strings.TrimSpace(direction)) {
+		case "", "asc":
+			orders = append(orders, Asc(f))
+		case "desc":
+			orders = append(orders, Desc(f))
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q of %s (support asc || desc)", direction, name)
+		}
+	}
+	return orders, nil
+}
+
+// ParseFields parse field selection parameter in form of <field>,... e.g. id,name,
+// fields are checked by parse, which is generated Parse<Model>Field
+func ParseFields[F ~string](s string, parse func(name string) (F, error)) ([]F, error) {
+	var fields []F
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		f, err := parse(name)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
@@ -0,0 +1,110 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// names of templates overriding built-in ones
+const (
+	modelTemplate       = "model.tmpl"        // model struct, executed with table metadata(generate.QueryStructMeta)
+	modelMethodTemplate = "model_method.tmpl" // each method of model, executed with parser.Method
+	modelEnumTemplate   = "model_enum.tmpl"   // each named type of model field, executed with model.Enum
+	modelUUIDTemplate   = "model_uuid.tmpl"   // each named uuid type of binary(16) field, executed with model.BinaryUUID
+	modelHookTemplate   = "model_hook.tmpl"   // BeforeCreate hook populating uuid primary keys, executed with generate.QueryStructMeta
+	// BeforeSave and AfterFind hooks converting time fields to UTC, executed with generate.QueryStructMeta
+	modelUTCHookTemplate = "model_utc_hook.tmpl"
+	// each named geometry type of spatial field, executed with model.Geometry
+	modelGeometryTemplate = "model_geometry.tmpl"
+	// constants of column and index names of model(FieldWithNameConst), executed with generate.QueryStructMeta
+	modelNameConstTemplate = "model_name_const.tmpl"
+	// constants of rows of lookup table(LookupTables), executed with model.Enum
+	modelLookupTemplate = "model_lookup.tmpl"
+	// shared struct embedded by models(BaseModelColumns), executed with generate.QueryStructMeta of its fields
+	baseModelTemplate = "base_model.tmpl"
+	// hook stubs of model(WithHooks) generated once, executed with hookStubs
+	modelHookStubTemplate = "model_hook_stub.tmpl"
+
+	queryStructTemplate = "query_struct.tmpl" // query struct of table, executed with generate.QueryStructMeta
+	queryIfaceTemplate  = "query_iface.tmpl"  // query interface of table(WithQueryInterface), executed with genInfo
+	diyMethodTemplate   = "diy_method.tmpl"   // each method of applied interfaces, executed with generate.InterfaceMethod
+	crudMethodTemplate  = "crud_method.tmpl"  // CRUD methods of query struct, executed with generate.QueryStructMeta
+	queryExtraTemplate  = "query_extra.tmpl"  // extra code appended to query file of each table, executed with generate.QueryStructMeta
+)
+
+// extraModelMethods supported ExtraModelMethods -> template of the method, executed with generate.QueryStructMeta
+var extraModelMethods = map[string]struct{ name, builtin string }{
+	"String":     {"model_string.tmpl", tmpl.ModelString},
+	"IsZero":     {"model_is_zero.tmpl", tmpl.ModelIsZero},
+	"PrimaryKey": {"model_primary_key.tmpl", tmpl.ModelPrimaryKey},
+	"Clone":      {"model_clone.tmpl", tmpl.ModelClone},
+	"Validate":   {"model_validate.tmpl", tmpl.ModelValidate},
+}
+
+// customTemplates user provided templates(*.tmpl files in a directory) overriding built-in ones,
+// each template is named by its file name, e.g. model.tmpl, and can use templates defined in other files
+type customTemplates struct{ *template.Template }
+
+// loadTemplates load model and query templates in ModelTemplateDir and QueryTemplateDir
+func (g *Generator) loadTemplates() (err error) {
+	if g.modelTemplates, err = parseTemplates(g.ModelTemplateDir, g.templateFuncs); err != nil {
+		return fmt.Errorf("load model templates fail: %w", err)
+	}
+	if g.queryTemplates, err = parseTemplates(g.QueryTemplateDir, g.templateFuncs); err != nil {
+		return fmt.Errorf("load query templates fail: %w", err)
+	}
+	return nil
+}
+
+// parseTemplates parse all *.tmpl files in dir with funcs, return nil if dir is empty
+func parseTemplates(dir string, funcs template.FuncMap) (*customTemplates, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no template(*.tmpl) found in %s", dir)
+	}
+	t, err := template.New("").Funcs(funcs).ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("parse templates in %s fail: %w", dir, err)
+	}
+	return &customTemplates{t}, nil
+}
+
+// render execute template named name if it's provided, otherwise the built-in one
+func (c *customTemplates) render(name string, builtin string, wr io.Writer, data interface{}) error {
+	if c != nil {
+		if t := c.Lookup(name); t != nil {
+			return t.Execute(wr, data)
+		}
+	}
+	if builtin == "" {
+		return nil
+	}
+	return render(builtin, wr, data)
+}
+
+// templateContents return contents of *.tmpl files in dirs, used to detect template changes
+func templateContents(dirs ...string) map[string]string {
+	contents := make(map[string]string)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		for _, file := range files {
+			content, _ := ioutil.ReadFile(file)
+			contents[filepath.Base(file)] += string(content)
+		}
+	}
+	return contents
+}
@@ -0,0 +1,67 @@
+package gen
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+)
+
+// temporalTableSQL count system-versioned temporal table @table of current database by dialect:
+// MariaDB system-versioned table(reported by mysql dialector) and SQL Server temporal table
+var temporalTableSQL = map[string]string{
+	"mysql": "SELECT COUNT(*) FROM information_schema.TABLES " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = @table AND TABLE_TYPE = 'SYSTEM VERSIONED'",
+	"sqlserver": "SELECT COUNT(*) FROM sys.tables WHERE object_id = OBJECT_ID(@table) AND temporal_type = 2",
+}
+
+// periodColumnsSQL query period columns(row start and row end) of temporal table @table by dialect,
+// which are maintained by db and cannot be written
+var periodColumnsSQL = map[string]string{
+	"mysql": "SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = @table " +
+		"AND (EXTRA LIKE '%ROW START%' OR EXTRA LIKE '%ROW END%')",
+	"sqlserver": "SELECT name FROM sys.columns WHERE object_id = OBJECT_ID(@table) AND generated_always_type IN (1, 2)",
+}
+
+// AsOf query rows of system-versioned temporal table as they were at time t by FOR SYSTEM_TIME AS OF,
+// which is supported by MariaDB and SQL Server
+func (d *DO) AsOf(t time.Time) Dao {
+	table := d.Quote(d.TableName()) + " FOR SYSTEM_TIME AS OF ?"
+	if d.alias != "" {
+		table += " AS " + d.Quote(d.alias)
+	}
+	return d.getInstance(d.db.Table(table, t))
+}
+
+// loadTemporal mark meta of system-versioned temporal table, whose period columns are tagged read only(->)
+// so they are excluded from insert and update. Temporal tables are not detected from schema json
+func (g *Generator) loadTemporal(meta *generate.QueryStructMeta) error {
+	query, ok := temporalTableSQL[g.db.Dialector.Name()]
+	if !ok || g.schema != nil {
+		return nil
+	}
+	args := map[string]interface{}{"table": meta.TableName}
+	var count int64
+	if err := g.db.Raw(query, args).Scan(&count).Error; err != nil {
+		g.info(fmt.Sprintf("detect temporal table %s fail: %s", meta.TableName, err))
+		return nil
+	}
+	if count == 0 {
+		return nil
+	}
+
+	var periodColumns []string
+	if err := g.db.Raw(periodColumnsSQL[g.db.Dialector.Name()], args).Scan(&periodColumns).Error; err != nil {
+		return fmt.Errorf("query period columns of temporal table %s fail: %w", meta.TableName, err)
+	}
+	for _, f := range meta.Fields {
+		for _, column := range periodColumns {
+			if f.ColumnName == column {
+				f.GORMTag.Remove(field.TagKeyGormWritePermission).Set(field.TagKeyGormReadOnly)
+			}
+		}
+	}
+	meta.Temporal = true
+	return nil
+}
@@ -0,0 +1,132 @@
+package gen
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const tenantSettingKey = "gen:tenant_column"
+
+type tenantCtxKey struct{}
+
+// WithTenant return context carrying tenant id, which is required by query code of tables with tenant column
+func WithTenant(ctx context.Context, tenantID interface{}) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID)
+}
+
+// TenantFromContext return tenant id carried by context
+func TenantFromContext(ctx context.Context) (tenantID interface{}, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tenantID = ctx.Value(tenantCtxKey{})
+	return tenantID, tenantID != nil
+}
+
+// UseTenant scope all statements to the tenant carried by context with column,
+// queries/updates/deletes are filtered by it and created records are filled with it,
+// statements fail with ErrMissingTenant when context carries no tenant.
+// SQL of Raw/Exec can't be scoped, it fails with ErrUnscopedRawSQL unless it references column, e.g. by
+// "WHERE tenant_id = @tenantID" of DIY methods. Statements of d fail with the returned error if callbacks can't be registered
+func (d *DO) UseTenant(column string) error {
+	if err := registerTenantCallbacks(d.db); err != nil {
+		d.db = d.withError(err).db
+		return err
+	}
+	d.tenantColumn = column
+	d.db = d.settingDB(d.db)
+	return nil
+}
+
+var tenantCallbacks sync.Map // callbacks of db -> struct{}
+
+func registerTenantCallbacks(db *gorm.DB) error {
+	if _, loaded := tenantCallbacks.LoadOrStore(db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+
+	callback := db.Callback()
+	if err := callback.Create().Before("gorm:create").Register("gen:tenant_create", fillTenant); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("gen:tenant_query", scopeTenant); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("gen:tenant_update", scopeTenant); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("gen:tenant_delete", scopeTenant); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("gen:tenant_row", scopeTenant); err != nil {
+		return err
+	}
+	return callback.Raw().Before("gorm:raw").Register("gen:tenant_raw", scopeTenant)
+}
+
+// tenant return tenant column and id of statement, ok is false when statement is not scoped by tenant
+func tenant(db *gorm.DB) (column string, tenantID interface{}, ok bool) {
+	v, scoped := db.Get(tenantSettingKey)
+	if !scoped {
+		return "", nil, false
+	}
+	if tenantID, ok = TenantFromContext(db.Statement.Context); !ok {
+		_ = db.AddError(ErrMissingTenant)
+		return "", nil, false
+	}
+	return v.(string), tenantID, true
+}
+
+func scopeTenant(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	column, tenantID, ok := tenant(db)
+	if !ok {
+		return
+	}
+	// SQL of Raw/Exec is built before callbacks, clauses added to it are ignored
+	if db.Statement.SQL.Len() > 0 {
+		if !strings.Contains(strings.ToLower(db.Statement.SQL.String()), strings.ToLower(column)) {
+			_ = db.AddError(ErrUnscopedRawSQL)
+		}
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: tenantID},
+	}})
+}
+
+func fillTenant(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	column, tenantID, ok := tenant(db)
+	if !ok {
+		return
+	}
+	f := db.Statement.Schema.LookUpField(column)
+	if f == nil {
+		return
+	}
+
+	ctx, rv := db.Statement.Context, db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := f.Set(ctx, reflect.Indirect(rv.Index(i)), tenantID); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := f.Set(ctx, rv, tenantID); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
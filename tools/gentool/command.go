@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gen"
+	"gorm.io/gen/tools/gentool/gentool"
+	"gorm.io/gorm"
+)
+
+// command subcommand of gentool
+type command struct {
+	name        string
+	summary     string
+	configUsage string       // usage of -c
+	writeConfig bool         // -c is path of config file written by command, it's not loaded
+	flags       [][]flagSpec // flag groups
+	run         func(config *CmdParams) error
+}
+
+// generateFlags flags of commands generating code
+var generateFlags = [][]flagSpec{sourceFlags, connFlags, outputFlags, modelFlags, queryFlags, packageFlags, exportFlags, runFlags}
+
+var commands = []*command{
+	{
+		name:        "generate",
+		summary:     "generate models and query code of tables",
+		configUsage: "is path for gen.yml",
+		flags:       append(generateFlags, interactiveFlags),
+		run:         runGenerate,
+	},
+	{
+		name:        "init",
+		summary:     "probe tables of db and write commented config file of chosen tables",
+		configUsage: "path of config file to write, default: gen.yml",
+		writeConfig: true,
+		flags:       [][]flagSpec{pickFlags(sourceFlags, "dsn", "db", "tables"), connFlags, pickFlags(outputFlags, "outPath")},
+		run:         runInit,
+	},
+	{
+		name:        "check",
+		summary:     "check config and introspect tables without generating code",
+		configUsage: "is path for gen.yml",
+		flags:       generateFlags,
+		run:         runCheck,
+	},
+	{
+		name:        "prune",
+		summary:     "generate all tables and remove generated files of tables which no longer exist",
+		configUsage: "is path for gen.yml",
+		flags:       generateFlags,
+		run:         runPrune,
+	},
+	{
+		name:        "watch",
+		summary:     "poll schema of tables or DDL files and regenerate changed tables",
+		configUsage: "is path for gen.yml",
+		flags:       append(generateFlags, watchFlags),
+		run:         runWatch,
+	},
+	{
+		name:        "export",
+		summary:     "export models into proto, OpenAPI, TypeScript, schema JSON or ER diagram without generating code",
+		configUsage: "is path for gen.yml",
+		flags:       [][]flagSpec{sourceFlags, connFlags, pickFlags(outputFlags, "outPath"), modelFlags, exportFlags},
+		run:         runExport,
+	},
+	{
+		name:        "migrate",
+		summary:     "write AutoMigrate file of models or versioned SQL migration of schema changes since snapshot",
+		configUsage: "is path for gen.yml",
+		flags:       [][]flagSpec{sourceFlags, connFlags, pickFlags(outputFlags, "outPath", "outFile", "modelPkgName", "modelLayout", "modelPackages"), modelFlags, migrateFlags},
+		run:         runMigrate,
+	},
+	{
+		name:        "diff",
+		summary:     "report schema drift of tables since schema JSON, lock file or models, exit with error if drifted",
+		configUsage: "is path for gen.yml",
+		flags:       append(generateFlags, diffFlags),
+		run:         runDiff,
+	},
+}
+
+// lookupCommand return command named name, nil if it's unknown
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// errPickCanceled picking tables and columns is canceled, nothing is generated
+var errPickCanceled = errors.New("picking canceled")
+
+func runGenerate(config *CmdParams) error {
+	if config.interactive {
+		config.BeforeGenerate = func(g *gen.Generator, db *gorm.DB, c *gentool.Config) error {
+			if err := pickInteractive(db, config); err != nil {
+				return err
+			}
+			c.Tables, c.ExcludeColumns = config.Tables, config.ExcludeColumns
+			g.ExcludeColumns = c.ExcludeColumns
+			return nil
+		}
+	}
+	if err := generate(config); !errors.Is(err, errPickCanceled) {
+		return err
+	}
+	return nil
+}
+
+// generate generate models of tables and query code of them, summary is logged
+func generate(config *CmdParams) error {
+	warnings := toolLog.count(levelWarn)
+	report, err := gentool.Run(context.Background(), config.toolConfig())
+	if err != nil {
+		return err
+	}
+
+	toolLog.summary([][2]string{
+		{"tables", strconv.Itoa(report.Tables)},
+		{"files written", strconv.Itoa(len(report.Written))},
+		{"files skipped", strconv.Itoa(len(report.Skipped))},
+		{"warnings", strconv.Itoa(toolLog.count(levelWarn) - warnings)},
+		{"elapsed", report.Elapsed.Round(time.Millisecond).String()},
+	})
+	return nil
+}
+
+// toolConfig options of generation with progress reported by a new progressReporter
+func (p *CmdParams) toolConfig() gentool.Config {
+	config := p.Config
+	config.OnProgress = newProgressReporter(p).report
+	return config
+}
+
+// pickInteractive pick tables and columns in terminal and write them back into config file,
+// return errPickCanceled if picking is canceled
+func pickInteractive(db *gorm.DB, config *CmdParams) error {
+	if db == nil || config.configPath == "" {
+		return fmt.Errorf("-interactive requires db and config file of -c to write selection back")
+	}
+	if ok, err := pickTables(db, config); err != nil || !ok {
+		if err == nil {
+			toolLog.Infof("picking canceled")
+			return errPickCanceled
+		}
+		return err
+	}
+	if err := writeSelection(config.configPath, config); err != nil {
+		return fmt.Errorf("write selection into %s fail: %w", config.configPath, err)
+	}
+	toolLog.Infof("write %d tables and %d excluded columns into %s", len(config.Tables), len(config.ExcludeColumns), config.configPath)
+	return nil
+}
+
+func runCheck(config *CmdParams) error {
+	report, err := gentool.Check(context.Background(), config.toolConfig())
+	if err != nil {
+		return err
+	}
+	toolLog.Infof("check %d tables done", report.Tables)
+	return nil
+}
+
+func runPrune(config *CmdParams) error {
+	if len(config.Tables) > 0 {
+		return fmt.Errorf("prune works on all tables, tables should be empty")
+	}
+	config.Prune = true
+	return runGenerate(config)
+}
+
+func runExport(config *CmdParams) error {
+	_, err := gentool.Export(context.Background(), config.toolConfig())
+	return err
+}
+
+func runMigrate(config *CmdParams) error {
+	if config.Migrate == "" {
+		config.Migrate = "automigrate"
+	}
+	report, err := gentool.Migrate(context.Background(), config.toolConfig())
+	if err != nil {
+		return err
+	}
+	toolLog.Infof("migrate %d tables, %d files written", report.Tables, len(report.Written))
+	return nil
+}
+
+// errDrift schema drifted since baseline, reported by diff
+var errDrift = errors.New("schema drifted")
+
+func runDiff(config *CmdParams) error {
+	drift, err := gentool.Diff(context.Background(), config.toolConfig())
+	if err != nil {
+		return err
+	}
+	fmt.Print(drift)
+	if !drift.Empty() {
+		return fmt.Errorf("%w: %d changes since %s", errDrift, len(drift.Changes), drift.Baseline)
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// flagSpec command line flag overriding CmdParams loaded from config file, flags are shared by commands in groups
+type flagSpec struct {
+	name  string
+	usage string
+	int   bool // registered as int flag
+	bool  bool // registered as bool flag set without value, e.g. -v, otherwise string flag
+	apply func(p *CmdParams, value string) error
+}
+
+func stringFlag(name, usage string, set func(p *CmdParams, v string)) flagSpec {
+	return flagSpec{name: name, usage: usage, apply: func(p *CmdParams, value string) error {
+		set(p, value)
+		return nil
+	}}
+}
+
+// boolFlag flag of true/false, any value other than true means false
+func boolFlag(name, usage string, set func(p *CmdParams, v bool)) flagSpec {
+	return flagSpec{name: name, usage: usage, apply: func(p *CmdParams, value string) error {
+		set(p, value == "true")
+		return nil
+	}}
+}
+
+// switchFlag bool flag set without value, e.g. -v
+func switchFlag(name, usage string, set func(p *CmdParams, v bool)) flagSpec {
+	return flagSpec{name: name, usage: usage, bool: true, apply: func(p *CmdParams, value string) error {
+		set(p, value == "true")
+		return nil
+	}}
+}
+
+func intFlag(name, usage string, set func(p *CmdParams, v int)) flagSpec {
+	return flagSpec{name: name, usage: usage, int: true, apply: func(p *CmdParams, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q of -%s: %w", value, name, err)
+		}
+		set(p, n)
+		return nil
+	}}
+}
+
+// listFlag flag of comma separated values, e.g. a,b
+func listFlag(name, usage string, set func(p *CmdParams, v []string)) flagSpec {
+	return flagSpec{name: name, usage: usage, apply: func(p *CmdParams, value string) error {
+		if value == "" {
+			set(p, nil)
+		} else {
+			set(p, strings.Split(value, ","))
+		}
+		return nil
+	}}
+}
+
+// mapFlag flag of comma separated key=value pairs, e.g. a=x,b=y
+func mapFlag(name, usage string, set func(p *CmdParams, v map[string]string)) flagSpec {
+	return flagSpec{name: name, usage: usage, apply: func(p *CmdParams, value string) error {
+		m := make(map[string]string)
+		for _, item := range strings.Split(value, ",") {
+			if k, v, ok := strings.Cut(item, "="); ok {
+				m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+		set(p, m)
+		return nil
+	}}
+}
+
+// multiMapFlag flag of comma separated key=value pairs whose key may repeat, e.g. a=x,a=y
+func multiMapFlag(name, usage string, set func(p *CmdParams, v map[string][]string)) flagSpec {
+	return flagSpec{name: name, usage: usage, apply: func(p *CmdParams, value string) error {
+		m := make(map[string][]string)
+		for _, item := range strings.Split(value, ",") {
+			if k, v, ok := strings.Cut(item, "="); ok {
+				k = strings.TrimSpace(k)
+				m[k] = append(m[k], strings.TrimSpace(v))
+			}
+		}
+		set(p, m)
+		return nil
+	}}
+}
+
+// pickFlags flags of group named names in order, it panics on unknown name so that renamed flags fail at startup
+func pickFlags(group []flagSpec, names ...string) []flagSpec {
+	flags := make([]flagSpec, 0, len(names))
+	for _, name := range names {
+		i := 0
+		for i < len(group) && group[i].name != name {
+			i++
+		}
+		if i == len(group) {
+			panic(fmt.Sprintf("unknown flag -%s", name))
+		}
+		flags = append(flags, group[i])
+	}
+	return flags
+}
+
+// logFlags flags of logging shared by all commands
+var logFlags = []flagSpec{
+	switchFlag("v", "verbose, log debug messages and sql of introspecting tables", func(p *CmdParams, v bool) { p.Verbose = v }),
+	switchFlag("quiet", "only log warnings and errors", func(p *CmdParams, v bool) { p.Quiet = v }),
+	stringFlag("logFormat", "log format: text(default) or json", func(p *CmdParams, v string) { p.LogFormat = v }),
+	intFlag("progress", "show progress when introspecting or generating more than N tables, default 50, -1 to disable", func(p *CmdParams, v int) { p.Progress = v }),
+}
+
+// sourceFlags flags of data source
+var sourceFlags = []flagSpec{
+	stringFlag("dsn", "consult[https://gorm.io/docs/connecting_to_the_database.html]", func(p *CmdParams, v string) { p.DSN = v }),
+	stringFlag("db", "input mysql|postgres|sqlite|sqlserver|clickhouse|oracle or name of registered driver. consult[https://gorm.io/docs/connecting_to_the_database.html]", func(p *CmdParams, v string) { p.DB = v }),
+	listFlag("tables", "enter the required data table or leave it blank", func(p *CmdParams, v []string) { p.Tables = v }),
+	stringFlag("fromSchemaJSON", "generate from metadata JSON file exported by -schemaJSON without connecting db", func(p *CmdParams, v string) { p.FromSchemaJSON = v }),
+}
+
+// connFlags flags of connection applied besides dsn
+var connFlags = []flagSpec{
+	intFlag("connectTimeout", "seconds of timeout connecting db", func(p *CmdParams, v int) { p.ConnectTimeout = v }),
+	intFlag("readTimeout", "seconds of timeout reading results, applied as statement_timeout for postgres", func(p *CmdParams, v int) { p.ReadTimeout = v }),
+	stringFlag("tlsCA", "path of CA certificate verifying db server", func(p *CmdParams, v string) { p.TLSCA = v }),
+	stringFlag("tlsCert", "path of client certificate, requires -tlsKey", func(p *CmdParams, v string) { p.TLSCert = v }),
+	stringFlag("tlsKey", "path of client private key, requires -tlsCert", func(p *CmdParams, v string) { p.TLSKey = v }),
+	boolFlag("tlsSkipVerify", "connect with tls without verifying certificate of server:true/false", func(p *CmdParams, v bool) { p.TLSSkipVerify = v }),
+	stringFlag("charset", "charset of session, mysql and postgres only", func(p *CmdParams, v string) { p.Charset = v }),
+	stringFlag("searchPath", "search_path of session, postgres only", func(p *CmdParams, v string) { p.SearchPath = v }),
+	stringFlag("sshHost", "host[:port] of bastion db is dialed through", func(p *CmdParams, v string) { p.SSH.Host = v }),
+	stringFlag("sshUser", "user of bastion, default: current user", func(p *CmdParams, v string) { p.SSH.User = v }),
+	stringFlag("sshKeyPath", "private key of bastion, default: ~/.ssh/id_ed25519, id_ecdsa or id_rsa, keys of ssh-agent are also tried", func(p *CmdParams, v string) { p.SSH.KeyPath = v }),
+	stringFlag("sshJumpHost", "[user@]host[:port] of jump host bastion is dialed through", func(p *CmdParams, v string) { p.SSH.JumpHost = v }),
+	stringFlag("sshKnownHosts", "known_hosts file verifying host keys, default: ~/.ssh/known_hosts", func(p *CmdParams, v string) { p.SSH.KnownHosts = v }),
+}
+
+// outputFlags flags of output paths and packages
+var outputFlags = []flagSpec{
+	stringFlag("outPath", "specify a directory for output", func(p *CmdParams, v string) { p.OutPath = v }),
+	stringFlag("outFile", "query code file name, default: gen.go", func(p *CmdParams, v string) { p.OutFile = v }),
+	stringFlag("modelPkgName", "generated model code's package name", func(p *CmdParams, v string) { p.ModelPkgName = v }),
+	stringFlag("modelLayout", "layout of model files: table(default, a file per table)/single(models.gen.go of each package)", func(p *CmdParams, v string) { p.ModelLayout = v }),
+	mapFlag("modelPackages", "sub-packages of model package grouping tables by pattern, e.g. user_*=user,order_*=order", func(p *CmdParams, v map[string]string) { p.ModelPackages = v }),
+	boolFlag("onlyModel", "only generate models (without query file): true/false", func(p *CmdParams, v bool) { p.OnlyModel = v }),
+	boolFlag("withUnitTest", "generate unit test for query code:true/false", func(p *CmdParams, v bool) { p.WithUnitTest = v }),
+	stringFlag("unitTestDB", "database of unit tests: sqlite(default)/testcontainers(database of dialect started by testcontainers-go)", func(p *CmdParams, v string) { p.UnitTestDB = v }),
+}
+
+// modelFlags flags of model generation
+var modelFlags = []flagSpec{
+	boolFlag("fieldNullable", "generate with pointer when field is nullable:true/false", func(p *CmdParams, v bool) { p.FieldNullable = v }),
+	boolFlag("fieldWithIndexTag", "generate field with gorm index tag:true/false", func(p *CmdParams, v bool) { p.FieldWithIndexTag = v }),
+	boolFlag("fieldWithTypeTag", "generate field with gorm column type tag:true/false", func(p *CmdParams, v bool) { p.FieldWithTypeTag = v }),
+	boolFlag("fieldSignable", "detect integer field's unsigned type, adjust generated data type:true/false", func(p *CmdParams, v bool) { p.FieldSignable = v }),
+	boolFlag("fieldDefaultTag", "generate pointer for field whose column default value is non-zero, so zero value can be written:true/false", func(p *CmdParams, v bool) { p.FieldDefaultTag = v }),
+	boolFlag("fieldWithEnumType", "generate named type with constants and Valid method for enum and set column:true/false", func(p *CmdParams, v bool) { p.FieldWithEnumType = v }),
+	boolFlag("fieldWithCommentEnum", "generate named type with constants for integer column declared in comment like 'status: 1-active 2-disabled':true/false", func(p *CmdParams, v bool) { p.FieldWithCommentEnum = v }),
+	boolFlag("fieldWithDocComment", "render column comments as doc comments above fields instead of trailing comments:true/false", func(p *CmdParams, v bool) { p.FieldWithDocComment = v }),
+	boolFlag("fieldOmitDeprecated", "tag fields of columns commented 'DEPRECATED: ...' with <-:update, so create omits them:true/false", func(p *CmdParams, v bool) { p.FieldOmitDeprecated = v }),
+	mapFlag("arrayTypeMap", "go type of postgres array column by element data type, e.g. integer=pq.Int64Array,uuid=pq.StringArray", func(p *CmdParams, v map[string]string) { p.ArrayTypeMap = v }),
+	boolFlag("fieldWithJSONType", "generate json/jsonb column as datatypes.JSON with JSON query helpers:true/false", func(p *CmdParams, v bool) { p.FieldWithJSONType = v }),
+	boolFlag("fieldWithUUIDType", "generate uuid column as uuid.UUID of github.com/google/uuid:true/false", func(p *CmdParams, v bool) { p.FieldWithUUIDType = v }),
+	listFlag("uuidColumnTypes", "column types also generated as uuid.UUID, e.g. char(36),binary(16)", func(p *CmdParams, v []string) { p.UUIDColumnTypes = v }),
+	boolFlag("uuidHook", "generate BeforeCreate hook populating zero uuid primary key:true/false", func(p *CmdParams, v bool) { p.UUIDHook = v }),
+	stringFlag("decimalType", "go type of decimal/numeric column: shopspring(decimal.Decimal), string or float64", func(p *CmdParams, v string) { p.DecimalType = v }),
+	stringFlag("nullableStyle", "type of nullable field when fieldNullable: pointer(default), sqlnull(sql.NullString...) or genericNull(sql.Null[T])", func(p *CmdParams, v string) { p.NullableStyle = v }),
+	stringFlag("dateType", "go type of date column: time(time.Time, default), datatypes(datatypes.Date) or string", func(p *CmdParams, v string) { p.DateType = v }),
+	stringFlag("timeType", "go type of time column: time(time.Time, default), datatypes(datatypes.Time) or string", func(p *CmdParams, v string) { p.TimeType = v }),
+	stringFlag("dateTimeType", "go type of datetime/timestamp column: time(time.Time, default) or string", func(p *CmdParams, v string) { p.DateTimeType = v }),
+	boolFlag("timeUTC", "generate BeforeSave and AfterFind hooks converting time fields to UTC:true/false", func(p *CmdParams, v bool) { p.TimeUTC = v }),
+	boolFlag("fieldWithGeometryType", "generate spatial column as named type of orb geometry with Scan/Value:true/false", func(p *CmdParams, v bool) { p.FieldWithGeometryType = v }),
+	mapFlag("geometryTypeMap", "orb type of spatial column, e.g. location=orb.Point,area=orb.Polygon", func(p *CmdParams, v map[string]string) { p.GeometryTypeMap = v }),
+	mapFlag("integerTypeMap", "go type of integer column by column type or data type, e.g. tinyint=int8,tinyint(1)=int8", func(p *CmdParams, v map[string]string) { p.IntegerTypeMap = v }),
+	boolFlag("fieldWithConstraintTag", "generate field with gorm check tag of check constraints:true/false", func(p *CmdParams, v bool) { p.FieldWithConstraintTag = v }),
+	boolFlag("fieldWithRelation", "generate belongs to and has many relation fields of foreign keys:true/false", func(p *CmdParams, v bool) { p.FieldWithRelation = v }),
+	multiMapFlag("relations", "relation fields of models by table, e.g. users=has_many:Orders:orders,users=many_to_many:Roles:roles:many2many:user_roles", func(p *CmdParams, v map[string][]string) { p.Relations = v }),
+	boolFlag("fieldWithNameConst", "generate constants of column names(Column<Model><Field>) and index names(Index<Model><Name>) of models:true/false", func(p *CmdParams, v bool) { p.FieldWithNameConst = v }),
+	mapFlag("lookupTables", "lookup tables whose rows are generated as constants of ids named by codes, e.g. statuses=id:code,countries=id:iso_code", func(p *CmdParams, v map[string]string) { p.LookupTables = v }),
+	stringFlag("noPrimaryKey", "handling of tables without primary key: skip, readOnly(models tagged ->) or uniqueIndex(unique index of NOT NULL columns as primary key)", func(p *CmdParams, v string) { p.NoPrimaryKey = v }),
+	mapFlag("logicalKeys", "unique index used as logical primary key of table without primary key, e.g. user_roles=idx_user_roles_user_role", func(p *CmdParams, v map[string]string) { p.LogicalKeys = v }),
+	listFlag("extraModelMethods", "extra methods generated on every model, e.g. String,IsZero,PrimaryKey,Clone,Validate", func(p *CmdParams, v []string) { p.ExtraModelMethods = v }),
+	boolFlag("validateNotNullZero", "Validate reports zero value of non-pointer field of NOT NULL column without default:true/false", func(p *CmdParams, v bool) { p.ValidateNotNullZero = v }),
+	multiMapFlag("withMethods", "types whose methods are copied onto models by table(* for all), e.g. users=./common.CommonMethod,*=github.com/acme/app/common.Timestamps", func(p *CmdParams, v map[string][]string) { p.WithMethods = v }),
+	listFlag("baseModelColumns", "columns extracted into BaseModel embedded by models having all of them, e.g. id,created_at,updated_at,deleted_at", func(p *CmdParams, v []string) { p.BaseModelColumns = v }),
+	listFlag("withHooks", "gorm hooks whose stubs are generated in <model file>_hooks.go once, e.g. BeforeCreate,BeforeUpdate", func(p *CmdParams, v []string) { p.WithHooks = v }),
+	listFlag("piiColumns", "sensitive columns generated as gen.PII encrypted by pii serializer and masked in output, e.g. users.email,users.phone", func(p *CmdParams, v []string) { p.PIIColumns = v }),
+	listFlag("excludeColumns", "columns not generated in models, e.g. users.password,deleted_by", func(p *CmdParams, v []string) { p.ExcludeColumns = v }),
+	multiMapFlag("ignoreColumns", "columns ignored by models by table(* for all), e.g. users=password_hash,users=legacy_blob", func(p *CmdParams, v map[string][]string) { p.IgnoreColumns = v }),
+	multiMapFlag("readOnlyColumns", "columns generated with read-only gorm tag -> by table(* for all), e.g. orders=computed_total", func(p *CmdParams, v map[string][]string) { p.ReadOnlyColumns = v }),
+}
+
+// interactiveFlags flags of picking tables and columns in terminal
+var interactiveFlags = []flagSpec{
+	boolFlag("interactive", "pick tables and columns in terminal and write them back into config file of -c before generating:true/false", func(p *CmdParams, v bool) { p.interactive = v }),
+}
+
+// watchFlags flags of watch
+var watchFlags = []flagSpec{
+	intFlag("watchInterval", "seconds between polls of schema or DDL files, default 2", func(p *CmdParams, v int) { p.WatchInterval = v }),
+	intFlag("watchDebounce", "seconds changes must settle before regenerating, default 1", func(p *CmdParams, v int) { p.WatchDebounce = v }),
+	stringFlag("ddlDir", "watch DDL/migration files in directory instead of polling schema of db", func(p *CmdParams, v string) { p.DDLDir = v }),
+	stringFlag("notify", "shell command run after regenerating, changed tables or files are in env GEN_CHANGED", func(p *CmdParams, v string) { p.Notify = v }),
+}
+
+// migrateFlags flags of migrate
+var migrateFlags = []flagSpec{
+	stringFlag("migrate", "output of migrate: automigrate(automigrate.gen.go registering models) or golang-migrate/goose(versioned SQL migration), default: automigrate", func(p *CmdParams, v string) { p.Migrate = v }),
+	stringFlag("migrateDir", "directory of SQL migration files, default: migrations next to outPath", func(p *CmdParams, v string) { p.MigrateDir = v }),
+	stringFlag("migrateSnapshot", "schema snapshot SQL migration is diffed from and rewritten after, default: schema.json of migrateDir", func(p *CmdParams, v string) { p.MigrateSnapshot = v }),
+	stringFlag("migrateName", "name of SQL migration files after version, default: gen", func(p *CmdParams, v string) { p.MigrateName = v }),
+}
+
+// diffFlags flags of diff
+var diffFlags = []flagSpec{
+	stringFlag("diffBaseline", "schema JSON, lock file or directory of models diff compares db with, default: lockFile or model package", func(p *CmdParams, v string) { p.DiffBaseline = v }),
+}
+
+// queryFlags flags of query generation
+var queryFlags = []flagSpec{
+	boolFlag("withExists", "generate Exists, ExistsBy<PK> and CountBy<IndexedColumn> methods, CountBy requires fieldWithIndexTag:true/false", func(p *CmdParams, v bool) { p.WithExists = v }),
+	boolFlag("withEach", "generate Each and EachInBatches methods streaming records instead of loading all of them:true/false", func(p *CmdParams, v bool) { p.WithEach = v }),
+	boolFlag("withLocking", "generate ForUpdate and ForShare row locking methods accepting gen.LockNoWait or gen.LockSkipLocked:true/false", func(p *CmdParams, v bool) { p.WithLocking = v }),
+	stringFlag("versionColumn", "column used for optimistic locking", func(p *CmdParams, v string) { p.VersionColumn = v }),
+	boolFlag("withCache", "generate GetBy<PK> methods served by cache:true/false", func(p *CmdParams, v bool) { p.WithCache = v }),
+	boolFlag("withTracing", "generated Use traces statements by OpenTelemetry global tracer, gen.WithTracing replaces it:true/false", func(p *CmdParams, v bool) { p.WithTracing = v }),
+	boolFlag("withResolver", "generate ReadOnly, Primary, UseResolver and Sticky methods pinning queries to dbresolver resolvers:true/false", func(p *CmdParams, v bool) { p.WithResolver = v }),
+	boolFlag("withPreload", "generate With<Relation> methods preloading relations, e.g. WithOrders(query.WithItems()):true/false", func(p *CmdParams, v bool) { p.WithPreload = v }),
+	boolFlag("withFilter", "generate <Model>Filter structs and ApplyFilter methods for list queries:true/false", func(p *CmdParams, v bool) { p.WithFilter = v }),
+	boolFlag("withSort", "generate <Model>Field enums, OrderBy and SelectFields methods:true/false", func(p *CmdParams, v bool) { p.WithSort = v }),
+	boolFlag("withBatch", "generate UpdateColumnsInBatches and DeleteBy<PK>s methods:true/false", func(p *CmdParams, v bool) { p.WithBatch = v }),
+	intFlag("batchSize", "default batch size of batch methods, capped by placeholder limit of database, default: 100", func(p *CmdParams, v int) { p.BatchSize = v }),
+	boolFlag("withReturning", "generate UpdateReturning/DeleteReturning methods, only for postgres and sqlite:true/false", func(p *CmdParams, v bool) { p.WithReturning = v }),
+	boolFlag("withAffected", "generate UpdateAffected, DeleteAffected, etc. returning number of affected rows:true/false", func(p *CmdParams, v bool) { p.WithAffected = v }),
+	boolFlag("strictRowsAffected", "<Method>Affected and DIY methods returning gen.RowsAffected return gen.ErrNoRowsAffected when no row is affected:true/false", func(p *CmdParams, v bool) { p.StrictRowsAffected = v }),
+	boolFlag("unscopedLookup", "ExistsBy<PK> and FindByPK of soft-deleted models also find soft-deleted records:true/false", func(p *CmdParams, v bool) { p.UnscopedLookup = v }),
+	boolFlag("withHint", "generate <Model>Index constants of indexes, UseIndex, ForceIndex, IgnoreIndex and OptimizerHint methods, indexes require fieldWithIndexTag:true/false", func(p *CmdParams, v bool) { p.WithHint = v }),
+	boolFlag("withGenericDAO", "generate CRUD methods once as generic core embedded by query structs of tables, requires Go 1.18+:true/false", func(p *CmdParams, v bool) { p.WithGenericDAO = v }),
+	boolFlag("withFakeDAO", "generate <Model>Fake, an in-memory fake of DAO of each table keyed by primary key, for unit tests:true/false", func(p *CmdParams, v bool) { p.WithFakeDAO = v }),
+	boolFlag("withImportExport", "generate ExportCSV/ImportCSV and ExportJSONLines/ImportJSONLines methods:true/false", func(p *CmdParams, v bool) { p.WithImportExport = v }),
+	stringFlag("tenantColumn", "column used for tenant scope", func(p *CmdParams, v string) { p.TenantColumn = v }),
+	mapFlag("shardedTables", "sharded tables with suffix pattern, e.g. orders=_200601,logs=_%02d", func(p *CmdParams, v map[string]string) { p.ShardedTables = v }),
+}
+
+// packageFlags flags of packages generated next to query code
+var packageFlags = []flagSpec{
+	stringFlag("withHandlers", "generate CRUD handlers into handler directory next to outPath: gin/echo", func(p *CmdParams, v string) { p.WithHandlers = v }),
+	stringFlag("withDI", "generate di.gen.go providing *Query and DAO interface of each table in query package: wire/fx", func(p *CmdParams, v string) { p.WithDI = v }),
+	stringFlag("dto", "generate DTO package with converters of models into directory, e.g. dao/dto", func(p *CmdParams, v string) { p.DTO = v }),
+	listFlag("dtoExclude", "columns excluded from DTO, e.g. password_hash,users.salt", func(p *CmdParams, v []string) { p.DTOExclude = v }),
+}
+
+// exportFlags flags of exporting models into other formats
+var exportFlags = []flagSpec{
+	stringFlag("proto", "export .proto files of models into directory, e.g. out/proto", func(p *CmdParams, v string) { p.Proto = v }),
+	boolFlag("protoService", "export CRUD service definitions in .proto files:true/false", func(p *CmdParams, v bool) { p.ProtoService = v }),
+	stringFlag("openapi", "export OpenAPI components.schemas of models into file, JSON for .json file otherwise YAML", func(p *CmdParams, v string) { p.OpenAPI = v }),
+	stringFlag("typescript", "export .d.ts files of models into directory, e.g. web/src/model", func(p *CmdParams, v string) { p.TypeScript = v }),
+	stringFlag("erd", "export ER diagram into erd.mmd or erd.dot next to outPath: mermaid/graphviz", func(p *CmdParams, v string) { p.ERD = v }),
+	stringFlag("schemaJSON", "export metadata of tables into JSON file, e.g. schema.json", func(p *CmdParams, v string) { p.SchemaJSON = v }),
+}
+
+// runFlags flags of generation process and format of generated files
+var runFlags = []flagSpec{
+	stringFlag("lockFile", "lock file storing schema fingerprints, unchanged tables are not regenerated, e.g. gen.lock", func(p *CmdParams, v string) { p.LockFile = v }),
+	boolFlag("force", "regenerate all tables even if unchanged since last generation:true/false", func(p *CmdParams, v bool) { p.Force = v }),
+	intFlag("concurrency", "number of tables introspected and rendered concurrently, default: number of CPUs", func(p *CmdParams, v int) { p.Concurrency = v }),
+	boolFlag("prune", "remove generated model/query files of tables which no longer exist:true/false", func(p *CmdParams, v bool) { p.Prune = v }),
+	stringFlag("manifest", "manifest file listing generated files with source table and sha256 of content, e.g. gen.manifest.json", func(p *CmdParams, v string) { p.Manifest = v }),
+	stringFlag("verify", "verify generated code after writing: build(type-check generated packages)", func(p *CmdParams, v string) { p.Verify = v }),
+	stringFlag("modelTemplateDir", "directory of templates overriding built-in model templates: model.tmpl/model_method.tmpl", func(p *CmdParams, v string) { p.ModelTemplateDir = v }),
+	stringFlag("queryTemplateDir", "directory of templates overriding built-in query templates, query_extra.tmpl for extra code of each table", func(p *CmdParams, v string) { p.QueryTemplateDir = v }),
+	stringFlag("fileHeader", "text or path of file injected at the top of generated go files, e.g. LICENSE_HEADER", func(p *CmdParams, v string) { p.FileHeader = v }),
+	stringFlag("buildTags", "build constraint of generated go files, e.g. !integration", func(p *CmdParams, v string) { p.BuildTags = v }),
+	stringFlag("formatter", "formatter of generated go files: goimports(default)/gofmt/gofumpt", func(p *CmdParams, v string) { p.Formatter = v }),
+	boolFlag("provenance", "emit header with generator version, config hash, source table and schema fingerprint:true/false", func(p *CmdParams, v bool) { p.Provenance = v }),
+	boolFlag("omitTimestamp", "omit generation time in provenance header to keep output reproducible:true/false", func(p *CmdParams, v bool) { p.OmitTimestamp = v }),
+	listFlag("plugins", "Go plugins(.so) or executables with arguments hooking generation, e.g. ./naming.so,./bin/lint-plugin", func(p *CmdParams, v []string) { p.Plugins = v }),
+	listFlag("afterGenerate", "shell commands run in outPath after generating, results are in env GEN_*, e.g. goimports -w .,go build ./...", func(p *CmdParams, v []string) { p.Hooks.AfterGenerate = v }),
+}
+
+// parse parse args of command, flags set explicitly override params loaded from config file of -c
+func (cmd *command) parse(args []string) (*CmdParams, error) {
+	fs := flag.NewFlagSet("gentool "+cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: gentool %s [flags]\n\n%s\n\nFlags:\n", cmd.name, cmd.summary)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("c", "", cmd.configUsage)
+	specs := make(map[string]flagSpec)
+	for _, group := range append(cmd.flags, logFlags) {
+		for _, spec := range group {
+			specs[spec.name] = spec
+			switch {
+			case spec.int:
+				fs.Int(spec.name, 0, spec.usage)
+			case spec.bool:
+				fs.Bool(spec.name, false, spec.usage)
+			default:
+				fs.String(spec.name, "", spec.usage)
+			}
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments of %s: %s", cmd.name, strings.Join(fs.Args(), " "))
+	}
+
+	params := &CmdParams{configPath: *configPath}
+	problems := &configError{path: *configPath}
+	if *configPath != "" && !cmd.writeConfig {
+		configFileParams, err := loadConfigFile(*configPath)
+		if err != nil && !errors.As(err, &problems) {
+			return nil, fmt.Errorf("loadConfigFile fail %w", err)
+		}
+		*params = *configFileParams
+		params.configPath = *configPath
+	}
+	// cmd first
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if spec, ok := specs[f.Name]; ok && err == nil {
+			err = spec.apply(params, f.Value.String())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defaultStrParams(params)
+	if !cmd.writeConfig {
+		validateParams(params, problems)
+	}
+	if len(problems.problems) > 0 {
+		return nil, problems
+	}
+	return params, nil
+}
+
+// printUsage print commands of gentool
+func printUsage() {
+	fmt.Fprint(os.Stderr, "Usage: gentool <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(os.Stderr, "\nCommand is generate if it's omitted. Run 'gentool <command> -h' for flags of command.\n")
+}
@@ -0,0 +1,185 @@
+// Package gentool generates models and query code of tables of db or metadata file by options of gentool,
+// it's the pipeline of gentool command for build tools embedding generation, e.g.
+//
+//	report, err := gentool.Run(ctx, gentool.Config{DB: "mysql", DSN: dsn, OutPath: "./dao/query"})
+package gentool
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+// DBType database type
+type DBType string
+
+const (
+	// MySQL Gorm Drivers mysql || postgres || sqlite || sqlserver || clickhouse || oracle
+	MySQL      DBType = "mysql"
+	Postgres   DBType = "postgres"
+	SQLite     DBType = "sqlite"
+	SQLServer  DBType = "sqlserver"
+	ClickHouse DBType = "clickhouse"
+	Oracle     DBType = "oracle"
+)
+
+// Config is options of generation, which are keys under database of gen.yml and flags of gentool.
+// Fields without yaml key are hooks customizing generation programmatically
+type Config struct {
+	DSN                    string              `yaml:"dsn"`                    // consult[https://gorm.io/docs/connecting_to_the_database.html]"
+	DB                     string              `yaml:"db"`                     // input mysql or postgres or sqlite or sqlserver or clickhouse or oracle, or name of registered driver. consult[https://gorm.io/docs/connecting_to_the_database.html]
+	Tables                 []string            `yaml:"tables"`                 // enter the required data table or leave it blank
+	OnlyModel              bool                `yaml:"onlyModel"`              // only generate model
+	OutPath                string              `yaml:"outPath"`                // specify a directory for output
+	OutFile                string              `yaml:"outFile"`                // query code file name, default: gen.go
+	WithUnitTest           bool                `yaml:"withUnitTest"`           // generate unit test for query code
+	UnitTestDB             string              `yaml:"unitTestDB"`             // database of unit tests: sqlite(default) or testcontainers
+	ModelPkgName           string              `yaml:"modelPkgName"`           // generated model code's package name
+	ModelLayout            string              `yaml:"modelLayout"`            // layout of model files: table(default) or single
+	ModelPackages          map[string]string   `yaml:"modelPackages"`          // table name pattern -> sub-package of model package
+	FieldNullable          bool                `yaml:"fieldNullable"`          // generate with pointer when field is nullable
+	FieldWithIndexTag      bool                `yaml:"fieldWithIndexTag"`      // generate field with gorm index tag
+	FieldWithTypeTag       bool                `yaml:"fieldWithTypeTag"`       // generate field with gorm column type tag
+	FieldSignable          bool                `yaml:"fieldSignable"`          // detect integer field's unsigned type, adjust generated data type
+	FieldDefaultTag        bool                `yaml:"fieldDefaultTag"`        // generate pointer for field whose column default is non-zero
+	FieldWithEnumType      bool                `yaml:"fieldWithEnumType"`      // generate named type with constants for enum column
+	FieldWithCommentEnum   bool                `yaml:"fieldWithCommentEnum"`   // generate named type with constants for integer column declared in comment
+	FieldWithDocComment    bool                `yaml:"fieldWithDocComment"`    // render column comments as doc comments above fields
+	FieldOmitDeprecated    bool                `yaml:"fieldOmitDeprecated"`    // tag fields of DEPRECATED: columns with <-:update, so create omits them
+	ArrayTypeMap           map[string]string   `yaml:"arrayTypeMap"`           // go type of postgres array column by element data type
+	FieldWithJSONType      bool                `yaml:"fieldWithJSONType"`      // generate json/jsonb column as datatypes.JSON
+	FieldWithUUIDType      bool                `yaml:"fieldWithUUIDType"`      // generate uuid column as uuid.UUID
+	UUIDColumnTypes        []string            `yaml:"uuidColumnTypes"`        // column types also generated as uuid.UUID, e.g. char(36), binary(16)
+	UUIDHook               bool                `yaml:"uuidHook"`               // generate BeforeCreate hook populating zero uuid primary key
+	DecimalType            string              `yaml:"decimalType"`            // go type of decimal column: shopspring, string or float64
+	NullableStyle          string              `yaml:"nullableStyle"`          // type of nullable field: pointer, sqlnull or genericNull
+	DateType               string              `yaml:"dateType"`               // go type of date column: time, datatypes or string
+	TimeType               string              `yaml:"timeType"`               // go type of time column: time, datatypes or string
+	DateTimeType           string              `yaml:"dateTimeType"`           // go type of datetime/timestamp column: time or string
+	TimeUTC                bool                `yaml:"timeUTC"`                // generate hooks converting time fields to UTC
+	FieldWithGeometryType  bool                `yaml:"fieldWithGeometryType"`  // generate spatial column as named type of orb geometry
+	GeometryTypeMap        map[string]string   `yaml:"geometryTypeMap"`        // column name -> orb type of spatial column
+	IntegerTypeMap         map[string]string   `yaml:"integerTypeMap"`         // column type or data type -> go type of integer column
+	FieldWithConstraintTag bool                `yaml:"fieldWithConstraintTag"` // generate field with gorm check tag of check constraints
+	FieldWithRelation      bool                `yaml:"fieldWithRelation"`      // generate relation fields of foreign keys
+	Relations              map[string][]string `yaml:"relations"`              // table name -> relations: <relationship>:<field>:<table>[:<gorm tag>]
+	FieldWithNameConst     bool                `yaml:"fieldWithNameConst"`     // generate constants of column and index names of models
+	LookupTables           map[string]string   `yaml:"lookupTables"`           // lookup table -> <id column>:<code column> of rows generated as constants
+	NoPrimaryKey           string              `yaml:"noPrimaryKey"`           // handling of tables without primary key: skip, readOnly or uniqueIndex
+	LogicalKeys            map[string]string   `yaml:"logicalKeys"`            // table without primary key -> unique index used as logical key
+	WithExists             bool                `yaml:"withExists"`             // generate Exists, ExistsBy<PK> and CountBy<IndexedColumn> methods
+	WithEach               bool                `yaml:"withEach"`               // generate Each and EachInBatches methods streaming records
+	WithLocking            bool                `yaml:"withLocking"`            // generate ForUpdate and ForShare row locking methods
+	VersionColumn          string              `yaml:"versionColumn"`          // column used for optimistic locking
+	WithCache              bool                `yaml:"withCache"`              // generate GetBy<PK> methods served by cache
+	WithTracing            bool                `yaml:"withTracing"`            // trace statements by OpenTelemetry global tracer
+	WithResolver           bool                `yaml:"withResolver"`           // generate ReadOnly, Primary, UseResolver and Sticky methods
+	WithPreload            bool                `yaml:"withPreload"`            // generate With<Relation> preload methods
+	WithFilter             bool                `yaml:"withFilter"`             // generate <Model>Filter structs and ApplyFilter methods
+	WithSort               bool                `yaml:"withSort"`               // generate <Model>Field enums, OrderBy and SelectFields methods
+	WithBatch              bool                `yaml:"withBatch"`              // generate UpdateColumnsInBatches and DeleteBy<PK>s methods
+	BatchSize              int                 `yaml:"batchSize"`              // default batch size of batch methods
+	WithReturning          bool                `yaml:"withReturning"`          // generate UpdateReturning/DeleteReturning methods
+	WithAffected           bool                `yaml:"withAffected"`           // generate <Method>Affected methods returning affected rows
+	StrictRowsAffected     bool                `yaml:"strictRowsAffected"`     // return gen.ErrNoRowsAffected when no row is affected
+	UnscopedLookup         bool                `yaml:"unscopedLookup"`         // unique lookup methods also find soft-deleted records
+	WithHint               bool                `yaml:"withHint"`               // generate index constants and hint methods
+	WithGenericDAO         bool                `yaml:"withGenericDAO"`         // generate CRUD methods once as generic core shared by tables
+	WithFakeDAO            bool                `yaml:"withFakeDAO"`            // generate in-memory fake DAO of each table for unit tests
+	WithImportExport       bool                `yaml:"withImportExport"`       // generate CSV and JSON Lines import/export methods
+	TenantColumn           string              `yaml:"tenantColumn"`           // column used for tenant scope
+	ShardedTables          map[string]string   `yaml:"shardedTables"`          // sharded table name -> suffix pattern
+	ExtraModelMethods      []string            `yaml:"extraModelMethods"`      // extra methods of models: String, IsZero, PrimaryKey, Clone, Validate
+	ValidateNotNullZero    bool                `yaml:"validateNotNullZero"`    // Validate reports zero value of NOT NULL column
+	WithMethods            map[string][]string `yaml:"withMethods"`            // table name("*" for all) -> types whose methods are copied onto model
+	BaseModelColumns       []string            `yaml:"baseModelColumns"`       // columns extracted into embedded BaseModel
+	WithHooks              []string            `yaml:"withHooks"`              // gorm hooks whose stubs are generated once
+	PIIColumns             []string            `yaml:"piiColumns"`             // sensitive columns encrypted and masked, e.g. users.email
+	ExcludeColumns         []string            `yaml:"excludeColumns"`         // columns not generated in models, e.g. users.password
+	IgnoreColumns          map[string][]string `yaml:"ignoreColumns"`          // table name("*" for all) -> columns ignored by models, see gen.FieldIgnore
+	ReadOnlyColumns        map[string][]string `yaml:"readOnlyColumns"`        // table name("*" for all) -> columns generated with read-only tag ->
+	Proto                  string              `yaml:"proto"`                  // directory for exported .proto files
+	ProtoService           bool                `yaml:"protoService"`           // export CRUD service definitions in .proto files
+	OpenAPI                string              `yaml:"openapi"`                // file for exported OpenAPI components.schemas
+	TypeScript             string              `yaml:"typescript"`             // directory for exported .d.ts files
+	WithHandlers           string              `yaml:"withHandlers"`           // generate CRUD handlers of framework gin or echo
+	WithDI                 string              `yaml:"withDI"`                 // generate providers of query and DAOs for wire or fx
+	DTO                    string              `yaml:"dto"`                    // directory for generated DTO package
+	DTOExclude             []string            `yaml:"dtoExclude"`             // columns excluded from DTO
+	ERD                    string              `yaml:"erd"`                    // export ER diagram in format mermaid or graphviz
+	SchemaJSON             string              `yaml:"schemaJSON"`             // file for exported metadata of tables
+	FromSchemaJSON         string              `yaml:"fromSchemaJSON"`         // generate from metadata file instead of db
+	LockFile               string              `yaml:"lockFile"`               // lock file storing schema fingerprints of tables
+	Force                  bool                `yaml:"force"`                  // regenerate tables even if unchanged
+	Concurrency            int                 `yaml:"concurrency"`            // number of tables introspected and rendered concurrently
+	Prune                  bool                `yaml:"prune"`                  // remove generated files of tables which no longer exist
+	Manifest               string              `yaml:"manifest"`               // manifest file listing generated files with source table and hash
+	Verify                 string              `yaml:"verify"`                 // verify generated code after writing: build
+	ModelTemplateDir       string              `yaml:"modelTemplateDir"`       // directory of templates overriding built-in model templates
+	QueryTemplateDir       string              `yaml:"queryTemplateDir"`       // directory of templates overriding built-in query templates
+	FileHeader             string              `yaml:"fileHeader"`             // text or path of file injected at the top of generated files
+	BuildTags              string              `yaml:"buildTags"`              // build constraint of generated files
+	Formatter              string              `yaml:"formatter"`              // formatter of generated files: goimports, gofmt or gofumpt
+	Provenance             bool                `yaml:"provenance"`             // emit provenance header in generated files
+	OmitTimestamp          bool                `yaml:"omitTimestamp"`          // omit generation time in provenance header
+	Plugins                []string            `yaml:"plugins"`                // Go plugins(.so) or executables hooking generation
+	Hooks                  HooksConfig         `yaml:"hooks"`                  // shell commands run at stages of generation
+	WatchInterval          int                 `yaml:"watchInterval"`          // seconds between polls of watch
+	WatchDebounce          int                 `yaml:"watchDebounce"`          // seconds changes must settle before watch regenerates
+	DDLDir                 string              `yaml:"ddlDir"`                 // directory of DDL files watched instead of db schema
+	Notify                 string              `yaml:"notify"`                 // shell command run after watch regenerates
+	Migrate                string              `yaml:"migrate"`                // output of migrate: automigrate, golang-migrate or goose
+	MigrateDir             string              `yaml:"migrateDir"`             // directory of SQL migration files, default migrations next to outPath
+	MigrateSnapshot        string              `yaml:"migrateSnapshot"`        // schema snapshot SQL migration is diffed from, default schema.json of migrateDir
+	MigrateName            string              `yaml:"migrateName"`            // name of SQL migration files after version, default gen
+	DiffBaseline           string              `yaml:"diffBaseline"`           // schema JSON, lock file or model directory diff compares db with
+	Verbose                bool                `yaml:"verbose"`                // log debug messages and sql of introspection
+	Quiet                  bool                `yaml:"quiet"`                  // only log warnings and errors
+	LogFormat              string              `yaml:"logFormat"`              // log format: text(default) or json
+	Progress               int                 `yaml:"progress"`               // show progress of more than N tables, default 50
+	ConnectTimeout         int                 `yaml:"connectTimeout"`         // seconds of timeout connecting db
+	ReadTimeout            int                 `yaml:"readTimeout"`            // seconds of timeout reading results, statement_timeout for postgres
+	TLSCA                  string              `yaml:"tlsCA"`                  // path of CA certificate verifying db server
+	TLSCert                string              `yaml:"tlsCert"`                // path of client certificate
+	TLSKey                 string              `yaml:"tlsKey"`                 // path of client private key
+	TLSSkipVerify          bool                `yaml:"tlsSkipVerify"`          // connect with tls without verifying certificate of server
+	Charset                string              `yaml:"charset"`                // charset of session, mysql and postgres only
+	SearchPath             string              `yaml:"searchPath"`             // search_path of session, postgres only
+	SSH                    SSHConfig           `yaml:"ssh"`                    // ssh tunnel through bastion host db is dialed by
+
+	ModelOpts      map[string][]gen.ModelOpt                            `yaml:"-"` // table name("*" for all) -> options of its model, e.g. gen.FieldType
+	Conn           *gorm.DB                                             `yaml:"-"` // opened db used instead of connecting dsn
+	BeforeGenerate func(g *gen.Generator, db *gorm.DB, c *Config) error `yaml:"-"` // called before tables are introspected, db is nil if metadata file is used
+	OnProgress     func(gen.Progress)                                   `yaml:"-"` // called once a table of stage is done, see gen.Config.WithProgress
+	Logger         Logger                                               `yaml:"-"` // logger of gentool messages, default std log
+}
+
+// Logger log messages of gentool, e.g. ssh tunnel is connected
+type Logger interface {
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// stdLogger Logger writing std log, which is also used by gen
+type stdLogger struct{}
+
+func (stdLogger) Infof(format string, args ...interface{}) { log.Printf(format, args...) }
+
+func (stdLogger) Warnf(format string, args ...interface{}) { log.Printf("WARN "+format, args...) }
+
+func (c *Config) logger() Logger {
+	if c.Logger == nil {
+		return stdLogger{}
+	}
+	return c.Logger
+}
+
+// seconds duration of n seconds, defaultSeconds if n is not positive
+func seconds(n, defaultSeconds int) time.Duration {
+	if n <= 0 {
+		n = defaultSeconds
+	}
+	return time.Duration(n) * time.Second
+}
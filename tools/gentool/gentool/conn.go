@@ -0,0 +1,78 @@
+package gentool
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// HasTLSOptions whether any tls option is set, db is connected with tls then
+func (c *Config) HasTLSOptions() bool {
+	return c.TLSCA != "" || c.TLSCert != "" || c.TLSKey != "" || c.TLSSkipVerify
+}
+
+// newDialector dialector of registered driver of db, connection options of config are applied by driver.
+// Drivers dial db through ssh tunnel if ssh host is set
+func newDialector(config *Config) (gorm.Dialector, error) {
+	d, err := lookupDriver(config.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts ConnOptions
+	if config.ConnectTimeout > 0 {
+		opts.ConnectTimeout = seconds(config.ConnectTimeout, 0)
+	}
+	if config.ReadTimeout > 0 {
+		opts.ReadTimeout = seconds(config.ReadTimeout, 0)
+	}
+	if opts.TLS, err = loadTLSConfig(config); err != nil {
+		return nil, err
+	}
+	if config.SSH.Host != "" {
+		opts.Dial = newSSHTunnel(config).DialContext
+	}
+	opts.Charset, opts.SearchPath = config.Charset, config.SearchPath
+	return d.open(config.DSN, opts)
+}
+
+// loadTLSConfig tls config of CA, client certificate and key files, nil if no tls option is set
+func loadTLSConfig(config *Config) (*tls.Config, error) {
+	if !config.HasTLSOptions() {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.TLSSkipVerify} // nolint
+	if config.TLSCA != "" {
+		pem, err := os.ReadFile(config.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("read tlsCA fail: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificate found in tlsCA %s", config.TLSCA)
+		}
+	}
+	if config.TLSCert != "" || config.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCert, config.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("load tlsCert and tlsKey fail: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// setServerName verify certificate of server against host of addr unless server name is set
+func setServerName(tlsConfig *tls.Config, addr string) {
+	if tlsConfig.ServerName != "" || tlsConfig.InsecureSkipVerify {
+		return
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	tlsConfig.ServerName = addr
+}
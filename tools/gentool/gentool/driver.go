@@ -0,0 +1,77 @@
+package gentool
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ConnOptions connection options applied besides dsn, drivers apply options they support
+type ConnOptions struct {
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	TLS            *tls.Config                                                       // nil if no tls option is set
+	Dial           func(ctx context.Context, network, addr string) (net.Conn, error) // dial through ssh tunnel, nil if it's not set
+	Charset        string
+	SearchPath     string
+}
+
+// isZero whether no option is set, dsn should be used as it is
+func (o ConnOptions) isZero() bool {
+	return o.ConnectTimeout == 0 && o.ReadTimeout == 0 && o.TLS == nil && o.Dial == nil && o.Charset == "" && o.SearchPath == ""
+}
+
+// OpenFunc open dialector of dsn with connection options
+type OpenFunc func(dsn string, opts ConnOptions) (gorm.Dialector, error)
+
+// Introspector list tables of db for drivers whose GORM migrator doesn't list tables properly
+type Introspector func(db *gorm.DB) ([]string, error)
+
+type driver struct {
+	open       OpenFunc
+	introspect Introspector // nil if tables are listed by GORM migrator
+}
+
+var drivers = make(map[string]driver)
+
+// RegisterDriver register driver of db name used by Config.DB(-db of gentool), introspect can be nil.
+// Built-in drivers are registered by driver_*.go files and can be excluded by build tags like nosqlserver,
+// other databases are compiled in by adding a file registering its driver in init
+func RegisterDriver(name string, open OpenFunc, introspect Introspector) {
+	if _, ok := drivers[name]; ok {
+		panic("gentool: driver " + name + " is registered twice")
+	}
+	drivers[name] = driver{open: open, introspect: introspect}
+}
+
+// Drivers sorted names of registered drivers
+func Drivers() []string {
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupDriver(name string) (driver, error) {
+	d, ok := drivers[name]
+	if !ok {
+		return driver{}, fmt.Errorf("unknown db %q (support %s)", name, strings.Join(Drivers(), " || "))
+	}
+	return d, nil
+}
+
+// Tables all tables of db by introspector of driver dbType, or GORM migrator
+func Tables(db *gorm.DB, dbType string) ([]string, error) {
+	if d, ok := drivers[dbType]; ok && d.introspect != nil {
+		return d.introspect(db)
+	}
+	return db.Migrator().GetTables()
+}
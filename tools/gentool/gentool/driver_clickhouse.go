@@ -0,0 +1,46 @@
+//go:build !noclickhouse
+
+package gentool
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	chdriver "github.com/ClickHouse/clickhouse-go/v2"
+	"gorm.io/driver/clickhouse"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDriver(string(ClickHouse), openClickHouse, nil)
+}
+
+func openClickHouse(dsn string, opts ConnOptions) (gorm.Dialector, error) {
+	if opts.isZero() {
+		return clickhouse.Open(dsn), nil
+	}
+
+	chOpts, err := chdriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse clickhouse dsn fail: %w", err)
+	}
+	if opts.ConnectTimeout > 0 {
+		chOpts.DialTimeout = opts.ConnectTimeout
+	}
+	if opts.ReadTimeout > 0 {
+		chOpts.ReadTimeout = opts.ReadTimeout
+	}
+	if opts.TLS != nil {
+		if len(chOpts.Addr) > 0 {
+			setServerName(opts.TLS, chOpts.Addr[0])
+		}
+		chOpts.TLS = opts.TLS
+	}
+	if opts.Dial != nil {
+		chOpts.DialContext = func(ctx context.Context, addr string) (net.Conn, error) {
+			return opts.Dial(ctx, "tcp", addr)
+		}
+	}
+	return clickhouse.New(clickhouse.Config{Conn: chdriver.OpenDB(chOpts)}), nil
+}
@@ -0,0 +1,60 @@
+//go:build !nomysql
+
+package gentool
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	mysqlTLSName = "gentool"     // name of tls config registered for mysql driver
+	mysqlSSHNet  = "gentool-ssh" // name of network dialed through ssh tunnel registered for mysql driver
+)
+
+func init() {
+	RegisterDriver(string(MySQL), openMySQL, nil)
+}
+
+func openMySQL(dsn string, opts ConnOptions) (gorm.Dialector, error) {
+	if opts.isZero() {
+		return mysql.Open(dsn), nil
+	}
+
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse mysql dsn fail: %w", err)
+	}
+	if opts.ConnectTimeout > 0 {
+		cfg.Timeout = opts.ConnectTimeout
+	}
+	if opts.ReadTimeout > 0 {
+		cfg.ReadTimeout = opts.ReadTimeout
+	}
+	if opts.TLS != nil {
+		setServerName(opts.TLS, cfg.Addr)
+		if err = mysqldriver.RegisterTLSConfig(mysqlTLSName, opts.TLS); err != nil {
+			return nil, fmt.Errorf("register mysql tls config fail: %w", err)
+		}
+		cfg.TLSConfig = mysqlTLSName
+	}
+	if opts.Charset != "" {
+		if cfg.Params == nil {
+			cfg.Params = make(map[string]string)
+		}
+		cfg.Params["charset"] = opts.Charset
+	}
+	if opts.Dial != nil {
+		network := cfg.Net
+		mysqldriver.RegisterDialContext(mysqlSSHNet, func(ctx context.Context, addr string) (net.Conn, error) {
+			return opts.Dial(ctx, network, addr)
+		})
+		cfg.Net = mysqlSSHNet
+	}
+	return mysql.New(mysql.Config{DSNConfig: cfg}), nil
+}
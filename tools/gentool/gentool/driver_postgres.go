@@ -0,0 +1,52 @@
+//go:build !nopostgres
+
+package gentool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDriver(string(Postgres), openPostgres, nil)
+}
+
+// openPostgres apply options on pgx config, read timeout is applied as statement_timeout of session
+func openPostgres(dsn string, opts ConnOptions) (gorm.Dialector, error) {
+	if opts.isZero() {
+		return postgres.Open(dsn), nil
+	}
+
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse postgres dsn fail: %w", err)
+	}
+	if opts.ConnectTimeout > 0 {
+		cfg.ConnectTimeout = opts.ConnectTimeout
+	}
+	if opts.TLS != nil {
+		setServerName(opts.TLS, cfg.Host)
+		cfg.TLSConfig, cfg.Fallbacks = opts.TLS, nil // no fallback to plaintext connection of sslmode=prefer
+	}
+	if opts.ReadTimeout > 0 {
+		cfg.RuntimeParams["statement_timeout"] = strconv.FormatInt(opts.ReadTimeout.Milliseconds(), 10)
+	}
+	if opts.Charset != "" {
+		cfg.RuntimeParams["client_encoding"] = opts.Charset
+	}
+	if opts.SearchPath != "" {
+		cfg.RuntimeParams["search_path"] = opts.SearchPath
+	}
+	if opts.Dial != nil {
+		cfg.DialFunc = opts.Dial
+		// host is resolved by bastion
+		cfg.LookupFunc = func(_ context.Context, host string) ([]string, error) { return []string{host}, nil }
+	}
+	return postgres.New(postgres.Config{Conn: stdlib.OpenDB(*cfg)}), nil
+}
@@ -0,0 +1,17 @@
+//go:build !nosqlite
+
+package gentool
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDriver(string(SQLite), openSQLite, nil)
+}
+
+// openSQLite open sqlite file, connection options are not supported
+func openSQLite(dsn string, _ ConnOptions) (gorm.Dialector, error) {
+	return sqlite.Open(dsn), nil
+}
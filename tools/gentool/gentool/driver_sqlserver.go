@@ -0,0 +1,60 @@
+//go:build !nosqlserver
+
+package gentool
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterDriver(string(SQLServer), openSQLServer, sqlServerTables)
+}
+
+// sqlServerTables tables of all schemas, tables of other schemas than default schema of user are qualified by schema,
+// e.g. users, sales.orders
+func sqlServerTables(db *gorm.DB) (tables []string, err error) {
+	err = db.Raw("SELECT CASE WHEN SCHEMA_NAME(schema_id) = SCHEMA_NAME() THEN name ELSE SCHEMA_NAME(schema_id) + '.' + name END " +
+		"FROM sys.tables WHERE is_ms_shipped = 0 ORDER BY SCHEMA_NAME(schema_id), name").Scan(&tables).Error
+	return tables, err
+}
+
+func openSQLServer(dsn string, opts ConnOptions) (gorm.Dialector, error) {
+	if opts.isZero() {
+		return sqlserver.Open(dsn), nil
+	}
+
+	cfg, _, err := msdsn.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse sqlserver dsn fail: %w", err)
+	}
+	if opts.ConnectTimeout > 0 {
+		cfg.DialTimeout = opts.ConnectTimeout
+	}
+	if opts.ReadTimeout > 0 {
+		cfg.ConnTimeout = opts.ReadTimeout
+	}
+	if opts.TLS != nil {
+		setServerName(opts.TLS, cfg.Host)
+		cfg.TLSConfig, cfg.Encryption = opts.TLS, msdsn.EncryptionRequired
+	}
+	connector := mssql.NewConnectorConfig(cfg)
+	if opts.Dial != nil {
+		connector.Dialer = dialerFunc(opts.Dial)
+	}
+	return sqlserver.New(sqlserver.Config{Conn: sql.OpenDB(connector)}), nil
+}
+
+// dialerFunc mssql.Dialer of dial function
+type dialerFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (f dialerFunc) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
@@ -0,0 +1,419 @@
+package gentool
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+)
+
+// Report result of generation
+type Report struct {
+	gen.Summary               // files written and skipped, empty of Check and Export
+	Tables      int           // number of tables introspected
+	Elapsed     time.Duration // time of connecting, introspecting and generating
+}
+
+// Run generate models and query code of tables by config, packages and exports of config are generated too,
+// then afterGenerate hooks are run. Generator panics on failure, which is returned as error
+func Run(ctx context.Context, config Config) (report Report, err error) {
+	defer recoverError(&err)
+	start := time.Now()
+	g := NewGenerator(&config)
+	addPackageExports(g, &config)
+	addExports(g, &config)
+	closePlugins, err := usePlugins(g, &config)
+	if err != nil {
+		return report, err
+	}
+	defer closeWith(closePlugins, &err)
+
+	models, err := introspect(ctx, g, &config)
+	if err != nil {
+		return report, err
+	}
+	if !config.OnlyModel {
+		g.ApplyBasic(models...)
+	}
+	g.Execute()
+	report = Report{Summary: g.Summary(), Tables: len(models)}
+	if err = runAfterGenerate(&config, &report); err != nil {
+		return report, err
+	}
+	report.Elapsed = time.Since(start)
+	return report, nil
+}
+
+// Check introspect tables by config without generating code
+func Check(ctx context.Context, config Config) (report Report, err error) {
+	defer recoverError(&err)
+	start := time.Now()
+	g := NewGenerator(&config)
+	closePlugins, err := usePlugins(g, &config)
+	if err != nil {
+		return report, err
+	}
+	defer closeWith(closePlugins, &err)
+
+	models, err := introspect(ctx, g, &config)
+	if err != nil {
+		return report, err
+	}
+	return Report{Tables: len(models), Elapsed: time.Since(start)}, nil
+}
+
+// Export export models of tables into formats of config(proto, openapi, typescript, schemaJSON, erd) without
+// generating code
+func Export(ctx context.Context, config Config) (report Report, err error) {
+	defer recoverError(&err)
+	start := time.Now()
+	g := NewGenerator(&config)
+	addExports(g, &config)
+	closePlugins, err := usePlugins(g, &config)
+	if err != nil {
+		return report, err
+	}
+	defer closeWith(closePlugins, &err)
+
+	models, err := introspect(ctx, g, &config)
+	if err != nil {
+		return report, err
+	}
+	g.Export()
+	return Report{Tables: len(models), Elapsed: time.Since(start)}, nil
+}
+
+// Migrate write automigrate.gen.go registering models into model package(migrate automigrate), or versioned SQL
+// migration of difference between models and schema snapshot(migrate golang-migrate or goose) without generating code
+func Migrate(ctx context.Context, config Config) (report Report, err error) {
+	defer recoverError(&err)
+	start := time.Now()
+	g := NewGenerator(&config)
+	switch config.Migrate {
+	case "automigrate":
+		g.WithAutoMigrateExport()
+	case "golang-migrate", "goose":
+		dir, snapshot := config.MigrateDir, config.MigrateSnapshot
+		if dir == "" {
+			dir = filepath.Join(filepath.Dir(config.OutPath), "migrations")
+		}
+		if snapshot == "" {
+			snapshot = filepath.Join(dir, "schema.json")
+		}
+		g.WithMigrationExport(dir, config.Migrate, snapshot, config.MigrateName)
+	default:
+		return report, fmt.Errorf("unknown migrate %q (support automigrate || golang-migrate || goose)", config.Migrate)
+	}
+	closePlugins, err := usePlugins(g, &config)
+	if err != nil {
+		return report, err
+	}
+	defer closeWith(closePlugins, &err)
+
+	models, err := introspect(ctx, g, &config)
+	if err != nil {
+		return report, err
+	}
+	g.Export()
+	return Report{Summary: g.Summary(), Tables: len(models), Elapsed: time.Since(start)}, nil
+}
+
+// Diff compare schema of tables in db with DiffBaseline(schema JSON, lock file or directory of models generated
+// before), default LockFile if it's set or models in model package, without generating code
+func Diff(ctx context.Context, config Config) (drift *gen.Drift, err error) {
+	defer recoverError(&err)
+	g := NewGenerator(&config)
+	closePlugins, err := usePlugins(g, &config)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWith(closePlugins, &err)
+
+	if _, err = introspect(ctx, g, &config); err != nil {
+		return nil, err
+	}
+	baseline := config.DiffBaseline
+	if baseline == "" {
+		baseline = config.LockFile
+	}
+	return g.Drift(baseline)
+}
+
+// introspect use source of config, call BeforeGenerate hook and introspect tables into models
+func introspect(ctx context.Context, g *gen.Generator, config *Config) ([]interface{}, error) {
+	db, err := useSource(ctx, g, config)
+	if err != nil {
+		return nil, err
+	}
+	if config.BeforeGenerate != nil {
+		if err = config.BeforeGenerate(g, db, config); err != nil {
+			return nil, err
+		}
+	}
+	models, err := genModels(g, db, config)
+	if err != nil {
+		return nil, fmt.Errorf("get tables info fail: %w", err)
+	}
+	return models, ctx.Err()
+}
+
+// closeWith call close, its error is returned as err unless err is set
+func closeWith(close func() error, err *error) {
+	if e := close(); e != nil && *err == nil {
+		*err = e
+	}
+}
+
+// recoverError return panic of generator as error
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%v", r)
+	}
+}
+
+// Connect connect to database of config by registered driver of DB
+func Connect(config *Config) (*gorm.DB, error) {
+	if config.DSN == "" {
+		return nil, fmt.Errorf("dsn cannot be empty")
+	}
+
+	dialector, err := newDialector(config)
+	if err != nil {
+		return nil, err
+	}
+	return gorm.Open(dialector)
+}
+
+// genModels is gorm/gen generated models
+func genModels(g *gen.Generator, db *gorm.DB, config *Config) (models []interface{}, err error) {
+	var tablesList []string
+	tables := config.Tables
+	if len(tables) == 0 && db == nil {
+		// Execute tasks for all tables in the schema
+		return g.GenerateAllTable(), nil
+	} else if len(tables) == 0 {
+		// Execute tasks for all tables in the database
+		tablesList, err = Tables(db, config.DB)
+		if err != nil {
+			return nil, fmt.Errorf("get all tables fail: %w", err)
+		}
+		sort.Strings(tablesList)
+	} else {
+		tablesList = tables
+	}
+
+	// Execute some data table tasks
+	return g.GenerateModels(tablesList), nil
+}
+
+// NewGenerator create generator of options of config, exporters and source are added by Run, Check and Export
+func NewGenerator(config *Config) *gen.Generator {
+	var mode gen.GenerateMode
+	if config.WithExists {
+		mode |= gen.WithExistsMethod
+	}
+	if config.WithEach {
+		mode |= gen.WithEachMethod
+	}
+	if config.WithLocking {
+		mode |= gen.WithLockingMethod
+	}
+	if config.WithCache {
+		mode |= gen.WithCacheMethod
+	}
+	if config.WithResolver {
+		mode |= gen.WithResolverMethod
+	}
+	if config.WithPreload {
+		mode |= gen.WithPreloadMethod
+	}
+	if config.WithFilter {
+		mode |= gen.WithFilterMethod
+	}
+	if config.WithSort {
+		mode |= gen.WithSortMethod
+	}
+	if config.WithBatch {
+		mode |= gen.WithBatchMethod
+	}
+	if config.WithReturning {
+		mode |= gen.WithReturningMethod
+	}
+	if config.WithAffected {
+		mode |= gen.WithAffectedMethod
+	}
+	if config.WithHint {
+		mode |= gen.WithHintMethod
+	}
+	if config.WithGenericDAO {
+		mode |= gen.WithGenericDAO
+	}
+	if config.WithFakeDAO {
+		mode |= gen.WithFakeDAO
+	}
+	if config.WithImportExport {
+		mode |= gen.WithImportExportMethod
+	}
+	if config.WithDI != "" {
+		mode |= gen.WithQueryInterface
+	}
+
+	g := gen.NewGenerator(gen.Config{
+		OutPath:           config.OutPath,
+		OutFile:           config.OutFile,
+		ModelPkgPath:      config.ModelPkgName,
+		ModelLayout:       config.ModelLayout,
+		ModelPackages:     config.ModelPackages,
+		WithUnitTest:      config.WithUnitTest,
+		UnitTestDB:        config.UnitTestDB,
+		FieldNullable:     config.FieldNullable,
+		FieldWithIndexTag: config.FieldWithIndexTag,
+		FieldWithTypeTag:  config.FieldWithTypeTag,
+		FieldSignable:     config.FieldSignable,
+		FieldCoverable:    config.FieldDefaultTag,
+		FieldWithEnumType: config.FieldWithEnumType,
+
+		FieldWithCommentEnum:   config.FieldWithCommentEnum,
+		FieldWithDocComment:    config.FieldWithDocComment,
+		FieldOmitDeprecated:    config.FieldOmitDeprecated,
+		ArrayTypeMap:           config.ArrayTypeMap,
+		FieldWithJSONType:      config.FieldWithJSONType,
+		FieldWithUUIDType:      config.FieldWithUUIDType,
+		UUIDColumnTypes:        config.UUIDColumnTypes,
+		UUIDHook:               config.UUIDHook,
+		DecimalType:            config.DecimalType,
+		NullableStyle:          config.NullableStyle,
+		DateType:               config.DateType,
+		TimeType:               config.TimeType,
+		DateTimeType:           config.DateTimeType,
+		TimeUTC:                config.TimeUTC,
+		FieldWithGeometryType:  config.FieldWithGeometryType,
+		IntegerTypeMap:         map[string]map[string]string{config.DB: config.IntegerTypeMap},
+		FieldWithConstraintTag: config.FieldWithConstraintTag,
+		FieldWithRelation:      config.FieldWithRelation,
+		Relations:              config.Relations,
+		FieldWithNameConst:     config.FieldWithNameConst,
+		LookupTables:           config.LookupTables,
+		NoPrimaryKey:           config.NoPrimaryKey,
+		LogicalKeys:            config.LogicalKeys,
+		BatchSize:              config.BatchSize,
+		StrictRowsAffected:     config.StrictRowsAffected,
+		Tracing:                config.WithTracing,
+		UnscopedLookup:         config.UnscopedLookup,
+		VersionColumn:          config.VersionColumn,
+		TenantColumn:           config.TenantColumn,
+		ShardedTables:          config.ShardedTables,
+		ExtraModelMethods:      config.ExtraModelMethods,
+		ValidateNotNullZero:    config.ValidateNotNullZero,
+		TableMethods:           config.WithMethods,
+		BaseModelColumns:       config.BaseModelColumns,
+		WithHooks:              config.WithHooks,
+		PIIColumns:             config.PIIColumns,
+		ExcludeColumns:         config.ExcludeColumns,
+		LockFile:               config.LockFile,
+		ForceGenerate:          config.Force,
+		Concurrency:            config.Concurrency,
+		Prune:                  config.Prune && len(config.Tables) == 0,
+		ManifestFile:           config.Manifest,
+		Verify:                 config.Verify,
+		ModelTemplateDir:       config.ModelTemplateDir,
+		QueryTemplateDir:       config.QueryTemplateDir,
+		FileHeader:             config.FileHeader,
+		BuildTags:              config.BuildTags,
+		Formatter:              config.Formatter,
+		Provenance:             config.Provenance,
+		OmitTimestamp:          config.OmitTimestamp,
+		Mode:                   mode,
+		TableModelOpts:         tableModelOpts(config),
+	})
+
+	for column, typ := range config.GeometryTypeMap {
+		g.WithOpts(gen.FieldGeometryType(column, typ))
+	}
+	if config.OnProgress != nil {
+		g.WithProgress(config.OnProgress)
+	}
+
+	return g
+}
+
+// tableModelOpts options of models of ModelOpts, with FieldIgnore of IgnoreColumns and read-only tag of
+// ReadOnlyColumns appended
+func tableModelOpts(config *Config) map[string][]gen.ModelOpt {
+	if len(config.IgnoreColumns) == 0 && len(config.ReadOnlyColumns) == 0 {
+		return config.ModelOpts
+	}
+
+	opts := make(map[string][]gen.ModelOpt, len(config.ModelOpts))
+	for table, o := range config.ModelOpts {
+		opts[table] = append([]gen.ModelOpt(nil), o...)
+	}
+	for table, columns := range config.IgnoreColumns {
+		opts[table] = append(opts[table], gen.FieldIgnore(columns...))
+	}
+	for table, columns := range config.ReadOnlyColumns {
+		for _, column := range columns {
+			opts[table] = append(opts[table], gen.FieldGORMTag(column, func(tag field.GormTag) field.GormTag {
+				return tag.Set(field.TagKeyGormReadOnly)
+			}))
+		}
+	}
+	return opts
+}
+
+// addPackageExports generate DTO, handler and DI packages of flags of packageFlags
+func addPackageExports(g *gen.Generator, config *Config) {
+	if config.DTO != "" {
+		g.WithDTOExport(config.DTO, config.DTOExclude...)
+	}
+	if config.WithHandlers != "" && !config.OnlyModel {
+		g.WithHandlerExport(filepath.Join(filepath.Dir(config.OutPath), "handler"), config.WithHandlers)
+	}
+	if config.WithDI != "" && !config.OnlyModel {
+		g.WithDIExport(config.WithDI)
+	}
+}
+
+// addExports export models into formats of flags of exportFlags
+func addExports(g *gen.Generator, config *Config) {
+	if config.Proto != "" {
+		g.WithProtoExport(config.Proto, config.ProtoService)
+	}
+	if config.OpenAPI != "" {
+		g.WithOpenAPIExport(config.OpenAPI)
+	}
+	if config.TypeScript != "" {
+		g.WithTypeScriptExport(config.TypeScript)
+	}
+	if config.SchemaJSON != "" {
+		g.WithSchemaJSONExport(config.SchemaJSON)
+	}
+	if config.ERD != "" {
+		erdFile := "erd.mmd"
+		if config.ERD == "graphviz" {
+			erdFile = "erd.dot"
+		}
+		g.WithERDExport(filepath.Join(filepath.Dir(config.OutPath), erdFile), config.ERD)
+	}
+}
+
+// useSource use metadata file of fromSchemaJSON, Conn or connect db, db is nil if metadata file is used
+func useSource(ctx context.Context, g *gen.Generator, config *Config) (db *gorm.DB, err error) {
+	if config.FromSchemaJSON != "" {
+		g.UseSchemaJSON(config.FromSchemaJSON)
+		return nil, nil
+	}
+	if db = config.Conn; db == nil {
+		if db, err = Connect(config); err != nil {
+			return nil, fmt.Errorf("connect db server fail: %w", err)
+		}
+	}
+	db = db.WithContext(ctx)
+	g.UseDB(db)
+	return db, nil
+}
@@ -0,0 +1,1082 @@
+package gentool
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+func TestSQLiteColumns(t *testing.T) {
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(t.TempDir(), "columns.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	for _, ddl := range []string{
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, payload ANY, kind TEXT NOT NULL DEFAULT 'click', n INT) STRICT",
+		"CREATE TABLE tags (name TEXT PRIMARY KEY, cnt INTEGER, big INT8, score DOUBLE PRECISION) WITHOUT ROWID",
+		"CREATE INDEX idx_tags_cnt ON tags (cnt, big)",
+	} {
+		if err = db.Exec(ddl).Error; err != nil {
+			t.Fatalf("create table fail: %s", err)
+		}
+	}
+
+	g := gen.NewGenerator(gen.Config{FieldWithIndexTag: true})
+	g.UseDB(db)
+	expects := map[string][]string{
+		"events": {
+			`ID int64 gorm:"column:id;primaryKey;autoIncrement:true"`,
+			`Payload interface{} gorm:"column:payload"`,
+			`Kind string gorm:"column:kind;not null;default:'click'"`,
+			`N int64 gorm:"column:n"`,
+		},
+		"tags": {
+			`Name string gorm:"column:name;primaryKey;autoIncrement:false"`,
+			`Cnt int32 gorm:"column:cnt;index:idx_tags_cnt,priority:1"`,
+			`Big int64 gorm:"column:big;index:idx_tags_cnt,priority:2"`,
+			`Score float64 gorm:"column:score"`,
+		},
+	}
+	for table, expect := range expects {
+		var got []string
+		for _, f := range g.GenerateModel(table).Fields {
+			got = append(got, fmt.Sprintf("%s %s gorm:%q", f.Name, f.Type, f.GORMTag.Build()))
+		}
+		if strings.Join(got, "\n") != strings.Join(expect, "\n") {
+			t.Errorf("fields of %s mismatch, expect:\n%s\ngot:\n%s", table, strings.Join(expect, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestConnOptions(t *testing.T) {
+	dialector, err := newDialector(&Config{DB: "mysql", DSN: "user:pass@tcp(127.0.0.1:3306)/db?parseTime=True",
+		ConnectTimeout: 5, ReadTimeout: 30, TLSSkipVerify: true, Charset: "utf8mb4"})
+	if err != nil {
+		t.Fatalf("create dialector fail: %s", err)
+	}
+	dsn := dialector.(*mysql.Dialector).DSNConfig.FormatDSN()
+	for _, expect := range []string{"charset=utf8mb4", "parseTime=true", "readTimeout=30s", "timeout=5s", "tls=gentool"} {
+		if !strings.Contains(dsn, expect) {
+			t.Errorf("dsn should contain %q, got: %s", expect, dsn)
+		}
+	}
+}
+
+func TestSSHTunnel(t *testing.T) {
+	dir := t.TempDir()
+	_, hostKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, clientKey, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, _ := ssh.NewSignerFromKey(hostKey)
+	clientSigner, _ := ssh.NewSignerFromKey(clientKey)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(clientKey)
+	if err != nil {
+		t.Fatalf("marshal key fail: %s", err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "id_ed25519"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+
+	// db echoing lines, reachable by bastion only
+	db := listenLocal(t, func(conn net.Conn) { _, _ = io.Copy(conn, conn) })
+	// bastion forwarding direct-tcpip channels, it's also used as jump host
+	serverConfig := &ssh.ServerConfig{PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+		if string(key.Marshal()) != string(clientSigner.PublicKey().Marshal()) {
+			return nil, fmt.Errorf("unknown key")
+		}
+		return nil, nil
+	}}
+	serverConfig.AddHostKey(hostSigner)
+	bastion := listenLocal(t, func(conn net.Conn) {
+		_, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		for newChan := range chans {
+			var target struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newChan.ExtraData(), &target); err != nil || newChan.ChannelType() != "direct-tcpip" {
+				_ = newChan.Reject(ssh.UnknownChannelType, "unsupported")
+				continue
+			}
+			upstream, err := net.Dial("tcp", net.JoinHostPort(target.Host, fmt.Sprint(target.Port)))
+			if err != nil {
+				_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			ch, chReqs, _ := newChan.Accept()
+			go ssh.DiscardRequests(chReqs)
+			go func() { _, _ = io.Copy(ch, upstream); _ = ch.Close() }()
+			go func() { _, _ = io.Copy(upstream, ch); _ = upstream.Close() }()
+		}
+	})
+	knownHosts := filepath.Join(dir, "known_hosts")
+	_ = os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{bastion}, hostSigner.PublicKey())+"\n"), 0600)
+
+	for _, jumpHost := range []string{"", "gen@" + bastion} {
+		tunnel := newSSHTunnel(&Config{SSH: SSHConfig{Host: bastion, User: "gen", JumpHost: jumpHost,
+			KeyPath: filepath.Join(dir, "id_ed25519"), KnownHosts: knownHosts}})
+		conn, err := tunnel.DialContext(context.Background(), "tcp", db)
+		if err != nil {
+			t.Fatalf("dial through tunnel(jump host %q) fail: %s", jumpHost, err)
+		}
+		_, _ = conn.Write([]byte("ping\n"))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil || line != "ping\n" {
+			t.Errorf("read through tunnel(jump host %q) should get ping, got: %q %v", jumpHost, line, err)
+		}
+		_ = conn.Close()
+		tunnel.close()
+	}
+
+	tunnel := newSSHTunnel(&Config{SSH: SSHConfig{Host: bastion, KeyPath: filepath.Join(dir, "id_ed25519"),
+		KnownHosts: filepath.Join(dir, "empty_known_hosts")}})
+	_ = os.WriteFile(filepath.Join(dir, "empty_known_hosts"), nil, 0600)
+	if _, err = tunnel.DialContext(context.Background(), "tcp", db); err == nil || !strings.Contains(err.Error(), "knownhosts") {
+		t.Errorf("unknown host key should be rejected, got: %v", err)
+	}
+}
+
+// listenLocal serve connections by handle on random local port, return address
+func listenLocal(t *testing.T, handle func(conn net.Conn)) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen fail: %s", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestRegisterDriver(t *testing.T) {
+	var gotDSN string
+	RegisterDriver("fakedb", func(dsn string, opts ConnOptions) (gorm.Dialector, error) {
+		gotDSN = dsn
+		if opts.ConnectTimeout != 3*time.Second {
+			t.Errorf("connect timeout should be passed to driver, got: %s", opts.ConnectTimeout)
+		}
+		return sqlite.Open(filepath.Join(t.TempDir(), "fake.db")), nil
+	}, func(db *gorm.DB) ([]string, error) { return []string{"fake_table"}, nil })
+	t.Cleanup(func() { delete(drivers, "fakedb") })
+
+	if names := strings.Join(Drivers(), " "); !strings.Contains(names, "fakedb") {
+		t.Errorf("registered driver should be listed, got: %s", names)
+	}
+	db, err := Connect(&Config{DB: "fakedb", DSN: "fake://db", ConnectTimeout: 3})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	if gotDSN != "fake://db" {
+		t.Errorf("dsn should be passed to driver, got: %q", gotDSN)
+	}
+	if tables, err := Tables(db, "fakedb"); err != nil || len(tables) != 1 || tables[0] != "fake_table" {
+		t.Errorf("tables should be listed by introspector, got: %v %v", tables, err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("registering driver twice should panic")
+		}
+	}()
+	RegisterDriver("fakedb", nil, nil)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(dir, "run.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	for _, ddl := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, score INTEGER)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER)",
+	} {
+		if err = db.Exec(ddl).Error; err != nil {
+			t.Fatalf("create table fail: %s", err)
+		}
+	}
+
+	var hooked []string
+	report, err := Run(context.Background(), Config{
+		DB:      "sqlite",
+		OutPath: filepath.Join(dir, "dao", "query"),
+		Conn:    db,
+		ModelOpts: map[string][]gen.ModelOpt{
+			"*":     {gen.FieldType("id", "uint64")},
+			"users": {gen.FieldType("score", "float64")},
+		},
+		BeforeGenerate: func(g *gen.Generator, db *gorm.DB, c *Config) error {
+			if db == nil {
+				return fmt.Errorf("db should be passed to hook")
+			}
+			hooked, c.Tables = c.Tables, []string{"orders", "users"}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("run fail: %s", err)
+	}
+	if hooked != nil || report.Tables != 2 || len(report.Written) == 0 {
+		t.Errorf("tables set by hook should be generated, got: %+v", report)
+	}
+
+	for file, expects := range map[string][]string{
+		"users.gen.go":  {"ID    uint64", "Score float64"},
+		"orders.gen.go": {"ID     uint64", "UserID int32"},
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, "dao", "model", file))
+		if err != nil {
+			t.Fatalf("read model fail: %s", err)
+		}
+		for _, expect := range expects {
+			if !strings.Contains(string(content), expect) {
+				t.Errorf("%s should contain %q, got:\n%s", file, expect, content)
+			}
+		}
+	}
+
+	if _, err = Run(context.Background(), Config{DB: "sqlite", OutPath: filepath.Join(dir, "dao", "query"), Conn: db,
+		Tables: []string{"missing"}}); err == nil {
+		t.Errorf("failure of generator should be returned as error")
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(dir, "plugin.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	for _, ddl := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, password TEXT)",
+		"CREATE TABLE schema_migrations (version TEXT)",
+	} {
+		if err = db.Exec(ddl).Error; err != nil {
+			t.Fatalf("create table fail: %s", err)
+		}
+	}
+
+	written := filepath.Join(dir, "written.txt")
+	t.Setenv("GENTOOL_PLUGIN_HELPER", written)
+	report, err := Run(context.Background(), Config{
+		DB:      "sqlite",
+		OutPath: filepath.Join(dir, "dao", "query"),
+		Conn:    db,
+		Plugins: []string{os.Args[0] + " -test.run=^TestPluginHelperProcess$"},
+	})
+	if err != nil {
+		t.Fatalf("run fail: %s", err)
+	}
+	if report.Tables != 1 {
+		t.Errorf("tables should be filtered by plugin, got: %d", report.Tables)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "dao", "model", "users.gen.go"))
+	if err != nil {
+		t.Fatalf("read model fail: %s", err)
+	}
+	for _, expect := range []string{"ID   uint64", "// rendered by plugin"} {
+		if !strings.Contains(string(content), expect) {
+			t.Errorf("model should contain %q, got:\n%s", expect, content)
+		}
+	}
+	if strings.Contains(string(content), "Password") {
+		t.Errorf("field removed by plugin should not be generated, got:\n%s", content)
+	}
+	paths, err := os.ReadFile(written)
+	if err != nil {
+		t.Fatalf("read written paths fail: %s", err)
+	}
+	if !strings.Contains(string(paths), "users.gen.go") || strings.Contains(string(paths), "schema_migrations") {
+		t.Errorf("written files should be passed to AfterWrite, got:\n%s", paths)
+	}
+
+	_, err = Run(context.Background(), Config{DB: "sqlite", OutPath: filepath.Join(dir, "dao", "query"), Conn: db,
+		Tables: []string{"fail"}, Plugins: []string{os.Args[0] + " -test.run=^TestPluginHelperProcess$"}})
+	if err == nil || !strings.Contains(err.Error(), "table fail is rejected") {
+		t.Errorf("error of plugin should fail generation, got: %v", err)
+	}
+}
+
+// TestPluginHelperProcess executable plugin started by TestPlugins
+func TestPluginHelperProcess(t *testing.T) {
+	written := os.Getenv("GENTOOL_PLUGIN_HELPER")
+	if written == "" {
+		t.Skip("helper process of TestPlugins")
+	}
+	defer os.Exit(0)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, 1<<24)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req pluginRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = encoder.Encode(pluginResponse{Error: err.Error()})
+			continue
+		}
+		var resp pluginResponse
+		switch req.Hook {
+		case "BeforeIntrospect":
+			resp.Tables = []string{}
+			for _, table := range req.Tables {
+				if table == "fail" {
+					resp.Error = "table fail is rejected"
+				}
+				if table != "schema_migrations" {
+					resp.Tables = append(resp.Tables, table)
+				}
+			}
+		case "AfterModel":
+			resp.Model = req.Model
+			fields := req.Model.Fields[:0]
+			for _, f := range req.Model.Fields {
+				if f.Column == "id" {
+					f.Type = "uint64"
+				}
+				if f.Column != "password" {
+					fields = append(fields, f)
+				}
+			}
+			resp.Model.Fields = fields
+		case "BeforeRender":
+			if strings.HasSuffix(req.File.Path, "users.gen.go") {
+				resp.File = req.File
+				resp.File.Content += "\n// rendered by plugin\n"
+			}
+		case "AfterWrite":
+			f, err := os.OpenFile(written, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err == nil {
+				_, err = fmt.Fprintln(f, req.Path)
+				_ = f.Close()
+			}
+			if err != nil {
+				resp.Error = err.Error()
+			}
+		}
+		_ = encoder.Encode(resp)
+	}
+}
+
+func TestAfterGenerateHooks(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(dir, "hooks.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	if err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatalf("create table fail: %s", err)
+	}
+
+	config := Config{DB: "sqlite", OutPath: filepath.Join(dir, "dao", "query"), Conn: db, Hooks: HooksConfig{
+		AfterGenerate: []string{"pwd > ../hooks.txt", `echo "$GEN_TABLES $GEN_OUT_PATH $GEN_WRITTEN" >> ../hooks.txt`},
+	}}
+	report, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("run fail: %s", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "dao", "hooks.txt"))
+	if err != nil {
+		t.Fatalf("read output of hooks fail: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || lines[0] != config.OutPath {
+		t.Fatalf("hooks should run in order in outPath, got:\n%s", content)
+	}
+	if expect := "1 " + config.OutPath + " " + strings.Join(report.Written, ","); lines[1] != expect {
+		t.Errorf("results should be in env of hooks, expect %q, got %q", expect, lines[1])
+	}
+
+	config.Hooks.AfterGenerate = []string{"exit 3", "touch ../never.txt"}
+	if _, err = Run(context.Background(), config); err == nil || !strings.Contains(err.Error(), `"exit 3"`) {
+		t.Errorf("failure of hook should fail generation, got: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "dao", "never.txt")); !os.IsNotExist(err) {
+		t.Errorf("hooks after failed one should not run")
+	}
+}
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(dir, "manifest.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	for _, ddl := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER)",
+	} {
+		if err = db.Exec(ddl).Error; err != nil {
+			t.Fatalf("create table fail: %s", err)
+		}
+	}
+
+	manifest := filepath.Join(dir, "gen.manifest.json")
+	config := Config{DB: "sqlite", OutPath: filepath.Join(dir, "dao", "query"), Conn: db, Manifest: manifest}
+	if _, err = Run(context.Background(), config); err != nil {
+		t.Fatalf("run fail: %s", err)
+	}
+	config.Tables = []string{"users"}
+	if _, err = Run(context.Background(), config); err != nil {
+		t.Fatalf("run fail: %s", err)
+	}
+
+	m, err := gen.ReadManifest(manifest)
+	if err != nil {
+		t.Fatalf("read manifest fail: %s", err)
+	}
+	files := make(map[string]gen.ManifestFile)
+	for _, f := range m.Files {
+		files[f.Path] = f
+	}
+	for path, table := range map[string]string{
+		"dao/model/users.gen.go":  "users",
+		"dao/model/orders.gen.go": "orders", // kept from last generation
+		"dao/query/users.gen.go":  "users",
+		"dao/query/gen.go":        "",
+	} {
+		f, ok := files[path]
+		if !ok {
+			t.Errorf("%s should be in manifest, got: %+v", path, m.Files)
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatalf("read generated file fail: %s", err)
+		}
+		if sum := sha256.Sum256(content); f.Table != table || f.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s should be generated from %q with hash of content, got: %+v", path, table, f)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(dir, "migrate.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	if err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)").Error; err != nil {
+		t.Fatalf("create table fail: %s", err)
+	}
+
+	outPath := filepath.Join(dir, "query")
+	if _, err = Migrate(context.Background(), Config{DB: "sqlite", OutPath: outPath, Conn: db, Migrate: "automigrate"}); err != nil {
+		t.Fatalf("migrate automigrate fail: %s", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "model", "automigrate.gen.go"))
+	if err != nil {
+		t.Fatalf("read automigrate file fail: %s", err)
+	}
+	if !strings.Contains(string(content), "&User{},") || !strings.Contains(string(content), "func AutoMigrate(db *gorm.DB) error") {
+		t.Errorf("automigrate file should register models, got:\n%s", content)
+	}
+
+	config := Config{DB: "sqlite", OutPath: outPath, Conn: db, Migrate: "golang-migrate", MigrateName: "init", FieldWithIndexTag: true}
+	if _, err = Migrate(context.Background(), config); err != nil {
+		t.Fatalf("migrate golang-migrate fail: %s", err)
+	}
+	if err = db.Exec("ALTER TABLE users ADD COLUMN age INTEGER").Error; err != nil {
+		t.Fatalf("alter table fail: %s", err)
+	}
+	if err = db.Exec("CREATE INDEX idx_users_age ON users (age)").Error; err != nil {
+		t.Fatalf("create index fail: %s", err)
+	}
+	config.MigrateName = "add_age"
+	if _, err = Migrate(context.Background(), config); err != nil {
+		t.Fatalf("migrate golang-migrate fail: %s", err)
+	}
+
+	migrations := filepath.Join(dir, "migrations")
+	for pattern, expects := range map[string][]string{
+		"*_init.up.sql":      {"CREATE TABLE \"users\" (\n  \"id\" INTEGER NOT NULL,\n  \"name\" TEXT NOT NULL,\n  PRIMARY KEY (\"id\")\n);"},
+		"*_init.down.sql":    {"DROP TABLE \"users\";"},
+		"*_add_age.up.sql":   {"ALTER TABLE \"users\" ADD COLUMN \"age\" INTEGER;", "CREATE INDEX \"idx_users_age\" ON \"users\" (\"age\");"},
+		"*_add_age.down.sql": {"DROP INDEX \"idx_users_age\";\nALTER TABLE \"users\" DROP COLUMN \"age\";"},
+	} {
+		files, _ := filepath.Glob(filepath.Join(migrations, pattern))
+		if len(files) != 1 {
+			t.Fatalf("should write one migration file of %s, got %v", pattern, files)
+		}
+		content, _ := os.ReadFile(files[0])
+		for _, expect := range expects {
+			if !strings.Contains(string(content), expect) {
+				t.Errorf("%s should contain %q, got:\n%s", files[0], expect, content)
+			}
+		}
+	}
+
+	config.MigrateName, config.Migrate = "noop", "goose"
+	if _, err = Migrate(context.Background(), config); err != nil {
+		t.Fatalf("migrate goose fail: %s", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(migrations, "*_noop.sql")); len(files) != 0 {
+		t.Errorf("migration should not be written when schema is unchanged, got %v", files)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(dir, "diff.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	if err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, nick TEXT)").Error; err != nil {
+		t.Fatalf("create table fail: %s", err)
+	}
+	if err = db.Exec("CREATE INDEX idx_users_nick ON users (nick)").Error; err != nil {
+		t.Fatalf("create index fail: %s", err)
+	}
+
+	config := Config{DB: "sqlite", OutPath: filepath.Join(dir, "query"), Conn: db, FieldWithIndexTag: true, FieldWithTypeTag: true,
+		LockFile: filepath.Join(dir, "gen.lock"), SchemaJSON: filepath.Join(dir, "schema.json")}
+	if _, err = Run(context.Background(), config); err != nil {
+		t.Fatalf("generate fail: %s", err)
+	}
+	diff := func(baseline string) (*gen.Drift, error) {
+		c := config
+		if c.DiffBaseline = baseline; baseline == "" { // models in model package
+			c.LockFile = ""
+		}
+		return Diff(context.Background(), c)
+	}
+	for _, baseline := range []string{config.SchemaJSON, config.LockFile, ""} {
+		drift, err := diff(baseline)
+		if err != nil {
+			t.Fatalf("diff with %q fail: %s", baseline, err)
+		}
+		if !drift.Empty() {
+			t.Errorf("schema should not drift since %q, got:\n%s", baseline, drift)
+		}
+	}
+
+	for _, ddl := range []string{
+		"ALTER TABLE users ADD COLUMN age INTEGER NOT NULL DEFAULT 0",
+		"DROP INDEX idx_users_nick",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY)",
+	} {
+		if err = db.Exec(ddl).Error; err != nil {
+			t.Fatalf("exec %s fail: %s", ddl, err)
+		}
+	}
+	for baseline, expects := range map[string][]string{
+		config.SchemaJSON: {"+ table orders", "users\n", "+ column age: INTEGER NOT NULL DEFAULT 0", "- index idx_users_nick: (nick)"},
+		"":                {"+ table orders", "+ column age: INTEGER NOT NULL", "- index idx_users_nick: (nick)"},
+		config.LockFile:   {"+ table orders", "~ table users: "},
+	} {
+		drift, err := diff(baseline)
+		if err != nil {
+			t.Fatalf("diff with %q fail: %s", baseline, err)
+		}
+		report := drift.String()
+		for _, expect := range expects {
+			if !strings.Contains(report, expect) {
+				t.Errorf("drift since %q should contain %q, got:\n%s", baseline, expect, report)
+			}
+		}
+	}
+}
+
+// verifyModule create module depending on gen of this repo in temp dir, so generated code can be verified by building
+func verifyModule(t *testing.T) string {
+	root, err := filepath.Abs(filepath.Join("..", "..", ".."))
+	if err != nil {
+		t.Fatalf("get path of gen fail: %s", err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatalf("read go.sum fail: %s", err)
+	}
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.18\n\nrequire gorm.io/gen v0.0.0\n\nreplace gorm.io/gen => " + filepath.ToSlash(root) + "\n"
+	if err = os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0o644); err != nil {
+		t.Fatalf("write go.sum fail: %s", err)
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	return dir
+}
+
+func TestVerifyBuild(t *testing.T) {
+	dir := verifyModule(t)
+	db, err := Connect(&Config{DB: "sqlite", DSN: filepath.Join(dir, "verify.db")})
+	if err != nil {
+		t.Fatalf("connect db fail: %s", err)
+	}
+	if err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatalf("create table fail: %s", err)
+	}
+
+	config := Config{DB: "sqlite", OutPath: filepath.Join(dir, "dao", "query"), Conn: db, Verify: "build"}
+	if _, err = Run(context.Background(), config); err != nil {
+		t.Fatalf("verify of generated code should pass: %s", err)
+	}
+
+	tmplDir := filepath.Join(dir, "tmpl")
+	if err = os.MkdirAll(tmplDir, 0o755); err != nil {
+		t.Fatalf("make template dir fail: %s", err)
+	}
+	if err = os.WriteFile(filepath.Join(tmplDir, "query_extra.tmpl"), []byte("var _ = undefinedOf{{.ModelStructName}}\n"), 0o644); err != nil {
+		t.Fatalf("write template fail: %s", err)
+	}
+	config.QueryTemplateDir = tmplDir
+	if _, err = Run(context.Background(), config); err == nil {
+		t.Errorf("verify of generated code referring undefined variable should fail")
+	}
+}
+
+// runModule run program of body against generated code in module dir, the program has db connected to dsn, q of
+// query.Use(db), ctx and check(ok, format, args...) exiting with message if not ok
+func runModule(t *testing.T, dir, dsn, body string, imports ...string) {
+	var importLines string
+	for _, path := range imports {
+		importLines += "\t" + strconv.Quote(path) + "\n"
+	}
+	program := `package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"example.com/app/dao/query"
+` + importLines + `)
+
+func check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		fmt.Printf(format+"\n", args...)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	db, err := gorm.Open(sqlite.Open(os.Args[1]), &gorm.Config{})
+	check(err == nil, "open db fail: %v", err)
+	q, ctx := query.Use(db), context.Background()
+	_, _ = q, ctx
+` + body + `
+}
+`
+	cmdDir := filepath.Join(dir, "cmd", "check")
+	if err := os.MkdirAll(cmdDir, 0o755); err != nil {
+		t.Fatalf("make program dir fail: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(cmdDir, "main.go"), []byte(program), 0o644); err != nil {
+		t.Fatalf("write program fail: %s", err)
+	}
+	cmd := exec.Command("go", "run", "./cmd/check", dsn)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("run generated code fail: %s\n%s", err, output)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	const schema = `{"dialect": "mysql", "tables": [{"name": "users", "columns": [
+		{"name": "id", "databaseType": "BIGINT", "columnType": "bigint", "goType": "int64", "primaryKey": true},
+		{"name": "nick", "databaseType": "VARCHAR", "columnType": "varchar(64)", "goType": "string", "comment": "nickname of user"},
+		{"name": "legacy_name", "databaseType": "VARCHAR", "columnType": "varchar(64)", "goType": "string", "comment": "DEPRECATED: use nick"}]}]}`
+	noPrimaryKey := []string{
+		"CREATE TABLE user_roles (user_id INTEGER NOT NULL, role TEXT NOT NULL, note TEXT)",
+		"CREATE UNIQUE INDEX idx_user_roles_note ON user_roles (note)",
+		"CREATE UNIQUE INDEX idx_user_roles_user_role ON user_roles (user_id, role)",
+		"CREATE TABLE audit_logs (message TEXT)",
+	}
+	composite := "CREATE TABLE memberships (tenant_id INTEGER NOT NULL, user_code TEXT NOT NULL, role TEXT, PRIMARY KEY (tenant_id, user_code))"
+	softDelete := []string{
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT, deleted_at DATETIME)",
+		"CREATE TABLE grants (account_id INTEGER NOT NULL, scope TEXT NOT NULL, deleted_at DATETIME, PRIMARY KEY (account_id, scope))",
+	}
+	books := "CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT NOT NULL, price REAL, published_at DATETIME)"
+
+	testcases := []struct {
+		name     string
+		ddl      []string            // executed in sqlite db generated from, or schema is generated from if empty
+		config   Config              // DB, Conn or FromSchemaJSON, and OutPath of dao/query are set by test
+		err      bool                // generation should fail
+		contains map[string][]string // content of files in dao dir
+		excludes map[string][]string
+		missing  []string // files in dao dir not generated
+		run      string   // program run against generated code and db by runModule, generated code isn't built if empty
+		imports  []string
+	}{
+		{
+			name: "single model layout",
+			ddl: []string{
+				"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+				"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER)",
+				"CREATE TABLE user_profiles (id INTEGER PRIMARY KEY, user_id INTEGER, bio TEXT)",
+				"CREATE TABLE user_addresses (id INTEGER PRIMARY KEY, user_id INTEGER, city TEXT)",
+			},
+			config: Config{ModelLayout: "single", ModelPackages: map[string]string{"user_*": "account"}},
+			contains: map[string][]string{
+				"model/models.gen.go":         {"package model", "type Order struct", "type User struct"},
+				"model/account/models.gen.go": {"package account", "type UserAddress struct", "type UserProfile struct"},
+				"query/user_profiles.gen.go":  {`"example.com/app/dao/model/account"`, "account.UserProfile"},
+				"query/users.gen.go":          {"model.User"},
+			},
+			missing: []string{"model/users.gen.go"},
+			run: `	check(q.User.WithContext(ctx).Create(&model.User{Name: "a"}) == nil, "create user fail")
+	check(q.UserProfile.WithContext(ctx).Create(&account.UserProfile{UserID: 1, Bio: "bio"}) == nil, "create profile fail")
+	profile, err := q.UserProfile.WithContext(ctx).Where(q.UserProfile.UserID.Eq(1)).Take()
+	check(err == nil && profile.Bio == "bio", "profile of package account should be found, got: %+v %v", profile, err)`,
+			imports: []string{"example.com/app/dao/model", "example.com/app/dao/model/account"},
+		},
+		{
+			name:   "model package named as query struct",
+			ddl:    []string{"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"},
+			config: Config{ModelLayout: "single", ModelPackages: map[string]string{"user*": "user"}},
+			err:    true,
+		},
+		{
+			name:   "generic dao",
+			ddl:    []string{"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"},
+			config: Config{WithGenericDAO: true},
+			contains: map[string][]string{
+				"query/gen.go":       {"type genericDo[M any, R any, W genericDoWrapper[R]] struct{ gen.DO }", "func (d genericDo[M, R, W]) Find() ([]*M, error)"},
+				"query/users.gen.go": {"genericDo[model.User, *userDo, *userDo]", "func (*userDo) wrapDO(do gen.Dao) *userDo"},
+			},
+			excludes: map[string][]string{"query/users.gen.go": {"func (u userDo) Where("}},
+			run: `	u := q.User
+	check(u.WithContext(ctx).Create(&model.User{Name: "a"}, &model.User{Name: "b"}) == nil, "create users fail")
+	users, err := u.WithContext(ctx).Where(u.Name.Eq("b")).Find()
+	check(err == nil && len(users) == 1 && users[0].Name == "b", "users should be found by generic Find, got: %v %v", users, err)`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name: "fake dao",
+			ddl: []string{
+				"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+				"CREATE INDEX idx_users_name ON users (name)",
+				"CREATE TABLE logs (content TEXT)",
+			},
+			config: Config{WithFakeDAO: true, FieldWithIndexTag: true},
+			contains: map[string][]string{"query/users.fake.gen.go": {
+				"records map[int64]*model.User",
+				"func NewUserFake(values ...*model.User) *UserFake",
+				"func (f *UserFake) GetByID(value int64) (*model.User, error)",
+				"func (f *UserFake) CountByName(value string) (int64, error)",
+				"func (f *UserFake) FindWhere(conds map[string]interface{}) (results []*model.User, err error)",
+				"value.ID = f.lastKey + 1",
+				`case "name":`,
+			}},
+			missing: []string{"query/logs.fake.gen.go"},
+			run: `	f := query.NewUserFake(&model.User{Name: "a"})
+	check(f.Create(&model.User{Name: "b"}, &model.User{Name: "b"}) == nil, "create fake users fail")
+	user, err := f.GetByID(2)
+	check(err == nil && user.Name == "b", "user should be got by key assigned, got: %+v %v", user, err)
+	count, err := f.CountByName("b")
+	check(err == nil && count == 2, "users should be counted by name, got: %d %v", count, err)
+	users, err := f.FindWhere(map[string]interface{}{"name": "a"})
+	check(err == nil && len(users) == 1 && users[0].ID == 1, "users should be found by conds, got: %v %v", users, err)`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name: "ignored and read only columns",
+			ddl:  []string{"CREATE TABLE orders (id INTEGER PRIMARY KEY, password_hash TEXT, legacy_blob BLOB, computed_total INTEGER)"},
+			config: Config{IgnoreColumns: map[string][]string{"orders": {"password_hash"}, "*": {"legacy_blob"}},
+				ReadOnlyColumns: map[string][]string{"orders": {"computed_total"}}},
+			contains: map[string][]string{"model/orders.gen.go": {`gorm:"column:computed_total;->"`}},
+			excludes: map[string][]string{"model/orders.gen.go": {"PasswordHash", "LegacyBlob"}},
+			run: `	check(q.Order.WithContext(ctx).Create(&model.Order{ComputedTotal: 5}) == nil, "create order fail")
+	check(db.Exec("UPDATE orders SET computed_total = 7").Error == nil, "update order fail")
+	order, err := q.Order.WithContext(ctx).Take()
+	check(err == nil && order.ComputedTotal == 7, "read only column should be read but not written, got: %+v %v", order, err)`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name: "lookup table",
+			ddl: []string{
+				"CREATE TABLE statuses (id INTEGER PRIMARY KEY, code TEXT NOT NULL)",
+				"INSERT INTO statuses (id, code) VALUES (1, 'pending'), (3, 'shipped'), (4, 'in-transit')",
+			},
+			config:   Config{OnlyModel: true, LookupTables: map[string]string{"statuses": ""}},
+			contains: map[string][]string{"model/statuses.gen.go": {"StatusPending   = 1", "StatusShipped   = 3", "StatusInTransit = 4"}},
+		},
+		{
+			name:   "lookup table without code column",
+			ddl:    []string{"CREATE TABLE statuses (id INTEGER PRIMARY KEY, code TEXT NOT NULL)"},
+			config: Config{OnlyModel: true, LookupTables: map[string]string{"statuses": "id:label"}},
+			err:    true,
+		},
+		{
+			name:    "skip tables without primary key",
+			ddl:     noPrimaryKey,
+			config:  Config{OnlyModel: true, NoPrimaryKey: "skip"},
+			missing: []string{"model/user_roles.gen.go", "model/audit_logs.gen.go"},
+		},
+		{
+			name:     "read only tables without primary key",
+			ddl:      noPrimaryKey,
+			config:   Config{NoPrimaryKey: "readOnly"},
+			contains: map[string][]string{"model/user_roles.gen.go": {`gorm:"column:role;not null;->"`}},
+			run: `	check(db.Exec("INSERT INTO user_roles (user_id, role) VALUES (1, 'admin')").Error == nil, "insert role fail")
+	roles, err := q.UserRole.WithContext(ctx).Find()
+	check(err == nil && len(roles) == 1 && roles[0].Role == "admin", "read only table should be read, got: %v %v", roles, err)`,
+		},
+		{
+			name:   "unique index as logical key",
+			ddl:    noPrimaryKey,
+			config: Config{NoPrimaryKey: "uniqueIndex"},
+			contains: map[string][]string{
+				"model/user_roles.gen.go": {`gorm:"column:user_id;primaryKey"`, `gorm:"column:role;primaryKey"`, `gorm:"column:note"`},
+				"model/audit_logs.gen.go": {`gorm:"column:message;->"`}, // fall back to read only
+			},
+			run: `	r := q.UserRole.WithContext(ctx)
+	err = r.Create(&model.UserRole{UserID: 1, Role: "admin", Note: "a"}, &model.UserRole{UserID: 1, Role: "dev", Note: "d"})
+	check(err == nil, "create roles fail: %v", err)
+	_, err = r.Delete(&model.UserRole{UserID: 1, Role: "admin"})
+	check(err == nil, "delete by logical key fail: %v", err)
+	roles, err := r.Find()
+	check(err == nil && len(roles) == 1 && roles[0].Role == "dev", "only role of logical key should be deleted, got: %v %v", roles, err)`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name:     "logical keys",
+			ddl:      noPrimaryKey,
+			config:   Config{OnlyModel: true, LogicalKeys: map[string]string{"user_roles": "idx_user_roles_note"}},
+			contains: map[string][]string{"model/user_roles.gen.go": {`gorm:"column:note;primaryKey"`}},
+			excludes: map[string][]string{"model/user_roles.gen.go": {"column:role;primaryKey"}},
+		},
+		{
+			name:   "missing logical key",
+			ddl:    noPrimaryKey,
+			config: Config{OnlyModel: true, LogicalKeys: map[string]string{"user_roles": "idx_missing"}},
+			err:    true,
+		},
+		{
+			name: "composite primary key",
+			ddl:  []string{composite},
+			contains: map[string][]string{"query/memberships.gen.go": {
+				"type MembershipPK struct", "FindByPK(pk MembershipPK)", "DeleteByPK(pk MembershipPK)", "UpsertByPK(",
+			}},
+			excludes: map[string][]string{"query/memberships.gen.go": {"GetByTenantID", "DeleteByTenantIDs"}},
+			run: `	m := q.Membership.WithContext(ctx)
+	check(m.UpsertByPK(&model.Membership{TenantID: 1, UserCode: "a", Role: "dev"}) == nil, "insert membership fail")
+	check(m.UpsertByPK(&model.Membership{TenantID: 1, UserCode: "a", Role: "admin"}) == nil, "update membership fail")
+	member, err := m.FindByPK(query.MembershipPK{TenantID: 1, UserCode: "a"})
+	check(err == nil && member.Role == "admin", "membership should be upserted by primary key, got: %+v %v", member, err)
+	info, err := m.DeleteByPK(query.MembershipPK{TenantID: 1, UserCode: "a"})
+	check(err == nil && info.RowsAffected == 1, "membership should be deleted by primary key, got: %+v %v", info, err)`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name:   "composite primary key of generic dao",
+			ddl:    []string{composite},
+			config: Config{WithGenericDAO: true, WithBatch: true, WithCache: true},
+			contains: map[string][]string{"query/memberships.gen.go": {
+				"type MembershipPK struct", "FindByPK(pk MembershipPK)", "DeleteByPK(pk MembershipPK)", "UpsertByPK(",
+			}},
+			excludes: map[string][]string{"query/memberships.gen.go": {"GetByTenantID", "DeleteByTenantIDs"}},
+			run: `	m := q.Membership.WithContext(ctx)
+	check(m.UpsertByPK(&model.Membership{TenantID: 1, UserCode: "a", Role: "dev"}) == nil, "insert membership fail")
+	member, err := m.FindByPK(query.MembershipPK{TenantID: 1, UserCode: "a"})
+	check(err == nil && member.Role == "dev", "membership should be found by primary key, got: %+v %v", member, err)`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name: "soft delete",
+			ddl:  softDelete,
+			contains: map[string][]string{
+				"query/accounts.gen.go": {"FindByIDUnscoped(value int64)", "Restore(values ...int64)", "HardDelete(models ...*model.Account)"},
+				"query/grants.gen.go":   {"HardDelete("},
+			},
+			excludes: map[string][]string{"query/grants.gen.go": {"Restore(", "g.Unscoped().Where(pk.conds()...).Take()"}},
+			run: `	a := q.Account.WithContext(ctx)
+	account := &model.Account{Name: "a"}
+	check(a.Create(account) == nil, "create account fail")
+	_, err = a.Delete(account)
+	check(err == nil, "delete account fail: %v", err)
+	_, err = a.Where(q.Account.ID.Eq(account.ID)).Take()
+	check(err != nil, "soft deleted account should not be found")
+	_, err = a.FindByIDUnscoped(account.ID)
+	check(err == nil, "soft deleted account should be found unscoped: %v", err)
+	_, err = a.Restore(account.ID)
+	check(err == nil, "restore account fail: %v", err)
+	_, err = a.Where(q.Account.ID.Eq(account.ID)).Take()
+	check(err == nil, "restored account should be found: %v", err)
+	_, err = a.HardDelete(account)
+	check(err == nil, "hard delete account fail: %v", err)
+	_, err = a.FindByIDUnscoped(account.ID)
+	check(err != nil, "hard deleted account should not be found unscoped")`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name:     "unscoped lookup of soft delete",
+			ddl:      softDelete,
+			config:   Config{WithGenericDAO: true, UnscopedLookup: true},
+			contains: map[string][]string{"query/grants.gen.go": {"HardDelete(", "g.Unscoped().Where(pk.conds()...).Take()"}},
+			excludes: map[string][]string{"query/grants.gen.go": {"Restore("}},
+			run: `	g := q.Grant.WithContext(ctx)
+	grant := &model.Grant{AccountID: 1, Scope: "read"}
+	check(g.Create(grant) == nil, "create grant fail")
+	_, err = g.Delete(grant)
+	check(err == nil, "delete grant fail: %v", err)
+	_, err = g.FindByPK(query.GrantPK{AccountID: 1, Scope: "read"})
+	check(err == nil, "soft deleted grant should be found by unscoped lookup: %v", err)`,
+			imports: []string{"example.com/app/dao/model"},
+		},
+		{
+			name:   "import and export",
+			ddl:    []string{books},
+			config: Config{WithImportExport: true},
+			contains: map[string][]string{"query/books.gen.go": {
+				"ExportCSV(w io.Writer, conds ...gen.Condition) error", "ImportCSV(r io.Reader, batchSize int)",
+				"ExportJSONLines(w io.Writer, conds ...gen.Condition) error", "ImportJSONLines(r io.Reader, batchSize int)",
+			}},
+			run: `	b := q.Book.WithContext(ctx)
+	count, err := b.ImportJSONLines(strings.NewReader("{\"title\": \"go\", \"price\": 9.5}\n{\"title\": \"sql\"}\n"), 10)
+	check(err == nil && count == 2, "books should be imported, got: %d %v", count, err)
+	var buf bytes.Buffer
+	check(b.ExportCSV(&buf, q.Book.Title.Eq("go")) == nil, "export books fail")
+	check(strings.Contains(buf.String(), "go,9.5") && !strings.Contains(buf.String(), "sql"), "books of conds should be exported, got:\n%s", &buf)`,
+			imports: []string{"bytes", "strings"},
+		},
+		{
+			name:   "import and export of generic dao",
+			ddl:    []string{books},
+			config: Config{WithImportExport: true, WithGenericDAO: true},
+			contains: map[string][]string{"query/books.gen.go": {
+				"ExportCSV(w io.Writer, conds ...gen.Condition) error", "ImportCSV(r io.Reader, batchSize int)",
+			}},
+			run: `	b := q.Book.WithContext(ctx)
+	count, err := b.ImportCSV(strings.NewReader("title,price\ngo,9.5\n"), 10)
+	check(err == nil && count == 1, "books should be imported, got: %d %v", count, err)
+	book, err := b.Take()
+	check(err == nil && book.Title == "go" && book.Price == 9.5, "imported book should be found, got: %+v %v", book, err)`,
+			imports: []string{"strings"},
+		},
+		{
+			name: "doc comment",
+			contains: map[string][]string{
+				"model/users.gen.go": {"// nickname of user\n", "// Deprecated: use nick\n\tLegacyName string"},
+			},
+		},
+		{
+			name:   "field doc comment and deprecated field omitted",
+			config: Config{FieldWithDocComment: true, FieldOmitDeprecated: true},
+			contains: map[string][]string{
+				"model/users.gen.go": {"// Nick nickname of user\n\tNick string", `gorm:"column:legacy_name;<-:update;comment:DEPRECATED: use nick"`},
+				"query/users.gen.go": {"// Deprecated: use nick\n\tLegacyName field.String"},
+			},
+		},
+		{
+			name:   "unit test with testcontainers",
+			config: Config{WithUnitTest: true, UnitTestDB: "testcontainers"},
+			contains: map[string][]string{"query/gen_test.go": {
+				`tcmysql "github.com/testcontainers/testcontainers-go/modules/mysql"`,
+				`"gorm.io/driver/mysql"`,
+				`container, err := tcmysql.Run(ctx, "mysql:8.0"`,
+				`container.ConnectionString(ctx, "parseTime=true")`,
+				"gorm.Open(sqlite.Open(dbName)",
+			}},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := verifyModule(t)
+			dsn := filepath.Join(dir, "gen.db")
+			config := tt.config
+			config.OutPath = filepath.Join(dir, "dao", "query")
+			if len(tt.ddl) == 0 {
+				config.FromSchemaJSON = filepath.Join(dir, "schema.json")
+				if err := os.WriteFile(config.FromSchemaJSON, []byte(schema), 0o644); err != nil {
+					t.Fatalf("write schema fail: %s", err)
+				}
+			} else {
+				db, err := Connect(&Config{DB: "sqlite", DSN: dsn})
+				if err != nil {
+					t.Fatalf("connect db fail: %s", err)
+				}
+				for _, ddl := range tt.ddl {
+					if err = db.Exec(ddl).Error; err != nil {
+						t.Fatalf("exec %s fail: %s", ddl, err)
+					}
+				}
+				config.DB, config.Conn = "sqlite", db
+			}
+
+			_, err := Run(context.Background(), config)
+			if tt.err {
+				if err == nil {
+					t.Errorf("generation should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run fail: %s", err)
+			}
+
+			read := func(file string) string {
+				content, err := os.ReadFile(filepath.Join(dir, "dao", file))
+				if err != nil {
+					t.Fatalf("read generated file fail: %s", err)
+				}
+				return string(content)
+			}
+			for file, expects := range tt.contains {
+				content := read(file)
+				for _, expect := range expects {
+					if !strings.Contains(content, expect) {
+						t.Errorf("%s should contain %q, got:\n%s", file, expect, content)
+					}
+				}
+			}
+			for file, excludes := range tt.excludes {
+				content := read(file)
+				for _, exclude := range excludes {
+					if strings.Contains(content, exclude) {
+						t.Errorf("%s should not contain %q, got:\n%s", file, exclude, content)
+					}
+				}
+			}
+			for _, file := range tt.missing {
+				if _, err := os.Stat(filepath.Join(dir, "dao", file)); !os.IsNotExist(err) {
+					t.Errorf("%s should not be generated, stat: %v", file, err)
+				}
+			}
+			if tt.run != "" {
+				runModule(t, dir, dsn, tt.run, tt.imports...)
+			}
+		})
+	}
+}
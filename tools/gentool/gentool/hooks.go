@@ -0,0 +1,57 @@
+package gentool
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HooksConfig shell commands run at stages of generation
+type HooksConfig struct {
+	AfterGenerate []string `yaml:"afterGenerate"` // commands run in order after code is generated, e.g. goimports -w ., go build ./...
+}
+
+// hookDir working directory of hooks, outPath or its parent containing model directory if only models are generated
+func hookDir(config *Config) (string, error) {
+	dir := config.OutPath
+	if config.OnlyModel {
+		dir = filepath.Dir(dir)
+	}
+	return filepath.Abs(dir)
+}
+
+// runAfterGenerate run afterGenerate commands with results of generation in env:
+// GEN_OUT_PATH, GEN_TABLES, GEN_WRITTEN and GEN_SKIPPED(files separated by comma).
+// Generation fails on first failed command, so verification runs atomically with generation
+func runAfterGenerate(config *Config, report *Report) error {
+	if len(config.Hooks.AfterGenerate) == 0 {
+		return nil
+	}
+	dir, err := hookDir(config)
+	if err != nil {
+		return err
+	}
+	outPath, err := filepath.Abs(config.OutPath)
+	if err != nil {
+		return err
+	}
+	env := append(os.Environ(),
+		"GEN_OUT_PATH="+outPath,
+		"GEN_TABLES="+strconv.Itoa(report.Tables),
+		"GEN_WRITTEN="+strings.Join(report.Written, ","),
+		"GEN_SKIPPED="+strings.Join(report.Skipped, ","),
+	)
+	for _, command := range config.Hooks.AfterGenerate {
+		config.logger().Infof("run afterGenerate hook: %s", command)
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir, cmd.Env = dir, env
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err = cmd.Run(); err != nil {
+			return fmt.Errorf("afterGenerate hook %q fail: %w", command, err)
+		}
+	}
+	return nil
+}
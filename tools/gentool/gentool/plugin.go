@@ -0,0 +1,242 @@
+package gentool
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"plugin"
+	"strings"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+)
+
+// usePlugins add plugins of config into generator, close should be called once generation is done
+func usePlugins(g *gen.Generator, config *Config) (close func() error, err error) {
+	plugins, close, err := loadPlugins(config.Plugins)
+	if err != nil {
+		return nil, err
+	}
+	g.WithPlugins(plugins...)
+	return close, nil
+}
+
+// loadPlugins load plugins of paths, path ending with .so is Go plugin exporting variable Plugin of gen.Plugin,
+// others are executables with arguments speaking JSON lines, see execPlugin. Executables are stopped by close
+func loadPlugins(paths []string) (plugins []gen.Plugin, close func() error, err error) {
+	var executables []*execPlugin
+	close = func() (err error) {
+		for _, p := range executables {
+			if e := p.close(); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	}
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".so") {
+			p, err := openGoPlugin(path)
+			if err != nil {
+				_ = close()
+				return nil, nil, err
+			}
+			plugins = append(plugins, p)
+			continue
+		}
+		p, err := startExecPlugin(path)
+		if err != nil {
+			_ = close()
+			return nil, nil, err
+		}
+		executables = append(executables, p)
+		plugins = append(plugins, p)
+	}
+	return plugins, close, nil
+}
+
+// openGoPlugin open Go plugin built by go build -buildmode=plugin, which declares var Plugin gen.Plugin = ...
+func openGoPlugin(path string) (gen.Plugin, error) {
+	so, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open plugin %s fail: %w", path, err)
+	}
+	sym, err := so.Lookup("Plugin")
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s should export variable Plugin: %w", path, err)
+	}
+	switch p := sym.(type) {
+	case *gen.Plugin:
+		return *p, nil
+	case gen.Plugin:
+		return p, nil
+	}
+	return nil, fmt.Errorf("Plugin of %s is %T, which doesn't implement gen.Plugin", path, sym)
+}
+
+// pluginRequest request of hook written to executable plugin as a JSON line
+type pluginRequest struct {
+	Hook   string       `json:"hook"` // BeforeIntrospect, AfterModel, BeforeRender or AfterWrite
+	Tables []string     `json:"tables,omitempty"`
+	Model  *pluginModel `json:"model,omitempty"`
+	File   *pluginFile  `json:"file,omitempty"`
+	Path   string       `json:"path,omitempty"`
+}
+
+// pluginResponse response of hook read from executable plugin as a JSON line, absent values are unchanged
+type pluginResponse struct {
+	Tables []string     `json:"tables"`
+	Model  *pluginModel `json:"model"`
+	File   *pluginFile  `json:"file"`
+	Error  string       `json:"error"`
+}
+
+type pluginModel struct {
+	Table  string         `json:"table"`
+	Name   string         `json:"name"`
+	Fields []*pluginField `json:"fields"`
+}
+
+// pluginField field of model, fields are matched by column(name if column is empty), absent fields are removed
+type pluginField struct {
+	Name    string              `json:"name"`
+	Type    string              `json:"type"`
+	Column  string              `json:"column"`
+	Comment string              `json:"comment"`
+	Tag     map[string]string   `json:"tag"`
+	GORMTag map[string][]string `json:"gormTag"`
+}
+
+type pluginFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// execPlugin executable plugin started once for generation, each hook writes a request line to its stdin and reads
+// a response line from its stdout, stderr is passed through for logging
+type execPlugin struct {
+	path   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+func startExecPlugin(command string) (*execPlugin, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("empty plugin")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start plugin %s fail: %w", command, err)
+	}
+	return &execPlugin{path: args[0], cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+func (p *execPlugin) call(req *pluginRequest) (*pluginResponse, error) {
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = p.stdin.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("write to plugin %s fail: %w", p.path, err)
+	}
+	line, err = p.stdout.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("read from plugin %s fail: %w", p.path, err)
+	}
+	var resp pluginResponse
+	if err = json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response of plugin %s: %w", p.path, err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// close close stdin of plugin and wait it to exit
+func (p *execPlugin) close() error {
+	_ = p.stdin.Close()
+	if err := p.cmd.Wait(); err != nil {
+		return fmt.Errorf("plugin %s exit: %w", p.path, err)
+	}
+	return nil
+}
+
+func (p *execPlugin) BeforeIntrospect(tables []string) ([]string, error) {
+	resp, err := p.call(&pluginRequest{Hook: "BeforeIntrospect", Tables: tables})
+	if err != nil || resp.Tables == nil {
+		return tables, err
+	}
+	return resp.Tables, nil
+}
+
+func (p *execPlugin) AfterModel(m gen.Model) error {
+	req := &pluginRequest{Hook: "AfterModel", Model: &pluginModel{Table: m.TableName, Name: m.ModelStructName}}
+	for _, f := range m.Fields {
+		req.Model.Fields = append(req.Model.Fields, &pluginField{Name: f.Name, Type: f.Type, Column: f.ColumnName,
+			Comment: f.ColumnComment, Tag: f.Tag, GORMTag: f.GORMTag})
+	}
+	resp, err := p.call(req)
+	if err != nil || resp.Model == nil {
+		return err
+	}
+
+	changed := make(map[string]*pluginField, len(resp.Model.Fields))
+	for _, f := range resp.Model.Fields {
+		changed[pluginFieldKey(f.Column, f.Name)] = f
+	}
+	fields := m.Fields[:0]
+	for _, f := range m.Fields {
+		c, ok := changed[pluginFieldKey(f.ColumnName, f.Name)]
+		if !ok {
+			continue
+		}
+		f.Name, f.Type, f.ColumnComment = c.Name, c.Type, c.Comment
+		f.Tag, f.GORMTag = field.Tag(c.Tag), field.GormTag(c.GORMTag)
+		if f.Tag == nil {
+			f.Tag = field.Tag{}
+		}
+		if f.GORMTag == nil {
+			f.GORMTag = field.GormTag{}
+		}
+		fields = append(fields, f)
+	}
+	m.Fields = fields
+	return nil
+}
+
+func pluginFieldKey(column, name string) string {
+	if column != "" {
+		return "column:" + column
+	}
+	return "name:" + name
+}
+
+func (p *execPlugin) BeforeRender(f *gen.File) error {
+	resp, err := p.call(&pluginRequest{Hook: "BeforeRender", File: &pluginFile{Path: f.Path, Content: string(f.Content)}})
+	if err != nil || resp.File == nil {
+		return err
+	}
+	f.Content = []byte(resp.File.Content)
+	return nil
+}
+
+func (p *execPlugin) AfterWrite(path string) error {
+	_, err := p.call(&pluginRequest{Hook: "AfterWrite", Path: path})
+	return err
+}
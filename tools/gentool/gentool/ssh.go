@@ -0,0 +1,226 @@
+package gentool
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHConfig ssh tunnel through bastion host, db is dialed by bastion
+type SSHConfig struct {
+	Host       string `yaml:"host"`       // host[:port] of bastion, port default 22
+	User       string `yaml:"user"`       // user of bastion, default current user
+	KeyPath    string `yaml:"keyPath"`    // private key, default ~/.ssh/id_ed25519, id_ecdsa or id_rsa, keys of ssh-agent are also tried
+	JumpHost   string `yaml:"jumpHost"`   // [user@]host[:port] of jump host bastion is dialed through
+	KnownHosts string `yaml:"knownHosts"` // known_hosts file verifying host keys, default ~/.ssh/known_hosts
+}
+
+// sshTunnel dial db through ssh client of bastion, client is connected on first dial and reconnected
+// when dialing fails, e.g. connection is dropped during watch
+type sshTunnel struct {
+	config  SSHConfig
+	timeout time.Duration
+	log     Logger
+
+	mu      sync.Mutex
+	clients []*ssh.Client // jump host client if any, then bastion client
+}
+
+func newSSHTunnel(config *Config) *sshTunnel {
+	return &sshTunnel{config: config.SSH, timeout: seconds(config.ConnectTimeout, 10), log: config.logger()}
+}
+
+// DialContext dial addr by bastion
+func (t *sshTunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := t.dial(network, addr)
+		done <- result{conn, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() { // close connection established after ctx is done
+			if r := <-done; r.conn != nil {
+				_ = r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+func (t *sshTunnel) dial(network, addr string) (net.Conn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.clients) > 0 {
+		conn, err := t.clients[len(t.clients)-1].Dial(network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		t.log.Warnf("dial %s through ssh tunnel fail, reconnect: %s", addr, err)
+		t.close()
+	}
+	if err := t.connect(); err != nil {
+		return nil, err
+	}
+	conn, err := t.clients[len(t.clients)-1].Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s through ssh tunnel fail: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// connect connect bastion, through jump host if it's set
+func (t *sshTunnel) connect() error {
+	auth, err := sshAuthMethods(t.config.KeyPath)
+	if err != nil {
+		return err
+	}
+	knownHosts := expandHome(t.config.KnownHosts)
+	if knownHosts == "" {
+		knownHosts = filepath.Join(homeDir(), ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHosts)
+	if err != nil {
+		return fmt.Errorf("load ssh known hosts fail: %w", err)
+	}
+
+	var hops []string
+	if t.config.JumpHost != "" {
+		hops = append(hops, t.config.JumpHost)
+	}
+	hops = append(hops, t.config.Host)
+
+	for _, hop := range hops {
+		username, addr := parseSSHAddr(hop, t.config.User)
+		clientConfig := &ssh.ClientConfig{User: username, Auth: auth, HostKeyCallback: hostKeyCallback, Timeout: t.timeout}
+
+		var client *ssh.Client
+		if len(t.clients) == 0 {
+			client, err = ssh.Dial("tcp", addr, clientConfig)
+		} else {
+			client, err = dialSSHThrough(t.clients[len(t.clients)-1], addr, clientConfig)
+		}
+		if err != nil {
+			t.close()
+			return fmt.Errorf("connect ssh %s@%s fail: %w", username, addr, err)
+		}
+		t.clients = append(t.clients, client)
+	}
+	t.log.Infof("ssh tunnel connected: %s", strings.Join(hops, " -> "))
+	return nil
+}
+
+// close close clients from bastion to jump host
+func (t *sshTunnel) close() {
+	for i := len(t.clients) - 1; i >= 0; i-- {
+		_ = t.clients[i].Close()
+	}
+	t.clients = nil
+}
+
+func dialSSHThrough(jump *ssh.Client, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	conn, err := jump.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// parseSSHAddr user and host:port of [user@]host[:port], defaultUser or current user if user is absent
+func parseSSHAddr(s, defaultUser string) (username, addr string) {
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		username, s = s[:i], s[i+1:]
+	}
+	if username == "" {
+		username = defaultUser
+	}
+	if username == "" {
+		if u, err := user.Current(); err == nil {
+			username = u.Username
+		}
+	}
+	if _, _, err := net.SplitHostPort(s); err != nil {
+		s = net.JoinHostPort(strings.Trim(s, "[]"), "22")
+	}
+	return username, s
+}
+
+// sshAuthMethods public keys of key file and ssh-agent, default key files are tried if keyPath is empty
+func sshAuthMethods(keyPath string) ([]ssh.AuthMethod, error) {
+	var signers []ssh.Signer
+	if keyPath != "" {
+		signer, err := loadSSHKey(expandHome(keyPath))
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	} else {
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			if signer, err := loadSSHKey(filepath.Join(homeDir(), ".ssh", name)); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+	}
+
+	var methods []ssh.AuthMethod
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no ssh key found, set ssh.keyPath or add key into ssh-agent")
+	}
+	return methods, nil
+}
+
+func loadSSHKey(path string) (ssh.Signer, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ssh key fail: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(pem)
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh key %s fail(key with passphrase should be added into ssh-agent): %w", path, err)
+	}
+	return signer, nil
+}
+
+func homeDir() string {
+	home, _ := os.UserHomeDir()
+	return home
+}
+
+// expandHome replace leading ~/ of path with home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir(), path[2:])
+	}
+	return path
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gorm.io/gen/tools/gentool/gentool"
+)
+
+// initConfigTemplate commented gen.yml written by init, options not listed are documented in README
+const initConfigTemplate = `version: "0.1"
+database:
+  # consult[https://gorm.io/docs/connecting_to_the_database.html]
+  dsn : {{quote .DSN}}
+  # input mysql or postgres or sqlite or sqlserver or clickhouse or oracle
+  db  : {{quote .DB}}
+  # tables to generate, leave it blank to generate all tables
+  tables  :{{range .Tables}}
+    - {{quote .}}{{end}}
+  # specify a directory for output
+  outPath :  {{quote .OutPath}}
+  # query code file name, default: gen.go
+  outFile :  ""
+  # generated model code's package name, default: model
+  modelPkgName  : ""
+  # generate unit test for query code
+  withUnitTest  : false
+  # generate with pointer when field is nullable
+  fieldNullable : true
+  # generate field with gorm index tag
+  fieldWithIndexTag : true
+  # generate field with gorm column type tag
+  fieldWithTypeTag  : true
+  # detect integer field's unsigned type, adjust generated data type
+  fieldSignable  : false
+  # generate pointer for field whose column default value is non-zero, so zero value can be written
+  fieldDefaultTag  : false
+  # generate named type with constants and Valid method for enum and set column
+  fieldWithEnumType  : false
+  # skip files whose table schema is unchanged since last generation
+  lockFile  : ""
+  # more options: https://github.com/go-gorm/gen/tree/master/tools/gentool#usage
+`
+
+var initConfigTmpl = template.Must(template.New("gen.yml").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(initConfigTemplate))
+
+// prompter read answers of questions from in, default answer is used if answer is empty or in is EOF
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *prompter) ask(question, defaultAnswer string) (string, error) {
+	if defaultAnswer != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, defaultAnswer)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(p.out)
+		}
+		return defaultAnswer, nil
+	}
+	return line, nil
+}
+
+// runInit ask db, dsn, tables and outPath with flags as default answers, probe tables of db
+// and write commented config file
+func runInit(config *CmdParams) error {
+	path := config.configPath
+	if path == "" {
+		path = "gen.yml"
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("config file %s already exists", path)
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	var err error
+	if config.DB, err = p.ask("db (mysql/postgres/sqlite/sqlserver/clickhouse/oracle)", config.DB); err != nil {
+		return err
+	}
+	if config.DSN, err = p.ask("dsn", config.DSN); err != nil {
+		return err
+	}
+
+	db, err := gentool.Connect(&config.Config)
+	if err != nil {
+		return fmt.Errorf("connect db server fail: %w", err)
+	}
+	tables, err := gentool.Tables(db, config.DB)
+	if err != nil {
+		return fmt.Errorf("get all tables fail: %w", err)
+	}
+	sort.Strings(tables)
+
+	fmt.Fprintf(p.out, "found %d tables:\n", len(tables))
+	for i, table := range tables {
+		fmt.Fprintf(p.out, "  %3d) %s\n", i+1, table)
+	}
+	answer, err := p.ask("tables to generate, numbers or names separated by comma, empty for all", strings.Join(config.Tables, ","))
+	if err != nil {
+		return err
+	}
+	if config.Tables, err = selectTables(tables, answer); err != nil {
+		return err
+	}
+	if config.OutPath, err = p.ask("outPath", config.OutPath); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint
+	if err = initConfigTmpl.Execute(file, config); err != nil {
+		return err
+	}
+	toolLog.Infof("write config file: %s, run 'gentool -c %s' to generate code", path, path)
+	return nil
+}
+
+// selectTables pick tables by 1-based numbers or names of answer, nil if answer is empty
+func selectTables(tables []string, answer string) ([]string, error) {
+	if strings.TrimSpace(answer) == "" {
+		return nil, nil
+	}
+
+	exist := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		exist[table] = true
+	}
+
+	var selected []string
+	for _, item := range strings.Split(answer, ",") {
+		item = strings.TrimSpace(item)
+		switch n, err := strconv.Atoi(item); {
+		case item == "":
+			continue
+		case exist[item]:
+			selected = append(selected, item)
+		case err == nil && n >= 1 && n <= len(tables):
+			selected = append(selected, tables[n-1])
+		default:
+			return nil, fmt.Errorf("unknown table %q", item)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var levelNames = map[logLevel]string{levelDebug: "debug", levelInfo: "info", levelWarn: "warn", levelError: "error"}
+
+// toolLogger leveled logger of gentool writing text or json lines, it's also used as gorm logger and output
+// of std log, so messages of gen are leveled too
+type toolLogger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level logLevel
+	json  bool
+	now   func() time.Time
+
+	status string           // live status line redrawn below log lines, see setStatus
+	counts map[logLevel]int // number of messages by level
+}
+
+var toolLog = &toolLogger{out: os.Stderr, level: levelInfo, now: time.Now, counts: make(map[logLevel]int)}
+
+// setup apply verbosity and format of config, and take over gorm and std logger
+func (l *toolLogger) setup(config *CmdParams) error {
+	switch config.LogFormat {
+	case "", "text":
+		l.json = false
+	case "json":
+		l.json = true
+	default:
+		return fmt.Errorf("unknown log format %q (support text || json)", config.LogFormat)
+	}
+	switch {
+	case config.Verbose:
+		l.level = levelDebug
+	case config.Quiet:
+		l.level = levelWarn
+	default:
+		l.level = levelInfo
+	}
+
+	logger.Default = l
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{l})
+	return nil
+}
+
+func (l *toolLogger) log(level logLevel, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.counts[level]++
+	if level < l.level {
+		return
+	}
+	msg = strings.TrimRight(msg, "\n")
+	now := l.now()
+
+	if l.status != "" {
+		_, _ = io.WriteString(l.out, "\r\x1b[2K")
+		defer io.WriteString(l.out, l.status) // nolint
+	}
+	if l.json {
+		line, _ := json.Marshal(struct {
+			Time  string `json:"time"`
+			Level string `json:"level"`
+			Msg   string `json:"msg"`
+		}{now.Format(time.RFC3339Nano), levelNames[level], msg})
+		_, _ = fmt.Fprintf(l.out, "%s\n", line)
+		return
+	}
+	_, _ = fmt.Fprintf(l.out, "%s %-5s %s\n", now.Format("2006/01/02 15:04:05"), strings.ToUpper(levelNames[level]), msg)
+}
+
+// count number of messages of level logged so far, including ones below verbosity
+func (l *toolLogger) count(level logLevel) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.counts[level]
+}
+
+// live whether status line can be shown, which requires text format on terminal
+func (l *toolLogger) live() bool {
+	if l.json || l.level > levelInfo || l.out != os.Stderr {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// setStatus show status below log lines on terminal until it's cleared by empty status
+func (l *toolLogger) setStatus(status string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.status = status
+	_, _ = io.WriteString(l.out, "\r\x1b[2K"+status)
+}
+
+// summary log rows of name and value as aligned table, or one json object
+func (l *toolLogger) summary(rows [][2]string) {
+	if l.level > levelInfo {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.json {
+		values := make(map[string]string, len(rows))
+		for _, row := range rows {
+			values[row[0]] = row[1]
+		}
+		line, _ := json.Marshal(struct {
+			Time    string            `json:"time"`
+			Level   string            `json:"level"`
+			Msg     string            `json:"msg"`
+			Summary map[string]string `json:"summary"`
+		}{l.now().Format(time.RFC3339Nano), levelNames[levelInfo], "summary", values})
+		_, _ = fmt.Fprintf(l.out, "%s\n", line)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("\nSummary\n")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "  %-14s %s\n", row[0], row[1])
+	}
+	_, _ = io.WriteString(l.out, b.String())
+}
+
+func (l *toolLogger) Infof(format string, args ...interface{}) {
+	l.log(levelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *toolLogger) Warnf(format string, args ...interface{}) {
+	l.log(levelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *toolLogger) Errorf(format string, args ...interface{}) {
+	l.log(levelError, fmt.Sprintf(format, args...))
+}
+
+// LogMode implement gorm logger.Interface, level is set by flags
+func (l *toolLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+// Info implement gorm logger.Interface, it's skipped as info of gen is logged by std log too
+func (l *toolLogger) Info(context.Context, string, ...interface{}) {}
+
+// Warn implement gorm logger.Interface
+func (l *toolLogger) Warn(_ context.Context, msg string, data ...interface{}) {
+	l.log(levelWarn, fmt.Sprintf(msg, data...))
+}
+
+// Error implement gorm logger.Interface
+func (l *toolLogger) Error(_ context.Context, msg string, data ...interface{}) {
+	l.log(levelError, fmt.Sprintf(msg, data...))
+}
+
+// Trace implement gorm logger.Interface, sql of introspecting tables is logged in verbose mode
+func (l *toolLogger) Trace(_ context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level > levelDebug {
+		return
+	}
+	sql, rows := fc()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		l.log(levelDebug, fmt.Sprintf("[%s] [rows:%d] %s: %s", time.Since(begin).Round(time.Microsecond), rows, sql, err))
+		return
+	}
+	l.log(levelDebug, fmt.Sprintf("[%s] [rows:%d] %s", time.Since(begin).Round(time.Microsecond), rows, sql))
+}
+
+// stdLogWriter output of std log, lines are logged in info level
+type stdLogWriter struct{ l *toolLogger }
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	w.l.log(levelInfo, string(p))
+	return len(p), nil
+}
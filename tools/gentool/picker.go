@@ -0,0 +1,369 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gen/tools/gentool/gentool"
+	"gorm.io/gorm"
+)
+
+// pickerHeight rows of items shown in picker
+const pickerHeight = 20
+
+type pickAction int
+
+const (
+	pickNone pickAction = iota
+	pickOpen            // open columns of item under cursor
+	pickDone
+	pickCancel
+)
+
+// pickItem checkbox of table or column
+type pickItem struct {
+	name    string
+	checked bool
+	note    string
+}
+
+// picker checkbox list in terminal, items are filtered by substring typed after /
+type picker struct {
+	title     string
+	help      string
+	items     []*pickItem
+	cursor    int // index in visible items
+	offset    int // first visible item shown
+	filter    string
+	filtering bool
+}
+
+func newPicker(title, help string, names []string, checked func(name string) bool) *picker {
+	p := &picker{title: title, help: help}
+	for _, name := range names {
+		p.items = append(p.items, &pickItem{name: name, checked: checked(name)})
+	}
+	return p
+}
+
+// visible items matching filter
+func (p *picker) visible() (items []*pickItem) {
+	for _, item := range p.items {
+		if strings.Contains(item.name, p.filter) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// current item under cursor, nil if no item is visible
+func (p *picker) current() *pickItem {
+	if items := p.visible(); p.cursor < len(items) {
+		return items[p.cursor]
+	}
+	return nil
+}
+
+// checked names of checked items
+func (p *picker) checked() (names []string) {
+	for _, item := range p.items {
+		if item.checked {
+			names = append(names, item.name)
+		}
+	}
+	return names
+}
+
+// press handle key read from terminal
+func (p *picker) press(key string) pickAction {
+	if p.filtering {
+		switch key {
+		case "\r", "\n", "\x1b":
+			p.filtering = false
+		case "\x7f", "\b":
+			if p.filter != "" {
+				p.filter = p.filter[:len(p.filter)-1]
+			}
+		case "\x03":
+			return pickCancel
+		default:
+			if len(key) == 1 && key[0] >= ' ' && key[0] <= '~' {
+				p.filter += key
+			}
+		}
+		p.cursor = 0
+		return pickNone
+	}
+
+	items := p.visible()
+	switch key {
+	case "\x1b[A", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "\x1b[B", "j":
+		if p.cursor < len(items)-1 {
+			p.cursor++
+		}
+	case " ":
+		if item := p.current(); item != nil {
+			item.checked = !item.checked
+		}
+	case "a":
+		all := true
+		for _, item := range items {
+			all = all && item.checked
+		}
+		for _, item := range items {
+			item.checked = !all
+		}
+	case "/":
+		p.filtering, p.filter, p.cursor = true, "", 0
+	case "\x1b[C", "l":
+		if p.current() != nil {
+			return pickOpen
+		}
+	case "\r", "\n":
+		return pickDone
+	case "q", "\x1b", "\x03":
+		return pickCancel
+	}
+	return pickNone
+}
+
+// render draw picker, lines end with \r\n for terminal in raw mode
+func (p *picker) render(w io.Writer) {
+	items := p.visible()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	} else if p.cursor >= p.offset+pickerHeight {
+		p.offset = p.cursor - pickerHeight + 1
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "%s (%d/%d selected)", p.title, len(p.checked()), len(p.items))
+	if p.filtering || p.filter != "" {
+		fmt.Fprintf(&b, "  filter: %s", p.filter)
+		if p.filtering {
+			b.WriteString("_")
+		}
+	}
+	fmt.Fprintf(&b, "\r\n%s\r\n\r\n", p.help)
+	for i := p.offset; i < len(items) && i < p.offset+pickerHeight; i++ {
+		cursor, box := "  ", "[ ]"
+		if i == p.cursor {
+			cursor = "> "
+		}
+		if items[i].checked {
+			box = "[x]"
+		}
+		fmt.Fprintf(&b, "%s%s %s", cursor, box, items[i].name)
+		if items[i].note != "" {
+			fmt.Fprintf(&b, "  (%s)", items[i].note)
+		}
+		b.WriteString("\r\n")
+	}
+	if len(items) > p.offset+pickerHeight {
+		fmt.Fprintf(&b, "  ... %d more\r\n", len(items)-p.offset-pickerHeight)
+	}
+	_, _ = io.WriteString(w, b.String())
+}
+
+// run read keys from in until picking is done or canceled, open is called on pickOpen
+func (p *picker) run(in io.Reader, out io.Writer, open func(item *pickItem) error) (ok bool, err error) {
+	buf := make([]byte, 16)
+	for {
+		p.render(out)
+		n, err := in.Read(buf)
+		if err != nil {
+			return false, err
+		}
+		switch p.press(string(buf[:n])) {
+		case pickOpen:
+			if open != nil {
+				if err = open(p.current()); err != nil {
+					return false, err
+				}
+			}
+		case pickDone:
+			return true, nil
+		case pickCancel:
+			return false, nil
+		}
+	}
+}
+
+// pickTables pick tables and columns of db in terminal, update Tables and ExcludeColumns of config,
+// return false if picking is canceled
+func pickTables(db *gorm.DB, config *CmdParams) (bool, error) {
+	tables, err := gentool.Tables(db, config.DB)
+	if err != nil {
+		return false, fmt.Errorf("get all tables fail: %w", err)
+	}
+	sort.Strings(tables)
+
+	selected := make(map[string]bool, len(config.Tables))
+	for _, table := range config.Tables {
+		selected[table] = true
+	}
+	excluded := make(map[string]map[string]bool)
+	var otherExcludes []string // excluded columns of all tables, kept as it is
+	for _, c := range config.ExcludeColumns {
+		table, column, ok := strings.Cut(c, ".")
+		if !ok {
+			otherExcludes = append(otherExcludes, c)
+			continue
+		}
+		if excluded[table] == nil {
+			excluded[table] = make(map[string]bool)
+		}
+		excluded[table][column] = true
+	}
+
+	tablePicker := newPicker("Select tables", "↑/↓ move  space toggle  a toggle all  / filter  → columns  enter done  q quit",
+		tables, func(name string) bool { return len(selected) == 0 || selected[name] })
+	for _, item := range tablePicker.items {
+		item.note = excludedNote(len(excluded[item.name]))
+	}
+
+	restore, err := rawTerminal()
+	if err != nil {
+		return false, err
+	}
+	defer restore()
+
+	ok, err := tablePicker.run(os.Stdin, os.Stdout, func(item *pickItem) error {
+		columnTypes, err := db.Migrator().ColumnTypes(item.name)
+		if err != nil {
+			return fmt.Errorf("GORM migrator get columns of %s fail: %w", item.name, err)
+		}
+		columns := make([]string, len(columnTypes))
+		for i, c := range columnTypes {
+			columns[i] = c.Name()
+		}
+		columnPicker := newPicker("Select columns of "+item.name, "↑/↓ move  space toggle  a toggle all  / filter  enter back  q discard",
+			columns, func(name string) bool { return !excluded[item.name][name] })
+		if ok, err := columnPicker.run(os.Stdin, os.Stdout, nil); err != nil || !ok {
+			return err
+		}
+
+		excluded[item.name] = make(map[string]bool)
+		for _, c := range columnPicker.items {
+			if !c.checked {
+				excluded[item.name][c.name] = true
+			}
+		}
+		item.checked = item.checked || len(excluded[item.name]) < len(columns)
+		item.note = excludedNote(len(excluded[item.name]))
+		return nil
+	})
+	_, _ = io.WriteString(os.Stdout, "\x1b[H\x1b[2J")
+	if err != nil || !ok {
+		return false, err
+	}
+
+	config.Tables = tablePicker.checked()
+	if len(config.Tables) == len(tables) {
+		config.Tables = nil // all tables, including tables created later
+	}
+	config.ExcludeColumns = otherExcludes
+	for _, table := range tablePicker.checked() {
+		columns := make([]string, 0, len(excluded[table]))
+		for column := range excluded[table] {
+			columns = append(columns, table+"."+column)
+		}
+		sort.Strings(columns)
+		config.ExcludeColumns = append(config.ExcludeColumns, columns...)
+	}
+	return true, nil
+}
+
+func excludedNote(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d columns excluded", n)
+}
+
+// rawTerminal switch terminal into raw mode by stty, restore is called to switch back
+func rawTerminal() (restore func(), err error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("interactive mode requires a terminal with stty: %w", err)
+	}
+	if _, err = stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("switch terminal into raw mode fail: %w", err)
+	}
+	_, _ = io.WriteString(os.Stdout, "\x1b[?25l")
+	return func() {
+		_, _ = io.WriteString(os.Stdout, "\x1b[?25h")
+		_, _ = stty(strings.TrimSpace(state))
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// writeSelection write tables and excludeColumns of config back into config file, comments of file are kept
+func writeSelection(path string, config *CmdParams) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("invalid config file %s", path)
+	}
+	database := mappingValue(doc.Content[0], "database")
+	if database.Kind != yaml.MappingNode {
+		database.Kind, database.Tag, database.Value = yaml.MappingNode, "!!map", ""
+	}
+	setSequence(mappingValue(database, "tables"), config.Tables)
+	setSequence(mappingValue(database, "excludeColumns"), config.ExcludeColumns)
+
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&doc); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// mappingValue value node of key in mapping node, key is appended if it's absent
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// setSequence replace node with sequence of values, or null if values is empty
+func setSequence(node *yaml.Node, values []string) {
+	node.Content, node.Style = nil, 0
+	if len(values) == 0 {
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", ""
+		return
+	}
+	node.Kind, node.Tag, node.Value = yaml.SequenceNode, "!!seq", ""
+	for _, v := range values {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gen"
+)
+
+// progressReporter show progress of stages having more tables than threshold, as live status line on
+// terminal, or log lines every 10 percent otherwise
+type progressReporter struct {
+	threshold int
+	live      bool
+	stage     string
+	logged    int // percent of stage logged last time
+}
+
+func newProgressReporter(config *CmdParams) *progressReporter {
+	threshold := config.Progress
+	if threshold == 0 {
+		threshold = 50
+	}
+	return &progressReporter{threshold: threshold, live: toolLog.live()}
+}
+
+func (r *progressReporter) report(p gen.Progress) {
+	if r.threshold < 0 || p.Total <= r.threshold {
+		return
+	}
+	if p.Stage != r.stage {
+		r.stage, r.logged = p.Stage, 0
+	}
+
+	percent := p.Done * 100 / p.Total
+	line := fmt.Sprintf("[%s] %d/%d tables (%d%%), current: %s", p.Stage, p.Done, p.Total, percent, p.Current)
+	if eta := p.ETA().Round(time.Second); eta > 0 {
+		line += ", ETA: " + eta.String()
+	}
+
+	switch {
+	case r.live && p.Done < p.Total:
+		toolLog.setStatus(line)
+	case r.live:
+		toolLog.setStatus("")
+		toolLog.Infof("[%s] %d tables done in %s", p.Stage, p.Total, p.Elapsed.Round(time.Millisecond))
+	case percent/10 > r.logged/10:
+		r.logged = percent
+		toolLog.Infof("%s", line)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gen/tools/gentool/gentool"
+)
+
+// configKeys yaml key of CmdParams -> type of field
+var configKeys = yamlKeys(reflect.TypeOf(CmdParams{}))
+
+// yamlKeys yaml key of fields of struct -> type of field, keys of inline struct are merged
+func yamlKeys(typ reflect.Type) map[string]reflect.Type {
+	keys := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("yaml"), ",")
+		switch {
+		case len(tag) > 1 && tag[1] == "inline":
+			for key, t := range yamlKeys(typ.Field(i).Type) {
+				keys[key] = t
+			}
+		case tag[0] != "" && tag[0] != "-":
+			keys[tag[0]] = typ.Field(i).Type
+		}
+	}
+	return keys
+}
+
+// configEnums key -> supported values, empty value is always allowed as default
+var configEnums = map[string][]string{
+	"erd":           {"mermaid", "graphviz"},
+	"withDI":        {"wire", "fx"},
+	"logFormat":     {"text", "json"},
+	"formatter":     {"goimports", "gofmt", "gofumpt"},
+	"modelLayout":   {"table", "single"},
+	"unitTestDB":    {"sqlite", "testcontainers"},
+	"verify":        {"build"},
+	"migrate":       {"automigrate", "golang-migrate", "goose"},
+	"decimalType":   {"shopspring", "string", "float64"},
+	"nullableStyle": {"pointer", "sqlnull", "genericNull"},
+	"dateType":      {"time", "datatypes", "string"},
+	"timeType":      {"time", "datatypes", "string"},
+	"dateTimeType":  {"time", "string"},
+	"noPrimaryKey":  {"skip", "readOnly", "uniqueIndex"},
+}
+
+// configProblem problem of config, line is 0 if it's not from config file
+type configProblem struct {
+	line int
+	msg  string
+}
+
+// configError all problems of config
+type configError struct {
+	path     string
+	problems []configProblem
+}
+
+func (e *configError) Error() string {
+	sort.SliceStable(e.problems, func(i, j int) bool { return e.problems[i].line < e.problems[j].line })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems in config:", len(e.problems))
+	for _, p := range e.problems {
+		b.WriteString("\n  ")
+		if p.line > 0 {
+			fmt.Fprintf(&b, "%s:%d: ", e.path, p.line)
+		}
+		b.WriteString(p.msg)
+	}
+	return b.String()
+}
+
+func (e *configError) add(line int, format string, args ...interface{}) {
+	e.problems = append(e.problems, configProblem{line: line, msg: fmt.Sprintf(format, args...)})
+}
+
+// checkConfigNode check keys and types of config file, return lines of keys under database
+func checkConfigNode(doc *yaml.Node, e *configError) map[string]int {
+	lines := make(map[string]int)
+	if len(doc.Content) == 0 {
+		return lines
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		e.add(root.Line, "config should be a mapping with key database")
+		return lines
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "version":
+		case "database":
+			if value.Kind != yaml.MappingNode {
+				e.add(value.Line, "database should be a mapping of options")
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				checkConfigKey(configKeys, "", value.Content[j], value.Content[j+1], lines, e)
+			}
+		default:
+			e.add(key.Line, "unknown key %q, options should be under database", key.Value)
+		}
+	}
+	return lines
+}
+
+// checkConfigKey check key is one of keys and type of value, keys of nested mapping are prefixed by prefix, e.g. ssh.host
+func checkConfigKey(keys map[string]reflect.Type, prefix string, key, value *yaml.Node, lines map[string]int, e *configError) {
+	typ, ok := keys[key.Value]
+	if !ok {
+		if suggestion := suggestKey(keys, key.Value); suggestion != "" {
+			e.add(key.Line, "unknown key %q, did you mean %q?", prefix+key.Value, prefix+suggestion)
+		} else {
+			e.add(key.Line, "unknown key %q", prefix+key.Value)
+		}
+		return
+	}
+	lines[prefix+key.Value] = key.Line
+	if typ.Kind() == reflect.Struct && value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			checkConfigKey(yamlKeys(typ), prefix+key.Value+".", value.Content[i], value.Content[i+1], lines, e)
+		}
+		return
+	}
+
+	if value.Tag == "!!null" {
+		return
+	}
+	ok = value.Decode(reflect.New(typ).Interface()) == nil
+	switch typ.Kind() {
+	case reflect.String: // any scalar can be decoded into string
+		ok = value.Kind == yaml.ScalarNode
+	case reflect.Bool:
+		ok = ok && value.Tag == "!!bool"
+	}
+	if !ok {
+		e.add(value.Line, "%s should be %s, got %s", prefix+key.Value, describeType(typ), describeNode(value))
+	}
+}
+
+// suggestKey known key of keys similar to key, e.g. outPath of outpath or outPth
+func suggestKey(keys map[string]reflect.Type, key string) (suggestion string) {
+	best := 3 // max distance of suggestion + 1
+	for known := range keys {
+		if strings.EqualFold(known, key) {
+			return known
+		}
+		if d := editDistance(strings.ToLower(known), strings.ToLower(key)); d < best || (d == best && known < suggestion) {
+			best, suggestion = d, known
+		}
+	}
+	if best >= 3 {
+		return ""
+	}
+	return suggestion
+}
+
+func editDistance(a, b string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func describeType(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int:
+		return "an integer"
+	case reflect.Slice:
+		return "a list of strings"
+	case reflect.Struct:
+		return "a mapping"
+	case reflect.Map:
+		if typ.Elem().Kind() == reflect.Slice {
+			return "a mapping of string lists"
+		}
+		return "a mapping of strings"
+	default:
+		return "a string"
+	}
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.MappingNode:
+		return "a mapping"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+// validateParams check options merged from config file and flags, all problems are added into e
+func validateParams(p *CmdParams, e *configError) {
+	line := func(key string) int { return p.keyLines[key] }
+
+	switch {
+	case p.DSN == "" && p.FromSchemaJSON == "":
+		e.add(0, "dsn is required, or set fromSchemaJSON to generate without connecting db")
+	case p.DSN != "" && p.FromSchemaJSON != "":
+		e.add(line("fromSchemaJSON"), "dsn and fromSchemaJSON are mutually exclusive, remove one of them")
+	}
+	if drivers := gentool.Drivers(); !contains(drivers, p.DB) {
+		e.add(line("db"), "unknown db %q (support %s)", p.DB, strings.Join(drivers, " || "))
+	}
+	if p.Verbose && p.Quiet {
+		e.add(line("quiet"), "verbose and quiet are mutually exclusive")
+	}
+	if p.Prune && len(p.Tables) > 0 {
+		e.add(line("prune"), "prune works on all tables, tables should be empty")
+	}
+	if p.OnlyModel && p.WithHandlers != "" {
+		e.add(line("withHandlers"), "withHandlers requires query code, which is not generated by onlyModel")
+	}
+	if p.OnlyModel && p.WithDI != "" {
+		e.add(line("withDI"), "withDI requires query code, which is not generated by onlyModel")
+	}
+	if p.ConnectTimeout < 0 {
+		e.add(line("connectTimeout"), "connectTimeout should not be negative")
+	}
+	if p.ReadTimeout < 0 {
+		e.add(line("readTimeout"), "readTimeout should not be negative")
+	}
+	if (p.TLSCert == "") != (p.TLSKey == "") {
+		key := "tlsCert"
+		if p.TLSCert == "" {
+			key = "tlsKey"
+		}
+		e.add(line(key), "tlsCert and tlsKey should be set together")
+	}
+	if p.DB == string(gentool.SQLite) && (p.ConnectTimeout > 0 || p.ReadTimeout > 0 || p.HasTLSOptions()) {
+		e.add(0, "timeout and tls options are not supported by sqlite")
+	}
+	if p.Charset != "" && p.DB != string(gentool.MySQL) && p.DB != string(gentool.Postgres) {
+		e.add(line("charset"), "charset is only supported by mysql and postgres")
+	}
+	if p.SearchPath != "" && p.DB != string(gentool.Postgres) {
+		e.add(line("searchPath"), "searchPath is only supported by postgres")
+	}
+	if p.SSH != (gentool.SSHConfig{}) && p.SSH.Host == "" {
+		e.add(line("ssh"), "ssh.host is required by ssh tunnel")
+	}
+	if p.SSH.Host != "" && p.DB == string(gentool.SQLite) {
+		e.add(line("ssh.host"), "ssh tunnel is not supported by sqlite")
+	}
+
+	values := reflect.ValueOf(&p.Config).Elem()
+	typ := values.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		key := strings.Split(typ.Field(i).Tag.Get("yaml"), ",")[0]
+		enum, ok := configEnums[key]
+		if value := values.Field(i).String(); ok && value != "" && !contains(enum, value) {
+			e.add(line(key), "unknown %s %q (support %s)", key, value, strings.Join(enum, " || "))
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gen/tools/gentool/gentool"
+	"gorm.io/gorm"
+)
+
+// watcher regenerate code when schema of tables, DDL files or metadata JSON file changes
+type watcher struct {
+	config *CmdParams
+	db     *gorm.DB // nil if code is generated from metadata JSON file
+}
+
+// runWatch generate code, then poll changes and regenerate changed tables until interrupted.
+// Unchanged tables are skipped by lock file, which is gen.lock next to outPath if it's not set
+func runWatch(config *CmdParams) error {
+	if config.LockFile == "" {
+		config.LockFile = filepath.Join(filepath.Dir(config.OutPath), "gen.lock")
+		toolLog.Infof("use lock file: %s", config.LockFile)
+	}
+	interval, debounce := seconds(config.WatchInterval, 2), seconds(config.WatchDebounce, 1)
+
+	w := &watcher{config: config}
+	if config.FromSchemaJSON == "" {
+		db, err := gentool.Connect(&config.Config)
+		if err != nil {
+			return fmt.Errorf("connect db server fail: %w", err)
+		}
+		w.db, config.Conn = db, db
+	}
+
+	last, err := w.poll()
+	if err != nil {
+		return err
+	}
+	w.regenerate(nil)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	toolLog.Infof("watching %s every %s, press Ctrl+C to stop", w.target(), interval)
+	var pending []string
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			toolLog.Infof("stop watching")
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := w.poll()
+		if err != nil {
+			toolLog.Warnf("poll changes fail: %s", err)
+			continue
+		}
+		if changes := diffSnapshot(last, current); len(changes) > 0 {
+			toolLog.Infof("detect changes: %s", strings.Join(changes, ","))
+			pending, last, changedAt = uniqueAppend(pending, changes...), current, time.Now()
+			continue
+		}
+		if len(pending) > 0 && time.Since(changedAt) >= debounce {
+			w.regenerate(pending)
+			pending = nil
+		}
+	}
+}
+
+// target what is watched
+func (w *watcher) target() string {
+	switch {
+	case w.config.DDLDir != "":
+		return "DDL files in " + w.config.DDLDir
+	case w.db == nil:
+		return "metadata file " + w.config.FromSchemaJSON
+	default:
+		return "schema of tables"
+	}
+}
+
+// poll snapshot of watched tables or files
+func (w *watcher) poll() (map[string]string, error) {
+	switch {
+	case w.config.DDLDir != "":
+		return fileSnapshot(w.config.DDLDir)
+	case w.db == nil:
+		return fileSnapshot(w.config.FromSchemaJSON)
+	default:
+		return schemaSnapshot(w.db, w.config.DB, w.config.Tables)
+	}
+}
+
+// regenerate generate code and run notify command, failure is logged to keep watching
+func (w *watcher) regenerate(changes []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			toolLog.Errorf("generate fail: %v", r)
+		}
+	}()
+
+	if err := generate(w.config); err != nil {
+		toolLog.Errorf("generate fail: %s", err)
+		return
+	}
+
+	if w.config.Notify == "" || changes == nil {
+		return
+	}
+	cmd := exec.Command("sh", "-c", w.config.Notify)
+	cmd.Env = append(os.Environ(), "GEN_CHANGED="+strings.Join(changes, ","))
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		toolLog.Warnf("run notify command fail: %s", err)
+	}
+}
+
+// schemaSnapshot columns and indexes of tables, all tables of db of type dbType if tables is empty
+func schemaSnapshot(db *gorm.DB, dbType string, tables []string) (map[string]string, error) {
+	if len(tables) == 0 {
+		var err error
+		if tables, err = gentool.Tables(db, dbType); err != nil {
+			return nil, fmt.Errorf("get all tables fail: %w", err)
+		}
+	}
+
+	snapshot := make(map[string]string, len(tables))
+	for _, table := range tables {
+		columnTypes, err := db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return nil, fmt.Errorf("GORM migrator get columns of %s fail: %w", table, err)
+		}
+		var b strings.Builder
+		for _, c := range columnTypes {
+			typ, _ := c.ColumnType()
+			nullable, _ := c.Nullable()
+			primaryKey, _ := c.PrimaryKey()
+			defaultValue, _ := c.DefaultValue()
+			comment, _ := c.Comment()
+			fmt.Fprintf(&b, "column %s %s %t %t %q %q\n", c.Name(), typ, nullable, primaryKey, defaultValue, comment)
+		}
+		if indexes, err := db.Migrator().GetIndexes(table); err == nil { // not supported by some dialects
+			for _, idx := range indexes {
+				unique, _ := idx.Unique()
+				fmt.Fprintf(&b, "index %s %s %t\n", idx.Name(), strings.Join(idx.Columns(), ","), unique)
+			}
+		}
+		snapshot[table] = b.String()
+	}
+	return snapshot, nil
+}
+
+// fileSnapshot modification time and size of file, or files under directory of path
+func fileSnapshot(path string) (map[string]string, error) {
+	snapshot := make(map[string]string)
+	err := filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		snapshot[name] = fmt.Sprintf("%d %d", info.ModTime().UnixNano(), info.Size())
+		return nil
+	})
+	return snapshot, err
+}
+
+// diffSnapshot sorted keys added, removed or changed in current snapshot
+func diffSnapshot(last, current map[string]string) (changes []string) {
+	for key, value := range current {
+		if lastValue, ok := last[key]; !ok || lastValue != value {
+			changes = append(changes, key)
+		}
+	}
+	for key := range last {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, key)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+func uniqueAppend(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, v := range list {
+			found = found || v == item
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// seconds duration of n seconds, defaultSeconds if n is not positive
+func seconds(n, defaultSeconds int) time.Duration {
+	if n <= 0 {
+		n = defaultSeconds
+	}
+	return time.Duration(n) * time.Second
+}
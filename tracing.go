@@ -0,0 +1,148 @@
+package gen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	tracerSettingKey = "gen:tracer"
+	spanInstanceKey  = "gen:span"
+)
+
+// Tracer trace statements executed by query code, implementation for OpenTelemetry is provided in package gorm.io/gen/tracing
+type Tracer interface {
+	// Start start span for operation(create/query/update/delete/row/raw) on table
+	Start(ctx context.Context, table, operation string) (context.Context, Span)
+}
+
+// Span span started by Tracer
+type Span interface {
+	// End finish span with rows affected and error of statement
+	End(rowsAffected int64, err error)
+}
+
+// WithTracing trace statements executed by query code with tracer
+func WithTracing(tracer Tracer) DOOption {
+	return &tracingOption{tracer: tracer}
+}
+
+type tracingOption struct{ tracer Tracer }
+
+// Apply update config to new config
+func (o *tracingOption) Apply(config *DOConfig) error {
+	config.tracer = o.tracer
+	return nil
+}
+
+// AfterInitialize initialize plugins after db connected
+func (o *tracingOption) AfterInitialize(do *DO) error {
+	if err := registerTraceCallbacks(do.db); err != nil {
+		return err
+	}
+	do.db = do.settingDB(do.db)
+	return nil
+}
+
+// MetricsTracer tracer which observe duration of statements, e.g. to feed a prometheus.HistogramVec:
+//
+//	gen.MetricsTracer(func(table, operation string, duration time.Duration, err error) {
+//		histogram.WithLabelValues(table, operation).Observe(duration.Seconds())
+//	})
+func MetricsTracer(observe func(table, operation string, duration time.Duration, err error)) Tracer {
+	return metricsTracer(observe)
+}
+
+type metricsTracer func(table, operation string, duration time.Duration, err error)
+
+func (t metricsTracer) Start(ctx context.Context, table, operation string) (context.Context, Span) {
+	return ctx, &metricsSpan{observe: t, table: table, operation: operation, start: time.Now()}
+}
+
+type metricsSpan struct {
+	observe   metricsTracer
+	table     string
+	operation string
+	start     time.Time
+}
+
+func (s *metricsSpan) End(_ int64, err error) {
+	s.observe(s.table, s.operation, time.Since(s.start), err)
+}
+
+// Tracers combine tracers, spans are started in order
+func Tracers(tracers ...Tracer) Tracer { return multiTracer(tracers) }
+
+type multiTracer []Tracer
+
+func (t multiTracer) Start(ctx context.Context, table, operation string) (context.Context, Span) {
+	spans := make(multiSpan, 0, len(t))
+	for _, tracer := range t {
+		var span Span
+		ctx, span = tracer.Start(ctx, table, operation)
+		spans = append(spans, span)
+	}
+	return ctx, spans
+}
+
+type multiSpan []Span
+
+func (s multiSpan) End(rowsAffected int64, err error) {
+	for i := len(s) - 1; i >= 0; i-- {
+		s[i].End(rowsAffected, err)
+	}
+}
+
+var tracedCallbacks sync.Map // callbacks of db -> struct{}
+
+// registerTraceCallbacks register callbacks starting/ending spans once for each db,
+// statements are traced only when db is marked by settingDB
+func registerTraceCallbacks(db *gorm.DB) error {
+	if _, loaded := tracedCallbacks.LoadOrStore(db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+
+	callback := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("*").Register, callback.Create().After("*").Register},
+		{"query", callback.Query().Before("*").Register, callback.Query().After("*").Register},
+		{"update", callback.Update().Before("*").Register, callback.Update().After("*").Register},
+		{"delete", callback.Delete().Before("*").Register, callback.Delete().After("*").Register},
+		{"row", callback.Row().Before("*").Register, callback.Row().After("*").Register},
+		{"raw", callback.Raw().Before("*").Register, callback.Raw().After("*").Register},
+	}
+	for _, p := range processors {
+		if err := p.before("gen:trace_before_"+p.operation, startSpan(p.operation)); err != nil {
+			return err
+		}
+		if err := p.after("gen:trace_after_"+p.operation, endSpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		tracer, ok := db.Get(tracerSettingKey)
+		if !ok {
+			return
+		}
+		ctx, span := tracer.(Tracer).Start(db.Statement.Context, db.Statement.Table, operation)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanInstanceKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	if span, ok := db.InstanceGet(spanInstanceKey); ok {
+		span.(Span).End(db.Statement.RowsAffected, db.Error)
+	}
+}
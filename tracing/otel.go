@@ -0,0 +1,47 @@
+// Package tracing provides gen.Tracer implementations
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"gorm.io/gen"
+)
+
+// OpenTelemetry create tracer which start OpenTelemetry span named "gen.<operation>" for each statement,
+// with attributes db.table, db.operation and db.rows_affected
+func OpenTelemetry(tracer trace.Tracer) gen.Tracer {
+	return &otelTracer{tracer: tracer}
+}
+
+// Global create tracer of OpenTelemetry by tracer provider registered globally(otel.SetTracerProvider), spans are
+// started by the provider registered at the time, it's used by Use of query code generated with Config.Tracing
+func Global() gen.Tracer {
+	return OpenTelemetry(otel.Tracer("gorm.io/gen"))
+}
+
+type otelTracer struct{ tracer trace.Tracer }
+
+func (t *otelTracer) Start(ctx context.Context, table, operation string) (context.Context, gen.Span) {
+	ctx, span := t.tracer.Start(ctx, "gen."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.table", table), attribute.String("db.operation", operation)),
+	)
+	return ctx, &otelSpan{span: span}
+}
+
+type otelSpan struct{ span trace.Span }
+
+func (s *otelSpan) End(rowsAffected int64, err error) {
+	s.span.SetAttributes(attribute.Int64("db.rows_affected", rowsAffected))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,246 @@
+package gen
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// ExportCSV write records matching conds to w as CSV, the header is column names in field order of model,
+// NULL is written as empty cell, time as RFC 3339 and columns with serializer serialized, e.g. PII is encrypted
+func (d *DO) ExportCSV(w io.Writer, conds ...Condition) error {
+	fields, err := d.transferFields(func(f *schema.Field) bool { return f.Readable })
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.DBName
+	}
+	if err = writer.Write(header); err != nil {
+		return err
+	}
+	ctx := d.db.Statement.Context
+	err = d.Where(conds...).(*DO).Each(func(result interface{}) error {
+		rv, record := reflect.ValueOf(result).Elem(), make([]string, len(fields))
+		for i, f := range fields {
+			value, _ := f.ValueOf(ctx, rv)
+			cell, err := csvCell(value)
+			if err != nil {
+				return fmt.Errorf("export column %s fail: %w", f.DBName, err)
+			}
+			record[i] = cell
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportCSV create records of CSV from r in batches and return number of created records, the header of CSV is
+// column names as written by ExportCSV, unknown columns are ignored and empty cells of nullable columns are NULL.
+// Batches are not run in a transaction, wrap the call in Query.Transaction to import all or nothing
+func (d *DO) ImportCSV(r io.Reader, batchSize int) (count int64, err error) {
+	columns, err := d.transferFields(func(f *schema.Field) bool { return f.Creatable })
+	if err != nil {
+		return 0, err
+	}
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("read csv header fail: %w", err)
+	}
+	fields := make([]*schema.Field, len(header))
+	for i, name := range header {
+		for _, f := range columns {
+			if f.DBName == name {
+				fields[i] = f
+			}
+		}
+	}
+
+	ctx := d.db.Statement.Context
+	return d.importRecords(batchSize, func(record reflect.Value) error {
+		cells, err := reader.Read()
+		if err != nil {
+			return err
+		}
+		for i, cell := range cells {
+			if i >= len(fields) || fields[i] == nil {
+				continue
+			}
+			value, null, err := csvValue(fields[i], cell)
+			if err != nil {
+				line, _ := reader.FieldPos(i)
+				return fmt.Errorf("import column %s of line %d fail: %w", fields[i].DBName, line, err)
+			}
+			if null {
+				continue
+			}
+			if fields[i].Serializer != nil { // cell is serialized by ExportCSV, e.g. PII is encrypted
+				err = fields[i].Serializer.Scan(ctx, fields[i], record, value)
+			} else {
+				err = fields[i].Set(ctx, record, value)
+			}
+			if err != nil {
+				return fmt.Errorf("import column %s fail: %w", fields[i].DBName, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ExportJSONLines write records matching conds to w as JSON Lines, one record per line encoded as JSON object keyed
+// by column name as records cached by WithCache, so columns are written regardless of json tags and masking of json
+// output, and columns with serializer are written serialized, e.g. PII is written encrypted instead of masked
+func (d *DO) ExportJSONLines(w io.Writer, conds ...Condition) error {
+	return d.Where(conds...).(*DO).Each(func(result interface{}) error {
+		data, err := d.encodeRecord(result)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	})
+}
+
+// ImportJSONLines create records of JSON Lines written by ExportJSONLines from r in batches and return number of
+// created records, unknown columns are ignored and batches are not run in a transaction as ImportCSV
+func (d *DO) ImportJSONLines(r io.Reader, batchSize int) (count int64, err error) {
+	if d.modelType == nil {
+		return 0, fmt.Errorf("model is not specified")
+	}
+	decoder := json.NewDecoder(r)
+	return d.importRecords(batchSize, func(record reflect.Value) error {
+		var data json.RawMessage
+		if err := decoder.Decode(&data); err != nil {
+			return err
+		}
+		return d.decodeRecord(data, record.Addr().Interface())
+	})
+}
+
+// transferFields return fields of columns of model matching filter in field order
+func (d *DO) transferFields(filter func(*schema.Field) bool) (fields []*schema.Field, err error) {
+	s, err := d.modelSchema()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range s.Fields {
+		if f.DBName != "" && filter(f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// importRecords create records read by read in batches until it returns io.EOF
+func (d *DO) importRecords(batchSize int, read func(record reflect.Value) error) (count int64, err error) {
+	batchSize = d.capBatchSize(batchSize, d.createParams(), 0)
+	batch := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(d.modelType)), 0, batchSize)
+	create := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+		if err := d.Create(batch.Interface()); err != nil {
+			return err
+		}
+		count += int64(batch.Len())
+		batch = batch.Slice(0, 0)
+		return nil
+	}
+	for {
+		record := reflect.New(d.modelType)
+		if err = read(record.Elem()); errors.Is(err, io.EOF) {
+			return count, create()
+		} else if err != nil {
+			return count, err
+		}
+		if batch = reflect.Append(batch, record); batch.Len() == batchSize {
+			if err = create(); err != nil {
+				return count, err
+			}
+		}
+	}
+}
+
+// csvCell format value of field as CSV cell
+func csvCell(value interface{}) (string, error) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", nil
+	}
+	value = rv.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return "", err
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// csvValue parse CSV cell as value of field, null is true if cell is empty and field is nullable,
+// cell of field with serializer is returned as it is to be deserialized
+func csvValue(f *schema.Field, cell string) (value interface{}, null bool, err error) {
+	kind := f.FieldType.Kind()
+	if cell == "" && (kind == reflect.Ptr || kind == reflect.Slice || reflect.PtrTo(f.FieldType).Implements(scannerType)) {
+		return nil, true, nil
+	}
+	if f.Serializer != nil {
+		return cell, false, nil
+	}
+	switch f.DataType {
+	case schema.Time:
+		value, err = time.Parse(time.RFC3339Nano, cell)
+		return value, false, err
+	case schema.Bytes:
+		return []byte(cell), false, nil
+	case schema.Int:
+		value, err = strconv.ParseInt(cell, 10, 64)
+		return value, false, err
+	case schema.Uint:
+		value, err = strconv.ParseUint(cell, 10, 64)
+		return value, false, err
+	case schema.Float:
+		value, err = strconv.ParseFloat(cell, 64)
+		return value, false, err
+	case schema.Bool:
+		value, err = strconv.ParseBool(cell)
+		return value, false, err
+	default:
+		return cell, false, nil
+	}
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
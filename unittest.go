@@ -0,0 +1,67 @@
+package gen
+
+import "fmt"
+
+// databases of generated unit tests, see Config.UnitTestDB
+const (
+	unitTestDBSQLite     = "sqlite"
+	unitTestDBContainers = "testcontainers"
+)
+
+// testContainer testcontainers-go module starting database of dialect in generated unit tests
+type testContainer struct {
+	Dialect  string
+	Module   string   // import path of testcontainers-go module
+	Alias    string   // import name of module, which differs from gorm driver
+	Driver   string   // import path of gorm driver
+	Image    string   // docker image
+	Options  []string // options of Run of module
+	ConnArgs []string // args of ConnectionString of container, e.g. parseTime=true
+}
+
+// testContainers dialect -> testcontainers-go module starting it
+var testContainers = map[string]*testContainer{
+	"mysql": {
+		Module:   "github.com/testcontainers/testcontainers-go/modules/mysql",
+		Alias:    "tcmysql",
+		Driver:   "mysql",
+		Image:    "mysql:8.0",
+		Options:  []string{`tcmysql.WithDatabase("gen")`, `tcmysql.WithUsername("gen")`, `tcmysql.WithPassword("gen")`},
+		ConnArgs: []string{"parseTime=true"},
+	},
+	"postgres": {
+		Module:   "github.com/testcontainers/testcontainers-go/modules/postgres",
+		Alias:    "tcpostgres",
+		Driver:   "postgres",
+		Image:    "postgres:16-alpine",
+		Options:  []string{`tcpostgres.WithDatabase("gen")`, `tcpostgres.WithUsername("gen")`, `tcpostgres.WithPassword("gen")`, "tcpostgres.BasicWaitStrategies()"},
+		ConnArgs: []string{"sslmode=disable"},
+	},
+	"sqlserver": {
+		Module:  "github.com/testcontainers/testcontainers-go/modules/mssql",
+		Alias:   "tcmssql",
+		Driver:  "sqlserver",
+		Image:   "mcr.microsoft.com/mssql/server:2022-latest",
+		Options: []string{"tcmssql.WithAcceptEULA()", `tcmssql.WithPassword("Gen-Test-Passw0rd")`},
+	},
+}
+
+// imports import paths of module and gorm driver
+func (c *testContainer) imports() []string {
+	return []string{c.Alias + ` "` + c.Module + `"`, "gorm.io/driver/" + c.Driver}
+}
+
+// unitTestContainer container of dialect used by generated unit tests, nil if they use SQLite file
+func (g *Generator) unitTestContainer() *testContainer {
+	if g.UnitTestDB != unitTestDBContainers {
+		return nil
+	}
+	c, ok := testContainers[g.dialect()]
+	if !ok {
+		g.info(fmt.Sprintf("unit tests use sqlite as dialect %s isn't supported by testcontainers", g.dialect()))
+		return nil
+	}
+	container := *c
+	container.Dialect = g.dialect()
+	return &container
+}
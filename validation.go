@@ -0,0 +1,71 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationRule constraint of column violated by field value
+type ValidationRule string
+
+const (
+	// ValidationRequired zero value of non-pointer field of NOT NULL column without default value
+	ValidationRequired ValidationRule = "required"
+	// ValidationMaxLength value longer than length of char/varchar column
+	ValidationMaxLength ValidationRule = "max_length"
+	// ValidationMin value less than min of tinyint/smallint/mediumint or unsigned integer column
+	ValidationMin ValidationRule = "min"
+	// ValidationMax value greater than max of tinyint/smallint/mediumint or unsigned integer column
+	ValidationMax ValidationRule = "max"
+	// ValidationEnum value is not one of values of enum column
+	ValidationEnum ValidationRule = "enum"
+)
+
+// ValidationError field of model violating constraint of its column, returned by generated Validate method in ValidationErrors
+type ValidationError struct {
+	Model  string
+	Field  string
+	Column string
+	Rule   ValidationRule
+	Limit  interface{} // max length, min or max of column
+	Value  interface{} // invalid value, nil for ValidationRequired and ValidationMaxLength
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	name := e.Model + "." + e.Field
+	switch e.Rule {
+	case ValidationRequired:
+		return fmt.Sprintf("%s: column %s is required", name, e.Column)
+	case ValidationMaxLength:
+		return fmt.Sprintf("%s: value exceeds max length %v of column %s", name, e.Limit, e.Column)
+	case ValidationMin:
+		return fmt.Sprintf("%s: value %v is less than min %v of column %s", name, e.Value, e.Limit, e.Column)
+	case ValidationMax:
+		return fmt.Sprintf("%s: value %v is greater than max %v of column %s", name, e.Value, e.Limit, e.Column)
+	case ValidationEnum:
+		return fmt.Sprintf("%s: value %v is not one of values of column %s", name, e.Value, e.Column)
+	default:
+		return fmt.Sprintf("%s: value violates %s of column %s", name, e.Rule, e.Column)
+	}
+}
+
+// ValidationErrors all violations of a model, use errors.As to read it from error returned by Validate
+type ValidationErrors []*ValidationError
+
+// Error implements error
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Err return errs as error, nil if it's empty
+func (errs ValidationErrors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,197 @@
+package gen
+
+import (
+	"fmt"
+	"go/build/constraint"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// verify modes, see Config.Verify
+const verifyBuild = "build"
+
+// maxVerifyTags max number of tags of BuildTags tried to find tags satisfying it
+const maxVerifyTags = 10
+
+// verify type-check packages of generated go files in-process, errors are reported with table and templates of
+// offending files, so broken output fails generation instead of next go build
+func (g *Generator) verify() error {
+	if g.Verify != verifyBuild {
+		return nil
+	}
+	summary := g.Summary()
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, fileName := range append(summary.Written, summary.Skipped...) {
+		if !strings.HasSuffix(fileName, ".go") || strings.HasSuffix(fileName, "_test.go") {
+			continue
+		}
+		dir, err := filepath.Abs(filepath.Dir(fileName))
+		if err != nil {
+			return err
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+	sort.Strings(dirs)
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedSyntax,
+		Dir:  dirs[0],
+		Fset: fset,
+	}
+	if tags := verifyTags(g.BuildTags); len(tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(tags, ",")}
+	}
+	pkgs, err := packages.Load(cfg, dirs...)
+	if err != nil {
+		return fmt.Errorf("load generated packages fail: %w", err)
+	}
+
+	tables := g.fileTables()
+	var errs []string
+	report := func(fileName, msg string) {
+		if source := g.fileSource(tables, fileName); source != "" {
+			msg += " (" + source + ")"
+		}
+		errs = append(errs, msg)
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			report(strings.SplitN(e.Pos, ":", 2)[0], e.Error())
+		}
+	}
+	// packages are type-checked by go/types from syntax instead of by go/packages, which fails to read export data
+	// and sizes of newer go toolchains
+	c := &typeChecker{fset: fset, roots: make(map[*packages.Package]bool), checked: make(map[string]*types.Package)}
+	c.report = func(err error) {
+		if e, ok := err.(types.Error); ok {
+			report(fset.Position(e.Pos).Filename, e.Error())
+		}
+	}
+	for _, pkg := range pkgs {
+		c.roots[pkg] = true
+	}
+	for _, pkg := range pkgs {
+		c.check(pkg)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("generated code doesn't build:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+	g.info(fmt.Sprintf("verify build of %d packages: ok", len(pkgs)))
+	return nil
+}
+
+// typeChecker type-check packages loaded with syntax, errors of roots are reported and dependencies are checked
+// without function bodies
+type typeChecker struct {
+	fset    *token.FileSet
+	roots   map[*packages.Package]bool
+	report  func(error)
+	checked map[string]*types.Package
+}
+
+func (c *typeChecker) check(pkg *packages.Package) *types.Package {
+	if pkg.PkgPath == "unsafe" {
+		return types.Unsafe
+	}
+	if p, ok := c.checked[pkg.PkgPath]; ok {
+		return p
+	}
+	root := c.roots[pkg]
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if imp, ok := pkg.Imports[path]; ok {
+				return c.check(imp), nil
+			}
+			return nil, fmt.Errorf("package %s is not imported by %s", path, pkg.PkgPath)
+		}),
+		IgnoreFuncBodies: !root,
+		Sizes:            types.SizesFor("gc", runtime.GOARCH),
+		Error: func(err error) {
+			if root {
+				c.report(err)
+			}
+		},
+	}
+	p, _ := conf.Check(pkg.PkgPath, c.fset, pkg.Syntax, nil)
+	c.checked[pkg.PkgPath] = p
+	return p
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// fileSource table and templates of generated file, e.g. table users, custom query templates in ./tmpl
+func (g *Generator) fileSource(tables map[string]string, fileName string) string {
+	if fileName == "" {
+		return ""
+	}
+	var source []string
+	if table := tables[manifestFileName(fileName)]; table != "" {
+		source = append(source, "table "+table)
+	}
+
+	dir := filepath.Dir(fileName)
+	modelOutPath, _ := g.getModelOutputPath()
+	switch {
+	case dir == filepath.Clean(g.OutPath) && g.QueryTemplateDir != "":
+		source = append(source, "custom query templates in "+g.QueryTemplateDir)
+	case dir == filepath.Clean(g.OutPath):
+		source = append(source, "built-in query templates")
+	case modelOutPath != "" && strings.HasPrefix(dir+string(filepath.Separator), modelOutPath) && g.ModelTemplateDir != "":
+		source = append(source, "custom model templates in "+g.ModelTemplateDir)
+	case modelOutPath != "" && strings.HasPrefix(dir+string(filepath.Separator), modelOutPath):
+		source = append(source, "built-in model templates")
+	}
+	return strings.Join(source, ", ")
+}
+
+// verifyTags tags satisfying build constraint of generated files, nil if there is none or too many tags to try
+func verifyTags(buildTags string) []string {
+	expr, err := constraint.Parse("//go:build " + strings.TrimSpace(buildTags))
+	if strings.TrimSpace(buildTags) == "" || err != nil {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	expr.Eval(func(tag string) bool {
+		if !seen[tag] {
+			seen[tag] = true
+			names = append(names, tag)
+		}
+		return false
+	})
+	sort.Strings(names)
+	if len(names) > maxVerifyTags {
+		return nil
+	}
+	for set := 0; set < 1<<len(names); set++ {
+		var tags []string
+		on := make(map[string]bool)
+		for i, name := range names {
+			if set&(1<<i) != 0 {
+				on[name] = true
+				tags = append(tags, name)
+			}
+		}
+		if expr.Eval(func(tag string) bool { return on[tag] }) {
+			return tags
+		}
+	}
+	return nil
+}
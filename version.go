@@ -0,0 +1,72 @@
+package gen
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const versionSettingKey = "gen:version_column"
+
+// UseVersion increase version column by 1 in statements updating records by columns or expressions,
+// e.g. Update/UpdateSimple/UpdateColumn/UpdateColumns(map), so records read before fail the optimistic locking of
+// generated Updates/Save with ErrStaleObject. Updates by struct are left alone, the struct carries its version.
+// Statements of d fail with the returned error if callbacks can't be registered
+func (d *DO) UseVersion(column string) error {
+	if err := registerVersionCallbacks(d.db); err != nil {
+		d.db = d.withError(err).db
+		return err
+	}
+	d.versionColumn = column
+	d.db = d.settingDB(d.db)
+	return nil
+}
+
+var versionCallbacks sync.Map // callbacks of db -> struct{}
+
+func registerVersionCallbacks(db *gorm.DB) error {
+	if _, loaded := versionCallbacks.LoadOrStore(db.Callback(), struct{}{}); loaded {
+		return nil
+	}
+	return db.Callback().Update().Before("gorm:update").Register("gen:version_update", increaseVersion)
+}
+
+func increaseVersion(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	v, ok := db.Get(versionSettingKey)
+	if !ok {
+		return
+	}
+	f := db.Statement.Schema.LookUpField(v.(string))
+	if f == nil {
+		return
+	}
+	increase := clause.Expr{SQL: "? + 1", Vars: []interface{}{clause.Column{Name: f.DBName}}}
+
+	// assignments are built from SET clause instead of dest when it exists
+	if c, exist := db.Statement.Clauses["SET"]; exist {
+		set, isSet := c.Expression.(clause.Set)
+		if !isSet {
+			return
+		}
+		for _, assignment := range set {
+			if assignment.Column.Name == f.DBName {
+				return
+			}
+		}
+		c.Expression = append(set, clause.Assignment{Column: clause.Column{Name: f.DBName}, Value: increase})
+		db.Statement.Clauses["SET"] = c
+		return
+	}
+	if dest, isMap := db.Statement.Dest.(map[string]interface{}); isMap {
+		for k := range dest {
+			if k == f.DBName || k == f.Name {
+				return
+			}
+		}
+		db.Statement.SetColumn(f.DBName, increase, true)
+	}
+}